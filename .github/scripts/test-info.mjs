@@ -172,6 +172,11 @@ const components = {
         conformanceSetup: 'docker-compose.sh vernemq',
         sourcePkg: ['bindings/mqtt3'],
     },
+    'bindings.mqtt5-mosquitto': {
+        conformance: true,
+        conformanceSetup: 'docker-compose.sh mosquitto',
+        sourcePkg: ['bindings/mqtt5'],
+    },
     'bindings.postgres': {
         certification: true,
     },
@@ -14,6 +14,7 @@ limitations under the License.
 package s3
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	b64 "encoding/base64"
@@ -24,11 +25,13 @@ import (
 	"net/http"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 
@@ -54,6 +57,11 @@ const (
 
 	metatadataContentType = "Content-Type"
 	metadataKey           = "key"
+	metadataPresignMethod = "presignMethod"
+	metadataPresignURL    = "presignURL"
+	metadataExpiresAt     = "expiresAt"
+	metadataIsTruncated   = "isTruncated"
+	metadataNextMarker    = "nextMarker"
 
 	defaultMaxResults = 1000
 	presignOperation  = "presign"
@@ -85,6 +93,8 @@ type s3Metadata struct {
 	FilePath       string `json:"filePath" mapstructure:"filePath"   mdignore:"true"`
 	PresignTTL     string `json:"presignTTL" mapstructure:"presignTTL"  mdignore:"true"`
 	StorageClass   string `json:"storageClass" mapstructure:"storageClass"  mdignore:"true"`
+	PartSize       int64  `json:"partSize,string" mapstructure:"partSize"`
+	Concurrency    int    `json:"concurrency,string" mapstructure:"concurrency"`
 }
 
 type createResponse struct {
@@ -142,7 +152,10 @@ func (s *AWSS3) Init(_ context.Context, metadata bindings.Metadata) error {
 	s.metadata = m
 	s.s3Client = s3.New(session, cfg)
 	s.downloader = s3manager.NewDownloaderWithClient(s.s3Client)
-	s.uploader = s3manager.NewUploaderWithClient(s.s3Client)
+	s.uploader = s3manager.NewUploaderWithClient(s.s3Client, func(u *s3manager.Uploader) {
+		u.PartSize = m.PartSize
+		u.Concurrency = m.Concurrency
+	})
 
 	return nil
 }
@@ -185,12 +198,14 @@ func (s *AWSS3) create(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 	}
 	var r io.Reader
 	if metadata.FilePath != "" {
-		r, err = os.Open(metadata.FilePath)
+		f, err := os.Open(metadata.FilePath)
 		if err != nil {
 			return nil, fmt.Errorf("s3 binding error: file read error: %w", err)
 		}
+		defer f.Close()
+		r = f
 	} else {
-		r = strings.NewReader(commonutils.Unquote(req.Data))
+		r = uploadBody(req.Data)
 	}
 
 	if metadata.DecodeBase64 {
@@ -215,7 +230,7 @@ func (s *AWSS3) create(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 
 	var presignURL string
 	if metadata.PresignTTL != "" {
-		url, presignErr := s.presignObject(metadata.Bucket, key, metadata.PresignTTL)
+		url, _, presignErr := s.presignObject(metadata.Bucket, key, metadata.PresignTTL, http.MethodGet, "")
 		if presignErr != nil {
 			return nil, fmt.Errorf("s3 binding error: %s", presignErr)
 		}
@@ -240,6 +255,17 @@ func (s *AWSS3) create(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 	}, nil
 }
 
+// uploadBody returns the reader for the data of a create request.
+// JSON strings and other JSON scalars are unquoted. Any other data, such as binary content or JSON documents, is read in place without being copied,
+// so large objects are uploaded part by part directly from the request data.
+func uploadBody(data []byte) io.Reader {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] == '{' || trimmed[0] == '[' || !json.Valid(trimmed) {
+		return bytes.NewReader(data)
+	}
+	return strings.NewReader(commonutils.Unquote(data))
+}
+
 func (s *AWSS3) presign(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
 	metadata, err := s.metadata.mergeWithRequestMetadata(req)
 	if err != nil {
@@ -255,7 +281,17 @@ func (s *AWSS3) presign(ctx context.Context, req *bindings.InvokeRequest) (*bind
 		return nil, fmt.Errorf("s3 binding error: required metadata '%s' missing", metadataPresignTTL)
 	}
 
-	url, err := s.presignObject(metadata.Bucket, key, metadata.PresignTTL)
+	method := strings.ToUpper(req.Metadata[metadataPresignMethod])
+	if method == "" {
+		method = http.MethodGet
+	}
+	// For uploads, the content type is signed if set, so clients must send the same Content-Type header
+	var contentType string
+	if method == http.MethodPut {
+		contentType = strings.TrimSpace(req.Metadata[metatadataContentType])
+	}
+
+	url, expiresAt, err := s.presignObject(metadata.Bucket, key, metadata.PresignTTL, method, contentType)
 	if err != nil {
 		return nil, fmt.Errorf("s3 binding error: %w", err)
 	}
@@ -269,25 +305,49 @@ func (s *AWSS3) presign(ctx context.Context, req *bindings.InvokeRequest) (*bind
 
 	return &bindings.InvokeResponse{
 		Data: jsonResponse,
+		Metadata: map[string]string{
+			metadataKey:           key,
+			metadataPresignURL:    url,
+			metadataPresignMethod: method,
+			metadataExpiresAt:     expiresAt.UTC().Format(time.RFC3339),
+		},
 	}, nil
 }
 
-func (s *AWSS3) presignObject(bucket, key, ttl string) (string, error) {
+// presignObject returns a URL that allows downloading (GET) or uploading (PUT) the object without credentials until it expires, and the expiration time.
+func (s *AWSS3) presignObject(bucket, key, ttl, method, contentType string) (string, time.Time, error) {
 	d, err := time.ParseDuration(ttl)
 	if err != nil {
-		return "", fmt.Errorf("s3 binding error: cannot parse duration %s: %w", ttl, err)
+		return "", time.Time{}, fmt.Errorf("s3 binding error: cannot parse duration %s: %w", ttl, err)
 	}
 
-	objReq, _ := s.s3Client.GetObjectRequest(&s3.GetObjectInput{
-		Bucket: ptr.Of(bucket),
-		Key:    ptr.Of(key),
-	})
+	var objReq *request.Request
+	switch method {
+	case http.MethodGet:
+		objReq, _ = s.s3Client.GetObjectRequest(&s3.GetObjectInput{
+			Bucket: ptr.Of(bucket),
+			Key:    ptr.Of(key),
+		})
+	case http.MethodPut:
+		input := &s3.PutObjectInput{
+			Bucket: ptr.Of(bucket),
+			Key:    ptr.Of(key),
+		}
+		if contentType != "" {
+			input.ContentType = ptr.Of(contentType)
+		}
+		objReq, _ = s.s3Client.PutObjectRequest(input)
+	default:
+		return "", time.Time{}, fmt.Errorf("s3 binding error: unsupported presign method %s (must be %s or %s)", method, http.MethodGet, http.MethodPut)
+	}
+
+	expiresAt := time.Now().Add(d)
 	url, err := objReq.Presign(d)
 	if err != nil {
-		return "", fmt.Errorf("s3 binding error: failed to presign URL: %w", err)
+		return "", time.Time{}, fmt.Errorf("s3 binding error: failed to presign URL: %w", err)
 	}
 
-	return url, nil
+	return url, expiresAt, nil
 }
 
 func (s *AWSS3) get(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
@@ -384,11 +444,37 @@ func (s *AWSS3) list(ctx context.Context, req *bindings.InvokeRequest) (*binding
 		return nil, fmt.Errorf("s3 binding error: list operation: cannot marshal list to json: %w", err)
 	}
 
+	md := map[string]string{
+		metadataIsTruncated: strconv.FormatBool(aws.BoolValue(result.IsTruncated)),
+	}
+	if aws.BoolValue(result.IsTruncated) {
+		md[metadataNextMarker] = nextListMarker(result)
+	}
+
 	return &bindings.InvokeResponse{
-		Data: jsonResponse,
+		Data:     jsonResponse,
+		Metadata: md,
 	}, nil
 }
 
+// nextListMarker returns the marker to request the page after a truncated list result.
+// S3 returns NextMarker only when a delimiter is set; otherwise, it's the last key in the page.
+func nextListMarker(result *s3.ListObjectsOutput) string {
+	if marker := aws.StringValue(result.NextMarker); marker != "" {
+		return marker
+	}
+	var last string
+	if n := len(result.Contents); n > 0 {
+		last = aws.StringValue(result.Contents[n-1].Key)
+	}
+	if n := len(result.CommonPrefixes); n > 0 {
+		if prefix := aws.StringValue(result.CommonPrefixes[n-1].Prefix); prefix > last {
+			last = prefix
+		}
+	}
+	return last
+}
+
 func (s *AWSS3) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
 	switch req.Operation {
 	case bindings.CreateOperation:
@@ -407,11 +493,20 @@ func (s *AWSS3) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 }
 
 func (s *AWSS3) parseMetadata(md bindings.Metadata) (*s3Metadata, error) {
-	var m s3Metadata
+	m := s3Metadata{
+		PartSize:    s3manager.DefaultUploadPartSize,
+		Concurrency: s3manager.DefaultUploadConcurrency,
+	}
 	err := kitmd.DecodeMetadata(md.Properties, &m)
 	if err != nil {
 		return nil, err
 	}
+	if m.PartSize < s3manager.MinUploadPartSize {
+		return nil, fmt.Errorf("s3 binding error: partSize must be at least %d bytes", s3manager.MinUploadPartSize)
+	}
+	if m.Concurrency < 1 {
+		return nil, errors.New("s3 binding error: concurrency must be at least 1")
+	}
 	return &m, nil
 }
 
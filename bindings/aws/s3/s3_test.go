@@ -14,13 +14,25 @@ limitations under the License.
 package s3
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/kit/logger"
 )
 
@@ -51,6 +63,31 @@ func TestParseMetadata(t *testing.T) {
 		assert.True(t, meta.ForcePathStyle)
 		assert.True(t, meta.DisableSSL)
 		assert.True(t, meta.InsecureSSL)
+		assert.Equal(t, int64(5*1024*1024), meta.PartSize)
+		assert.Equal(t, 5, meta.Concurrency)
+	})
+
+	t.Run("Has multipart upload metadata", func(t *testing.T) {
+		m := bindings.Metadata{}
+		m.Properties = map[string]string{
+			"bucket":      "test",
+			"partSize":    "10485760",
+			"concurrency": "2",
+		}
+		s3 := AWSS3{}
+		meta, err := s3.parseMetadata(m)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(10*1024*1024), meta.PartSize)
+		assert.Equal(t, 2, meta.Concurrency)
+	})
+
+	t.Run("Has invalid multipart upload metadata", func(t *testing.T) {
+		s3 := AWSS3{}
+		_, err := s3.parseMetadata(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"partSize": "1024"}}})
+		require.ErrorContains(t, err, "partSize must be at least")
+		_, err = s3.parseMetadata(bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"concurrency": "0"}}})
+		require.ErrorContains(t, err, "concurrency must be at least 1")
 	})
 }
 
@@ -191,3 +228,237 @@ func TestDeleteOption(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestUploadBody(t *testing.T) {
+	tests := map[string]struct {
+		data     string
+		expected string
+	}{
+		"JSON string": {data: `"hello world"`, expected: "hello world"},
+		"JSON number": {data: `42`, expected: "42"},
+		"JSON object": {data: `{"a": "b"}`, expected: `{"a": "b"}`},
+		"JSON array":  {data: ` ["a"]`, expected: ` ["a"]`},
+		"plain text":  {data: `hello world`, expected: "hello world"},
+		"binary":      {data: "\x00\xff\x10", expected: "\x00\xff\x10"},
+		"empty":       {data: "", expected: ""},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := io.ReadAll(uploadBody([]byte(tc.data)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(res))
+		})
+	}
+}
+
+func TestCreateMultipart(t *testing.T) {
+	// Minimal S3 endpoint that records the size of each uploaded part
+	var (
+		lock      sync.Mutex
+		parts     = map[string]int{}
+		completed bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		q := r.URL.Query()
+		lock.Lock()
+		defer lock.Unlock()
+		switch {
+		case r.Method == http.MethodPost && q.Has("uploads"):
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>test</Bucket><Key>big</Key><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == http.MethodPut && q.Get("uploadId") == "upload-1":
+			parts[q.Get("partNumber")] = len(body)
+			w.Header().Set("ETag", `"etag-`+q.Get("partNumber")+`"`)
+		case r.Method == http.MethodPost && q.Get("uploadId") == "upload-1":
+			completed = true
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><Bucket>test</Bucket><Key>big</Key></CompleteMultipartUploadResult>`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	s3 := initTestBinding(t, srv.URL, map[string]string{
+		"partSize":    "5242880",
+		"concurrency": "2",
+	})
+
+	data := bytes.Repeat([]byte("x"), 12*1024*1024)
+	res, err := s3.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      data,
+		Metadata:  map[string]string{"key": "big"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(res.Data), "/test/big")
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.True(t, completed)
+	assert.Equal(t, map[string]int{"1": 5 * 1024 * 1024, "2": 5 * 1024 * 1024, "3": 2 * 1024 * 1024}, parts)
+}
+
+func TestPresign(t *testing.T) {
+	s3 := initTestBinding(t, "http://127.0.0.1:4566", nil)
+
+	presign := func(md map[string]string) (*bindings.InvokeResponse, error) {
+		return s3.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: presignOperation,
+			Metadata:  md,
+		})
+	}
+
+	t.Run("GET", func(t *testing.T) {
+		res, err := presign(map[string]string{"key": "file.txt", "presignTTL": "15m"})
+		require.NoError(t, err)
+		u, err := url.Parse(res.Metadata["presignURL"])
+		require.NoError(t, err)
+		assert.Equal(t, "/test/file.txt", u.Path)
+		assert.Equal(t, "900", u.Query().Get("X-Amz-Expires"))
+		assert.Equal(t, "host", u.Query().Get("X-Amz-SignedHeaders"))
+		assert.Equal(t, http.MethodGet, res.Metadata["presignMethod"])
+		expiresAt, err := time.Parse(time.RFC3339, res.Metadata["expiresAt"])
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), expiresAt, time.Minute)
+		assert.JSONEq(t, `{"presignURL": "`+res.Metadata["presignURL"]+`"}`, string(res.Data))
+	})
+
+	t.Run("PUT with content type", func(t *testing.T) {
+		res, err := presign(map[string]string{"key": "upload.png", "presignTTL": "1h", "presignMethod": "put", "Content-Type": "image/png"})
+		require.NoError(t, err)
+		u, err := url.Parse(res.Metadata["presignURL"])
+		require.NoError(t, err)
+		assert.Equal(t, "3600", u.Query().Get("X-Amz-Expires"))
+		assert.Equal(t, "content-type;host", u.Query().Get("X-Amz-SignedHeaders"))
+		assert.Equal(t, http.MethodPut, res.Metadata["presignMethod"])
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := presign(map[string]string{"key": "file.txt"})
+		require.ErrorContains(t, err, "presignTTL")
+		_, err = presign(map[string]string{"key": "file.txt", "presignTTL": "15m", "presignMethod": "DELETE"})
+		require.ErrorContains(t, err, "unsupported presign method DELETE")
+	})
+}
+
+func initTestBinding(t *testing.T, endpoint string, props map[string]string) *AWSS3 {
+	t.Helper()
+	md := bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"bucket":         "test",
+		"region":         "us-east-1",
+		"endpoint":       endpoint,
+		"accessKey":      "key",
+		"secretKey":      "secret",
+		"forcePathStyle": "true",
+		"disableSSL":     "true",
+	}}}
+	for k, v := range props {
+		md.Properties[k] = v
+	}
+	s3 := NewAWSS3(logger.NewLogger("s3")).(*AWSS3)
+	require.NoError(t, s3.Init(context.Background(), md))
+	return s3
+}
+
+func TestList(t *testing.T) {
+	// Minimal S3 endpoint that lists sorted keys, supporting prefix, delimiter, marker, and max-keys
+	keys := []string{"a.txt", "dir/b.txt", "dir/c.txt", "dir/sub/d.txt", "e.txt"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		maxKeys, _ := strconv.Atoi(q.Get("max-keys"))
+		var (
+			res      strings.Builder
+			prefixes = map[string]bool{}
+			count    int
+			last     string
+		)
+		truncated := false
+		for _, k := range keys {
+			if !strings.HasPrefix(k, q.Get("prefix")) || k <= q.Get("marker") {
+				continue
+			}
+			entry := k
+			if d := q.Get("delimiter"); d != "" {
+				if i := strings.Index(k[len(q.Get("prefix")):], d); i >= 0 {
+					entry = k[:len(q.Get("prefix"))+i+len(d)]
+					if prefixes[entry] || entry <= q.Get("marker") {
+						continue
+					}
+				}
+			}
+			if count == maxKeys {
+				truncated = true
+				break
+			}
+			count++
+			last = entry
+			if entry != k {
+				prefixes[entry] = true
+				fmt.Fprintf(&res, "<CommonPrefixes><Prefix>%s</Prefix></CommonPrefixes>", entry)
+			} else {
+				fmt.Fprintf(&res, `<Contents><Key>%s</Key><Size>%d</Size><ETag>"etag-%s"</ETag></Contents>`, k, len(k), k)
+			}
+		}
+		nextMarker := ""
+		if truncated && q.Get("delimiter") != "" {
+			nextMarker = "<NextMarker>" + last + "</NextMarker>"
+		}
+		fmt.Fprintf(w, "<ListBucketResult><Name>test</Name><IsTruncated>%t</IsTruncated>%s%s</ListBucketResult>", truncated, nextMarker, res.String())
+	}))
+	defer srv.Close()
+
+	s3 := initTestBinding(t, srv.URL, nil)
+
+	// listAll returns the keys and common prefixes of all pages, following the nextMarker metadata
+	listAll := func(t *testing.T, payload listPayload) (entries []string, pages int) {
+		for {
+			data, err := json.Marshal(payload)
+			require.NoError(t, err)
+			res, err := s3.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.ListOperation, Data: data})
+			require.NoError(t, err)
+			pages++
+
+			var out struct {
+				Contents []struct {
+					Key  string
+					Size int64
+					ETag string
+				}
+				CommonPrefixes []struct{ Prefix string }
+			}
+			require.NoError(t, json.Unmarshal(res.Data, &out))
+			for _, c := range out.Contents {
+				assert.Equal(t, int64(len(c.Key)), c.Size)
+				assert.Equal(t, `"etag-`+c.Key+`"`, c.ETag)
+				entries = append(entries, c.Key)
+			}
+			for _, p := range out.CommonPrefixes {
+				entries = append(entries, p.Prefix)
+			}
+
+			if res.Metadata["isTruncated"] != "true" {
+				assert.Empty(t, res.Metadata["nextMarker"])
+				return entries, pages
+			}
+			require.NotEmpty(t, res.Metadata["nextMarker"])
+			payload.Marker = res.Metadata["nextMarker"]
+		}
+	}
+
+	t.Run("all pages", func(t *testing.T) {
+		entries, pages := listAll(t, listPayload{MaxResults: 2})
+		assert.Equal(t, keys, entries)
+		assert.Equal(t, 3, pages)
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		entries, pages := listAll(t, listPayload{MaxResults: 2, Prefix: "dir/"})
+		assert.Equal(t, []string{"dir/b.txt", "dir/c.txt", "dir/sub/d.txt"}, entries)
+		assert.Equal(t, 2, pages)
+	})
+
+	t.Run("delimiter", func(t *testing.T) {
+		entries, _ := listAll(t, listPayload{MaxResults: 1, Delimiter: "/"})
+		assert.Equal(t, []string{"a.txt", "dir/", "e.txt"}, entries)
+	})
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
 	"github.com/google/uuid"
 
 	"github.com/dapr/components-contrib/bindings"
@@ -67,6 +68,8 @@ var ErrMissingBlobName = errors.New("blobName is a required attribute")
 type AzureBlobStorage struct {
 	metadata        *storagecommon.BlobStorageMetadata
 	containerClient *container.Client
+	// Used to request user delegation keys for SAS, with Azure AD authentication only
+	serviceClient *service.Client
 
 	logger logger.Logger
 }
@@ -103,7 +106,7 @@ func (a *AzureBlobStorage) Init(ctx context.Context, metadata bindings.Metadata)
 	if err != nil {
 		return err
 	}
-	return nil
+	return a.initSASServiceClient(metadata.Properties)
 }
 
 func (a *AzureBlobStorage) Operations() []bindings.OperationKind {
@@ -112,6 +115,9 @@ func (a *AzureBlobStorage) Operations() []bindings.OperationKind {
 		bindings.GetOperation,
 		bindings.DeleteOperation,
 		bindings.ListOperation,
+		sasOperation,
+		stageBlockOperation,
+		commitBlockListOperation,
 	}
 }
 
@@ -128,39 +134,47 @@ func (a *AzureBlobStorage) create(ctx context.Context, req *bindings.InvokeReque
 		blobName = id.String()
 	}
 
+	blobType := req.Metadata[metadataKeyBlobType]
+	delete(req.Metadata, metadataKeyBlobType)
+	if blobType != "" && blobType != blobTypeBlock && blobType != blobTypeAppend {
+		return nil, fmt.Errorf("invalid %s: %s; allowed: %s, %s", metadataKeyBlobType, blobType, blobTypeBlock, blobTypeAppend)
+	}
+
 	blobHTTPHeaders, err := storagecommon.CreateBlobHTTPHeadersFromRequest(req.Metadata, nil, a.logger)
 	if err != nil {
 		return nil, err
 	}
 
-	d, err := strconv.Unquote(string(req.Data))
-	if err == nil {
-		req.Data = []byte(d)
+	data, err := a.requestData(req)
+	if err != nil {
+		return nil, err
 	}
 
-	if a.metadata.DecodeBase64 {
-		decoded, decodeError := b64.StdEncoding.DecodeString(string(req.Data))
-		if decodeError != nil {
-			return nil, decodeError
+	var blobURL string
+	if blobType == blobTypeAppend {
+		blobURL, err = a.appendBlob(ctx, blobName, data, blobHTTPHeaders, storagecommon.SanitizeMetadata(a.logger, req.Metadata))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		uploadOptions := azblob.UploadBufferOptions{
+			BlockSize:               a.metadata.BlockSize,
+			Metadata:                storagecommon.SanitizeMetadata(a.logger, req.Metadata),
+			HTTPHeaders:             &blobHTTPHeaders,
+			TransactionalContentMD5: blobHTTPHeaders.BlobContentMD5,
 		}
-		req.Data = decoded
-	}
-
-	uploadOptions := azblob.UploadBufferOptions{
-		Metadata:                storagecommon.SanitizeMetadata(a.logger, req.Metadata),
-		HTTPHeaders:             &blobHTTPHeaders,
-		TransactionalContentMD5: blobHTTPHeaders.BlobContentMD5,
-	}
 
-	blockBlobClient := a.containerClient.NewBlockBlobClient(blobName)
-	_, err = blockBlobClient.UploadBuffer(ctx, req.Data, &uploadOptions)
+		blockBlobClient := a.containerClient.NewBlockBlobClient(blobName)
+		_, err = blockBlobClient.UploadBuffer(ctx, data, &uploadOptions)
 
-	if err != nil {
-		return nil, fmt.Errorf("error uploading az blob: %w", err)
+		if err != nil {
+			return nil, fmt.Errorf("error uploading az blob: %w", err)
+		}
+		blobURL = blockBlobClient.URL()
 	}
 
 	resp := createResponse{
-		BlobURL: blockBlobClient.URL(),
+		BlobURL: blobURL,
 	}
 	b, err := json.Marshal(resp)
 	if err != nil {
@@ -177,6 +191,24 @@ func (a *AzureBlobStorage) create(ctx context.Context, req *bindings.InvokeReque
 	}, nil
 }
 
+// requestData returns the data of a request, unquoting JSON strings and decoding base64 if decodeBase64 is enabled.
+func (a *AzureBlobStorage) requestData(req *bindings.InvokeRequest) ([]byte, error) {
+	data := req.Data
+	d, err := strconv.Unquote(string(data))
+	if err == nil {
+		data = []byte(d)
+	}
+
+	if a.metadata.DecodeBase64 {
+		decoded, decodeError := b64.StdEncoding.DecodeString(string(data))
+		if decodeError != nil {
+			return nil, decodeError
+		}
+		data = decoded
+	}
+	return data, nil
+}
+
 func (a *AzureBlobStorage) get(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
 	var blockBlobClient *blockblob.Client
 	if val, ok := req.Metadata[metadataKeyBlobName]; ok && val != "" {
@@ -355,6 +387,12 @@ func (a *AzureBlobStorage) Invoke(ctx context.Context, req *bindings.InvokeReque
 		return a.delete(ctx, req)
 	case bindings.ListOperation:
 		return a.list(ctx, req)
+	case sasOperation:
+		return a.sas(ctx, req)
+	case stageBlockOperation:
+		return a.stageBlock(ctx, req)
+	case commitBlockListOperation:
+		return a.commitBlockList(ctx, req)
 	default:
 		return nil, fmt.Errorf("unsupported operation %s", req.Operation)
 	}
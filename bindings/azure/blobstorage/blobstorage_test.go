@@ -15,11 +15,22 @@ package blobstorage
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/kit/logger"
 )
 
@@ -54,3 +65,172 @@ func TestDeleteOption(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestSASOption(t *testing.T) {
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test")).(*AzureBlobStorage)
+	err := blobStorage.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"accountName":             "account",
+		"accountKey":              base64.StdEncoding.EncodeToString([]byte("key")),
+		"containerName":           "container",
+		"disableEntityManagement": "true",
+	}}})
+	require.NoError(t, err)
+	require.Nil(t, blobStorage.serviceClient)
+
+	sas := func(md map[string]string) (sasResponse, url.Values, error) {
+		var res sasResponse
+		resp, err := blobStorage.Invoke(context.Background(), &bindings.InvokeRequest{Operation: sasOperation, Metadata: md})
+		if err != nil {
+			return res, nil, err
+		}
+		require.NoError(t, json.Unmarshal(resp.Data, &res))
+		u, err := url.Parse(res.SASURL)
+		require.NoError(t, err)
+		return res, u.Query(), nil
+	}
+
+	t.Run("blob SAS", func(t *testing.T) {
+		res, q, err := sas(map[string]string{"blobName": "file.txt", "sasPermissions": "wr", "sasTTL": "15m"})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(res.SASURL, "https://account.blob.core.windows.net/container/file.txt?"))
+		assert.Equal(t, "b", q.Get("sr"))
+		assert.Equal(t, "rw", q.Get("sp"))
+		assert.NotEmpty(t, q.Get("sig"))
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), res.ExpiresOn, time.Minute)
+		se, err := time.Parse(time.RFC3339, q.Get("se"))
+		require.NoError(t, err)
+		assert.WithinDuration(t, res.ExpiresOn, se, time.Second)
+	})
+
+	t.Run("container SAS with defaults", func(t *testing.T) {
+		res, q, err := sas(nil)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(res.SASURL, "https://account.blob.core.windows.net/container?"))
+		assert.Equal(t, "c", q.Get("sr"))
+		assert.Equal(t, "r", q.Get("sp"))
+		assert.WithinDuration(t, time.Now().Add(time.Hour), res.ExpiresOn, time.Minute)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		_, _, err := sas(map[string]string{"sasPermissions": "rz"})
+		require.ErrorContains(t, err, "unsupported permission 'z'")
+		_, _, err = sas(map[string]string{"sasTTL": "-1h"})
+		require.ErrorContains(t, err, "must be a positive duration")
+		_, _, err = sas(map[string]string{"sasTTL": "soon"})
+		require.ErrorContains(t, err, "must be a positive duration")
+	})
+}
+
+func TestBlockOperations(t *testing.T) {
+	// Minimal Blob Storage endpoint that records the written blocks
+	var (
+		lock         sync.Mutex
+		appendBlobs  = map[string][]string{}
+		stagedBlocks = map[string]string{}
+		blockList    string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lock.Lock()
+		defer lock.Unlock()
+		switch q := r.URL.Query(); {
+		case q.Get("comp") == "appendblock":
+			appendBlobs[r.URL.Path] = append(appendBlobs[r.URL.Path], string(body))
+		case q.Get("comp") == "block":
+			stagedBlocks[q.Get("blockid")] = string(body)
+		case q.Get("comp") == "blocklist":
+			blockList = string(body)
+		case r.Header.Get("x-ms-blob-type") == "AppendBlob":
+			if _, ok := appendBlobs[r.URL.Path]; ok {
+				w.Header().Set("x-ms-error-code", "BlobAlreadyExists")
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			appendBlobs[r.URL.Path] = []string{}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	blobStorage := NewAzureBlobStorage(logger.NewLogger("test")).(*AzureBlobStorage)
+	err := blobStorage.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"accountName":             "account",
+		"accountKey":              base64.StdEncoding.EncodeToString([]byte("key")),
+		"containerName":           "container",
+		"endpoint":                srv.URL,
+		"disableEntityManagement": "true",
+		"blockSize":               "4",
+	}}})
+	require.NoError(t, err)
+
+	t.Run("append blob", func(t *testing.T) {
+		for _, data := range []string{"hello world", "!"} {
+			res, err := blobStorage.Invoke(context.Background(), &bindings.InvokeRequest{
+				Operation: bindings.CreateOperation,
+				Data:      []byte(data),
+				Metadata:  map[string]string{"blobName": "log.txt", "blobType": "append"},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "log.txt", res.Metadata["blobName"])
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		assert.Equal(t, []string{"hell", "o wo", "rld", "!"}, appendBlobs["/account/container/log.txt"])
+	})
+
+	t.Run("invalid blob type", func(t *testing.T) {
+		_, err := blobStorage.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Metadata:  map[string]string{"blobName": "log.txt", "blobType": "page"},
+		})
+		require.ErrorContains(t, err, "invalid blobType")
+	})
+
+	t.Run("stage and commit blocks", func(t *testing.T) {
+		for id, data := range map[string]string{"block-1": "hello ", "block-2": "world"} {
+			res, err := blobStorage.Invoke(context.Background(), &bindings.InvokeRequest{
+				Operation: stageBlockOperation,
+				Data:      []byte(data),
+				Metadata:  map[string]string{"blobName": "big.txt", "blockId": id},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, id, res.Metadata["blockId"])
+		}
+		res, err := blobStorage.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: commitBlockListOperation,
+			Data:      []byte(`{"blockIds": ["block-1", "block-2"]}`),
+			Metadata:  map[string]string{"blobName": "big.txt", "contentType": "text/plain"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, string(res.Data), "/account/container/big.txt")
+
+		id1 := base64.StdEncoding.EncodeToString([]byte("block-1"))
+		id2 := base64.StdEncoding.EncodeToString([]byte("block-2"))
+		lock.Lock()
+		defer lock.Unlock()
+		assert.Equal(t, map[string]string{id1: "hello ", id2: "world"}, stagedBlocks)
+		assert.Contains(t, blockList, "<Latest>"+id1+"</Latest><Latest>"+id2+"</Latest>")
+	})
+
+	t.Run("missing block metadata", func(t *testing.T) {
+		_, err := blobStorage.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: stageBlockOperation,
+			Metadata:  map[string]string{"blobName": "big.txt"},
+		})
+		require.ErrorIs(t, err, ErrMissingBlockID)
+		_, err = blobStorage.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: commitBlockListOperation,
+			Data:      []byte(`{"blockIds": []}`),
+			Metadata:  map[string]string{"blobName": "big.txt"},
+		})
+		require.ErrorContains(t, err, "blockIds must not be empty")
+		_, err = blobStorage.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: commitBlockListOperation,
+			Data:      []byte(`{"blockIds": ["block-1"]}`),
+		})
+		require.ErrorIs(t, err, ErrMissingBlobName)
+	})
+}
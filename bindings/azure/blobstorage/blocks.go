@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobstorage
+
+import (
+	"bytes"
+	"context"
+	b64 "encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/appendblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+
+	"github.com/dapr/components-contrib/bindings"
+	storagecommon "github.com/dapr/components-contrib/common/component/azure/blobstorage"
+	"github.com/dapr/kit/ptr"
+)
+
+const (
+	stageBlockOperation      bindings.OperationKind = "stageBlock"
+	commitBlockListOperation bindings.OperationKind = "commitBlockList"
+
+	// Type of the blob written by the create operation: "block" (the default) or "append".
+	// With "append", the data is appended to the blob, which is created if it doesn't exist.
+	metadataKeyBlobType = "blobType"
+	// ID of the block uploaded by the stageBlock operation. All blocks of a blob must have IDs of the same length.
+	metadataKeyBlockID = "blockId"
+
+	blobTypeBlock  = "block"
+	blobTypeAppend = "append"
+
+	// Maximum size of the data appended with a single request, used when blockSize is not set.
+	// See: https://learn.microsoft.com/en-us/rest/api/storageservices/append-block#remarks
+	defaultAppendBlockBytes = 4 * 1024 * 1024
+	maxAppendBlockBytes     = 100 * 1024 * 1024
+)
+
+var ErrMissingBlockID = errors.New("blockId is a required attribute")
+
+type commitBlockListPayload struct {
+	BlockIDs []string `json:"blockIds"`
+}
+
+// appendBlob appends the data to the append blob, creating it with the headers and metadata if it doesn't exist.
+// Data larger than blockSize is appended with multiple requests, so other writers could append data in between.
+func (a *AzureBlobStorage) appendBlob(ctx context.Context, blobName string, data []byte, headers blob.HTTPHeaders, metadata map[string]*string) (string, error) {
+	appendBlobClient := a.containerClient.NewAppendBlobClient(blobName)
+	_, err := appendBlobClient.Create(ctx, &appendblob.CreateOptions{
+		HTTPHeaders: &headers,
+		Metadata:    metadata,
+		AccessConditions: &blob.AccessConditions{
+			ModifiedAccessConditions: &blob.ModifiedAccessConditions{
+				IfNoneMatch: ptr.Of(azcore.ETagAny),
+			},
+		},
+	})
+	if err != nil && !bloberror.HasCode(err, bloberror.BlobAlreadyExists) {
+		return "", fmt.Errorf("error creating append blob: %w", err)
+	}
+
+	blockSize := int(a.metadata.BlockSize)
+	if blockSize == 0 {
+		blockSize = defaultAppendBlockBytes
+	}
+	blockSize = min(blockSize, maxAppendBlockBytes)
+	for start := 0; start < len(data); start += blockSize {
+		end := min(start+blockSize, len(data))
+		_, err = appendBlobClient.AppendBlock(ctx, streaming.NopCloser(bytes.NewReader(data[start:end])), nil)
+		if err != nil {
+			return "", fmt.Errorf("error appending to az blob: %w", err)
+		}
+	}
+
+	return appendBlobClient.URL(), nil
+}
+
+// stageBlock uploads a block of a block blob, which is added to the blob by the commitBlockList operation.
+func (a *AzureBlobStorage) stageBlock(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	blobName := req.Metadata[metadataKeyBlobName]
+	if blobName == "" {
+		return nil, ErrMissingBlobName
+	}
+	blockID := req.Metadata[metadataKeyBlockID]
+	if blockID == "" {
+		return nil, ErrMissingBlockID
+	}
+
+	data, err := a.requestData(req)
+	if err != nil {
+		return nil, err
+	}
+
+	blockBlobClient := a.containerClient.NewBlockBlobClient(blobName)
+	_, err = blockBlobClient.StageBlock(ctx, encodeBlockID(blockID), streaming.NopCloser(bytes.NewReader(data)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error staging block %s of az blob: %w", blockID, err)
+	}
+
+	return &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			metadataKeyBlobName: blobName,
+			metadataKeyBlockID:  blockID,
+		},
+	}, nil
+}
+
+// commitBlockList writes the block blob from the list of staged blocks, in order.
+// The remaining request metadata is used for the headers and the metadata of the blob, as in the create operation.
+func (a *AzureBlobStorage) commitBlockList(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	blobName := req.Metadata[metadataKeyBlobName]
+	if blobName == "" {
+		return nil, ErrMissingBlobName
+	}
+	delete(req.Metadata, metadataKeyBlobName)
+
+	var payload commitBlockListPayload
+	err := json.Unmarshal(req.Data, &payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commitBlockList payload: %w", err)
+	}
+	if len(payload.BlockIDs) == 0 {
+		return nil, errors.New("invalid commitBlockList payload: blockIds must not be empty")
+	}
+	blockIDs := make([]string, len(payload.BlockIDs))
+	for i, id := range payload.BlockIDs {
+		blockIDs[i] = encodeBlockID(id)
+	}
+
+	blobHTTPHeaders, err := storagecommon.CreateBlobHTTPHeadersFromRequest(req.Metadata, nil, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	blockBlobClient := a.containerClient.NewBlockBlobClient(blobName)
+	_, err = blockBlobClient.CommitBlockList(ctx, blockIDs, &blockblob.CommitBlockListOptions{
+		HTTPHeaders: &blobHTTPHeaders,
+		Metadata:    storagecommon.SanitizeMetadata(a.logger, req.Metadata),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error committing block list of az blob: %w", err)
+	}
+
+	b, err := json.Marshal(createResponse{
+		BlobURL: blockBlobClient.URL(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling create response for azure blob: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+		Metadata: map[string]string{
+			metadataKeyBlobName: blobName,
+		},
+	}, nil
+}
+
+// encodeBlockID encodes the block ID in base64, as required by the service.
+func encodeBlockID(id string) string {
+	return b64.StdEncoding.EncodeToString([]byte(id))
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
+
+	"github.com/dapr/components-contrib/bindings"
+	azauth "github.com/dapr/components-contrib/common/authentication/azure"
+	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/ptr"
+)
+
+const (
+	sasOperation bindings.OperationKind = "sas"
+
+	// Permissions granted by the SAS, as a combination of the letters in supportedSASPermissions, such as "r" or "rw".
+	// See: https://learn.microsoft.com/en-us/rest/api/storageservices/create-service-sas#permissions-for-a-directory-container-or-blob
+	metadataKeySASPermissions = "sasPermissions"
+	// Validity of the SAS, as a Go duration such as "15m".
+	metadataKeySASTTL = "sasTTL"
+
+	defaultSASPermissions   = "r"
+	supportedSASPermissions = "racwdxlt"
+	defaultSASTTL           = time.Hour
+	// The SAS is valid from a few minutes in the past, to allow for clock skew between the client and the storage service.
+	sasClockSkew = 5 * time.Minute
+)
+
+type sasResponse struct {
+	SASURL    string    `json:"sasURL"`
+	ExpiresOn time.Time `json:"expiresOn"`
+}
+
+// initSASServiceClient creates the service client used to obtain user delegation keys, when the binding authenticates with Azure AD.
+// With a shared key, the SAS is signed with the account key instead.
+func (a *AzureBlobStorage) initSASServiceClient(meta map[string]string) error {
+	if a.metadata.AccountKey != "" || a.metadata.ConnectionString != "" {
+		return nil
+	}
+
+	azEnvSettings, err := azauth.NewEnvironmentSettings(meta)
+	if err != nil {
+		return err
+	}
+	credential, err := azEnvSettings.GetTokenCredential()
+	if err != nil {
+		return fmt.Errorf("invalid token credentials with error: %w", err)
+	}
+
+	// The service URL is the URL of the container, without the container name
+	urlParts, err := blob.ParseURL(a.containerClient.URL())
+	if err != nil {
+		return fmt.Errorf("failed to parse container URL: %w", err)
+	}
+	urlParts.ContainerName = ""
+	a.serviceClient, err = service.NewClient(urlParts.String(), credential, &service.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Telemetry: policy.TelemetryOptions{
+				ApplicationID: "dapr-" + logger.DaprVersion,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot init blob storage service client with Azure AD token: %w", err)
+	}
+	return nil
+}
+
+// sas returns a URL with a shared access signature for the blob in the blobName metadata, or for the container if blobName is empty.
+// With Azure AD authentication, the SAS is a user delegation SAS, and the identity must be allowed to request user delegation keys.
+func (a *AzureBlobStorage) sas(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	val := req.Metadata[metadataKeySASPermissions]
+	if val == "" {
+		val = defaultSASPermissions
+	}
+	permissions, err := parseSASPermissions(val)
+	if err != nil {
+		return nil, err
+	}
+	ttl := defaultSASTTL
+	if val = req.Metadata[metadataKeySASTTL]; val != "" {
+		ttl, err = time.ParseDuration(val)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q must be a positive duration", metadataKeySASTTL, val)
+		}
+	}
+
+	now := time.Now().UTC()
+	start := now.Add(-sasClockSkew)
+	expiry := now.Add(ttl)
+	blobName := req.Metadata[metadataKeyBlobName]
+
+	var sasURL string
+	if a.serviceClient != nil {
+		sasURL, err = a.userDelegationSASURL(ctx, blobName, permissions, start, expiry)
+	} else {
+		sasURL, err = a.sharedKeySASURL(blobName, permissions, start, expiry)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error generating SAS: %w", err)
+	}
+
+	jsonResponse, err := json.Marshal(sasResponse{
+		SASURL:    sasURL,
+		ExpiresOn: expiry,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot marshal SAS response to json: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: jsonResponse,
+	}, nil
+}
+
+// parseSASPermissions parses the permissions of a SAS. The supported permissions apply to both containers and blobs.
+func parseSASPermissions(val string) (sas.ContainerPermissions, error) {
+	var p sas.ContainerPermissions
+	for _, r := range val {
+		switch r {
+		case 'r':
+			p.Read = true
+		case 'a':
+			p.Add = true
+		case 'c':
+			p.Create = true
+		case 'w':
+			p.Write = true
+		case 'd':
+			p.Delete = true
+		case 'x':
+			p.DeletePreviousVersion = true
+		case 'l':
+			p.List = true
+		case 't':
+			p.Tag = true
+		default:
+			return p, fmt.Errorf("invalid %s: unsupported permission %q (must be a combination of %q)", metadataKeySASPermissions, r, supportedSASPermissions)
+		}
+	}
+	return p, nil
+}
+
+func (a *AzureBlobStorage) sharedKeySASURL(blobName string, permissions sas.ContainerPermissions, start time.Time, expiry time.Time) (string, error) {
+	if blobName == "" {
+		return a.containerClient.GetSASURL(permissions, expiry, &container.GetSASURLOptions{StartTime: &start})
+	}
+
+	blobPermissions := sas.BlobPermissions{
+		Read:                  permissions.Read,
+		Add:                   permissions.Add,
+		Create:                permissions.Create,
+		Write:                 permissions.Write,
+		Delete:                permissions.Delete,
+		DeletePreviousVersion: permissions.DeletePreviousVersion,
+		List:                  permissions.List,
+		Tag:                   permissions.Tag,
+	}
+	return a.containerClient.NewBlobClient(blobName).GetSASURL(blobPermissions, expiry, &blob.GetSASURLOptions{StartTime: &start})
+}
+
+func (a *AzureBlobStorage) userDelegationSASURL(ctx context.Context, blobName string, permissions sas.ContainerPermissions, start time.Time, expiry time.Time) (string, error) {
+	// The user delegation key must be valid for as long as the SAS
+	udc, err := a.serviceClient.GetUserDelegationCredential(ctx, service.KeyInfo{
+		Start:  ptr.Of(start.Format(sas.TimeFormat)),
+		Expiry: ptr.Of(expiry.Format(sas.TimeFormat)),
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user delegation key: %w", err)
+	}
+
+	urlParts, err := blob.ParseURL(a.containerClient.URL())
+	if err != nil {
+		return "", err
+	}
+	qps, err := sas.BlobSignatureValues{
+		Protocol:      sas.ProtocolHTTPS,
+		StartTime:     start,
+		ExpiryTime:    expiry,
+		Permissions:   permissions.String(),
+		ContainerName: urlParts.ContainerName,
+		BlobName:      blobName,
+	}.SignWithUserDelegation(udc)
+	if err != nil {
+		return "", err
+	}
+
+	u := a.containerClient.URL()
+	if blobName != "" {
+		u = a.containerClient.NewBlobClient(blobName).URL()
+	}
+	return u + "?" + qps.Encode(), nil
+}
@@ -0,0 +1,409 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	ch "github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	// list of operations.
+	execOperation   bindings.OperationKind = "exec"
+	queryOperation  bindings.OperationKind = "query"
+	insertOperation bindings.OperationKind = "insert"
+	closeOperation  bindings.OperationKind = "close"
+
+	// keys from request's metadata.
+	commandSQLKey    = "sql"
+	commandParamsKey = "params"
+	tableKey         = "table"
+	columnsKey       = "columns"
+
+	// keys from response's metadata.
+	respOpKey           = "operation"
+	respSQLKey          = "sql"
+	respStartTimeKey    = "start-time"
+	respRowsAffectedKey = "rows-affected"
+	respEndTimeKey      = "end-time"
+	respDurationKey     = "duration"
+)
+
+// ClickHouse represents ClickHouse output binding.
+type ClickHouse struct {
+	conn     driver.Conn
+	metadata clickhouseMetadata
+	logger   logger.Logger
+	closed   atomic.Bool
+}
+
+// NewClickHouse returns a new ClickHouse output binding.
+func NewClickHouse(logger logger.Logger) bindings.OutputBinding {
+	return &ClickHouse{logger: logger}
+}
+
+// Init initializes the ClickHouse binding.
+func (c *ClickHouse) Init(ctx context.Context, meta bindings.Metadata) (err error) {
+	if c.closed.Load() {
+		return errors.New("cannot initialize a previously-closed component")
+	}
+
+	c.metadata, err = parseMetadata(meta.Properties)
+	if err != nil {
+		return err
+	}
+
+	opts, err := ch.ParseDSN(c.metadata.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+
+	c.conn, err = ch.Open(opts)
+	if err != nil {
+		return fmt.Errorf("unable to open connection: %w", err)
+	}
+
+	err = c.conn.Ping(ctx)
+	if err != nil {
+		c.conn.Close()
+		return fmt.Errorf("unable to connect to the DB: %w", err)
+	}
+
+	return nil
+}
+
+// Operations returns list of operations supported by ClickHouse binding.
+func (c *ClickHouse) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{
+		execOperation,
+		queryOperation,
+		insertOperation,
+		closeOperation,
+	}
+}
+
+// Invoke handles all invoke operations.
+func (c *ClickHouse) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if req == nil {
+		return nil, errors.New("invoke request required")
+	}
+
+	// We let the "close" operation here succeed even if the component has been closed already
+	if req.Operation == closeOperation {
+		return nil, c.Close()
+	}
+
+	if c.closed.Load() {
+		return nil, errors.New("component is closed")
+	}
+
+	startTime := time.Now().UTC()
+	resp := &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			respOpKey:        string(req.Operation),
+			respStartTimeKey: startTime.Format(time.RFC3339Nano),
+		},
+	}
+
+	switch req.Operation {
+	case execOperation, queryOperation:
+		s := req.Metadata[commandSQLKey]
+		if s == "" {
+			return nil, fmt.Errorf("required metadata not set: %s", commandSQLKey)
+		}
+		resp.Metadata[respSQLKey] = s
+
+		// Metadata property "params" contains JSON-encoded parameters, and it's optional
+		params, err := parseParams(req.Metadata[commandParamsKey])
+		if err != nil {
+			return nil, err
+		}
+
+		if req.Operation == execOperation {
+			err = c.conn.Exec(ctx, s, params...)
+			if err != nil {
+				return nil, fmt.Errorf("error executing query: %w", err)
+			}
+		} else {
+			resp.Data, err = c.query(ctx, s, params...)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+	case insertOperation:
+		n, err := c.insert(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Metadata[respRowsAffectedKey] = strconv.Itoa(n)
+
+	default:
+		return nil, fmt.Errorf("invalid operation type: %s. Expected %s, %s, %s, or %s",
+			req.Operation, execOperation, queryOperation, insertOperation, closeOperation)
+	}
+
+	endTime := time.Now().UTC()
+	resp.Metadata[respEndTimeKey] = endTime.Format(time.RFC3339Nano)
+	resp.Metadata[respDurationKey] = endTime.Sub(startTime).String()
+
+	return resp, nil
+}
+
+// query executes the query and returns the rows as a JSON array of objects keyed by column name.
+func (c *ClickHouse) query(ctx context.Context, sql string, params ...any) ([]byte, error) {
+	rows, err := c.conn.Query(ctx, sql, params...)
+	if err != nil {
+		return nil, fmt.Errorf("error executing query: %w", err)
+	}
+	defer rows.Close()
+
+	columnTypes := rows.ColumnTypes()
+	result := []map[string]any{}
+	for rows.Next() {
+		values := make([]any, len(columnTypes))
+		for i, ct := range columnTypes {
+			values[i] = reflect.New(ct.ScanType()).Interface()
+		}
+		err = rows.Scan(values...)
+		if err != nil {
+			return nil, fmt.Errorf("error reading query result: %w", err)
+		}
+
+		row := make(map[string]any, len(columnTypes))
+		for i, ct := range columnTypes {
+			row[ct.Name()] = reflect.ValueOf(values[i]).Elem().Interface()
+		}
+		result = append(result, row)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error reading query result: %w", err)
+	}
+
+	return json.Marshal(result)
+}
+
+// insert appends the rows in the request data to the table in a single batch, using the native protocol, and returns the number of rows.
+func (c *ClickHouse) insert(ctx context.Context, req *bindings.InvokeRequest) (int, error) {
+	table := req.Metadata[tableKey]
+	if table == "" {
+		return 0, fmt.Errorf("required metadata not set: %s", tableKey)
+	}
+
+	rows, err := parseRows(req.Data)
+	if err != nil {
+		return 0, err
+	}
+
+	columns := rowColumns(rows)
+	if val := req.Metadata[columnsKey]; val != "" {
+		columns = strings.Split(val, ",")
+		for i := range columns {
+			columns[i] = strings.TrimSpace(columns[i])
+		}
+	}
+
+	statement, err := insertStatement(table, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	if c.metadata.AsyncInsert {
+		waitForAsyncInsert := 0
+		if c.metadata.WaitForAsyncInsert {
+			waitForAsyncInsert = 1
+		}
+		ctx = ch.Context(ctx, ch.WithSettings(ch.Settings{
+			"async_insert":          1,
+			"wait_for_async_insert": waitForAsyncInsert,
+		}))
+	}
+
+	batch, err := c.conn.PrepareBatch(ctx, statement)
+	if err != nil {
+		return 0, fmt.Errorf("error preparing batch: %w", err)
+	}
+	for _, row := range rows {
+		values := make([]any, len(columns))
+		for i, col := range columns {
+			values[i] = row[col]
+		}
+		err = batch.Append(values...)
+		if err != nil {
+			batch.Abort()
+			return 0, fmt.Errorf("error appending row to batch: %w", err)
+		}
+	}
+	err = batch.Send()
+	if err != nil {
+		return 0, fmt.Errorf("error sending batch: %w", err)
+	}
+
+	return len(rows), nil
+}
+
+// parseParams parses the JSON-encoded parameters of the query, which must be an array.
+func parseParams(val string) ([]any, error) {
+	if val == "" {
+		return nil, nil
+	}
+	var params []any
+	err := json.Unmarshal([]byte(val), &params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata property %s: failed to unserialize into an array: %w", commandParamsKey, err)
+	}
+	return params, nil
+}
+
+// parseRows parses the rows to insert from a JSON array of objects, or a single object.
+// Integer numbers are decoded as int64 rather than float64, so they keep their precision.
+func parseRows(data []byte) ([]map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var rows []map[string]any
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var row map[string]any
+		err := dec.Decode(&row)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request data: %w", err)
+		}
+		rows = []map[string]any{row}
+	} else {
+		err := dec.Decode(&rows)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request data: must be a JSON array of objects: %w", err)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("invalid request data: no rows to insert")
+	}
+
+	for _, row := range rows {
+		for k, v := range row {
+			row[k] = convertNumbers(v)
+		}
+	}
+	return rows, nil
+}
+
+// convertNumbers converts the JSON numbers in the value to int64 or float64.
+func convertNumbers(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, _ := val.Float64()
+		return f
+	case []any:
+		for i := range val {
+			val[i] = convertNumbers(val[i])
+		}
+	case map[string]any:
+		for k := range val {
+			val[k] = convertNumbers(val[k])
+		}
+	}
+	return v
+}
+
+// rowColumns returns the sorted names of the columns in the rows.
+func rowColumns(rows []map[string]any) []string {
+	set := map[string]struct{}{}
+	for _, row := range rows {
+		for col := range row {
+			set[col] = struct{}{}
+		}
+	}
+	columns := make([]string, 0, len(set))
+	for col := range set {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// insertStatement returns the INSERT statement for the batch.
+// Table and column names are quoted, as they come from the request.
+func insertStatement(table string, columns []string) (string, error) {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		quoted, err := quoteIdentifier(part)
+		if err != nil {
+			return "", fmt.Errorf("invalid table name %q: %w", table, err)
+		}
+		parts[i] = quoted
+	}
+
+	quotedColumns := make([]string, len(columns))
+	for i, col := range columns {
+		quoted, err := quoteIdentifier(col)
+		if err != nil {
+			return "", fmt.Errorf("invalid column name %q: %w", col, err)
+		}
+		quotedColumns[i] = quoted
+	}
+
+	return "INSERT INTO " + strings.Join(parts, ".") + " (" + strings.Join(quotedColumns, ", ") + ")", nil
+}
+
+func quoteIdentifier(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("identifier is empty")
+	}
+	if strings.ContainsAny(name, "`\\") {
+		return "", errors.New("identifier contains backticks or backslashes")
+	}
+	return "`" + name + "`", nil
+}
+
+// Close will close the DB.
+func (c *ClickHouse) Close() error {
+	if !c.closed.CompareAndSwap(false, true) {
+		// If this failed, the component has already been closed
+		// We allow multiple calls to close
+		return nil
+	}
+
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+
+	return nil
+}
+
+// GetComponentMetadata returns the metadata of the component.
+func (c *ClickHouse) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
+	metadataStruct := clickhouseMetadata{}
+	metadata.GetMetadataInfoFromStructType(reflect.TypeOf(metadataStruct), &metadataInfo, metadata.BindingType)
+	return
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{"url": "clickhouse://localhost:9000/default"})
+		require.NoError(t, err)
+		assert.False(t, m.AsyncInsert)
+		assert.True(t, m.WaitForAsyncInsert)
+	})
+
+	t.Run("async insert", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{
+			"url":                "clickhouse://localhost:9000/default",
+			"asyncInsert":        "true",
+			"waitForAsyncInsert": "false",
+		})
+		require.NoError(t, err)
+		assert.True(t, m.AsyncInsert)
+		assert.False(t, m.WaitForAsyncInsert)
+	})
+
+	t.Run("missing url", func(t *testing.T) {
+		_, err := parseMetadata(map[string]string{})
+		require.Error(t, err)
+	})
+}
+
+func TestParseRows(t *testing.T) {
+	t.Run("array of rows", func(t *testing.T) {
+		rows, err := parseRows([]byte(`[{"id": 9007199254740993, "value": 1.5}, {"id": 2, "name": "dapr"}]`))
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, int64(9007199254740993), rows[0]["id"])
+		assert.InDelta(t, 1.5, rows[0]["value"], 0)
+		assert.Equal(t, []string{"id", "name", "value"}, rowColumns(rows))
+	})
+
+	t.Run("single row", func(t *testing.T) {
+		rows, err := parseRows([]byte(` {"id": 1, "tags": [1, 2]}`))
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, []any{int64(1), int64(2)}, rows[0]["tags"])
+	})
+
+	t.Run("invalid rows", func(t *testing.T) {
+		for _, data := range []string{``, `[]`, `"row"`, `[1, 2]`} {
+			_, err := parseRows([]byte(data))
+			require.Error(t, err, data)
+		}
+	})
+}
+
+func TestInsertStatement(t *testing.T) {
+	statement, err := insertStatement("analytics.events", []string{"id", "event type"})
+	require.NoError(t, err)
+	assert.Equal(t, "INSERT INTO `analytics`.`events` (`id`, `event type`)", statement)
+
+	_, err = insertStatement("events`; DROP TABLE events", []string{"id"})
+	require.Error(t, err)
+	_, err = insertStatement("events", []string{""})
+	require.Error(t, err)
+}
+
+func TestInvokeInvalidRequests(t *testing.T) {
+	c := NewClickHouse(logger.NewLogger("test")).(*ClickHouse)
+
+	for name, req := range map[string]*bindings.InvokeRequest{
+		"missing sql":            {Operation: queryOperation},
+		"invalid params":         {Operation: execOperation, Metadata: map[string]string{commandSQLKey: "SELECT 1", commandParamsKey: "1"}},
+		"missing table":          {Operation: insertOperation, Data: []byte(`[{"id": 1}]`)},
+		"unsupported operation":  {Operation: "unsupported"},
+		"invalid rows to insert": {Operation: insertOperation, Data: []byte(`[]`), Metadata: map[string]string{tableKey: "events"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := c.Invoke(context.Background(), req)
+			assert.Nil(t, resp)
+			require.Error(t, err)
+		})
+	}
+}
+
+// SETUP TESTS
+// 1. `docker run -d --name clickhouse -p 9000:9000 clickhouse/clickhouse-server`
+// 2. `export CLICKHOUSE_TEST_CONN_URL=clickhouse://default@localhost:9000/default`
+// 3. `go test -v -count=1 ./bindings/clickhouse -run ^TestClickHouseIntegration`
+func TestClickHouseIntegration(t *testing.T) {
+	url := os.Getenv("CLICKHOUSE_TEST_CONN_URL")
+	if url == "" {
+		t.SkipNow()
+	}
+
+	for _, asyncInsert := range []string{"false", "true"} {
+		t.Run("asyncInsert "+asyncInsert, func(t *testing.T) {
+			b := NewClickHouse(logger.NewLogger("test")).(*ClickHouse)
+			err := b.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+				"url":         url,
+				"asyncInsert": asyncInsert,
+			}}})
+			require.NoError(t, err)
+			defer b.Close()
+
+			invoke := func(req *bindings.InvokeRequest) *bindings.InvokeResponse {
+				res, err := b.Invoke(context.Background(), req)
+				require.NoError(t, err)
+				return res
+			}
+
+			invoke(&bindings.InvokeRequest{
+				Operation: execOperation,
+				Metadata:  map[string]string{commandSQLKey: "DROP TABLE IF EXISTS dapr_test_events"},
+			})
+			invoke(&bindings.InvokeRequest{
+				Operation: execOperation,
+				Metadata: map[string]string{
+					commandSQLKey: "CREATE TABLE dapr_test_events (id UInt64, name String, ts DateTime, value Nullable(Float64)) ENGINE = MergeTree ORDER BY id",
+				},
+			})
+
+			res := invoke(&bindings.InvokeRequest{
+				Operation: insertOperation,
+				Data: []byte(`[
+					{"id": 1, "name": "first", "ts": "2024-01-02 03:04:05", "value": 1.5},
+					{"id": 2, "name": "second", "ts": "2024-01-02 03:04:06"}
+				]`),
+				Metadata: map[string]string{tableKey: "dapr_test_events"},
+			})
+			assert.Equal(t, "2", res.Metadata[respRowsAffectedKey])
+
+			res = invoke(&bindings.InvokeRequest{
+				Operation: queryOperation,
+				Metadata: map[string]string{
+					commandSQLKey:    "SELECT id, name, value FROM dapr_test_events WHERE id >= ? ORDER BY id",
+					commandParamsKey: `[1]`,
+				},
+			})
+			var rows []map[string]any
+			require.NoError(t, json.Unmarshal(res.Data, &rows))
+			assert.Equal(t, []map[string]any{
+				{"id": float64(1), "name": "first", "value": 1.5},
+				{"id": float64(2), "name": "second", "value": nil},
+			}, rows)
+
+			invoke(&bindings.InvokeRequest{
+				Operation: execOperation,
+				Metadata:  map[string]string{commandSQLKey: "DROP TABLE dapr_test_events"},
+			})
+		})
+	}
+}
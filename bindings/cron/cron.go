@@ -15,8 +15,10 @@ package cron
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -33,18 +35,38 @@ import (
 
 // Binding represents Cron input binding.
 type Binding struct {
-	logger   logger.Logger
-	name     string
-	schedule string
-	parser   cron.Parser
-	clk      clock.Clock
-	closed   atomic.Bool
-	closeCh  chan struct{}
-	wg       sync.WaitGroup
+	logger    logger.Logger
+	name      string
+	schedules []schedule
+	location  *time.Location
+	parser    cron.Parser
+	clk       clock.Clock
+	closed    atomic.Bool
+	closeCh   chan struct{}
+	wg        sync.WaitGroup
 }
 
 type metadata struct {
 	Schedule string
+	// JSON array of named schedules, as objects with the "name", "schedule", and optional "jitter" properties.
+	Schedules string
+	// Time zone of the schedules, unless they start with "CRON_TZ=". Defaults to the local time zone.
+	TimeZone string
+	// Maximum random delay added to each trigger.
+	Jitter time.Duration
+}
+
+type scheduleMetadata struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	Jitter   string `json:"jitter"`
+}
+
+type schedule struct {
+	name     string
+	spec     string
+	jitter   time.Duration
+	location *time.Location
 }
 
 // NewCron returns a new Cron event input binding.
@@ -68,6 +90,7 @@ func NewCronWithClock(logger logger.Logger, clk clock.Clock) bindings.InputBindi
 //
 //	"15 * * * * *" - Every 15 sec
 //	"0 30 * * * *" - Every 30 min
+//	"CRON_TZ=Europe/Paris 0 0 9 * * MON-FRI" - At 9am in Paris on weekdays
 func (b *Binding) Init(ctx context.Context, meta bindings.Metadata) error {
 	b.name = meta.Name
 	m := metadata{}
@@ -75,39 +98,100 @@ func (b *Binding) Init(ctx context.Context, meta bindings.Metadata) error {
 	if err != nil {
 		return err
 	}
-	if m.Schedule == "" {
+
+	b.location = time.Local
+	if m.TimeZone != "" {
+		b.location, err = time.LoadLocation(m.TimeZone)
+		if err != nil {
+			return fmt.Errorf("invalid timeZone '%s': %w", m.TimeZone, err)
+		}
+	}
+	if m.Jitter < 0 {
+		return errors.New("jitter must not be negative")
+	}
+
+	var list []scheduleMetadata
+	switch {
+	case m.Schedule != "" && m.Schedules != "":
+		return errors.New("only one of schedule and schedules can be set")
+	case m.Schedule != "":
+		list = []scheduleMetadata{{Schedule: m.Schedule}}
+	case m.Schedules != "":
+		err = json.Unmarshal([]byte(m.Schedules), &list)
+		if err != nil {
+			return fmt.Errorf("invalid schedules: %w", err)
+		}
+		if len(list) == 0 {
+			return errors.New("invalid schedules: the list is empty")
+		}
+	default:
 		return fmt.Errorf("schedule not set")
 	}
-	_, err = b.parser.Parse(m.Schedule)
-	if err != nil {
-		return fmt.Errorf("invalid schedule format '%s': %w", m.Schedule, err)
+
+	b.schedules = make([]schedule, len(list))
+	names := make(map[string]struct{}, len(list))
+	for i, item := range list {
+		if m.Schedules != "" {
+			if item.Name == "" {
+				return fmt.Errorf("invalid schedules: schedule at index %d has no name", i)
+			}
+			if _, ok := names[item.Name]; ok {
+				return fmt.Errorf("invalid schedules: duplicate schedule name '%s'", item.Name)
+			}
+			names[item.Name] = struct{}{}
+		}
+		b.schedules[i], err = b.parseSchedule(item, m.Jitter)
+		if err != nil {
+			return err
+		}
 	}
-	b.schedule = m.Schedule
 
 	return nil
 }
 
+func (b *Binding) parseSchedule(item scheduleMetadata, defaultJitter time.Duration) (schedule, error) {
+	parsed, err := b.parser.Parse(item.Schedule)
+	if err != nil {
+		return schedule{}, fmt.Errorf("invalid schedule format '%s': %w", item.Schedule, err)
+	}
+	s := schedule{
+		name:     item.Name,
+		spec:     item.Schedule,
+		jitter:   defaultJitter,
+		location: b.location,
+	}
+	// Schedules with a CRON_TZ prefix run in their own time zone
+	if spec, ok := parsed.(*cron.SpecSchedule); ok && spec.Location != time.Local {
+		s.location = spec.Location
+	}
+	if item.Jitter != "" {
+		s.jitter, err = time.ParseDuration(item.Jitter)
+		if err != nil || s.jitter < 0 {
+			return schedule{}, fmt.Errorf("invalid jitter '%s' for schedule '%s': must be a non-negative duration", item.Jitter, item.Name)
+		}
+	}
+	return s, nil
+}
+
 // Read triggers the Cron scheduler.
 func (b *Binding) Read(ctx context.Context, handler bindings.Handler) error {
 	if b.closed.Load() {
 		return errors.New("binding is closed")
 	}
 
-	c := cron.New(cron.WithParser(b.parser), cron.WithClock(b.clk))
-	id, err := c.AddFunc(b.schedule, func() {
-		b.logger.Debugf("name: %s, schedule fired: %v", b.name, time.Now())
-		handler(ctx, &bindings.ReadResponse{
-			Metadata: map[string]string{
-				"timeZone":    c.Location().String(),
-				"readTimeUTC": time.Now().UTC().String(),
-			},
-		})
-	})
-	if err != nil {
-		return fmt.Errorf("name: %s, error scheduling %s: %w", b.name, b.schedule, err)
+	c := cron.New(cron.WithParser(b.parser), cron.WithClock(b.clk), cron.WithLocation(b.location))
+	ids := make([]cron.EntryID, len(b.schedules))
+	for i, s := range b.schedules {
+		id, err := c.AddFunc(s.spec, b.trigger(ctx, handler, s))
+		if err != nil {
+			return fmt.Errorf("name: %s, error scheduling %s: %w", b.name, s.spec, err)
+		}
+		ids[i] = id
 	}
 	c.Start()
-	b.logger.Debugf("name: %s, next run: %v", b.name, time.Until(c.Entry(id).Next))
+	for i, id := range ids {
+		b.logger.Debugf("name: %s, schedule: %s, next run: %v", b.name, b.schedules[i].spec, time.Until(c.Entry(id).Next))
+	}
 
 	b.wg.Add(1)
 	go func() {
@@ -117,13 +201,42 @@ func (b *Binding) Read(ctx context.Context, handler bindings.Handler) error {
 		case <-ctx.Done():
 		case <-b.closeCh:
 		}
-		b.logger.Debugf("name: %s, stopping schedule: %s", b.name, b.schedule)
+		b.logger.Debugf("name: %s, stopping schedules", b.name)
 		c.Stop()
 	}()
 
 	return nil
 }
 
+// trigger returns the function that invokes the handler when the schedule fires, after a random delay of up to the schedule's jitter.
+func (b *Binding) trigger(ctx context.Context, handler bindings.Handler, s schedule) func() {
+	return func() {
+		if s.jitter > 0 {
+			//nolint:gosec
+			delay := time.Duration(rand.Int63n(int64(s.jitter)))
+			select {
+			case <-b.clk.After(delay):
+			case <-ctx.Done():
+				return
+			case <-b.closeCh:
+				return
+			}
+		}
+
+		b.logger.Debugf("name: %s, schedule fired: %s", b.name, s.spec)
+		md := map[string]string{
+			"timeZone":    s.location.String(),
+			"readTimeUTC": time.Now().UTC().String(),
+		}
+		if s.name != "" {
+			md["scheduleName"] = s.name
+		}
+		handler(ctx, &bindings.ReadResponse{
+			Metadata: md,
+		})
+	}
+}
+
 func (b *Binding) Close() error {
 	if b.closed.CompareAndSwap(false, true) {
 		close(b.closeCh)
@@ -17,6 +17,7 @@ import (
 	"context"
 	"os"
 	"runtime"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -27,6 +28,7 @@ import (
 	clocktesting "k8s.io/utils/clock/testing"
 
 	"github.com/dapr/components-contrib/bindings"
+	contribMetadata "github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/kit/logger"
 )
 
@@ -156,3 +158,148 @@ func TestCronReadWithContextCancellation(t *testing.T) {
 	require.NoErrorf(t, err, "error on read")
 	require.NoError(t, c.Close())
 }
+
+func TestCronInitSchedules(t *testing.T) {
+	initTests := []struct {
+		name          string
+		properties    map[string]string
+		errorExpected bool
+	}{
+		{
+			name: "named schedules",
+			properties: map[string]string{
+				"schedules": `[{"name": "hourly", "schedule": "0 0 * * * *"}, {"name": "daily", "schedule": "CRON_TZ=Europe/Paris 0 0 9 * * *", "jitter": "5m"}]`,
+			},
+		},
+		{
+			name: "time zone and jitter",
+			properties: map[string]string{
+				"schedule": "0 0 9 * * MON-FRI",
+				"timeZone": "America/New_York",
+				"jitter":   "30s",
+			},
+		},
+		{
+			name:          "no schedule",
+			properties:    map[string]string{},
+			errorExpected: true,
+		},
+		{
+			name: "schedule and schedules",
+			properties: map[string]string{
+				"schedule":  "@every 1s",
+				"schedules": `[{"name": "a", "schedule": "@every 1s"}]`,
+			},
+			errorExpected: true,
+		},
+		{
+			name:          "schedule without name",
+			properties:    map[string]string{"schedules": `[{"schedule": "@every 1s"}]`},
+			errorExpected: true,
+		},
+		{
+			name:          "duplicate names",
+			properties:    map[string]string{"schedules": `[{"name": "a", "schedule": "@every 1s"}, {"name": "a", "schedule": "@every 2s"}]`},
+			errorExpected: true,
+		},
+		{
+			name:          "invalid schedule",
+			properties:    map[string]string{"schedules": `[{"name": "a", "schedule": "INVALID_SCHEDULE"}]`},
+			errorExpected: true,
+		},
+		{
+			name:          "invalid time zone",
+			properties:    map[string]string{"schedule": "@every 1s", "timeZone": "Nowhere/Invalid"},
+			errorExpected: true,
+		},
+		{
+			name:          "invalid jitter",
+			properties:    map[string]string{"schedules": `[{"name": "a", "schedule": "@every 1s", "jitter": "-1s"}]`},
+			errorExpected: true,
+		},
+	}
+
+	for _, test := range initTests {
+		t.Run(test.name, func(t *testing.T) {
+			c := getNewCron()
+			err := c.Init(context.Background(), bindings.Metadata{Base: contribMetadata.Base{Properties: test.properties}})
+			if test.errorExpected {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("time zone of the schedules", func(t *testing.T) {
+		c := getNewCron()
+		err := c.Init(context.Background(), bindings.Metadata{Base: contribMetadata.Base{Properties: map[string]string{
+			"schedules": `[{"name": "a", "schedule": "0 0 9 * * *"}, {"name": "b", "schedule": "CRON_TZ=Europe/Paris 0 0 9 * * *"}]`,
+			"timeZone":  "America/New_York",
+		}}})
+		require.NoError(t, err)
+		require.Len(t, c.schedules, 2)
+		assert.Equal(t, "America/New_York", c.schedules[0].location.String())
+		assert.Equal(t, "Europe/Paris", c.schedules[1].location.String())
+	})
+}
+
+func TestCronReadSchedules(t *testing.T) {
+	clk := clocktesting.NewFakeClock(time.Now())
+	c := getNewCronWithClock(clk)
+	err := c.Init(context.Background(), bindings.Metadata{Base: contribMetadata.Base{Properties: map[string]string{
+		"schedules": `[{"name": "fast", "schedule": "@every 1s"}, {"name": "slow", "schedule": "@every 2s"}]`,
+	}}})
+	require.NoError(t, err)
+
+	var lock sync.Mutex
+	observed := map[string]int{}
+	err = c.Read(context.Background(), func(ctx context.Context, res *bindings.ReadResponse) ([]byte, error) {
+		lock.Lock()
+		observed[res.Metadata["scheduleName"]]++
+		lock.Unlock()
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		clk.Step(time.Second)
+		runtime.Gosched()
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return observed["fast"] == 4 && observed["slow"] == 2
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, c.Close())
+}
+
+func TestCronReadWithJitter(t *testing.T) {
+	clk := clocktesting.NewFakeClock(time.Now())
+	c := getNewCronWithClock(clk)
+	err := c.Init(context.Background(), bindings.Metadata{Base: contribMetadata.Base{Properties: map[string]string{
+		"schedule": "@every 1s",
+		"jitter":   "1h",
+	}}})
+	require.NoError(t, err)
+
+	var observedCount atomic.Int32
+	err = c.Read(context.Background(), func(ctx context.Context, res *bindings.ReadResponse) ([]byte, error) {
+		observedCount.Add(1)
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	// The schedule fires, and the trigger waits for the random delay
+	clk.Step(time.Second)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(0), observedCount.Load())
+
+	// After the maximum delay, the handler is invoked
+	clk.Step(time.Hour)
+	assert.Eventually(t, func() bool {
+		return observedCount.Load() >= 1
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, c.Close())
+}
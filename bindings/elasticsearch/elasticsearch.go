@@ -0,0 +1,353 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/dapr/components-contrib/bindings"
+	awsAuth "github.com/dapr/components-contrib/common/authentication/aws"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	// list of operations.
+	indexOperation     bindings.OperationKind = "index"
+	bulkIndexOperation bindings.OperationKind = "bulkIndex"
+	searchOperation    bindings.OperationKind = "search"
+
+	// keys from request's metadata.
+	indexKey   = "index"
+	idKey      = "id"
+	idFieldKey = "idField"
+	refreshKey = "refresh"
+
+	// keys from response's metadata.
+	respFoundKey   = "found"
+	respVersionKey = "version"
+)
+
+// Elasticsearch is an output binding for Elasticsearch and OpenSearch, using their REST API.
+type Elasticsearch struct {
+	client   *http.Client
+	metadata elasticsearchMetadata
+	logger   logger.Logger
+}
+
+// NewElasticsearch returns a new Elasticsearch output binding.
+func NewElasticsearch(logger logger.Logger) bindings.OutputBinding {
+	return &Elasticsearch{logger: logger}
+}
+
+// Init performs metadata parsing.
+func (e *Elasticsearch) Init(_ context.Context, meta bindings.Metadata) (err error) {
+	e.metadata, err = parseMetadata(meta.Properties)
+	if err != nil {
+		return err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if e.metadata.CACert != "" {
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM([]byte(e.metadata.CACert)) {
+			return errors.New("failed to load ca certificate")
+		}
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    rootCAs,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	e.client = &http.Client{
+		Transport: transport,
+		Timeout:   e.metadata.Timeout,
+	}
+	if e.metadata.AWSRegion != "" {
+		sess, err := awsAuth.GetClient(e.metadata.AWSAccessKey, e.metadata.AWSSecretKey, e.metadata.AWSSessionToken, e.metadata.AWSRegion, "")
+		if err != nil {
+			return fmt.Errorf("failed to load AWS credentials: %w", err)
+		}
+		e.client.Transport = awsAuth.NewSigV4Transport(transport, sess.Config.Credentials, e.metadata.AWSRegion, e.metadata.AWSService)
+	}
+
+	return nil
+}
+
+// Operations returns list of operations supported by Elasticsearch binding.
+func (e *Elasticsearch) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{
+		indexOperation,
+		bulkIndexOperation,
+		bindings.GetOperation,
+		bindings.DeleteOperation,
+		searchOperation,
+	}
+}
+
+// Invoke handles all invoke operations.
+func (e *Elasticsearch) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	index := req.Metadata[indexKey]
+	if index == "" {
+		index = e.metadata.Index
+	}
+	if index == "" {
+		return nil, fmt.Errorf("elasticsearch binding: missing %s in request metadata", indexKey)
+	}
+	id := req.Metadata[idKey]
+
+	query := url.Values{}
+	if refresh := req.Metadata[refreshKey]; refresh != "" {
+		query.Set(refreshKey, refresh)
+	}
+
+	switch req.Operation {
+	case indexOperation:
+		// Without an ID, the cluster generates one
+		method, path := http.MethodPost, "/"+url.PathEscape(index)+"/_doc"
+		if id != "" {
+			method, path = http.MethodPut, path+"/"+url.PathEscape(id)
+		}
+		res, err := e.do(ctx, method, path, query, "application/json", req.Data)
+		if err != nil {
+			return nil, err
+		}
+		return &bindings.InvokeResponse{Data: res}, nil
+
+	case bulkIndexOperation:
+		body, err := bulkBody(index, req.Metadata[idFieldKey], req.Data)
+		if err != nil {
+			return nil, err
+		}
+		res, err := e.do(ctx, http.MethodPost, "/_bulk", query, "application/x-ndjson", body)
+		if err != nil {
+			return nil, err
+		}
+		err = bulkError(res)
+		if err != nil {
+			return nil, err
+		}
+		return &bindings.InvokeResponse{Data: res}, nil
+
+	case bindings.GetOperation:
+		if id == "" {
+			return nil, fmt.Errorf("elasticsearch binding: missing %s in request metadata", idKey)
+		}
+		return e.get(ctx, index, id)
+
+	case bindings.DeleteOperation:
+		if id == "" {
+			return nil, fmt.Errorf("elasticsearch binding: missing %s in request metadata", idKey)
+		}
+		_, err := e.do(ctx, http.MethodDelete, "/"+url.PathEscape(index)+"/_doc/"+url.PathEscape(id), query, "", nil)
+		var statusErr *statusError
+		if errors.As(err, &statusErr) && statusErr.status == http.StatusNotFound {
+			// The document doesn't exist
+			return nil, nil
+		}
+		return nil, err
+
+	case searchOperation:
+		// The request data is the query DSL, which is passed to the cluster as-is
+		res, err := e.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", query, "application/json", req.Data)
+		if err != nil {
+			return nil, err
+		}
+		return &bindings.InvokeResponse{Data: res}, nil
+
+	default:
+		return nil, fmt.Errorf("elasticsearch binding: invalid operation type: %s", req.Operation)
+	}
+}
+
+// get returns the source of the document, or an empty response if it doesn't exist.
+func (e *Elasticsearch) get(ctx context.Context, index string, id string) (*bindings.InvokeResponse, error) {
+	res, err := e.do(ctx, http.MethodGet, "/"+url.PathEscape(index)+"/_doc/"+url.PathEscape(id), nil, "", nil)
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && statusErr.status == http.StatusNotFound {
+		return &bindings.InvokeResponse{
+			Metadata: map[string]string{respFoundKey: "false"},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		Source  json.RawMessage `json:"_source"`
+		Version int64           `json:"_version"`
+	}
+	err = json.Unmarshal(res, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch binding: invalid response: %w", err)
+	}
+	return &bindings.InvokeResponse{
+		Data: doc.Source,
+		Metadata: map[string]string{
+			respFoundKey:   "true",
+			respVersionKey: strconv.FormatInt(doc.Version, 10),
+		},
+	}, nil
+}
+
+// statusError is returned when the cluster responds with an error status.
+type statusError struct {
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("elasticsearch binding: request failed with status %d: %s", e.status, e.body)
+}
+
+func (e *Elasticsearch) do(ctx context.Context, method string, path string, query url.Values, contentType string, body []byte) ([]byte, error) {
+	u := e.metadata.URL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	switch {
+	case e.metadata.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+e.metadata.APIKey)
+	case e.metadata.Username != "" || e.metadata.Password != "":
+		req.SetBasicAuth(e.metadata.Username, e.metadata.Password)
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch binding: request failed: %w", err)
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch binding: failed to read response: %w", err)
+	}
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return nil, &statusError{status: res.StatusCode, body: string(resBody)}
+	}
+	return resBody, nil
+}
+
+// bulkBody returns the body of a bulk request to index the documents in the JSON array.
+// If idField is set, the ID of each document is the value of that field.
+func bulkBody(index string, idField string, data []byte) ([]byte, error) {
+	var docs []json.RawMessage
+	err := json.Unmarshal(data, &docs)
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch binding: data must be a JSON array of documents: %w", err)
+	}
+	if len(docs) == 0 {
+		return nil, errors.New("elasticsearch binding: no documents to index")
+	}
+
+	var buf bytes.Buffer
+	for i, doc := range docs {
+		action := map[string]string{"_index": index}
+		if idField != "" {
+			var fields map[string]any
+			err = json.Unmarshal(doc, &fields)
+			if err != nil {
+				return nil, fmt.Errorf("elasticsearch binding: document %d is not a JSON object: %w", i, err)
+			}
+			switch id := fields[idField].(type) {
+			case string:
+				action["_id"] = id
+			case float64:
+				action["_id"] = strconv.FormatFloat(id, 'f', -1, 64)
+			default:
+				return nil, fmt.Errorf("elasticsearch binding: document %d doesn't have a string or number %s field", i, idField)
+			}
+		}
+		line, err := json.Marshal(map[string]any{"index": action})
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		// Documents must be on a single line
+		err = json.Compact(&buf, doc)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// bulkError returns an error if any document of the bulk request failed.
+func bulkError(res []byte) error {
+	var bulk struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	err := json.Unmarshal(res, &bulk)
+	if err != nil {
+		return fmt.Errorf("elasticsearch binding: invalid bulk response: %w", err)
+	}
+	if !bulk.Errors {
+		return nil
+	}
+
+	failed := 0
+	var first string
+	for _, item := range bulk.Items {
+		for _, result := range item {
+			if len(result.Error) > 0 {
+				if failed == 0 {
+					first = string(result.Error)
+				}
+				failed++
+			}
+		}
+	}
+	return fmt.Errorf("elasticsearch binding: failed to index %d of %d documents; first error: %s", failed, len(bulk.Items), first)
+}
+
+func (e *Elasticsearch) Close() error {
+	if e.client != nil {
+		e.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// GetComponentMetadata returns the metadata of the component.
+func (e *Elasticsearch) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
+	metadataStruct := elasticsearchMetadata{}
+	metadata.GetMetadataInfoFromStructType(reflect.TypeOf(metadataStruct), &metadataInfo, metadata.BindingType)
+	return
+}
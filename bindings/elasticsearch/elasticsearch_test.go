@@ -0,0 +1,314 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+// recordedRequest is a request received by the test server.
+type recordedRequest struct {
+	method        string
+	path          string
+	query         string
+	contentType   string
+	authorization string
+	body          string
+}
+
+func newTestServer(t *testing.T, status int, response string) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+
+	var requests []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, recordedRequest{
+			method:        r.Method,
+			path:          r.URL.Path,
+			query:         r.URL.RawQuery,
+			contentType:   r.Header.Get("Content-Type"),
+			authorization: r.Header.Get("Authorization"),
+			body:          string(body),
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func newBinding(t *testing.T, properties map[string]string) *Elasticsearch {
+	t.Helper()
+
+	b := NewElasticsearch(logger.NewLogger("test")).(*Elasticsearch)
+	err := b.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: properties}})
+	require.NoError(t, err)
+	return b
+}
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{"url": "https://localhost:9200/"})
+		require.NoError(t, err)
+		assert.Equal(t, "https://localhost:9200", m.URL)
+		assert.Equal(t, defaultTimeout, m.Timeout)
+	})
+
+	t.Run("AWS service defaults to es", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{"url": "https://search.example.com", "awsRegion": "us-west-2"})
+		require.NoError(t, err)
+		assert.Equal(t, "es", m.AWSService)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		for name, properties := range map[string]map[string]string{
+			"missing url":          {},
+			"relative url":         {"url": "localhost:9200"},
+			"invalid timeout":      {"url": "http://localhost:9200", "timeout": "0"},
+			"several auth methods": {"url": "http://localhost:9200", "apiKey": "key", "username": "user"},
+			"invalid ca cert":      {"url": "http://localhost:9200", "caCert": "not a certificate"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := parseMetadata(properties)
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestIndex(t *testing.T) {
+	srv, requests := newTestServer(t, http.StatusCreated, `{"_id": "1", "result": "created"}`)
+	b := newBinding(t, map[string]string{"url": srv.URL, "index": "default", "apiKey": "encoded-key"})
+
+	t.Run("with ID", func(t *testing.T) {
+		res, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: indexOperation,
+			Data:      []byte(`{"name": "dapr"}`),
+			Metadata:  map[string]string{"index": "my-index", "id": "1", "refresh": "true"},
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"_id": "1", "result": "created"}`, string(res.Data))
+
+		req := (*requests)[len(*requests)-1]
+		assert.Equal(t, http.MethodPut, req.method)
+		assert.Equal(t, "/my-index/_doc/1", req.path)
+		assert.Equal(t, "refresh=true", req.query)
+		assert.Equal(t, "application/json", req.contentType)
+		assert.Equal(t, "ApiKey encoded-key", req.authorization)
+		assert.Equal(t, `{"name": "dapr"}`, req.body)
+	})
+
+	t.Run("without ID in the default index", func(t *testing.T) {
+		_, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: indexOperation,
+			Data:      []byte(`{"name": "dapr"}`),
+		})
+		require.NoError(t, err)
+
+		req := (*requests)[len(*requests)-1]
+		assert.Equal(t, http.MethodPost, req.method)
+		assert.Equal(t, "/default/_doc", req.path)
+	})
+}
+
+func TestBulkIndex(t *testing.T) {
+	t.Run("documents are sent as NDJSON", func(t *testing.T) {
+		srv, requests := newTestServer(t, http.StatusOK, `{"errors": false, "items": [{"index": {"status": 201}}, {"index": {"status": 201}}]}`)
+		b := newBinding(t, map[string]string{"url": srv.URL, "username": "user", "password": "pass"})
+
+		_, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bulkIndexOperation,
+			Data: []byte(`[
+				{"key": "a", "value": 1},
+				{"key": 2, "value": 2}
+			]`),
+			Metadata: map[string]string{"index": "my-index", "idField": "key"},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, *requests, 1)
+		req := (*requests)[0]
+		assert.Equal(t, "/_bulk", req.path)
+		assert.Equal(t, "application/x-ndjson", req.contentType)
+		assert.True(t, strings.HasPrefix(req.authorization, "Basic "))
+		assert.Equal(t, `{"index":{"_id":"a","_index":"my-index"}}
+{"key":"a","value":1}
+{"index":{"_id":"2","_index":"my-index"}}
+{"key":2,"value":2}
+`, req.body)
+	})
+
+	t.Run("failed documents", func(t *testing.T) {
+		srv, _ := newTestServer(t, http.StatusOK, `{"errors": true, "items": [
+			{"index": {"status": 201}},
+			{"index": {"status": 400, "error": {"type": "mapper_parsing_exception"}}}
+		]}`)
+		b := newBinding(t, map[string]string{"url": srv.URL, "index": "my-index"})
+
+		_, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bulkIndexOperation,
+			Data:      []byte(`[{"value": 1}, {"value": "x"}]`),
+		})
+		require.ErrorContains(t, err, "failed to index 1 of 2 documents")
+		require.ErrorContains(t, err, "mapper_parsing_exception")
+	})
+
+	t.Run("invalid documents", func(t *testing.T) {
+		b := newBinding(t, map[string]string{"url": "http://localhost:9200", "index": "my-index"})
+		for _, data := range []string{`{"value": 1}`, `[]`} {
+			_, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+				Operation: bulkIndexOperation,
+				Data:      []byte(data),
+			})
+			require.Error(t, err)
+		}
+
+		_, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bulkIndexOperation,
+			Data:      []byte(`[{"value": 1}]`),
+			Metadata:  map[string]string{"idField": "key"},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestGet(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		srv, requests := newTestServer(t, http.StatusOK, `{"_id": "1", "_version": 3, "found": true, "_source": {"name": "dapr"}}`)
+		b := newBinding(t, map[string]string{"url": srv.URL, "index": "my-index"})
+
+		res, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.GetOperation,
+			Metadata:  map[string]string{"id": "1"},
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name": "dapr"}`, string(res.Data))
+		assert.Equal(t, "true", res.Metadata[respFoundKey])
+		assert.Equal(t, "3", res.Metadata[respVersionKey])
+		assert.Equal(t, "/my-index/_doc/1", (*requests)[0].path)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		srv, _ := newTestServer(t, http.StatusNotFound, `{"_id": "1", "found": false}`)
+		b := newBinding(t, map[string]string{"url": srv.URL, "index": "my-index"})
+
+		res, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.GetOperation,
+			Metadata:  map[string]string{"id": "1"},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, res.Data)
+		assert.Equal(t, "false", res.Metadata[respFoundKey])
+	})
+
+	t.Run("missing ID", func(t *testing.T) {
+		b := newBinding(t, map[string]string{"url": "http://localhost:9200", "index": "my-index"})
+		_, err := b.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.GetOperation})
+		require.Error(t, err)
+	})
+}
+
+func TestDelete(t *testing.T) {
+	t.Run("deleted", func(t *testing.T) {
+		srv, requests := newTestServer(t, http.StatusOK, `{"result": "deleted"}`)
+		b := newBinding(t, map[string]string{"url": srv.URL, "index": "my-index"})
+
+		_, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.DeleteOperation,
+			Metadata:  map[string]string{"id": "a/b"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodDelete, (*requests)[0].method)
+		assert.Equal(t, "/my-index/_doc/a/b", (*requests)[0].path)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		srv, _ := newTestServer(t, http.StatusNotFound, `{"result": "not_found"}`)
+		b := newBinding(t, map[string]string{"url": srv.URL, "index": "my-index"})
+
+		_, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.DeleteOperation,
+			Metadata:  map[string]string{"id": "1"},
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestSearch(t *testing.T) {
+	t.Run("query is passed through", func(t *testing.T) {
+		srv, requests := newTestServer(t, http.StatusOK, `{"hits": {"total": {"value": 0}, "hits": []}}`)
+		b := newBinding(t, map[string]string{"url": srv.URL})
+
+		query := `{"query": {"match": {"name": "dapr"}}}`
+		res, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: searchOperation,
+			Data:      []byte(query),
+			Metadata:  map[string]string{"index": "logs-*"},
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"hits": {"total": {"value": 0}, "hits": []}}`, string(res.Data))
+		assert.Equal(t, "/logs-*/_search", (*requests)[0].path)
+		assert.Equal(t, query, (*requests)[0].body)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		srv, _ := newTestServer(t, http.StatusBadRequest, `{"error": {"type": "parsing_exception"}}`)
+		b := newBinding(t, map[string]string{"url": srv.URL, "index": "my-index"})
+
+		_, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: searchOperation,
+			Data:      []byte(`{"query": {"bad": {}}}`),
+		})
+		require.ErrorContains(t, err, "status 400")
+		require.ErrorContains(t, err, "parsing_exception")
+	})
+
+	t.Run("missing index", func(t *testing.T) {
+		b := newBinding(t, map[string]string{"url": "http://localhost:9200"})
+		_, err := b.Invoke(context.Background(), &bindings.InvokeRequest{Operation: searchOperation})
+		require.Error(t, err)
+	})
+}
+
+func TestSigV4(t *testing.T) {
+	srv, requests := newTestServer(t, http.StatusOK, `{"hits": {"hits": []}}`)
+	b := newBinding(t, map[string]string{
+		"url":          srv.URL,
+		"index":        "my-index",
+		"awsRegion":    "us-west-2",
+		"awsAccessKey": "AKID",
+		"awsSecretKey": "SECRET",
+	})
+
+	_, err := b.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: searchOperation,
+		Data:      []byte(`{"query": {"match_all": {}}}`),
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix((*requests)[0].authorization, "AWS4-HMAC-SHA256 Credential=AKID/"))
+	assert.Contains(t, (*requests)[0].authorization, "/us-west-2/es/aws4_request")
+}
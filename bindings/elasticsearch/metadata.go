@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"encoding/pem"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	kitmd "github.com/dapr/kit/metadata"
+)
+
+const (
+	// Service name used to sign requests to Amazon OpenSearch Service; "aoss" is used for OpenSearch Serverless.
+	defaultAWSService = "es"
+	defaultTimeout    = 30 * time.Second
+)
+
+type elasticsearchMetadata struct {
+	// URL of the cluster, for example "https://localhost:9200".
+	URL string `mapstructure:"url"`
+	// Default index, used when the request doesn't have an index.
+	Index string `mapstructure:"index"`
+	// Timeout of requests to the cluster.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Credentials for basic authentication.
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// Encoded API key, sent in the "Authorization: ApiKey" header.
+	APIKey string `mapstructure:"apiKey"`
+
+	// If set, requests are signed with AWS Signature Version 4, for Amazon OpenSearch Service.
+	AWSRegion       string `mapstructure:"awsRegion"`
+	AWSService      string `mapstructure:"awsService"`
+	AWSAccessKey    string `mapstructure:"awsAccessKey"`
+	AWSSecretKey    string `mapstructure:"awsSecretKey"`
+	AWSSessionToken string `mapstructure:"awsSessionToken"`
+
+	// PEM-encoded CA certificate used to verify the cluster's certificate.
+	CACert string `mapstructure:"caCert"`
+}
+
+func parseMetadata(meta map[string]string) (elasticsearchMetadata, error) {
+	m := elasticsearchMetadata{
+		Timeout: defaultTimeout,
+	}
+	err := kitmd.DecodeMetadata(meta, &m)
+	if err != nil {
+		return m, err
+	}
+
+	if m.URL == "" {
+		return m, errors.New("missing url")
+	}
+	u, err := url.Parse(m.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return m, errors.New("url must be an absolute http or https URL")
+	}
+	m.URL = strings.TrimSuffix(m.URL, "/")
+
+	if m.Timeout <= 0 {
+		return m, errors.New("timeout must be greater than 0")
+	}
+
+	authMethods := 0
+	if m.Username != "" || m.Password != "" {
+		authMethods++
+	}
+	if m.APIKey != "" {
+		authMethods++
+	}
+	if m.AWSRegion != "" {
+		authMethods++
+		if m.AWSService == "" {
+			m.AWSService = defaultAWSService
+		}
+	}
+	if authMethods > 1 {
+		return m, errors.New("only one of username and password, apiKey, or awsRegion can be set")
+	}
+
+	if m.CACert != "" {
+		if block, _ := pem.Decode([]byte(m.CACert)); block == nil {
+			return m, errors.New("invalid ca certificate")
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,362 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ftp
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ftpConn is a connection to an FTP server.
+// It implements the small subset of RFC 959 (and RFC 2228, 2428 and 4217) used by the binding, including active mode:
+// github.com/jlaffaye/ftp only opens data connections with EPSV or PASV, and it doesn't export a way to send other commands.
+type ftpConn struct {
+	conn     net.Conn
+	text     *textproto.Conn
+	deadline time.Time
+	meta     ftpMetadata
+	// If set, data connections are protected with TLS.
+	tlsConfig *tls.Config
+}
+
+// dial connects and logs in to the server.
+// All operations on the connection must complete before the deadline of the context, or the binding's timeout.
+func dial(ctx context.Context, meta ftpMetadata, tlsConfig *tls.Config) (*ftpConn, error) {
+	deadline := time.Now().Add(meta.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", meta.address())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", meta.address(), err)
+	}
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	c := &ftpConn{
+		conn:     conn,
+		deadline: deadline,
+		meta:     meta,
+	}
+	if meta.TLSMode == tlsModeImplicit {
+		c.conn = tls.Client(conn, tlsConfig)
+	}
+	c.text = textproto.NewConn(c.conn)
+
+	err = c.login(tlsConfig)
+	if err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *ftpConn) login(tlsConfig *tls.Config) error {
+	_, _, err := c.text.ReadResponse(220)
+	if err != nil {
+		return fmt.Errorf("unexpected greeting: %w", err)
+	}
+
+	if c.meta.TLSMode == tlsModeExplicit {
+		_, err = c.cmd(234, "AUTH TLS")
+		if err != nil {
+			return fmt.Errorf("failed to enable TLS: %w", err)
+		}
+		c.conn = tls.Client(c.conn, tlsConfig)
+		c.text = textproto.NewConn(c.conn)
+	}
+
+	username, password := c.meta.Username, c.meta.Password
+	if username == "" {
+		username = "anonymous"
+	}
+	code, err := c.cmd(0, "USER %s", username)
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to log in: %w", err)
+	case code == 331:
+		_, err = c.cmd(2, "PASS %s", password)
+		if err != nil {
+			return fmt.Errorf("failed to log in: %w", err)
+		}
+	case code != 230:
+		return fmt.Errorf("failed to log in: unexpected response code %d", code)
+	}
+
+	if tlsConfig != nil {
+		_, err = c.cmd(200, "PBSZ 0")
+		if err == nil {
+			_, err = c.cmd(200, "PROT P")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to protect data connections: %w", err)
+		}
+		c.tlsConfig = tlsConfig
+	}
+
+	_, err = c.cmd(200, "TYPE I")
+	if err != nil {
+		return fmt.Errorf("failed to set binary mode: %w", err)
+	}
+	return nil
+}
+
+// cmd sends a command and reads the response, which must have the expected code (see textproto.Reader.ReadResponse).
+func (c *ftpConn) cmd(expectCode int, format string, args ...any) (int, error) {
+	_, err := c.text.Cmd(format, args...)
+	if err != nil {
+		return 0, err
+	}
+	code, _, err := c.text.ReadResponse(expectCode)
+	return code, err
+}
+
+// Store uploads a file, copying its content from r to the data connection.
+func (c *ftpConn) Store(path string, r io.Reader) error {
+	conn, err := c.dataCmd("STOR %s", path)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(conn, r)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	return c.closeData(conn)
+}
+
+// Retrieve downloads a file, whose content is read from the data connection.
+// The returned reader must be closed, which returns an error if the transfer didn't complete.
+func (c *ftpConn) Retrieve(path string) (io.ReadCloser, error) {
+	conn, err := c.dataCmd("RETR %s", path)
+	if err != nil {
+		return nil, err
+	}
+	return &dataReader{Conn: conn, c: c}, nil
+}
+
+// dataReader reads from a data connection, and reads the result of the transfer when it's closed.
+type dataReader struct {
+	net.Conn
+	c *ftpConn
+}
+
+func (r *dataReader) Close() error {
+	return r.c.closeData(r.Conn)
+}
+
+// NameList returns the names of the files in a directory.
+func (c *ftpConn) NameList(path string) ([]string, error) {
+	conn, err := c.dataCmd("NLST %s", path)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		name := strings.TrimRight(scanner.Text(), "\r")
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to list %s: %w", path, err)
+	}
+	return names, c.closeData(conn)
+}
+
+// Delete deletes a file.
+func (c *ftpConn) Delete(path string) error {
+	_, err := c.cmd(250, "DELE %s", path)
+	return err
+}
+
+// Quit ends the session and closes the connection.
+func (c *ftpConn) Quit() error {
+	// Errors are ignored as the operation has already completed
+	_, _ = c.cmd(221, "QUIT")
+	return c.conn.Close()
+}
+
+// dataCmd sends a command that transfers data, and returns the data connection.
+func (c *ftpConn) dataCmd(format string, args ...any) (net.Conn, error) {
+	var (
+		conn net.Conn
+		err  error
+	)
+	if c.meta.TransferMode == transferModeActive {
+		conn, err = c.activeDataCmd(format, args...)
+	} else {
+		conn, err = c.passiveDataCmd(format, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = conn.SetDeadline(c.deadline)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if c.tlsConfig != nil {
+		// The client is the TLS client of data connections even when the server opens them
+		tlsConn := tls.Client(conn, c.tlsConfig)
+		err = tlsConn.Handshake()
+		if err != nil {
+			tlsConn.Close()
+			return nil, fmt.Errorf("TLS handshake of data connection failed: %w", err)
+		}
+		conn = tlsConn
+	}
+	return conn, nil
+}
+
+// passiveDataCmd connects to the port opened by the server, then sends the command.
+func (c *ftpConn) passiveDataCmd(format string, args ...any) (net.Conn, error) {
+	port, err := c.passivePort()
+	if err != nil {
+		return nil, err
+	}
+
+	// The address in PASV responses is ignored as it's often wrong when the server is behind NAT
+	host, _, _ := net.SplitHostPort(c.conn.RemoteAddr().String())
+	dialer := net.Dialer{Deadline: c.deadline}
+	conn, err := dialer.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data connection: %w", err)
+	}
+
+	_, err = c.cmd(1, format, args...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// passivePort returns the port opened by the server for the next data connection, using EPSV or PASV.
+func (c *ftpConn) passivePort() (int, error) {
+	_, err := c.text.Cmd("EPSV")
+	if err != nil {
+		return 0, err
+	}
+	_, msg, err := c.text.ReadResponse(229)
+	if err == nil {
+		// Format is "Entering Extended Passive Mode (|||port|)"
+		start := strings.Index(msg, "(|||")
+		end := strings.LastIndex(msg, "|)")
+		if start < 0 || end < start+4 {
+			return 0, fmt.Errorf("invalid EPSV response: %s", msg)
+		}
+		port, err := strconv.Atoi(msg[start+4 : end])
+		if err != nil || port <= 0 || port > 65535 {
+			return 0, fmt.Errorf("invalid EPSV response: %s", msg)
+		}
+		return port, nil
+	}
+	var protoErr *textproto.Error
+	if !errors.As(err, &protoErr) {
+		return 0, err
+	}
+
+	// The server doesn't support EPSV
+	_, err = c.text.Cmd("PASV")
+	if err != nil {
+		return 0, err
+	}
+	_, msg, err = c.text.ReadResponse(227)
+	if err != nil {
+		return 0, err
+	}
+	// Format is "Entering Passive Mode (h1,h2,h3,h4,p1,p2)"
+	start := strings.Index(msg, "(")
+	end := strings.LastIndex(msg, ")")
+	if start < 0 || end < start {
+		return 0, fmt.Errorf("invalid PASV response: %s", msg)
+	}
+	fields := strings.Split(msg[start+1:end], ",")
+	if len(fields) != 6 {
+		return 0, fmt.Errorf("invalid PASV response: %s", msg)
+	}
+	p1, err1 := strconv.Atoi(strings.TrimSpace(fields[4]))
+	p2, err2 := strconv.Atoi(strings.TrimSpace(fields[5]))
+	if err1 != nil || err2 != nil || p1 < 0 || p1 > 255 || p2 < 0 || p2 > 255 {
+		return 0, fmt.Errorf("invalid PASV response: %s", msg)
+	}
+	return p1<<8 | p2, nil
+}
+
+// activeDataCmd listens on a local port, sends it to the server with PORT or EPRT, then sends the command and waits
+// for the server to connect.
+func (c *ftpConn) activeDataCmd(format string, args ...any) (net.Conn, error) {
+	ip := net.ParseIP(c.meta.ActiveAddress)
+	if ip == nil {
+		ip = c.conn.LocalAddr().(*net.TCPAddr).IP
+	}
+
+	listener, err := net.ListenTCP("tcp", &net.TCPAddr{IP: ip})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for data connection: %w", err)
+	}
+	defer listener.Close()
+	err = listener.SetDeadline(c.deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	if ip4 := ip.To4(); ip4 != nil {
+		_, err = c.cmd(200, "PORT %d,%d,%d,%d,%d,%d", ip4[0], ip4[1], ip4[2], ip4[3], port>>8, port&0xff)
+	} else {
+		_, err = c.cmd(200, "EPRT |2|%s|%d|", ip, port)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set data port: %w", err)
+	}
+
+	_, err = c.cmd(1, format, args...)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := listener.Accept()
+	if err != nil {
+		return nil, fmt.Errorf("server didn't open data connection: %w", err)
+	}
+	return conn, nil
+}
+
+// closeData closes the data connection and reads the result of the transfer.
+func (c *ftpConn) closeData(conn net.Conn) error {
+	err := conn.Close()
+	if err != nil {
+		return err
+	}
+	_, _, err = c.text.ReadResponse(2)
+	return err
+}
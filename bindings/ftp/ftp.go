@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ftp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/utils"
+)
+
+const (
+	// keys from request's metadata.
+	fileNameKey     = "fileName"
+	decodeBase64Key = "decodeBase64"
+)
+
+// FTP is an output binding that transfers files with an FTP or FTPS server.
+type FTP struct {
+	metadata  ftpMetadata
+	tlsConfig *tls.Config
+	logger    logger.Logger
+}
+
+type createResponse struct {
+	FileName string `json:"fileName"`
+}
+
+// NewFTP returns a new FTP output binding.
+func NewFTP(logger logger.Logger) bindings.OutputBinding {
+	return &FTP{logger: logger}
+}
+
+// Init performs metadata parsing.
+func (f *FTP) Init(_ context.Context, meta bindings.Metadata) (err error) {
+	f.metadata, err = parseMetadata(meta.Properties)
+	if err != nil {
+		return fmt.Errorf("ftp binding: failed to parse metadata: %w", err)
+	}
+	f.tlsConfig, err = f.metadata.tlsConfig()
+	if err != nil {
+		return fmt.Errorf("ftp binding: %w", err)
+	}
+	return nil
+}
+
+// Operations returns list of operations supported by FTP binding.
+func (f *FTP) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{
+		bindings.CreateOperation,
+		bindings.GetOperation,
+		bindings.ListOperation,
+		bindings.DeleteOperation,
+	}
+}
+
+// Invoke handles all invoke operations.
+// Each invocation uses its own connection to the server, as FTP sessions are stateful and servers close idle ones.
+func (f *FTP) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	fileName := req.Metadata[fileNameKey]
+	switch req.Operation {
+	case bindings.CreateOperation:
+		if fileName == "" {
+			fileName = uuid.NewString()
+		}
+	case bindings.GetOperation, bindings.DeleteOperation:
+		if fileName == "" {
+			return nil, fmt.Errorf("ftp binding: missing %s in request metadata", fileNameKey)
+		}
+	case bindings.ListOperation:
+	default:
+		return nil, fmt.Errorf("ftp binding: unsupported operation %s", req.Operation)
+	}
+	// Line breaks would allow injecting commands in the control connection
+	if strings.ContainsAny(fileName, "\r\n") {
+		return nil, errors.New("ftp binding: file name must not contain line breaks")
+	}
+	// File names are always inside the root path
+	filePath := path.Join(f.metadata.RootPath, path.Clean("/"+fileName))
+
+	data := req.Data
+	if req.Operation == bindings.CreateOperation && (f.metadata.DecodeBase64 || utils.IsTruthy(req.Metadata[decodeBase64Key])) {
+		decoded, err := base64.StdEncoding.DecodeString(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("ftp binding: failed to decode base64 data: %w", err)
+		}
+		data = decoded
+	}
+
+	conn, err := dial(ctx, f.metadata, f.tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("ftp binding: %w", err)
+	}
+	defer conn.Quit()
+
+	switch req.Operation {
+	case bindings.CreateOperation:
+		err = conn.Store(filePath, bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("ftp binding: failed to upload %s: %w", filePath, err)
+		}
+		f.logger.Debugf("uploaded file %s, size: %d bytes", filePath, len(data))
+		res, err := json.Marshal(createResponse{FileName: fileName})
+		if err != nil {
+			return nil, fmt.Errorf("ftp binding: error encoding response as JSON: %w", err)
+		}
+		return &bindings.InvokeResponse{Data: res}, nil
+
+	case bindings.GetOperation:
+		r, err := conn.Retrieve(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("ftp binding: failed to download %s: %w", filePath, err)
+		}
+		res, err := io.ReadAll(r)
+		closeErr := r.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ftp binding: failed to download %s: %w", filePath, err)
+		}
+		return &bindings.InvokeResponse{Data: res}, nil
+
+	case bindings.ListOperation:
+		names, err := conn.NameList(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("ftp binding: failed to list %s: %w", filePath, err)
+		}
+		res, err := json.Marshal(names)
+		if err != nil {
+			return nil, fmt.Errorf("ftp binding: error encoding response as JSON: %w", err)
+		}
+		return &bindings.InvokeResponse{Data: res}, nil
+
+	default: // bindings.DeleteOperation
+		err = conn.Delete(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("ftp binding: failed to delete %s: %w", filePath, err)
+		}
+		return nil, nil
+	}
+}
+
+func (f *FTP) Close() error {
+	return nil
+}
+
+// GetComponentMetadata returns the metadata of the component.
+func (f *FTP) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
+	metadataStruct := ftpMetadata{}
+	metadata.GetMetadataInfoFromStructType(reflect.TypeOf(metadataStruct), &metadataInfo, metadata.BindingType)
+	return
+}
@@ -0,0 +1,378 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ftp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{"host": "ftp.example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, 21, m.Port)
+		assert.Equal(t, "/", m.RootPath)
+		assert.Equal(t, transferModePassive, m.TransferMode)
+		assert.Equal(t, tlsModeNone, m.TLSMode)
+		assert.Equal(t, defaultTimeout, m.Timeout)
+		assert.Equal(t, "ftp.example.com:21", m.address())
+
+		cfg, err := m.tlsConfig()
+		require.NoError(t, err)
+		assert.Nil(t, cfg)
+	})
+
+	t.Run("implicit TLS", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{
+			"host":     "ftp.example.com",
+			"tlsMode":  "implicit",
+			"rootPath": "outbox/",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 990, m.Port)
+		assert.Equal(t, "/outbox", m.RootPath)
+
+		cfg, err := m.tlsConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "ftp.example.com", cfg.ServerName)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		for name, properties := range map[string]map[string]string{
+			"missing host":            {},
+			"invalid transfer mode":   {"host": "localhost", "transferMode": "extended"},
+			"invalid active address":  {"host": "localhost", "transferMode": "active", "activeAddress": "localhost"},
+			"invalid TLS mode":        {"host": "localhost", "tlsMode": "ssl"},
+			"TLS options without TLS": {"host": "localhost", "insecureSkipVerify": "true"},
+			"invalid port":            {"host": "localhost", "port": "70000"},
+			"invalid timeout":         {"host": "localhost", "timeout": "0"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := parseMetadata(properties)
+				require.Error(t, err)
+			})
+		}
+	})
+
+	t.Run("invalid CA certificate", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{"host": "localhost", "tlsMode": "explicit", "caCert": "invalid"})
+		require.NoError(t, err)
+		_, err = m.tlsConfig()
+		require.Error(t, err)
+	})
+}
+
+func TestOperations(t *testing.T) {
+	for name, tc := range map[string]struct {
+		transferMode string
+		disableEPSV  bool
+	}{
+		"passive":              {transferMode: transferModePassive},
+		"passive without EPSV": {transferMode: transferModePassive, disableEPSV: true},
+		"active":               {transferMode: transferModeActive},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := newFakeServer(t, tc.disableEPSV)
+			f := newBinding(t, srv, map[string]string{"transferMode": tc.transferMode, "rootPath": "/data"})
+
+			invoke := func(operation bindings.OperationKind, data string, meta map[string]string) (*bindings.InvokeResponse, error) {
+				return f.Invoke(context.Background(), &bindings.InvokeRequest{
+					Operation: operation,
+					Data:      []byte(data),
+					Metadata:  meta,
+				})
+			}
+
+			res, err := invoke(bindings.CreateOperation, "hello", map[string]string{fileNameKey: "in/a.txt"})
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"fileName": "in/a.txt"}`, string(res.Data))
+			assert.Equal(t, []byte("hello"), srv.file("/data/in/a.txt"))
+
+			_, err = invoke(bindings.CreateOperation, "aGVsbG8gYmFzZTY0", map[string]string{fileNameKey: "in/b.txt", decodeBase64Key: "true"})
+			require.NoError(t, err)
+			assert.Equal(t, []byte("hello base64"), srv.file("/data/in/b.txt"))
+
+			res, err = invoke(bindings.GetOperation, "", map[string]string{fileNameKey: "in/a.txt"})
+			require.NoError(t, err)
+			assert.Equal(t, []byte("hello"), res.Data)
+
+			res, err = invoke(bindings.ListOperation, "", map[string]string{fileNameKey: "in"})
+			require.NoError(t, err)
+			var names []string
+			require.NoError(t, json.Unmarshal(res.Data, &names))
+			assert.ElementsMatch(t, []string{"/data/in/a.txt", "/data/in/b.txt"}, names)
+
+			_, err = invoke(bindings.DeleteOperation, "", map[string]string{fileNameKey: "in/a.txt"})
+			require.NoError(t, err)
+			assert.Nil(t, srv.file("/data/in/a.txt"))
+
+			_, err = invoke(bindings.GetOperation, "", map[string]string{fileNameKey: "in/a.txt"})
+			require.ErrorContains(t, err, "550")
+			_, err = invoke(bindings.DeleteOperation, "", map[string]string{fileNameKey: "in/a.txt"})
+			require.ErrorContains(t, err, "550")
+		})
+	}
+}
+
+func TestFileNames(t *testing.T) {
+	srv := newFakeServer(t, false)
+	f := newBinding(t, srv, map[string]string{"rootPath": "/data"})
+
+	t.Run("file names are inside the root path", func(t *testing.T) {
+		_, err := f.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("x"),
+			Metadata:  map[string]string{fileNameKey: "../../etc/passwd"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("x"), srv.file("/data/etc/passwd"))
+	})
+
+	t.Run("generated file name", func(t *testing.T) {
+		res, err := f.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: bindings.CreateOperation,
+			Data:      []byte("x"),
+		})
+		require.NoError(t, err)
+		var created createResponse
+		require.NoError(t, json.Unmarshal(res.Data, &created))
+		assert.NotEmpty(t, created.FileName)
+		assert.Equal(t, []byte("x"), srv.file("/data/"+created.FileName))
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for name, req := range map[string]*bindings.InvokeRequest{
+			"missing file name":     {Operation: bindings.GetOperation},
+			"line break":            {Operation: bindings.DeleteOperation, Metadata: map[string]string{fileNameKey: "a\r\nDELE b"}},
+			"unsupported operation": {Operation: "unsupported"},
+			"invalid base64":        {Operation: bindings.CreateOperation, Data: []byte("!"), Metadata: map[string]string{decodeBase64Key: "true"}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := f.Invoke(context.Background(), req)
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestLoginFailure(t *testing.T) {
+	srv := newFakeServer(t, false)
+	f := newBinding(t, srv, map[string]string{"password": "wrong"})
+
+	_, err := f.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.ListOperation})
+	require.ErrorContains(t, err, "failed to log in")
+}
+
+func newBinding(t *testing.T, srv *fakeServer, properties map[string]string) *FTP {
+	t.Helper()
+
+	props := map[string]string{
+		"host":     "127.0.0.1",
+		"port":     strconv.Itoa(srv.port),
+		"username": "user",
+		"password": "secret",
+		"timeout":  "10s",
+	}
+	for k, v := range properties {
+		props[k] = v
+	}
+	f := NewFTP(logger.NewLogger("test")).(*FTP)
+	err := f.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: props}})
+	require.NoError(t, err)
+	return f
+}
+
+// fakeServer is an in-memory FTP server that supports the commands used by the binding.
+type fakeServer struct {
+	port        int
+	disableEPSV bool
+
+	lock  sync.Mutex
+	files map[string][]byte
+}
+
+func newFakeServer(t *testing.T, disableEPSV bool) *fakeServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	srv := &fakeServer{
+		port:        listener.Addr().(*net.TCPAddr).Port,
+		disableEPSV: disableEPSV,
+		files:       map[string][]byte{},
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	return srv
+}
+
+func (s *fakeServer) file(name string) []byte {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.files[name]
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	text := textproto.NewConn(conn)
+	reply := func(format string, args ...any) {
+		text.PrintfLine(format, args...)
+	}
+
+	var (
+		passive    net.Listener
+		activeAddr string
+	)
+	defer func() {
+		if passive != nil {
+			passive.Close()
+		}
+	}()
+	// openData returns the data connection opened with the last PASV, EPSV, PORT or EPRT command.
+	openData := func() (net.Conn, error) {
+		if passive != nil {
+			defer func() {
+				passive.Close()
+				passive = nil
+			}()
+			return passive.Accept()
+		}
+		return net.Dial("tcp", activeAddr)
+	}
+	listen := func() int {
+		passive, _ = net.Listen("tcp", "127.0.0.1:0")
+		return passive.Addr().(*net.TCPAddr).Port
+	}
+
+	reply("220 fake server ready")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+
+		switch cmd {
+		case "USER":
+			reply("331 password required")
+		case "PASS":
+			if arg == "secret" {
+				reply("230 logged in")
+			} else {
+				reply("530 login incorrect")
+			}
+		case "TYPE":
+			reply("200 type set")
+		case "EPSV":
+			if s.disableEPSV {
+				reply("502 command not implemented")
+				continue
+			}
+			reply("229 Entering Extended Passive Mode (|||%d|)", listen())
+		case "PASV":
+			port := listen()
+			// The address is ignored by the client
+			reply("227 Entering Passive Mode (10,0,0,1,%d,%d)", port>>8, port&0xff)
+		case "PORT":
+			var h1, h2, h3, h4, p1, p2 int
+			fmt.Sscanf(arg, "%d,%d,%d,%d,%d,%d", &h1, &h2, &h3, &h4, &p1, &p2)
+			activeAddr = fmt.Sprintf("%d.%d.%d.%d:%d", h1, h2, h3, h4, p1<<8|p2)
+			reply("200 PORT command successful")
+		case "EPRT":
+			parts := strings.Split(arg, "|")
+			activeAddr = net.JoinHostPort(parts[2], parts[3])
+			reply("200 EPRT command successful")
+		case "STOR", "RETR", "NLST":
+			s.lock.Lock()
+			data, ok := s.files[arg]
+			var names []string
+			for name := range s.files {
+				if path.Dir(name) == arg {
+					names = append(names, name)
+				}
+			}
+			s.lock.Unlock()
+			if cmd == "RETR" && !ok {
+				reply("550 file not found")
+				continue
+			}
+
+			reply("150 opening data connection")
+			dataConn, err := openData()
+			if err != nil {
+				reply("425 can't open data connection")
+				continue
+			}
+			switch cmd {
+			case "STOR":
+				data, _ = io.ReadAll(dataConn)
+				s.lock.Lock()
+				s.files[arg] = data
+				s.lock.Unlock()
+			case "RETR":
+				dataConn.Write(data)
+			case "NLST":
+				for _, name := range names {
+					fmt.Fprintf(dataConn, "%s\r\n", name)
+				}
+			}
+			dataConn.Close()
+			reply("226 transfer complete")
+		case "DELE":
+			s.lock.Lock()
+			_, ok := s.files[arg]
+			delete(s.files, arg)
+			s.lock.Unlock()
+			if ok {
+				reply("250 file deleted")
+			} else {
+				reply("550 file not found")
+			}
+		case "QUIT":
+			reply("221 goodbye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
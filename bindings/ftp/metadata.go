@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ftp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"time"
+
+	kitmd "github.com/dapr/kit/metadata"
+)
+
+const (
+	// Values for transferMode.
+	transferModePassive = "passive"
+	transferModeActive  = "active"
+
+	// Values for tlsMode.
+	tlsModeNone     = "none"
+	tlsModeExplicit = "explicit"
+	tlsModeImplicit = "implicit"
+
+	defaultPort         = 21
+	defaultImplicitPort = 990
+	defaultTimeout      = time.Minute
+)
+
+type ftpMetadata struct {
+	// Host name or IP address of the server.
+	Host string `mapstructure:"host"`
+	// Port of the server. Defaults to 21, or 990 with implicit TLS.
+	Port int `mapstructure:"port"`
+	// Credentials of the user. Without a username, the binding logs in as "anonymous".
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// Directory on the server that file names are relative to.
+	RootPath string `mapstructure:"rootPath"`
+	// Timeout of each operation, including the transfer of the file.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// "passive" (the default) or "active".
+	TransferMode string `mapstructure:"transferMode"`
+	// IP address the server connects to in active mode. Defaults to the local address of the control connection.
+	ActiveAddress string `mapstructure:"activeAddress"`
+
+	// "none" (the default), "explicit" (AUTH TLS) or "implicit" (FTPS).
+	TLSMode string `mapstructure:"tlsMode"`
+	// PEM-encoded CA certificate used to verify the server's certificate.
+	CACert string `mapstructure:"caCert"`
+	// Server name used to verify the server's certificate. Defaults to the host.
+	TLSServerName      string `mapstructure:"tlsServerName"`
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
+
+	// If true, the data of create requests is decoded from base64 before being uploaded.
+	DecodeBase64 bool `mapstructure:"decodeBase64"`
+}
+
+func parseMetadata(meta map[string]string) (ftpMetadata, error) {
+	m := ftpMetadata{
+		Timeout:      defaultTimeout,
+		TransferMode: transferModePassive,
+		TLSMode:      tlsModeNone,
+		RootPath:     "/",
+	}
+	err := kitmd.DecodeMetadata(meta, &m)
+	if err != nil {
+		return m, err
+	}
+
+	if m.Host == "" {
+		return m, errors.New("missing host")
+	}
+	if m.Timeout <= 0 {
+		return m, errors.New("timeout must be greater than 0")
+	}
+	m.RootPath = path.Clean("/" + m.RootPath)
+
+	switch m.TransferMode {
+	case transferModePassive:
+	case transferModeActive:
+		if m.ActiveAddress != "" && net.ParseIP(m.ActiveAddress) == nil {
+			return m, fmt.Errorf("invalid activeAddress '%s': must be an IP address", m.ActiveAddress)
+		}
+	default:
+		return m, fmt.Errorf("invalid transferMode '%s': must be '%s' or '%s'", m.TransferMode, transferModePassive, transferModeActive)
+	}
+
+	switch m.TLSMode {
+	case tlsModeNone, tlsModeExplicit:
+		if m.Port == 0 {
+			m.Port = defaultPort
+		}
+	case tlsModeImplicit:
+		if m.Port == 0 {
+			m.Port = defaultImplicitPort
+		}
+	default:
+		return m, fmt.Errorf("invalid tlsMode '%s': must be '%s', '%s' or '%s'", m.TLSMode, tlsModeNone, tlsModeExplicit, tlsModeImplicit)
+	}
+	if m.Port < 0 || m.Port > 65535 {
+		return m, fmt.Errorf("invalid port %d", m.Port)
+	}
+
+	if m.TLSMode == tlsModeNone && (m.CACert != "" || m.TLSServerName != "" || m.InsecureSkipVerify) {
+		return m, errors.New("TLS options require tlsMode 'explicit' or 'implicit'")
+	}
+
+	return m, nil
+}
+
+// address returns the address of the server's control connection.
+func (m ftpMetadata) address() string {
+	return net.JoinHostPort(m.Host, fmt.Sprint(m.Port))
+}
+
+// tlsConfig returns the TLS configuration for the control and data connections, or nil if TLS is disabled.
+func (m ftpMetadata) tlsConfig() (*tls.Config, error) {
+	if m.TLSMode == tlsModeNone {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		ServerName:         m.TLSServerName,
+		InsecureSkipVerify: m.InsecureSkipVerify, //nolint:gosec
+		MinVersion:         tls.VersionTLS12,
+		// Many servers require the data connections to resume the TLS session of the control connection
+		ClientSessionCache: tls.NewLRUClientSessionCache(0),
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = m.Host
+	}
+	if m.CACert != "" {
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM([]byte(m.CACert)) {
+			return nil, errors.New("invalid caCert")
+		}
+	}
+	return cfg, nil
+}
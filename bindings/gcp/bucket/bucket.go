@@ -73,6 +73,8 @@ type gcpMetadata struct {
 	Bucket       string `json:"bucket" mapstructure:"bucket"`
 	DecodeBase64 bool   `json:"decodeBase64,string" mapstructure:"decodeBase64"`
 	EncodeBase64 bool   `json:"encodeBase64,string" mapstructure:"encodeBase64"`
+	// Not part of the credentials
+	ChunkSize int `json:"-" mapstructure:"chunkSize"`
 }
 
 type listPayload struct {
@@ -115,11 +117,16 @@ func (g *GCPStorage) Init(ctx context.Context, metadata bindings.Metadata) error
 }
 
 func (g *GCPStorage) parseMetadata(meta bindings.Metadata) (*gcpMetadata, error) {
-	m := gcpMetadata{}
+	m := gcpMetadata{
+		ChunkSize: googleapi.DefaultUploadChunkSize,
+	}
 	err := kitmd.DecodeMetadata(meta.Properties, &m)
 	if err != nil {
 		return nil, err
 	}
+	if m.ChunkSize < 0 {
+		return nil, errors.New("gcp bucket binding error: chunkSize must not be negative")
+	}
 
 	return &m, nil
 }
@@ -130,6 +137,8 @@ func (g *GCPStorage) Operations() []bindings.OperationKind {
 		bindings.GetOperation,
 		bindings.DeleteOperation,
 		bindings.ListOperation,
+		signOperation,
+		createUploadSessionOperation,
 	}
 }
 
@@ -145,6 +154,10 @@ func (g *GCPStorage) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*
 		return g.delete(ctx, req)
 	case bindings.ListOperation:
 		return g.list(ctx, req)
+	case signOperation:
+		return g.sign(ctx, req)
+	case createUploadSessionOperation:
+		return g.createUploadSession(ctx, req)
 	default:
 		return nil, fmt.Errorf("unsupported operation %s", req.Operation)
 	}
@@ -175,9 +188,15 @@ func (g *GCPStorage) create(ctx context.Context, req *bindings.InvokeRequest) (*
 		r = b64.NewDecoder(b64.StdEncoding, r)
 	}
 
+	// Objects larger than the chunk size are uploaded with a resumable upload, one chunk per request
 	h := g.client.Bucket(g.metadata.Bucket).Object(name).NewWriter(ctx)
-	defer h.Close()
+	h.ChunkSize = g.metadata.ChunkSize
 	if _, err = io.Copy(h, r); err != nil {
+		h.Close()
+		return nil, fmt.Errorf("gcp bucket binding error. Uploading: %w", err)
+	}
+	// Errors of the upload are reported when the writer is closed
+	if err = h.Close(); err != nil {
 		return nil, fmt.Errorf("gcp bucket binding error. Uploading: %w", err)
 	}
 
@@ -15,13 +15,24 @@ package bucket
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
 	"github.com/dapr/kit/logger"
 )
 
@@ -253,3 +264,101 @@ func TestDeleteOption(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestSignAndUploadSession(t *testing.T) {
+	// Fake storage endpoint that starts resumable upload sessions
+	var sessionReq *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("x-goog-resumable") != "start" || r.URL.Query().Get("X-Goog-Signature") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		sessionReq = r
+		w.Header().Set("Location", "http://"+r.Host+r.URL.Path+"?upload_id=session-1")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+	t.Setenv("STORAGE_EMULATOR_HOST", srv.URL)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	gs := NewGCPStorage(logger.NewLogger("test")).(*GCPStorage)
+	err = gs.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"bucket":      "my_bucket",
+		"type":        "service_account",
+		"clientEmail": "dapr@project.iam.gserviceaccount.com",
+		"privateKey":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	}}})
+	require.NoError(t, err)
+	defer gs.Close()
+
+	t.Run("sign GET", func(t *testing.T) {
+		res, err := gs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: signOperation,
+			Metadata:  map[string]string{"key": "file.txt", "signTTL": "1h"},
+		})
+		require.NoError(t, err)
+		var out signResponse
+		require.NoError(t, json.Unmarshal(res.Data, &out))
+		u, err := url.Parse(out.SignedURL)
+		require.NoError(t, err)
+		assert.Equal(t, "/my_bucket/file.txt", u.Path)
+		assert.Equal(t, "GOOG4-RSA-SHA256", u.Query().Get("X-Goog-Algorithm"))
+		expires, _ := strconv.Atoi(u.Query().Get("X-Goog-Expires"))
+		assert.InDelta(t, 3600, expires, 5)
+		assert.True(t, strings.HasPrefix(u.Query().Get("X-Goog-Credential"), "dapr@project.iam.gserviceaccount.com/"))
+		assert.Equal(t, "host", u.Query().Get("X-Goog-SignedHeaders"))
+		assert.WithinDuration(t, time.Now().Add(time.Hour), out.ExpiresAt, time.Minute)
+	})
+
+	t.Run("sign PUT with content type", func(t *testing.T) {
+		res, err := gs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: signOperation,
+			Metadata:  map[string]string{"key": "file.txt", "signMethod": "put", "contentType": "text/plain"},
+		})
+		require.NoError(t, err)
+		var out signResponse
+		require.NoError(t, json.Unmarshal(res.Data, &out))
+		u, err := url.Parse(out.SignedURL)
+		require.NoError(t, err)
+		expires, _ := strconv.Atoi(u.Query().Get("X-Goog-Expires"))
+		assert.InDelta(t, 900, expires, 5)
+		assert.Equal(t, "content-type;host", u.Query().Get("X-Goog-SignedHeaders"))
+	})
+
+	t.Run("invalid sign metadata", func(t *testing.T) {
+		for md, expectedErr := range map[string]string{
+			`{"signTTL": "8d"}`:       "invalid signTTL",
+			`{"signTTL": "200h"}`:     "invalid signTTL",
+			`{"signMethod": "POST"}`:  "unsupported signMethod POST",
+			`{"signMethod": "patch"}`: "unsupported signMethod PATCH",
+		} {
+			m := map[string]string{}
+			require.NoError(t, json.Unmarshal([]byte(md), &m))
+			m["key"] = "file.txt"
+			_, err := gs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: signOperation, Metadata: m})
+			require.ErrorContains(t, err, expectedErr, md)
+		}
+		_, err := gs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: signOperation})
+		require.ErrorContains(t, err, "can't read key value")
+	})
+
+	t.Run("create upload session", func(t *testing.T) {
+		res, err := gs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: createUploadSessionOperation,
+			Metadata:  map[string]string{"key": "big.bin", "contentType": "application/octet-stream", "origin": "https://app.example.com"},
+		})
+		require.NoError(t, err)
+		var out createUploadSessionResponse
+		require.NoError(t, json.Unmarshal(res.Data, &out))
+		assert.Equal(t, srv.URL+"/my_bucket/big.bin?upload_id=session-1", out.SessionURL)
+
+		require.NotNil(t, sessionReq)
+		assert.Equal(t, "application/octet-stream", sessionReq.Header.Get("Content-Type"))
+		assert.Equal(t, "https://app.example.com", sessionReq.Header.Get("Origin"))
+		assert.Equal(t, "content-type;host;x-goog-resumable", sessionReq.URL.Query().Get("X-Goog-SignedHeaders"))
+	})
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bucket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const (
+	signOperation                bindings.OperationKind = "sign"
+	createUploadSessionOperation bindings.OperationKind = "createUploadSession"
+
+	// Validity of the signed URL, as a Go duration. V4 signed URLs are valid for up to 7 days.
+	metadataSignTTL = "signTTL"
+	// HTTP method allowed by the signed URL: GET (the default) or PUT.
+	metadataSignMethod = "signMethod"
+	// Content type of the object, which clients must send when uploading with a signed URL or an upload session.
+	metadataContentType = "contentType"
+	// Origin of the browser that uploads to the session URL, which is allowed if the bucket's CORS configuration allows it.
+	metadataOrigin = "origin"
+
+	defaultSignTTL = 15 * time.Minute
+	maxSignTTL     = 7 * 24 * time.Hour
+
+	// Header that starts a resumable upload session.
+	// See: https://cloud.google.com/storage/docs/performing-resumable-uploads#initiate-session
+	headerResumable = "x-goog-resumable"
+)
+
+type signResponse struct {
+	SignedURL string    `json:"signedURL"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type createUploadSessionResponse struct {
+	SessionURL string `json:"sessionURL"`
+}
+
+// sign returns a V4 signed URL that allows downloading (GET) or uploading (PUT) the object without credentials until it expires.
+func (g *GCPStorage) sign(_ context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKey]
+	if key == "" {
+		return nil, errors.New("gcp bucket binding error: can't read key value")
+	}
+
+	ttl := defaultSignTTL
+	if val := req.Metadata[metadataSignTTL]; val != "" {
+		var err error
+		ttl, err = time.ParseDuration(val)
+		if err != nil || ttl <= 0 || ttl > maxSignTTL {
+			return nil, fmt.Errorf("gcp bucket binding error: invalid %s %q: must be a positive duration up to %v", metadataSignTTL, val, maxSignTTL)
+		}
+	}
+
+	method := strings.ToUpper(req.Metadata[metadataSignMethod])
+	switch method {
+	case "":
+		method = http.MethodGet
+	case http.MethodGet, http.MethodPut:
+		// Nop
+	default:
+		return nil, fmt.Errorf("gcp bucket binding error: unsupported %s %s (must be %s or %s)", metadataSignMethod, method, http.MethodGet, http.MethodPut)
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	opts := g.signedURLOptions(method, expiresAt)
+	if method == http.MethodPut {
+		opts.ContentType = req.Metadata[metadataContentType]
+	}
+	signedURL, err := g.client.Bucket(g.metadata.Bucket).SignedURL(key, opts)
+	if err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: error signing URL: %w", err)
+	}
+
+	b, err := json.Marshal(signResponse{
+		SignedURL: signedURL,
+		ExpiresAt: expiresAt.UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: error marshalling sign response: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+// createUploadSession starts a resumable upload session for the object, and returns its URL.
+// Clients upload the object by sending chunks to the session URL, without credentials, and can resume interrupted uploads.
+// See: https://cloud.google.com/storage/docs/performing-resumable-uploads
+func (g *GCPStorage) createUploadSession(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	key := req.Metadata[metadataKey]
+	if key == "" {
+		return nil, errors.New("gcp bucket binding error: can't read key value")
+	}
+	contentType := req.Metadata[metadataContentType]
+
+	// The session is started with a signed URL, which is only used by the binding, so it's short-lived
+	opts := g.signedURLOptions(http.MethodPost, time.Now().Add(time.Minute))
+	opts.ContentType = contentType
+	opts.Headers = []string{headerResumable + ":start"}
+	signedURL, err := g.client.Bucket(g.metadata.Bucket).SignedURL(key, opts)
+	if err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: error signing URL: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, signedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: error creating upload session request: %w", err)
+	}
+	httpReq.Header.Set(headerResumable, "start")
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if origin := req.Metadata[metadataOrigin]; origin != "" {
+		httpReq.Header.Set("Origin", origin)
+	}
+	res, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: error creating upload session: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("gcp bucket binding error: error creating upload session: status %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	sessionURL := res.Header.Get("Location")
+	if sessionURL == "" {
+		return nil, errors.New("gcp bucket binding error: error creating upload session: response has no session URL")
+	}
+
+	b, err := json.Marshal(createUploadSessionResponse{
+		SessionURL: sessionURL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gcp bucket binding error: error marshalling upload session response: %w", err)
+	}
+
+	return &bindings.InvokeResponse{
+		Data: b,
+	}, nil
+}
+
+// signedURLOptions returns the options to sign a V4 URL.
+// The URL is signed with the private key of the service account in the metadata, if set; otherwise, the storage client detects the credentials to use.
+func (g *GCPStorage) signedURLOptions(method string, expires time.Time) *storage.SignedURLOptions {
+	opts := &storage.SignedURLOptions{
+		Scheme:  storage.SigningSchemeV4,
+		Method:  method,
+		Expires: expires,
+	}
+	if g.metadata.ClientEmail != "" && g.metadata.PrivateKey != "" {
+		opts.GoogleAccessID = g.metadata.ClientEmail
+		opts.PrivateKey = []byte(g.metadata.PrivateKey)
+	}
+	// The emulator is reached over plain HTTP, unless its address has the https scheme
+	if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" && !strings.HasPrefix(host, "https://") {
+		opts.Insecure = true
+	}
+	return opts
+}
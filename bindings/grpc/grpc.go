@@ -0,0 +1,293 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	grpcmd "google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/ptr"
+)
+
+const (
+	// list of operations.
+	invokeOperation bindings.OperationKind = "invoke"
+
+	// keys from request's metadata.
+	methodKey  = "method"
+	timeoutKey = "timeout"
+)
+
+// GRPC is an output binding that invokes unary gRPC methods, with the request and response messages in JSON.
+type GRPC struct {
+	conn     *grpc.ClientConn
+	metadata grpcMetadata
+	// gRPC metadata sent with every call.
+	header grpcmd.MD
+	// Descriptors from the protoset, or nil if they are obtained with server reflection.
+	files      *protoregistry.Files
+	reflection *grpcreflect.Client
+	// Cancels the stream used by the reflection client.
+	cancelReflection context.CancelFunc
+	unmarshalOpts    protojson.UnmarshalOptions
+	marshalOpts      protojson.MarshalOptions
+	logger           logger.Logger
+}
+
+// NewGRPC returns a new gRPC output binding.
+func NewGRPC(logger logger.Logger) bindings.OutputBinding {
+	return &GRPC{logger: logger}
+}
+
+// Init performs metadata parsing and connects to the server.
+func (g *GRPC) Init(_ context.Context, meta bindings.Metadata) (err error) {
+	g.metadata, err = parseMetadata(meta.Properties)
+	if err != nil {
+		return fmt.Errorf("grpc binding: failed to parse metadata: %w", err)
+	}
+	g.header = grpcmd.MD{}
+	for k, v := range meta.Properties {
+		if strings.HasPrefix(k, headerPrefix) {
+			g.header.Append(strings.TrimPrefix(k, headerPrefix), v)
+		}
+	}
+
+	g.files, err = g.metadata.protosetFiles()
+	if err != nil {
+		return fmt.Errorf("grpc binding: %w", err)
+	}
+	if g.files != nil {
+		// Messages in google.protobuf.Any fields are resolved with the types in the protoset
+		types := dynamicpb.NewTypes(g.files)
+		g.unmarshalOpts.Resolver = types
+		g.marshalOpts.Resolver = types
+	}
+
+	tlsConfig, err := g.metadata.tlsConfig()
+	if err != nil {
+		return fmt.Errorf("grpc binding: %w", err)
+	}
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	// The connection is established when the first call is made, and re-established when it's lost
+	g.conn, err = grpc.NewClient(g.metadata.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return fmt.Errorf("grpc binding: failed to create connection to %s: %w", g.metadata.Address, err)
+	}
+
+	if g.files == nil {
+		var ctx context.Context
+		ctx, g.cancelReflection = context.WithCancel(context.Background())
+		g.reflection = grpcreflect.NewClientAuto(ctx, g.conn)
+		// Messages in google.protobuf.Any fields are resolved with server reflection too
+		resolver := reflectionResolver{client: g.reflection}
+		g.unmarshalOpts.Resolver = resolver
+		g.marshalOpts.Resolver = resolver
+	}
+	return nil
+}
+
+// Operations returns list of operations supported by gRPC binding.
+func (g *GRPC) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{invokeOperation}
+}
+
+// Invoke calls the method in the request metadata, with the request data as JSON representation of the request message.
+// The response data is the JSON representation of the response message, and the response metadata contains the headers and trailers of the response.
+func (g *GRPC) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	if req.Operation != invokeOperation {
+		return nil, fmt.Errorf("grpc binding: unsupported operation %s", req.Operation)
+	}
+
+	method, err := g.resolveMethod(req.Metadata[methodKey])
+	if err != nil {
+		return nil, fmt.Errorf("grpc binding: %w", err)
+	}
+
+	in := dynamicpb.NewMessage(method.Input())
+	if len(bytes.TrimSpace(req.Data)) > 0 {
+		err = g.unmarshalOpts.Unmarshal(req.Data, in)
+		if err != nil {
+			return nil, fmt.Errorf("grpc binding: data is not a valid %s message: %w", method.Input().FullName(), err)
+		}
+	}
+
+	timeout := g.metadata.Timeout
+	if val := req.Metadata[timeoutKey]; val != "" {
+		timeout, err = time.ParseDuration(val)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("grpc binding: invalid %s in request metadata: %s", timeoutKey, val)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	header := g.header.Copy()
+	for k, v := range req.Metadata {
+		if strings.HasPrefix(k, headerPrefix) {
+			header.Set(strings.TrimPrefix(k, headerPrefix), v)
+		}
+	}
+	if header.Len() > 0 {
+		ctx = grpcmd.NewOutgoingContext(ctx, header)
+	}
+
+	fullMethod := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+	out := dynamicpb.NewMessage(method.Output())
+	var resHeader, resTrailer grpcmd.MD
+	err = g.conn.Invoke(ctx, fullMethod, in, out, grpc.Header(&resHeader), grpc.Trailer(&resTrailer))
+	if err != nil {
+		return nil, fmt.Errorf("grpc binding: call to %s failed: %w", fullMethod, err)
+	}
+
+	data, err := g.marshalOpts.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("grpc binding: failed to encode response as JSON: %w", err)
+	}
+	resMetadata := make(map[string]string, resHeader.Len()+resTrailer.Len())
+	for _, md := range []grpcmd.MD{resHeader, resTrailer} {
+		for k, v := range md {
+			resMetadata[k] = strings.Join(v, ", ")
+		}
+	}
+	return &bindings.InvokeResponse{
+		Data:        data,
+		Metadata:    resMetadata,
+		ContentType: ptr.Of("application/json"),
+	}, nil
+}
+
+// resolveMethod returns the descriptor of a unary method, in the format "package.Service/Method".
+func (g *GRPC) resolveMethod(name string) (protoreflect.MethodDescriptor, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing %s in request metadata", methodKey)
+	}
+	serviceName, methodName, ok := strings.Cut(strings.TrimPrefix(name, "/"), "/")
+	if !ok || serviceName == "" || methodName == "" {
+		return nil, fmt.Errorf("invalid %s '%s': must be in the format 'package.Service/Method'", methodKey, name)
+	}
+
+	var service protoreflect.ServiceDescriptor
+	if g.files != nil {
+		d, err := g.files.FindDescriptorByName(protoreflect.FullName(serviceName))
+		if err != nil {
+			return nil, fmt.Errorf("service %s not found in protoset: %w", serviceName, err)
+		}
+		service, ok = d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a service", serviceName)
+		}
+	} else {
+		d, err := g.reflection.ResolveService(serviceName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve service %s with server reflection: %w", serviceName, err)
+		}
+		service = d.UnwrapService()
+	}
+
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, fmt.Errorf("method %s not found in service %s", methodName, serviceName)
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, errors.New("only unary methods are supported")
+	}
+	return method, nil
+}
+
+func (g *GRPC) Close() error {
+	if g.reflection != nil {
+		g.cancelReflection()
+		g.reflection.Reset()
+	}
+	if g.conn != nil {
+		return g.conn.Close()
+	}
+	return nil
+}
+
+// reflectionResolver resolves message and extension types with server reflection.
+type reflectionResolver struct {
+	client *grpcreflect.Client
+}
+
+func (r reflectionResolver) FindMessageByName(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	d, err := r.client.ResolveMessage(string(name))
+	if err != nil {
+		return nil, notFoundError(err)
+	}
+	return dynamicpb.NewMessageType(d.UnwrapMessage()), nil
+}
+
+func (r reflectionResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	// The message name is the part of the URL after the last "/", as in "type.googleapis.com/package.Message"
+	name := url[strings.LastIndexByte(url, '/')+1:]
+	return r.FindMessageByName(protoreflect.FullName(name))
+}
+
+func (r reflectionResolver) FindExtensionByName(name protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	fd, err := r.client.FileContainingSymbol(string(name))
+	if err != nil {
+		return nil, notFoundError(err)
+	}
+	d, ok := fd.FindSymbol(string(name)).(*desc.FieldDescriptor)
+	if !ok || !d.IsExtension() {
+		return nil, protoregistry.NotFound
+	}
+	return dynamicpb.NewExtensionType(d.UnwrapField()), nil
+}
+
+func (r reflectionResolver) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	d, err := r.client.ResolveExtension(string(message), int32(field))
+	if err != nil {
+		return nil, notFoundError(err)
+	}
+	return dynamicpb.NewExtensionType(d.UnwrapField()), nil
+}
+
+// notFoundError returns protoregistry.NotFound if the server doesn't know the element, which lets protojson handle unknown extensions.
+func notFoundError(err error) error {
+	if grpcreflect.IsElementNotFoundError(err) {
+		return protoregistry.NotFound
+	}
+	return err
+}
+
+// GetComponentMetadata returns the metadata of the component.
+func (g *GRPC) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
+	metadataStruct := grpcMetadata{}
+	metadata.GetMetadataInfoFromStructType(reflect.TypeOf(metadataStruct), &metadataInfo, metadata.BindingType)
+	return
+}
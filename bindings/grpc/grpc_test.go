@@ -0,0 +1,350 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	grpcmd "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+// testHealthServer is a health service that responds according to the requested service name.
+type testHealthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (s *testHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	switch req.GetService() {
+	case "slow":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	case "unknown":
+		return nil, status.Error(codes.NotFound, "unknown service")
+	case "echo-header":
+		// Responds with the value of the "x-token" header in the "x-token" trailer
+		md, _ := grpcmd.FromIncomingContext(ctx)
+		grpc.SetTrailer(ctx, grpcmd.MD{"x-token": md.Get("x-token")})
+	}
+	grpc.SetHeader(ctx, grpcmd.Pairs("x-server", "test"))
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func startServer(t *testing.T, opts ...grpc.ServerOption) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer(opts...)
+	healthpb.RegisterHealthServer(srv, &testHealthServer{})
+	reflection.Register(srv)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+	return listener.Addr().String()
+}
+
+func newBinding(t *testing.T, properties map[string]string) *GRPC {
+	t.Helper()
+
+	g := NewGRPC(logger.NewLogger("test")).(*GRPC)
+	err := g.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: properties}})
+	require.NoError(t, err)
+	t.Cleanup(func() { g.Close() })
+	return g
+}
+
+// healthProtoset returns the protoset with the descriptors of the health service.
+func healthProtoset(t *testing.T) []byte {
+	t.Helper()
+
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto)},
+	}
+	data, err := proto.Marshal(set)
+	require.NoError(t, err)
+	return data
+}
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{"address": "localhost:50051"})
+		require.NoError(t, err)
+		assert.Equal(t, defaultTimeout, m.Timeout)
+		assert.False(t, m.EnableTLS)
+
+		cfg, err := m.tlsConfig()
+		require.NoError(t, err)
+		assert.Nil(t, cfg)
+		files, err := m.protosetFiles()
+		require.NoError(t, err)
+		assert.Nil(t, files)
+	})
+
+	t.Run("TLS options enable TLS", func(t *testing.T) {
+		m, err := parseMetadata(map[string]string{"address": "localhost:50051", "tlsServerName": "server"})
+		require.NoError(t, err)
+		assert.True(t, m.EnableTLS)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		for name, properties := range map[string]map[string]string{
+			"missing address":         {},
+			"invalid timeout":         {"address": "localhost:50051", "timeout": "-1s"},
+			"two protosets":           {"address": "localhost:50051", "protosetFile": "a.protoset", "protoset": "Cg=="},
+			"client cert without key": {"address": "localhost:50051", "clientCert": "cert"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := parseMetadata(properties)
+				require.Error(t, err)
+			})
+		}
+	})
+
+	t.Run("invalid protoset", func(t *testing.T) {
+		for _, protoset := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("not a protoset"))} {
+			m, err := parseMetadata(map[string]string{"address": "localhost:50051", "protoset": protoset})
+			require.NoError(t, err)
+			_, err = m.protosetFiles()
+			require.Error(t, err)
+		}
+	})
+}
+
+func TestInvoke(t *testing.T) {
+	addr := startServer(t)
+
+	protosetFile := filepath.Join(t.TempDir(), "health.protoset")
+	require.NoError(t, os.WriteFile(protosetFile, healthProtoset(t), 0o600))
+
+	for name, properties := range map[string]map[string]string{
+		"protoset file":     {"protosetFile": protosetFile},
+		"protoset":          {"protoset": base64.StdEncoding.EncodeToString(healthProtoset(t))},
+		"server reflection": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			properties["address"] = addr
+			properties["header:x-token"] = "component"
+			g := newBinding(t, properties)
+
+			invoke := func(data string, meta map[string]string) (*bindings.InvokeResponse, error) {
+				return g.Invoke(context.Background(), &bindings.InvokeRequest{
+					Operation: invokeOperation,
+					Data:      []byte(data),
+					Metadata:  meta,
+				})
+			}
+
+			t.Run("unary call", func(t *testing.T) {
+				res, err := invoke(`{"service": "dapr"}`, map[string]string{methodKey: "grpc.health.v1.Health/Check"})
+				require.NoError(t, err)
+				assert.JSONEq(t, `{"status": "SERVING"}`, string(res.Data))
+				assert.Equal(t, "application/json", *res.ContentType)
+				assert.Equal(t, "test", res.Metadata["x-server"])
+
+				// The data can be empty, and the method can start with "/"
+				_, err = invoke("", map[string]string{methodKey: "/grpc.health.v1.Health/Check"})
+				require.NoError(t, err)
+			})
+
+			t.Run("headers", func(t *testing.T) {
+				res, err := invoke(`{"service": "echo-header"}`, map[string]string{methodKey: "grpc.health.v1.Health/Check"})
+				require.NoError(t, err)
+				assert.Equal(t, "component", res.Metadata["x-token"])
+
+				res, err = invoke(`{"service": "echo-header"}`, map[string]string{methodKey: "grpc.health.v1.Health/Check", "header:x-token": "request"})
+				require.NoError(t, err)
+				assert.Equal(t, "request", res.Metadata["x-token"])
+			})
+
+			t.Run("error status", func(t *testing.T) {
+				_, err := invoke(`{"service": "unknown"}`, map[string]string{methodKey: "grpc.health.v1.Health/Check"})
+				require.Error(t, err)
+				assert.Equal(t, codes.NotFound, status.Code(err))
+			})
+
+			t.Run("deadline", func(t *testing.T) {
+				start := time.Now()
+				_, err := invoke(`{"service": "slow"}`, map[string]string{methodKey: "grpc.health.v1.Health/Check", timeoutKey: "100ms"})
+				require.Error(t, err)
+				assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+				assert.Less(t, time.Since(start), 5*time.Second)
+			})
+
+			t.Run("invalid requests", func(t *testing.T) {
+				for name, meta := range map[string]map[string]string{
+					"missing method":   {},
+					"invalid method":   {methodKey: "grpc.health.v1.Health.Check"},
+					"unknown service":  {methodKey: "grpc.health.v1.Missing/Check"},
+					"unknown method":   {methodKey: "grpc.health.v1.Health/Missing"},
+					"streaming method": {methodKey: "grpc.health.v1.Health/Watch"},
+					"invalid timeout":  {methodKey: "grpc.health.v1.Health/Check", timeoutKey: "soon"},
+				} {
+					t.Run(name, func(t *testing.T) {
+						_, err := invoke(`{}`, meta)
+						require.Error(t, err)
+					})
+				}
+
+				_, err := invoke(`{"unknownField": 1}`, map[string]string{methodKey: "grpc.health.v1.Health/Check"})
+				require.ErrorContains(t, err, "not a valid grpc.health.v1.HealthCheckRequest message")
+
+				_, err = g.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+				require.Error(t, err)
+			})
+		})
+	}
+}
+
+func TestReflectionResolver(t *testing.T) {
+	addr := startServer(t)
+	g := newBinding(t, map[string]string{"address": addr})
+
+	// Messages in google.protobuf.Any fields are resolved with server reflection
+	const data = `{"@type": "type.googleapis.com/grpc.health.v1.HealthCheckRequest", "service": "dapr"}`
+	msg := &anypb.Any{}
+	require.NoError(t, g.unmarshalOpts.Unmarshal([]byte(data), msg))
+	assert.Equal(t, "type.googleapis.com/grpc.health.v1.HealthCheckRequest", msg.GetTypeUrl())
+	out, err := g.marshalOpts.Marshal(msg)
+	require.NoError(t, err)
+	assert.JSONEq(t, data, string(out))
+
+	err = g.unmarshalOpts.Unmarshal([]byte(`{"@type": "type.googleapis.com/grpc.health.v1.Missing"}`), &anypb.Any{})
+	require.ErrorContains(t, err, "unable to resolve")
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+
+	serverPair, err := tls.X509KeyPair(serverCert, serverKey)
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(ca.certPEM)
+	addr := startServer(t, grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverPair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	})))
+
+	invoke := func(properties map[string]string) error {
+		properties["address"] = addr
+		properties["caCert"] = string(ca.certPEM)
+		properties["tlsServerName"] = "server"
+		properties["timeout"] = "5s"
+		g := newBinding(t, properties)
+		_, err := g.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: invokeOperation,
+			Metadata:  map[string]string{methodKey: "grpc.health.v1.Health/Check"},
+		})
+		return err
+	}
+
+	t.Run("with client certificate", func(t *testing.T) {
+		err := invoke(map[string]string{
+			"clientCert": string(clientCert),
+			"clientKey":  string(clientKey),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("without client certificate", func(t *testing.T) {
+		err := invoke(map[string]string{})
+		require.Error(t, err)
+	})
+}
+
+type testCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue returns a PEM-encoded certificate and key for the DNS name.
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (certPEM []byte, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	kitmd "github.com/dapr/kit/metadata"
+)
+
+const (
+	// Prefix of metadata keys that are sent as gRPC metadata, in the component and request metadata.
+	headerPrefix = "header:"
+
+	defaultTimeout = 30 * time.Second
+)
+
+type grpcMetadata struct {
+	// Address of the server, such as "localhost:50051" or "unix:///path/to/socket".
+	Address string `mapstructure:"address"`
+	// Path to a protoset file (a FileDescriptorSet created with "protoc --include_imports --descriptor_set_out"), with the descriptors of the services.
+	// If neither protosetFile nor protoset is set, the descriptors are obtained with server reflection.
+	ProtosetFile string `mapstructure:"protosetFile"`
+	// Base64-encoded protoset, as an alternative to protosetFile.
+	Protoset string `mapstructure:"protoset"`
+	// Deadline of each call, which can be overridden per request with the "timeout" metadata.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// If true, connections to the server use TLS.
+	// TLS is enabled implicitly when any of the other TLS options is set.
+	EnableTLS bool `mapstructure:"enableTLS"`
+	// PEM-encoded CA certificate used to verify the server's certificate.
+	CACert string `mapstructure:"caCert"`
+	// PEM-encoded client certificate and key, for mutual TLS.
+	ClientCert string `mapstructure:"clientCert"`
+	ClientKey  string `mapstructure:"clientKey"`
+	// Server name used to verify the server's certificate. Defaults to the host of the address.
+	TLSServerName      string `mapstructure:"tlsServerName"`
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
+}
+
+func parseMetadata(meta map[string]string) (grpcMetadata, error) {
+	m := grpcMetadata{
+		Timeout: defaultTimeout,
+	}
+	err := kitmd.DecodeMetadata(meta, &m)
+	if err != nil {
+		return m, err
+	}
+
+	if m.Address == "" {
+		return m, errors.New("missing address")
+	}
+	if m.Timeout <= 0 {
+		return m, errors.New("timeout must be greater than 0")
+	}
+	if m.ProtosetFile != "" && m.Protoset != "" {
+		return m, errors.New("only one of protosetFile and protoset can be set")
+	}
+	if (m.ClientCert == "") != (m.ClientKey == "") {
+		return m, errors.New("clientCert and clientKey must be set together")
+	}
+	if m.CACert != "" || m.ClientCert != "" || m.TLSServerName != "" || m.InsecureSkipVerify {
+		m.EnableTLS = true
+	}
+
+	return m, nil
+}
+
+// tlsConfig returns the TLS configuration of the connection, or nil if TLS is disabled.
+func (m grpcMetadata) tlsConfig() (*tls.Config, error) {
+	if !m.EnableTLS {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		ServerName:         m.TLSServerName,
+		InsecureSkipVerify: m.InsecureSkipVerify, //nolint:gosec
+		MinVersion:         tls.VersionTLS12,
+	}
+	if m.CACert != "" {
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM([]byte(m.CACert)) {
+			return nil, errors.New("invalid caCert")
+		}
+	}
+	if m.ClientCert != "" {
+		cert, err := tls.X509KeyPair([]byte(m.ClientCert), []byte(m.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid clientCert or clientKey: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// protosetFiles returns the files in the protoset, or nil if no protoset is configured.
+func (m grpcMetadata) protosetFiles() (*protoregistry.Files, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch {
+	case m.ProtosetFile != "":
+		data, err = os.ReadFile(m.ProtosetFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read protosetFile: %w", err)
+		}
+	case m.Protoset != "":
+		data, err = base64.StdEncoding.DecodeString(strings.TrimSpace(m.Protoset))
+		if err != nil {
+			return nil, fmt.Errorf("protoset is not base64-encoded: %w", err)
+		}
+	default:
+		return nil, nil
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	err = proto.Unmarshal(data, &set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protoset: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protoset (it must include the imported files): %w", err)
+	}
+	return files, nil
+}
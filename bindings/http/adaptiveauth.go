@@ -0,0 +1,263 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/md5" //nolint:gosec
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	authSchemeBasic  = "basic"
+	authSchemeBearer = "bearer"
+	authSchemeDigest = "digest"
+	// Used only with authType
+	authSchemeNTLM      = "ntlm"
+	authSchemeNegotiate = "negotiate"
+)
+
+// authChallenge is a challenge in a WWW-Authenticate header.
+type authChallenge struct {
+	// Scheme, in lowercase
+	scheme string
+	// Auth parameters, with lowercase names
+	params map[string]string
+}
+
+// parseAuthChallenges parses the challenges in the values of the WWW-Authenticate header.
+// A header value can contain multiple comma-separated challenges, each one followed by its comma-separated parameters.
+// Challenges using the token68 syntax are returned without parameters.
+func parseAuthChallenges(values []string) []authChallenge {
+	var res []authChallenge
+	for _, val := range values {
+		for _, part := range splitQuoted(val, ',') {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			// A part that starts with a token followed by a space begins a new challenge
+			if name, rest, ok := strings.Cut(part, " "); ok && isToken(name) {
+				res = append(res, authChallenge{
+					scheme: strings.ToLower(name),
+					params: map[string]string{},
+				})
+				part = strings.TrimSpace(rest)
+			} else if !strings.Contains(part, "=") {
+				res = append(res, authChallenge{
+					scheme: strings.ToLower(part),
+					params: map[string]string{},
+				})
+				continue
+			}
+
+			if len(res) == 0 {
+				continue
+			}
+			k, v, ok := strings.Cut(part, "=")
+			k = strings.TrimSpace(k)
+			v = strings.TrimSpace(v)
+			if !ok || !isToken(k) || strings.Trim(v, "=") == "" {
+				// token68 values (which end with optional padding) are ignored
+				continue
+			}
+			if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+				v = unquote(v[1 : len(v)-1])
+			}
+			res[len(res)-1].params[strings.ToLower(k)] = v
+		}
+	}
+	return res
+}
+
+// hasAuthCredentials returns true if credentials are configured for the scheme.
+func (h *HTTPSource) hasAuthCredentials(scheme string) bool {
+	switch scheme {
+	case authSchemeBasic, authSchemeDigest:
+		return h.metadata.AuthUsername != ""
+	case authSchemeBearer:
+		return h.metadata.AuthBearerToken != ""
+	default:
+		return false
+	}
+}
+
+// retryWithAuth handles a 401 response when adaptiveAuth is enabled, or authType is "digest", "ntlm", or "negotiate".
+// If credentials are configured for one of the schemes in the challenges, the request is sent again, once, with the Authorization header for that scheme; the scheme used is returned.
+// With authType "digest" and without adaptiveAuth, only Digest challenges are answered. With authType "ntlm" or "negotiate", only challenges for that scheme are answered, performing its handshake.
+// Otherwise, or if the request body can't be sent again, the original response is returned.
+func (h *HTTPSource) retryWithAuth(request *http.Request, resp *http.Response) (*http.Response, string, error) {
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+		return resp, "", nil
+	}
+
+	switch h.metadata.AuthType {
+	case authSchemeNTLM, authSchemeNegotiate:
+		handshake := h.ntlmHandshake
+		if h.metadata.AuthType == authSchemeNegotiate {
+			handshake = h.negotiateHandshake
+		}
+		resp, ok, err := handshake(request, resp)
+		if !ok {
+			return resp, "", err
+		}
+		return resp, h.metadata.AuthType, err
+	}
+
+	var challenge *authChallenge
+	for _, c := range parseAuthChallenges(resp.Header.Values("WWW-Authenticate")) {
+		if !h.metadata.AdaptiveAuth && c.scheme != authSchemeDigest {
+			continue
+		}
+		if h.hasAuthCredentials(c.scheme) {
+			challenge = &c
+			break
+		}
+	}
+	if challenge == nil {
+		return resp, "", nil
+	}
+
+	authorization, err := h.authorizationHeader(request, challenge)
+	if err != nil {
+		// The challenge can't be answered (e.g. unsupported digest algorithm)
+		h.logger.Debugf("Cannot answer %s challenge from %s: %v", challenge.scheme, request.URL.Redacted(), err)
+		return resp, "", nil
+	}
+
+	resp, err = h.resendWithAuthorization(request, resp, authorization)
+	return resp, challenge.scheme, err
+}
+
+// resendWithAuthorization discards the response, and sends the request again with the Authorization header.
+// The request body must be nil, or have GetBody set.
+func (h *HTTPSource) resendWithAuthorization(request *http.Request, resp *http.Response, authorization string) (*http.Response, error) {
+	// Discard the response before retrying, so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if request.GetBody != nil {
+		var err error
+		request.Body, err = request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+	request.Header.Set("Authorization", authorization)
+	return h.client.Do(request)
+}
+
+// authorizationHeader returns the value of the Authorization header that answers the challenge.
+func (h *HTTPSource) authorizationHeader(request *http.Request, challenge *authChallenge) (string, error) {
+	switch challenge.scheme {
+	case authSchemeBasic:
+		r := &http.Request{Header: http.Header{}}
+		r.SetBasicAuth(h.metadata.AuthUsername, h.metadata.AuthPassword)
+		return r.Header.Get("Authorization"), nil
+	case authSchemeBearer:
+		return "Bearer " + h.metadata.AuthBearerToken, nil
+	case authSchemeDigest:
+		nc := uint32(1)
+		if h.metadata.AuthType == authSchemeDigest {
+			// The challenge is reused by the following requests
+			nc = h.digestSession.update(challenge.params)
+		}
+		return h.digestAuthorization(request, challenge.params, nc)
+	default:
+		return "", fmt.Errorf("unsupported scheme %s", challenge.scheme)
+	}
+}
+
+// digestAuthorization computes the response to a Digest challenge (RFC 7616), for the MD5 and SHA-256 algorithms.
+// If the server offers the "auth" quality of protection, it's used; "auth-int" is not supported.
+// The nonce count is nc, which is greater than 1 when the nonce of a previous challenge is reused.
+func (h *HTTPSource) digestAuthorization(request *http.Request, params map[string]string, nc uint32) (string, error) {
+	algorithm := params["algorithm"]
+	if algorithm == "" {
+		algorithm = "MD5"
+	}
+	var newHash func() hash.Hash
+	switch strings.ToUpper(algorithm) {
+	case "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("unsupported digest algorithm %s", algorithm)
+	}
+	hashHex := func(s string) string {
+		hh := newHash()
+		hh.Write([]byte(s))
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+
+	realm := params["realm"]
+	nonce := params["nonce"]
+	if nonce == "" {
+		return "", errors.New("missing nonce")
+	}
+	uri := request.URL.RequestURI()
+	ha1 := hashHex(h.metadata.AuthUsername + ":" + realm + ":" + h.metadata.AuthPassword)
+	ha2 := hashHex(request.Method + ":" + uri)
+
+	var qop string
+	for _, q := range strings.Split(params["qop"], ",") {
+		if strings.TrimSpace(q) == "auth" {
+			qop = "auth"
+			break
+		}
+	}
+	if qop == "" && params["qop"] != "" {
+		return "", fmt.Errorf("unsupported qop %s", params["qop"])
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `Digest username="%s", realm="%s", nonce="%s", uri="%s", algorithm=%s`, h.metadata.AuthUsername, realm, nonce, uri, algorithm)
+	if qop != "" {
+		cnonceBytes := make([]byte, 16)
+		_, err := io.ReadFull(rand.Reader, cnonceBytes)
+		if err != nil {
+			return "", err
+		}
+		cnonce := hex.EncodeToString(cnonceBytes)
+		ncHex := fmt.Sprintf("%08x", nc)
+		fmt.Fprintf(&b, `, response="%s", qop=%s, nc=%s, cnonce="%s"`, hashHex(ha1+":"+nonce+":"+ncHex+":"+cnonce+":"+qop+":"+ha2), qop, ncHex, cnonce)
+	} else {
+		fmt.Fprintf(&b, `, response="%s"`, hashHex(ha1+":"+nonce+":"+ha2))
+	}
+	if opaque, ok := params["opaque"]; ok {
+		fmt.Fprintf(&b, `, opaque="%s"`, opaque)
+	}
+	return b.String(), nil
+}
+
+// addAuthChallengeMetadata adds the scheme and realm of the first challenge in a 401 response to the metadata, so callers can react.
+func addAuthChallengeMetadata(resp *http.Response, metadata map[string]string) {
+	challenges := parseAuthChallenges(resp.Header.Values("WWW-Authenticate"))
+	if len(challenges) == 0 {
+		return
+	}
+	metadata["authScheme"] = challenges[0].scheme
+	if realm := challenges[0].params["realm"]; realm != "" {
+		metadata["authRealm"] = realm
+	}
+}
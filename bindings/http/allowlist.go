@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const regexURLPatternPrefix = "regex:"
+
+// compileURLPatterns compiles the list of allowed URL patterns into regular expressions.
+// Patterns prefixed with "regex:" are used as-is (but always anchored), while all others are treated as globs.
+func compileURLPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		var expr string
+		if strings.HasPrefix(p, regexURLPatternPrefix) {
+			expr = "^(?:" + strings.TrimPrefix(p, regexURLPatternPrefix) + ")$"
+		} else {
+			expr = globToRegexp(p)
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// globToRegexp converts a glob into an anchored regular expression.
+// "*" matches any sequence of characters (including "/"), and "?" matches a single character.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// isURLAllowed returns true if the URL matches at least one of the allowed patterns, or if no pattern is configured.
+func (h *HTTPSource) isURLAllowed(u *url.URL) bool {
+	if len(h.metadata.allowedURLPatterns) == 0 {
+		return true
+	}
+
+	str := u.String()
+	for _, re := range h.metadata.allowedURLPatterns {
+		if re.MatchString(str) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const (
+	uploadArchiveOperation bindings.OperationKind = "uploadArchive"
+
+	archiveFormatTar   = "tar"
+	archiveFormatTarGz = "tar.gz"
+)
+
+// errArchiveRequestCompleted stops writing the archive when the request has completed before the server read the entire body.
+var errArchiveRequestCompleted = errors.New("request completed")
+
+// archiveUpload streams a directory as a tar archive, optionally gzip-compressed, to be used as the request body.
+// The archive is written by a background goroutine into a pipe, so it's never buffered in memory.
+type archiveUpload struct {
+	sourcePath  string
+	gzip        bool
+	exclude     []string
+	contentType string
+
+	pr *io.PipeReader
+	pw *io.PipeWriter
+
+	started   bool
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+
+	// Size of the tar stream, and of the bytes sent after compression
+	uncompressed countingWriter
+	compressed   countingWriter
+}
+
+// newArchiveUpload validates the metadata of the uploadArchive operation.
+func newArchiveUpload(md map[string]string) (*archiveUpload, error) {
+	a := &archiveUpload{
+		sourcePath: md["sourcePath"],
+		done:       make(chan struct{}),
+	}
+	if a.sourcePath == "" {
+		return nil, errors.New("the sourcePath metadata property is required for the uploadArchive operation")
+	}
+	info, err := os.Stat(a.sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sourcePath: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("invalid sourcePath: %s is not a directory", a.sourcePath)
+	}
+
+	switch strings.ToLower(md["archiveFormat"]) {
+	case archiveFormatTar:
+		a.contentType = "application/x-tar"
+	case "", archiveFormatTarGz:
+		a.gzip = true
+		a.contentType = "application/gzip"
+	default:
+		return nil, fmt.Errorf("invalid value for archiveFormat: %s (must be %q or %q)", md["archiveFormat"], archiveFormatTar, archiveFormatTarGz)
+	}
+
+	if val := md["archiveExclude"]; val != "" {
+		for _, p := range strings.Split(val, ",") {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			_, err = path.Match(p, "")
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern in archiveExclude: %q: %w", p, err)
+			}
+			a.exclude = append(a.exclude, p)
+		}
+	}
+
+	a.pr, a.pw = io.Pipe()
+	return a, nil
+}
+
+// reader returns the body of the request.
+func (a *archiveUpload) reader() io.Reader {
+	return a.pr
+}
+
+// start begins writing the archive in background.
+// Writing stops with an error if the context is canceled.
+func (a *archiveUpload) start(ctx context.Context) {
+	a.started = true
+	go func() {
+		defer close(a.done)
+		err := a.write(ctx)
+		a.pw.CloseWithError(err)
+		a.err = err
+	}()
+}
+
+// close stops writing the archive if still in progress, and returns the error that occurred while writing it, if any.
+func (a *archiveUpload) close() error {
+	a.closeOnce.Do(func() {
+		a.pr.CloseWithError(errArchiveRequestCompleted)
+		if a.started {
+			<-a.done
+		}
+	})
+	return a.err
+}
+
+// addMetadata adds the byte counts to the response metadata.
+func (a *archiveUpload) addMetadata(metadata map[string]string) {
+	metadata["archiveUncompressedBytes"] = strconv.FormatInt(a.uncompressed.n, 10)
+	metadata["archiveCompressedBytes"] = strconv.FormatInt(a.compressed.n, 10)
+}
+
+func (a *archiveUpload) write(ctx context.Context) error {
+	a.compressed.w = a.pw
+	var gw *gzip.Writer
+	if a.gzip {
+		gw = gzip.NewWriter(&a.compressed)
+		a.uncompressed.w = gw
+	} else {
+		a.uncompressed.w = &a.compressed
+	}
+	tw := tar.NewWriter(&a.uncompressed)
+
+	err := filepath.WalkDir(a.sourcePath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		rel, err := filepath.Rel(a.sourcePath, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if a.isExcluded(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return a.writeEntry(ctx, tw, p, rel, d)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+	if gw != nil {
+		return gw.Close()
+	}
+	return nil
+}
+
+// writeEntry adds a file, directory, or symbolic link to the archive.
+func (a *archiveUpload) writeEntry(ctx context.Context, tw *tar.Writer, p string, rel string, d fs.DirEntry) error {
+	info, err := d.Info()
+	if err != nil {
+		return err
+	}
+	var link string
+	if info.Mode()&fs.ModeSymlink != 0 {
+		link, err = os.Readlink(p)
+		if err != nil {
+			return err
+		}
+	}
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	hdr.Name = rel
+	if info.IsDir() {
+		hdr.Name += "/"
+	}
+	err = tw.WriteHeader(hdr)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(tw, &contextReader{ctx: ctx, r: f})
+	return err
+}
+
+// isExcluded returns true if the slash-separated relative path, or its base name, matches one of the exclude patterns.
+func (a *archiveUpload) isExcluded(rel string) bool {
+	for _, p := range a.exclude {
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// contextReader stops reading with an error when the context is canceled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if c.ctx.Err() != nil {
+		return 0, context.Cause(c.ctx)
+	}
+	return c.r.Read(p)
+}
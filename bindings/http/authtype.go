@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// validateAuthType validates authType and resolves the username and password aliases of authUsername and authPassword.
+func (m *httpMetadata) validateAuthType() error {
+	if m.Username != "" {
+		if m.AuthUsername != "" && m.AuthUsername != m.Username {
+			return errors.New("username and authUsername can't be set to different values")
+		}
+		m.AuthUsername = m.Username
+	}
+	if m.Password != "" {
+		if m.AuthPassword != "" && m.AuthPassword != m.Password {
+			return errors.New("password and authPassword can't be set to different values")
+		}
+		m.AuthPassword = m.Password
+	}
+
+	switch m.AuthType {
+	case "":
+		return nil
+	case authSchemeBasic, authSchemeDigest, authSchemeNTLM, authSchemeNegotiate:
+	default:
+		return fmt.Errorf("invalid value for authType: %s (must be one of %q, %q, %q, %q)", m.AuthType, authSchemeBasic, authSchemeDigest, authSchemeNTLM, authSchemeNegotiate)
+	}
+	if m.AuthUsername == "" {
+		return fmt.Errorf("username is required with authType %s", m.AuthType)
+	}
+	if m.OAuth2TokenURL != "" {
+		return errors.New("authType and oauth2TokenURL can't be used together")
+	}
+	return nil
+}
+
+// answersAuthChallenges returns true if 401 responses with a WWW-Authenticate challenge are answered with the configured credentials.
+func (m *httpMetadata) answersAuthChallenges() bool {
+	switch m.AuthType {
+	case authSchemeDigest, authSchemeNTLM, authSchemeNegotiate:
+		return true
+	default:
+		return m.AdaptiveAuth
+	}
+}
+
+// setAuthTypeHeader sets the Authorization header for authType.
+// With "basic", the credentials are always sent. With "digest", the header is set only after a challenge was received, reusing its nonce.
+// With "ntlm" and "negotiate", the header is set only when answering a challenge.
+func (h *HTTPSource) setAuthTypeHeader(request *http.Request) error {
+	switch h.metadata.AuthType {
+	case authSchemeBasic:
+		request.SetBasicAuth(h.metadata.AuthUsername, h.metadata.AuthPassword)
+	case authSchemeDigest:
+		params, nc, ok := h.digestSession.next()
+		if !ok {
+			// The first request is sent without credentials, to receive a challenge
+			return nil
+		}
+		authorization, err := h.digestAuthorization(request, params, nc)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Authorization", authorization)
+	}
+	return nil
+}
+
+// digestSession caches the last Digest challenge, so requests can be authenticated without a 401 round trip each.
+// Each request uses the cached nonce with an increasing nonce count, until the server sends a new challenge.
+type digestSession struct {
+	lock   sync.Mutex
+	params map[string]string
+	nc     uint32
+}
+
+// update stores a new challenge and returns the nonce count to answer it with.
+func (s *digestSession) update(params map[string]string) uint32 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.params = params
+	s.nc = 1
+	return s.nc
+}
+
+// next returns the cached challenge and the next nonce count, if a challenge was received.
+func (s *digestSession) next() (map[string]string, uint32, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.params == nil {
+		return nil, 0, false
+	}
+	s.nc++
+	return s.params, s.nc, true
+}
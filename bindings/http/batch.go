@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const (
+	batchOperation bindings.OperationKind = "batch"
+
+	defaultBatchConcurrency = 4
+)
+
+// batchSubRequest is a request inside a batch.
+type batchSubRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body can be a string, which is sent as-is, or any other JSON value, which is sent encoded as JSON.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// batchSubResponse is the result of a request inside a batch.
+type batchSubResponse struct {
+	StatusCode int               `json:"statusCode,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	// Body of the response, if it's valid UTF-8.
+	Body string `json:"body,omitempty"`
+	// Body of the response, base64-encoded, if it's not valid UTF-8.
+	BodyBase64 []byte `json:"bodyBase64,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// invokeBatch performs all requests in the batch, with limited concurrency, and returns all responses.
+// Failures of individual requests are reported in their response and do not fail the batch.
+func (h *HTTPSource) invokeBatch(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var subRequests []batchSubRequest
+	err := json.Unmarshal(req.Data, &subRequests)
+	if err != nil {
+		return nil, fmt.Errorf("invalid batch: data must be a JSON array of requests: %w", err)
+	}
+	for i, sr := range subRequests {
+		if sr.Method == "" {
+			return nil, fmt.Errorf("invalid batch: request %d does not have a method", i)
+		}
+		if strings.EqualFold(sr.Method, string(batchOperation)) {
+			return nil, fmt.Errorf("invalid batch: request %d cannot be a batch", i)
+		}
+	}
+
+	concurrency := h.metadata.BatchConcurrency
+	if val := req.Metadata["batchConcurrency"]; val != "" {
+		concurrency, err = strconv.Atoi(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for batchConcurrency: %s", val)
+		}
+	}
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	responses := make([]batchSubResponse, len(subRequests))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range subRequests {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			// The deadline expired before the request could start
+			responses[i].Error = ctx.Err().Error()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			responses[i] = h.invokeBatchItem(ctx, subRequests[i])
+		}(i)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, r := range responses {
+		if r.Error != "" {
+			failed++
+		}
+	}
+
+	data, err := json.Marshal(responses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode batch responses: %w", err)
+	}
+	return &bindings.InvokeResponse{
+		Data: data,
+		Metadata: map[string]string{
+			"batchSize":   strconv.Itoa(len(responses)),
+			"batchFailed": strconv.Itoa(failed),
+		},
+	}, nil
+}
+
+// invokeBatchItem performs a request inside a batch.
+func (h *HTTPSource) invokeBatchItem(ctx context.Context, sr batchSubRequest) (res batchSubResponse) {
+	subReq := &bindings.InvokeRequest{
+		Operation: bindings.OperationKind(strings.ToLower(sr.Method)),
+		Metadata:  make(map[string]string, len(sr.Headers)+1),
+	}
+	if sr.Path != "" {
+		subReq.Metadata["path"] = sr.Path
+	}
+	// Headers are passed as metadata keys starting with a capital letter
+	for k, v := range sr.Headers {
+		subReq.Metadata[http.CanonicalHeaderKey(k)] = v
+	}
+	if len(sr.Body) > 0 {
+		var str string
+		if json.Unmarshal(sr.Body, &str) == nil {
+			subReq.Data = []byte(str)
+		} else {
+			subReq.Data = sr.Body
+		}
+	}
+
+	resp, err := h.Invoke(ctx, subReq)
+	if err != nil {
+		res.Error = err.Error()
+	}
+	if resp == nil {
+		if err == nil {
+			res.Error = "no response"
+		}
+		return res
+	}
+
+	res.StatusCode, _ = strconv.Atoi(resp.Metadata["statusCode"])
+	res.Headers = make(map[string]string, len(resp.Metadata))
+	for k, v := range resp.Metadata {
+		// Response headers are the metadata keys that start with a capital letter
+		if k != "" && k[0] >= 'A' && k[0] <= 'Z' {
+			res.Headers[k] = v
+		}
+	}
+	if utf8.Valid(resp.Data) {
+		res.Body = string(resp.Data)
+	} else {
+		res.BodyBase64 = resp.Data
+	}
+	return res
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+// ErrBodyIndicatesError is returned when the response has a 2xx status code, but bodyErrorJMESPath indicates that the body contains an error.
+var ErrBodyIndicatesError = errors.New("response body indicates an error")
+
+// compileBodyErrorJMESPath compiles the bodyErrorJMESPath expression, if set.
+func (m *httpMetadata) compileBodyErrorJMESPath() (err error) {
+	if m.BodyErrorJMESPath == "" {
+		if m.BodyErrorValue != "" {
+			return errors.New("bodyErrorValue requires bodyErrorJMESPath")
+		}
+		return nil
+	}
+	m.bodyErrorExpr, err = jmespath.Compile(m.BodyErrorJMESPath)
+	if err != nil {
+		return fmt.Errorf("invalid value for bodyErrorJMESPath: %w", err)
+	}
+	return nil
+}
+
+// checkBodyError evaluates bodyErrorJMESPath against the response body, and returns the error message if the body indicates an error.
+// Bodies that are not valid JSON are skipped.
+// Without bodyErrorValue, the body indicates an error if the result of the expression is not null, false, or empty; with bodyErrorValue, if the result is equal to it.
+func (h *HTTPSource) checkBodyError(body []byte) (string, bool) {
+	var parsed any
+	if len(body) == 0 || json.Unmarshal(body, &parsed) != nil {
+		return "", false
+	}
+	res, err := h.metadata.bodyErrorExpr.Search(parsed)
+	if err != nil {
+		h.logger.Debugf("Failed to evaluate bodyErrorJMESPath: %v", err)
+		return "", false
+	}
+
+	msg := jmespathResultString(res)
+	if h.metadata.BodyErrorValue != "" {
+		return msg, msg == h.metadata.BodyErrorValue
+	}
+	return msg, jmespathTruthy(res)
+}
+
+// jmespathResultString returns strings as-is, and other values JSON-encoded.
+func jmespathResultString(res any) string {
+	if s, ok := res.(string); ok {
+		return s
+	}
+	enc, _ := json.Marshal(res)
+	return string(enc)
+}
+
+// jmespathTruthy implements the JMESPath definition of truth: null, false, and empty strings, arrays, and objects are false.
+func jmespathTruthy(res any) bool {
+	switch v := res.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	bodyOverflowPolicyFail     = "fail"
+	bodyOverflowPolicyTruncate = "truncate"
+)
+
+// ErrResponseBodyTooLarge is returned when the response body is larger than maxResponseBodySize and responseBodyOverflowPolicy is "fail".
+// The error is a *BodySizeError with the details.
+var ErrResponseBodyTooLarge = errors.New("response body too large")
+
+// BodySizeError is returned when the response body is larger than allowed.
+type BodySizeError struct {
+	// Maximum size of the body, in bytes
+	Limit int64
+	// Size of the body declared in the Content-Length header, or -1 if unknown
+	ContentLength int64
+}
+
+func (e *BodySizeError) Error() string {
+	if e.ContentLength >= 0 {
+		return fmt.Sprintf("%v: response has %d bytes, exceeding the limit of %d bytes set in maxResponseBodySize", ErrResponseBodyTooLarge, e.ContentLength, e.Limit)
+	}
+	return fmt.Sprintf("%v: response exceeds the limit of %d bytes set in maxResponseBodySize", ErrResponseBodyTooLarge, e.Limit)
+}
+
+func (e *BodySizeError) Unwrap() error {
+	return ErrResponseBodyTooLarge
+}
+
+// validateBodyOverflowPolicy validates the value of responseBodyOverflowPolicy.
+func validateBodyOverflowPolicy(val string) error {
+	switch val {
+	case "", bodyOverflowPolicyFail, bodyOverflowPolicyTruncate:
+		return nil
+	default:
+		return fmt.Errorf("invalid value for responseBodyOverflowPolicy: %s (must be %q or %q)", val, bodyOverflowPolicyFail, bodyOverflowPolicyTruncate)
+	}
+}
+
+// limitResponseBody returns a reader for the response body that stops at maxResponseBodySize.
+// With the "fail" policy, reading past the limit returns a *BodySizeError; otherwise, the rest of the body is ignored.
+// When the Content-Length of the response is known to exceed the limit, the error is returned without reading the body.
+func (h *HTTPSource) limitResponseBody(body io.Reader, contentLength int64) (io.Reader, error) {
+	limit := h.metadata.maxResponseBodySizeBytes
+	if limit <= 0 {
+		return body, nil
+	}
+	if h.metadata.ResponseBodyOverflowPolicy != bodyOverflowPolicyFail {
+		return io.LimitReader(body, limit), nil
+	}
+	if contentLength > limit {
+		return nil, &BodySizeError{Limit: limit, ContentLength: contentLength}
+	}
+	return &bodySizeLimiter{r: body, remaining: limit, limit: limit}, nil
+}
+
+// bodySizeLimiter is like io.LimitReader, but returns a *BodySizeError if there's more data after the limit.
+type bodySizeLimiter struct {
+	r         io.Reader
+	remaining int64
+	limit     int64
+}
+
+func (l *bodySizeLimiter) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if l.remaining <= 0 {
+		// Read one more byte to find out if the body continues past the limit
+		var b [1]byte
+		n, err := l.r.Read(b[:])
+		if n > 0 {
+			return 0, &BodySizeError{Limit: l.limit, ContentLength: -1}
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
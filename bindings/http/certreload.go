@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dapr/kit/logger"
+)
+
+// clientCertReloader presents the client certificate loaded from mtlsClientCert and mtlsClientKey, re-reading the files when they change.
+// The files are checked at most once per interval, and always when the certificate has expired, at the time a TLS handshake requests the certificate.
+// If the files can't be loaded (for example, because they are being rotated and the certificate doesn't match the key yet), the previous certificate is kept.
+type clientCertReloader struct {
+	certPath string
+	keyPath  string
+	interval time.Duration
+	logger   logger.Logger
+	now      func() time.Time
+
+	lock      sync.Mutex
+	cert      *tls.Certificate
+	notAfter  time.Time
+	certStat  fileStamp
+	keyStat   fileStamp
+	lastCheck time.Time
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func newClientCertReloader(certPath string, keyPath string, interval time.Duration, log logger.Logger) (*clientCertReloader, error) {
+	r := &clientCertReloader{
+		certPath: certPath,
+		keyPath:  keyPath,
+		interval: interval,
+		logger:   log,
+		now:      time.Now,
+	}
+	err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// getClientCertificate is used as tls.Config.GetClientCertificate.
+func (r *clientCertReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.now()
+	if now.Sub(r.lastCheck) >= r.interval || now.After(r.notAfter) {
+		r.lastCheck = now
+		if r.changed() {
+			err := r.load()
+			if err != nil {
+				r.logger.Warnf("Failed to reload the mTLS client certificate, the previous certificate will be used: %v", err)
+			} else {
+				r.logger.Info("Reloaded the mTLS client certificate")
+			}
+		}
+	}
+	return r.cert, nil
+}
+
+// changed returns true if the certificate or key file was modified since it was loaded.
+func (r *clientCertReloader) changed() bool {
+	certStat, err := statFile(r.certPath)
+	if err != nil {
+		return false
+	}
+	keyStat, err := statFile(r.keyPath)
+	if err != nil {
+		return false
+	}
+	return certStat != r.certStat || keyStat != r.keyStat
+}
+
+// load reads the certificate and key from the files.
+// Must be invoked while holding the lock, or before the reloader is used.
+func (r *clientCertReloader) load() error {
+	certStat, err := statFile(r.certPath)
+	if err != nil {
+		return err
+	}
+	keyStat, err := statFile(r.keyPath)
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse client certificate: %w", err)
+	}
+	cert.Leaf = leaf
+
+	r.cert = &cert
+	r.notAfter = leaf.NotAfter
+	r.certStat = certStat
+	r.keyStat = keyStat
+	r.lastCheck = r.now()
+	return nil
+}
+
+func statFile(name string) (fileStamp, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
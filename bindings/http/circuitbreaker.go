@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCircuitBreakerOpenDuration          = 30 * time.Second
+	defaultCircuitBreakerHalfOpenMaxConcurrent = 1
+	defaultCircuitBreakerHalfOpenSuccesses     = 1
+
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half-open"
+)
+
+// ErrCircuitOpen is returned when a request is rejected because the circuit breaker is open, or because the maximum number of probe requests are already in flight while half-open.
+// The error is a *CircuitOpenError with the details.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// ErrorCodeCircuitOpen is the value of the "errorCode" response metadata when a request is rejected by the circuit breaker.
+const ErrorCodeCircuitOpen = "ERR_CIRCUIT_OPEN"
+
+// CircuitOpenError is returned when a request is rejected by the circuit breaker.
+type CircuitOpenError struct {
+	// Time until the circuit breaker becomes half-open, or zero if it's already half-open
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%v: retry after %v", ErrCircuitOpen, e.RetryAfter)
+	}
+	return fmt.Sprintf("%v: too many probe requests in flight", ErrCircuitOpen)
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// addMetadata adds the error code and the time until the circuit breaker becomes half-open to the response metadata.
+func (e *CircuitOpenError) addMetadata(metadata map[string]string) {
+	metadata["errorCode"] = ErrorCodeCircuitOpen
+	if e.RetryAfter > 0 {
+		metadata["circuitBreakerState"] = circuitOpen
+		metadata["retryAfterMs"] = strconv.FormatInt(e.RetryAfter.Milliseconds(), 10)
+	} else {
+		metadata["circuitBreakerState"] = circuitHalfOpen
+	}
+}
+
+// resolveCircuitBreakerAliases copies the values of the short cb* aliases into the circuitBreaker* settings.
+func (m *httpMetadata) resolveCircuitBreakerAliases() error {
+	if m.CBFailureThreshold != 0 {
+		if m.CircuitBreakerFailureThreshold != 0 && m.CircuitBreakerFailureThreshold != m.CBFailureThreshold {
+			return errors.New("cbFailureThreshold and circuitBreakerFailureThreshold can't be set to different values")
+		}
+		m.CircuitBreakerFailureThreshold = m.CBFailureThreshold
+	}
+	if m.CBOpenDuration != 0 {
+		if m.CircuitBreakerOpenDuration != 0 && m.CircuitBreakerOpenDuration != m.CBOpenDuration {
+			return errors.New("cbOpenDuration and circuitBreakerOpenDuration can't be set to different values")
+		}
+		m.CircuitBreakerOpenDuration = m.CBOpenDuration
+	}
+	if m.CBHalfOpenRequests != 0 {
+		if m.CircuitBreakerHalfOpenMaxConcurrent != 0 && m.CircuitBreakerHalfOpenMaxConcurrent != m.CBHalfOpenRequests {
+			return errors.New("cbHalfOpenRequests and circuitBreakerHalfOpenMaxConcurrent can't be set to different values")
+		}
+		m.CircuitBreakerHalfOpenMaxConcurrent = m.CBHalfOpenRequests
+	}
+	return nil
+}
+
+// circuitBreaker stops sending requests after a number of consecutive failures.
+// After openDuration, it becomes half-open and admits a limited number of concurrent probe requests: the circuit closes after enough consecutive probe successes, and opens again on any probe failure.
+type circuitBreaker struct {
+	failureThreshold      int
+	openDuration          time.Duration
+	halfOpenMaxConcurrent int
+	halfOpenSuccesses     int
+	now                   func() time.Time
+
+	lock     sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	// Incremented on every state transition, to ignore the results of requests admitted in a previous state
+	generation     uint64
+	probesInFlight int
+	probeSuccesses int
+}
+
+// circuitTicket is returned when a request is admitted, and must be passed to record with the outcome of the request.
+type circuitTicket struct {
+	probe      bool
+	generation uint64
+}
+
+// circuitStats is a snapshot of the state of the circuit breaker.
+type circuitStats struct {
+	state          string
+	probesInFlight int
+	probeSuccesses int
+}
+
+func newCircuitBreaker(failureThreshold int, openDuration time.Duration, halfOpenMaxConcurrent int, halfOpenSuccesses int) *circuitBreaker {
+	if openDuration <= 0 {
+		openDuration = defaultCircuitBreakerOpenDuration
+	}
+	if halfOpenMaxConcurrent <= 0 {
+		halfOpenMaxConcurrent = defaultCircuitBreakerHalfOpenMaxConcurrent
+	}
+	if halfOpenSuccesses <= 0 {
+		halfOpenSuccesses = defaultCircuitBreakerHalfOpenSuccesses
+	}
+	return &circuitBreaker{
+		failureThreshold:      failureThreshold,
+		openDuration:          openDuration,
+		halfOpenMaxConcurrent: halfOpenMaxConcurrent,
+		halfOpenSuccesses:     halfOpenSuccesses,
+		now:                   time.Now,
+		state:                 circuitClosed,
+	}
+}
+
+// allow returns a ticket if the request can be sent, or a *CircuitOpenError if it must fail fast.
+func (cb *circuitBreaker) allow() (circuitTicket, error) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	if cb.state == circuitOpen {
+		if elapsed := cb.now().Sub(cb.openedAt); elapsed < cb.openDuration {
+			return circuitTicket{}, &CircuitOpenError{RetryAfter: cb.openDuration - elapsed}
+		}
+		cb.transitionLocked(circuitHalfOpen)
+	}
+
+	if cb.state == circuitHalfOpen {
+		if cb.probesInFlight >= cb.halfOpenMaxConcurrent {
+			return circuitTicket{}, &CircuitOpenError{}
+		}
+		cb.probesInFlight++
+		return circuitTicket{probe: true, generation: cb.generation}, nil
+	}
+
+	return circuitTicket{generation: cb.generation}, nil
+}
+
+// record records the outcome of an admitted request, and returns the updated state.
+func (cb *circuitBreaker) record(ticket circuitTicket, success bool) circuitStats {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	if ticket.generation == cb.generation {
+		switch {
+		case ticket.probe:
+			cb.probesInFlight--
+			if !success {
+				cb.transitionLocked(circuitOpen)
+				break
+			}
+			cb.probeSuccesses++
+			if cb.probeSuccesses >= cb.halfOpenSuccesses {
+				cb.transitionLocked(circuitClosed)
+			}
+		case success:
+			cb.failures = 0
+		default:
+			cb.failures++
+			if cb.failures >= cb.failureThreshold {
+				cb.transitionLocked(circuitOpen)
+			}
+		}
+	}
+
+	return circuitStats{
+		state:          cb.state,
+		probesInFlight: cb.probesInFlight,
+		probeSuccesses: cb.probeSuccesses,
+	}
+}
+
+// transitionLocked moves the circuit to the new state, resetting the counters.
+// Must be invoked while holding the lock.
+func (cb *circuitBreaker) transitionLocked(state string) {
+	cb.state = state
+	cb.generation++
+	cb.failures = 0
+	cb.probesInFlight = 0
+	cb.probeSuccesses = 0
+	if state == circuitOpen {
+		cb.openedAt = cb.now()
+	}
+}
+
+// addMetadata adds the state of the circuit breaker to the response metadata.
+func (s circuitStats) addMetadata(metadata map[string]string) {
+	metadata["circuitBreakerState"] = s.state
+	if s.state == circuitHalfOpen {
+		metadata["circuitBreakerProbesInFlight"] = strconv.Itoa(s.probesInFlight)
+		metadata["circuitBreakerProbeSuccesses"] = strconv.Itoa(s.probeSuccesses)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// clientCertPair is an entry of the mtlsClientCerts metadata property.
+// Both values can be PEM-encoded strings or paths to files.
+type clientCertPair struct {
+	Cert string `json:"cert"`
+	Key  string `json:"key"`
+}
+
+// clientCertCandidate is a client certificate with the issuers of all certificates in its chain.
+type clientCertCandidate struct {
+	cert    *tls.Certificate
+	issuers [][]byte
+}
+
+// setClientCertificates loads the certificates in mtlsClientCerts, and configures the client to present the one matching the CAs requested by the server.
+// Returns the (possibly new) tls.Config.
+func (h *HTTPSource) setClientCertificates(tlsConfig *tls.Config) (*tls.Config, error) {
+	var pairs []clientCertPair
+	err := json.Unmarshal([]byte(h.metadata.MTLSClientCerts), &pairs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for mtlsClientCerts: must be a JSON array of objects with cert and key: %w", err)
+	}
+	if len(pairs) == 0 {
+		return nil, errors.New("invalid value for mtlsClientCerts: no certificates specified")
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	// The certificate in mtlsClientCert, if any, is the first candidate
+	certs := make([]tls.Certificate, 0, len(tlsConfig.Certificates)+len(pairs))
+	certs = append(certs, tlsConfig.Certificates...)
+	for i, p := range pairs {
+		certBytes, err := h.getPemBytes(fmt.Sprintf("mtlsClientCerts[%d].cert", i), p.Cert)
+		if err != nil {
+			return nil, err
+		}
+		keyBytes, err := h.getPemBytes(fmt.Sprintf("mtlsClientCerts[%d].key", i), p.Key)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := tls.X509KeyPair(certBytes, keyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate %d: %w", i, err)
+		}
+		certs = append(certs, cert)
+	}
+
+	candidates := make([]clientCertCandidate, len(certs))
+	for i := range certs {
+		candidates[i].cert = &certs[i]
+		for _, der := range certs[i].Certificate {
+			parsed, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse client certificate %d: %w", i, err)
+			}
+			candidates[i].issuers = append(candidates[i].issuers, parsed.RawIssuer)
+		}
+	}
+
+	tlsConfig.GetClientCertificate = func(cri *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return selectClientCertificate(candidates, cri.AcceptableCAs), nil
+	}
+	return tlsConfig, nil
+}
+
+// selectClientCertificate returns the first certificate whose chain was issued by one of the acceptable CAs.
+// If none matches, or the server doesn't indicate acceptable CAs, the first certificate is returned.
+func selectClientCertificate(candidates []clientCertCandidate, acceptableCAs [][]byte) *tls.Certificate {
+	for _, c := range candidates {
+		for _, issuer := range c.issuers {
+			for _, ca := range acceptableCAs {
+				if bytes.Equal(issuer, ca) {
+					return c.cert
+				}
+			}
+		}
+	}
+	return candidates[0].cert
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Value of the Accept-Encoding header sent when it's not set explicitly.
+// Responses are decompressed only when this value is sent, as otherwise the caller asked for a specific encoding.
+const defaultAcceptEncoding = "gzip, deflate, br"
+
+// gzipPayload compresses a request body with gzip.
+func gzipPayload(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = zw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompressResponse returns a reader that decompresses the response body, if it's encoded with gzip, deflate, or br and the request was sent with the default Accept-Encoding.
+// The Content-Encoding and Content-Length headers are removed from decompressed responses, and the original encoding is returned.
+func decompressResponse(request *http.Request, resp *http.Response) (io.Reader, string, error) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding == "" || encoding == "identity" || request.Header.Get("Accept-Encoding") != defaultAcceptEncoding {
+		return resp.Body, "", nil
+	}
+	if request.Method == http.MethodHead || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return resp.Body, "", nil
+	}
+
+	var (
+		r   io.Reader
+		err error
+	)
+	switch encoding {
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(resp.Body)
+	case "deflate":
+		r, err = newDeflateReader(resp.Body)
+	case "br":
+		r = brotli.NewReader(resp.Body)
+	default:
+		// Unknown encodings are returned as-is
+		return resp.Body, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decompress response with Content-Encoding %s: %w", encoding, err)
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return r, encoding, nil
+}
+
+// newDeflateReader returns a reader for a "deflate" body.
+// This should be zlib-wrapped deflate data, but some servers send raw deflate data, so both are accepted.
+func newDeflateReader(body io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(body)
+	header, err := br.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"mime"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// suggestedFilename returns the file name suggested by the server in the Content-Disposition header.
+// The name is reduced to its last path element, so it's safe to join to a directory.
+// Returns an empty string if the header doesn't contain a usable file name.
+func suggestedFilename(contentDisposition string) string {
+	if contentDisposition == "" {
+		return ""
+	}
+
+	// mime.ParseMediaType handles quoting and prefers "filename*" (RFC 5987) over "filename".
+	var name string
+	_, params, err := mime.ParseMediaType(contentDisposition)
+	if err == nil {
+		name = params["filename"]
+	}
+	if name == "" {
+		// Fall back to a lenient parser, for the encodings that the standard library doesn't decode (e.g. ISO-8859-1)
+		// and for values that aren't quoted properly
+		name = parseFilenameLenient(contentDisposition)
+	}
+	return sanitizeFilename(name)
+}
+
+// parseFilenameLenient extracts the file name from a Content-Disposition header that mime.ParseMediaType could not parse.
+func parseFilenameLenient(contentDisposition string) string {
+	var plain, extended string
+	parts := strings.Split(contentDisposition, ";")
+	for _, part := range parts[1:] {
+		key, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "filename":
+			plain = strings.Trim(strings.TrimSpace(val), `"`)
+		case "filename*":
+			extended = decodeExtendedValue(strings.TrimSpace(val))
+		}
+	}
+	if extended != "" {
+		return extended
+	}
+	return plain
+}
+
+// decodeExtendedValue decodes a RFC 5987 value, in the format "charset'language'percent-encoded-value".
+// Only the UTF-8, US-ASCII, and ISO-8859-1 charsets are supported.
+func decodeExtendedValue(val string) string {
+	charset, rest, ok := strings.Cut(val, "'")
+	if !ok {
+		return ""
+	}
+	_, encoded, ok := strings.Cut(rest, "'")
+	if !ok {
+		return ""
+	}
+	decoded, err := url.PathUnescape(encoded)
+	if err != nil {
+		return ""
+	}
+
+	switch strings.ToLower(charset) {
+	case "utf-8", "us-ascii":
+		return decoded
+	case "iso-8859-1":
+		// Each byte is the code point of the character
+		runes := make([]rune, len(decoded))
+		for i := 0; i < len(decoded); i++ {
+			runes[i] = rune(decoded[i])
+		}
+		return string(runes)
+	default:
+		return ""
+	}
+}
+
+// sanitizeFilename returns the last element of the name, without control characters.
+// Names that refer to a directory, such as "..", are rejected and an empty string is returned.
+func sanitizeFilename(name string) string {
+	// Servers may send paths using either separator, regardless of the OS
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "." || name == ".." {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultDebugLogBodyMaxBytes = 4 << 10 // 4KB
+	redactedValue               = "[REDACTED]"
+)
+
+// List of headers whose values are always redacted in logs.
+// The header configured in securityTokenHeader is redacted too.
+var redactedHeaders = map[string]struct{}{
+	"Authorization":       {},
+	"Proxy-Authorization": {},
+	"Cookie":              {},
+	"Set-Cookie":          {},
+	"X-Api-Key":           {},
+}
+
+// isRedactedHeader returns true if the value of the header must not be logged.
+func (h *HTTPSource) isRedactedHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	if _, ok := redactedHeaders[name]; ok {
+		return true
+	}
+	return h.metadata.SecurityTokenHeader != "" && name == http.CanonicalHeaderKey(h.metadata.SecurityTokenHeader)
+}
+
+// debugLogEnabled returns true if request and response bodies should be logged.
+func (h *HTTPSource) debugLogEnabled() bool {
+	return h.metadata.DebugLogBodies && h.logger.IsOutputLevelEnabled(logger.DebugLevel)
+}
+
+// logRequest logs the request, including a truncated and redacted body, at debug level.
+func (h *HTTPSource) logRequest(request *http.Request, body []byte) {
+	secrets := h.secretValues(request.Header)
+	h.logger.Debugf("HTTP request: %s %s headers=%s body=%s",
+		request.Method, request.URL.Redacted(),
+		h.formatHeadersForLog(request.Header),
+		h.formatBodyForLog(body, request.Header.Get("Content-Type"), secrets),
+	)
+}
+
+// logResponse logs the response, including a truncated and redacted body, at debug level.
+func (h *HTTPSource) logResponse(request *http.Request, resp *http.Response, body []byte) {
+	secrets := h.secretValues(request.Header)
+	secrets = append(secrets, h.secretValues(resp.Header)...)
+	h.logger.Debugf("HTTP response: %s %s status=%d headers=%s body=%s",
+		request.Method, request.URL.Redacted(), resp.StatusCode,
+		h.formatHeadersForLog(resp.Header),
+		h.formatBodyForLog(body, resp.Header.Get("Content-Type"), secrets),
+	)
+}
+
+// secretValues returns the values of the redacted headers, which are masked if they appear in bodies too.
+func (h *HTTPSource) secretValues(header http.Header) []string {
+	res := []string{}
+	for k, values := range header {
+		if !h.isRedactedHeader(k) {
+			continue
+		}
+		for _, v := range values {
+			// Mask the credentials only, without the scheme (e.g. "Bearer")
+			if _, cred, ok := strings.Cut(v, " "); ok && cred != "" {
+				v = cred
+			}
+			if v != "" {
+				res = append(res, v)
+			}
+		}
+	}
+	if h.metadata.SecurityToken != "" {
+		res = append(res, h.metadata.SecurityToken)
+	}
+	return res
+}
+
+// formatHeadersForLog formats headers for logging, in a deterministic order, redacting sensitive values.
+func (h *HTTPSource) formatHeadersForLog(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("{")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(k)
+		b.WriteString(": ")
+		if h.isRedactedHeader(k) {
+			b.WriteString(redactedValue)
+		} else {
+			b.WriteString(strings.Join(header[k], ", "))
+		}
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// formatBodyForLog returns the body to log: textual bodies are truncated and have secrets masked, while binary ones are summarized.
+func (h *HTTPSource) formatBodyForLog(body []byte, contentType string, secrets []string) string {
+	if len(body) == 0 {
+		return "<empty>"
+	}
+	if !isTextualContentType(contentType) {
+		return "<" + strconv.Itoa(len(body)) + " bytes, " + contentType + ">"
+	}
+
+	maxBytes := h.metadata.DebugLogBodyMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultDebugLogBodyMaxBytes
+	}
+	str := string(truncateUTF8(body, maxBytes))
+	for _, s := range secrets {
+		str = strings.ReplaceAll(str, s, redactedValue)
+	}
+	if len(body) > maxBytes {
+		str += "... (truncated, " + strconv.Itoa(len(body)) + " bytes total)"
+	}
+	return strconv.Quote(str)
+}
+
+// truncateUTF8 truncates b to at most n bytes, without splitting a multi-byte UTF-8 character.
+func truncateUTF8(b []byte, n int) []byte {
+	if len(b) <= n {
+		return b
+	}
+	for n > 0 && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	return b[:n]
+}
+
+// isTextualContentType returns true if bodies with the content type can be logged as text.
+// Bodies without a content type are assumed to be textual, as the binding defaults to JSON.
+func isTextualContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript",
+		"application/x-www-form-urlencoded", "application/graphql":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+var (
+	// ErrUnexpectedStatus is returned by DecodeResponse when the response has a non-2xx status code.
+	ErrUnexpectedStatus = errors.New("unexpected response status")
+	// ErrUnsupportedContentType is returned by DecodeResponse when the response has a content type that can't be decoded.
+	ErrUnsupportedContentType = errors.New("unsupported response content type")
+)
+
+// DecodeResponse decodes the body of a response returned by Invoke into v, according to its Content-Type:
+//
+//   - JSON ("application/json" and "+json" types), decoded with encoding/json; this is also used when the response has no Content-Type
+//   - XML ("application/xml", "text/xml" and "+xml" types), decoded with encoding/xml
+//   - forms ("application/x-www-form-urlencoded"), which can be decoded into a *url.Values or a *map[string]string (keeping the first value of each key)
+//
+// If the response has a non-2xx status code, ErrUnexpectedStatus is returned and v is not modified.
+// If the body is empty, v is not modified and no error is returned.
+func DecodeResponse(resp *bindings.InvokeResponse, v any) error {
+	if resp == nil {
+		return errors.New("response is nil")
+	}
+	if code := resp.Metadata["statusCode"]; code != "" {
+		status, err := strconv.Atoi(code)
+		if err == nil && status/100 != 2 {
+			return fmt.Errorf("%w: %s", ErrUnexpectedStatus, code)
+		}
+	}
+	if len(resp.Data) == 0 {
+		return nil
+	}
+
+	mediaType := "application/json"
+	if contentType := resp.Metadata["Content-Type"]; contentType != "" {
+		var err error
+		mediaType, _, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+		}
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return json.Unmarshal(resp.Data, v)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return xml.Unmarshal(resp.Data, v)
+	case mediaType == "application/x-www-form-urlencoded":
+		return decodeForm(resp.Data, v)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedContentType, mediaType)
+	}
+}
+
+// decodeForm decodes a URL-encoded form into a *url.Values or a *map[string]string.
+func decodeForm(data []byte, v any) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse form: %w", err)
+	}
+	switch t := v.(type) {
+	case *url.Values:
+		*t = values
+	case *map[string]string:
+		*t = make(map[string]string, len(values))
+		for k := range values {
+			(*t)[k] = values.Get(k)
+		}
+	default:
+		return fmt.Errorf("cannot decode form into %T: must be *url.Values or *map[string]string", v)
+	}
+	return nil
+}
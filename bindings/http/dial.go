@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// newDialer returns the dialer used to establish connections.
+// If dnsServer is set, hostnames are resolved by querying it.
+func (h *HTTPSource) newDialer() *net.Dialer {
+	d := &net.Dialer{
+		Timeout:       h.metadata.DialTimeout,
+		FallbackDelay: h.metadata.DialFallbackDelay,
+	}
+	if h.metadata.dnsServerAddr != "" {
+		d.Resolver = newResolver(h.metadata.dnsServerAddr, &net.Dialer{Timeout: h.metadata.DialTimeout})
+	}
+	return d
+}
+
+// validateTransportSettings validates the timeouts and limits of the transport.
+func (m *httpMetadata) validateTransportSettings() error {
+	switch {
+	case m.RequestTimeout < 0:
+		return errors.New("invalid value for requestTimeout: must not be negative")
+	case m.DialTimeout < 0:
+		return errors.New("invalid value for dialTimeout: must not be negative")
+	case m.TLSHandshakeTimeout < 0:
+		return errors.New("invalid value for tlsHandshakeTimeout: must not be negative")
+	case m.MaxIdleConns < 0:
+		return errors.New("invalid value for maxIdleConns: must not be negative")
+	case m.MaxIdleConnsPerHost < 0:
+		return errors.New("invalid value for maxIdleConnsPerHost: must not be negative")
+	case m.IdleConnTimeout < 0:
+		return errors.New("invalid value for idleConnTimeout: must not be negative")
+	}
+	return nil
+}
+
+// dialTLSContext returns a function that establishes TLS connections, performing the handshake with the request's context.
+// This way, canceling the request aborts a stalled handshake immediately, in addition to the handshake timeout.
+func dialTLSContext(t *http.Transport, dial func(ctx context.Context, network, addr string) (net.Conn, error), handshakeTimeout time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		rawConn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		// The TLS config is read at dial time, as it can be modified after the transport is created (e.g. when configuring HTTP/2)
+		var cfg *tls.Config
+		if t.TLSClientConfig != nil {
+			cfg = t.TLSClientConfig.Clone()
+		} else {
+			cfg = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		if cfg.ServerName == "" {
+			host, _, splitErr := net.SplitHostPort(addr)
+			if splitErr != nil {
+				host = addr
+			}
+			cfg.ServerName = host
+		}
+
+		handshakeCtx := ctx
+		if handshakeTimeout > 0 {
+			var cancel context.CancelFunc
+			handshakeCtx, cancel = context.WithTimeout(ctx, handshakeTimeout)
+			defer cancel()
+		}
+
+		conn := tls.Client(rawConn, cfg)
+		err = conn.HandshakeContext(handshakeCtx)
+		if err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+}
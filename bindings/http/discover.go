@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const discoverMethodsOperation bindings.OperationKind = "discoverMethods"
+
+// discoverMethods sends an OPTIONS request and returns the methods supported by the endpoint in the "allowedMethods" metadata, as a comma-separated list.
+// Methods are read from the Allow header, or from Access-Control-Allow-Methods if the former is not present.
+func (h *HTTPSource) discoverMethods(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	optionsReq := &bindings.InvokeRequest{
+		Operation: "options",
+		Metadata:  req.Metadata,
+	}
+	res, err := h.Invoke(ctx, optionsReq)
+	if res == nil {
+		return nil, err
+	}
+
+	allow := res.Metadata["Allow"]
+	if allow == "" {
+		allow = res.Metadata["Access-Control-Allow-Methods"]
+	}
+	res.Metadata["allowedMethods"] = strings.Join(parseMethodList(allow), ",")
+	return res, err
+}
+
+// parseMethodList parses a comma-separated list of methods, returning them in upper case and without duplicates.
+func parseMethodList(val string) []string {
+	res := []string{}
+	seen := map[string]struct{}{}
+	for _, m := range strings.Split(val, ",") {
+		m = strings.ToUpper(strings.TrimSpace(m))
+		if m == "" {
+			continue
+		}
+		if _, ok := seen[m]; ok {
+			continue
+		}
+		seen[m] = struct{}{}
+		res = append(res, m)
+	}
+	return res
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// namedEndpoint is an endpoint defined in the "endpoints" metadata property.
+type namedEndpoint struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// UnmarshalJSON allows endpoints to be defined as just a URL string, or as an object with "url" and "headers".
+func (e *namedEndpoint) UnmarshalJSON(data []byte) error {
+	var u string
+	if json.Unmarshal(data, &u) == nil {
+		e.URL = u
+		return nil
+	}
+
+	type plain namedEndpoint
+	return json.Unmarshal(data, (*plain)(e))
+}
+
+// parseEndpoints parses the value of the "endpoints" metadata property, which is a JSON object mapping names to endpoints.
+func parseEndpoints(val string) (map[string]namedEndpoint, error) {
+	if val == "" {
+		return nil, nil
+	}
+
+	var endpoints map[string]namedEndpoint
+	err := json.Unmarshal([]byte(val), &endpoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	for name, e := range endpoints {
+		if name == "" {
+			return nil, errors.New("endpoint names must not be empty")
+		}
+		u, err := url.Parse(e.URL)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %q has an invalid URL: %w", name, err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("endpoint %q has an invalid URL: must be absolute", name)
+		}
+	}
+
+	return endpoints, nil
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	defaultOnErrorWebhookInterval = time.Minute
+	errorWebhookQueueSize         = 16
+	errorWebhookTimeout           = 10 * time.Second
+	// Maximum number of keys tracked for deduplication
+	errorWebhookMaxTracked = 1000
+
+	errorCategoryConnection = "connection"
+	errorCategoryTimeout    = "timeout"
+	errorCategoryStatus     = "status"
+)
+
+// errorEvent is the summary of a failed invocation that is sent to the error webhook.
+type errorEvent struct {
+	Endpoint   string    `json:"endpoint"`
+	StatusCode int       `json:"status,omitempty"`
+	Category   string    `json:"errorCategory"`
+	Error      string    `json:"error,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// errorNotifier sends best-effort notifications of failed invocations to a webhook, in background.
+// Notifications for the same endpoint and kind of error are sent at most once per interval.
+type errorNotifier struct {
+	webhookURL string
+	interval   time.Duration
+	client     *http.Client
+	logger     logger.Logger
+
+	queue   chan errorEvent
+	closeCh chan struct{}
+	wg      sync.WaitGroup
+
+	lock     sync.Mutex
+	lastSent map[string]time.Time
+	closed   bool
+}
+
+func newErrorNotifier(webhookURL string, interval time.Duration, log logger.Logger) *errorNotifier {
+	if interval <= 0 {
+		interval = defaultOnErrorWebhookInterval
+	}
+	n := &errorNotifier{
+		webhookURL: webhookURL,
+		interval:   interval,
+		client:     &http.Client{Timeout: errorWebhookTimeout},
+		logger:     log,
+		queue:      make(chan errorEvent, errorWebhookQueueSize),
+		closeCh:    make(chan struct{}),
+		lastSent:   make(map[string]time.Time),
+	}
+	n.wg.Add(1)
+	go n.run()
+	return n
+}
+
+// notify enqueues a notification for a failed request. It never blocks.
+func (n *errorNotifier) notify(u *url.URL, statusCode int, err error) {
+	ev := errorEvent{
+		Endpoint:   (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String(),
+		StatusCode: statusCode,
+		Timestamp:  time.Now().UTC(),
+	}
+	switch {
+	case statusCode != 0:
+		ev.Category = errorCategoryStatus
+	case errors.Is(err, context.DeadlineExceeded):
+		ev.Category = errorCategoryTimeout
+	default:
+		ev.Category = errorCategoryConnection
+	}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.closed {
+		return
+	}
+
+	// Deduplicate
+	key := ev.Endpoint + "|" + ev.Category + "|" + strconv.Itoa(ev.StatusCode)
+	if last, ok := n.lastSent[key]; ok && ev.Timestamp.Sub(last) < n.interval {
+		return
+	}
+	if len(n.lastSent) >= errorWebhookMaxTracked {
+		n.pruneLocked(ev.Timestamp)
+	}
+
+	select {
+	case n.queue <- ev:
+		n.lastSent[key] = ev.Timestamp
+	default:
+		n.logger.Warn("Error webhook queue is full: dropping notification")
+	}
+}
+
+// pruneLocked removes the expired deduplication keys, or all of them if none is expired.
+func (n *errorNotifier) pruneLocked(now time.Time) {
+	for k, last := range n.lastSent {
+		if now.Sub(last) >= n.interval {
+			delete(n.lastSent, k)
+		}
+	}
+	if len(n.lastSent) >= errorWebhookMaxTracked {
+		n.lastSent = make(map[string]time.Time)
+	}
+}
+
+func (n *errorNotifier) run() {
+	defer n.wg.Done()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-n.closeCh
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-n.closeCh:
+			return
+		case ev := <-n.queue:
+			n.send(ctx, ev)
+		}
+	}
+}
+
+func (n *errorNotifier) send(ctx context.Context, ev errorEvent) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		n.logger.Warnf("Failed to create error webhook request: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		n.logger.Warnf("Failed to send error webhook: %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		n.logger.Warnf("Error webhook returned status code %d", resp.StatusCode)
+	}
+}
+
+// Close stops the background worker, canceling any in-flight notification.
+func (n *errorNotifier) Close() {
+	n.lock.Lock()
+	if n.closed {
+		n.lock.Unlock()
+		return
+	}
+	n.closed = true
+	close(n.closeCh)
+	n.lock.Unlock()
+
+	n.wg.Wait()
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+const defaultOptimisticConcurrencyMaxEntries = 1000
+
+// ErrPreconditionFailed is returned when optimistic concurrency is enabled and the server responds with 412 Precondition Failed.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// etagStore keeps the ETags returned by the server for each URL, so they can be sent with If-Match in subsequent requests.
+type etagStore struct {
+	cache *lru.Cache[string, string]
+}
+
+func newETagStore(maxEntries int) (*etagStore, error) {
+	if maxEntries <= 0 {
+		maxEntries = defaultOptimisticConcurrencyMaxEntries
+	}
+	cache, err := lru.New[string, string](maxEntries)
+	if err != nil {
+		return nil, err
+	}
+	return &etagStore{cache: cache}, nil
+}
+
+// applyIfMatch adds the If-Match header to requests that modify a resource, if an ETag was seen for its URL.
+// If the request already contains an If-Match header, it's not changed.
+func (s *etagStore) applyIfMatch(request *http.Request) {
+	switch request.Method {
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return
+	}
+	if request.Header.Get("If-Match") != "" {
+		return
+	}
+	etag, ok := s.cache.Get(etagStoreKey(request))
+	if ok {
+		request.Header.Set("If-Match", etag)
+	}
+}
+
+// track updates the store with the ETag in the response.
+func (s *etagStore) track(request *http.Request, resp *http.Response) {
+	key := etagStoreKey(request)
+
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		// The stored ETag is stale
+		s.cache.Remove(key)
+		return
+	}
+	if resp.StatusCode/100 != 2 {
+		return
+	}
+
+	if request.Method == http.MethodDelete {
+		s.cache.Remove(key)
+		return
+	}
+	etag := resp.Header.Get("ETag")
+	switch {
+	case etag != "":
+		s.cache.Add(key, etag)
+	case request.Method != http.MethodGet && request.Method != http.MethodHead:
+		// The resource was modified but we don't know its new ETag
+		s.cache.Remove(key)
+	}
+}
+
+func etagStoreKey(request *http.Request) string {
+	u := *request.URL
+	u.Fragment = ""
+	return u.String()
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// isConnectionError returns true if the error happened while establishing the connection, before the request was sent.
+// This makes it safe to re-send the request to another host, even if it's not idempotent.
+func isConnectionError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// fallbackRequest returns a copy of the request that targets the fallback URL.
+func (h *HTTPSource) fallbackRequest(request *http.Request, path string) (*http.Request, error) {
+	u, err := url.Parse(composeURL(h.metadata.FallbackURL, path))
+	if err != nil {
+		return nil, fmt.Errorf("invalid fallback URL: %w", err)
+	}
+	h.normalizeURLPath(u)
+	if !h.isURLAllowed(u) {
+		return nil, fmt.Errorf("URL not allowed: %s", u.Redacted())
+	}
+
+	fallback := request.Clone(request.Context())
+	fallback.URL = u
+	// Reset Host so the Host header and TLS SNI match the fallback host
+	fallback.Host = ""
+	if request.GetBody != nil {
+		fallback.Body, err = request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fallback, nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const (
+	defaultFireAndForgetWorkers   = 4
+	defaultFireAndForgetQueueSize = 100
+	// Maximum time Close waits for queued requests to complete, before canceling them
+	fireAndForgetDrainTimeout = 10 * time.Second
+)
+
+// fireAndForgetQueue sends requests in background, with a fixed number of workers and a bounded queue.
+type fireAndForgetQueue struct {
+	invoke func(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error)
+	onDone func(req *bindings.InvokeRequest, err error)
+
+	queue   chan *bindings.InvokeRequest
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	dropped atomic.Int64
+
+	lock   sync.RWMutex
+	closed bool
+}
+
+func newFireAndForgetQueue(workers int, queueSize int, invoke func(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error), onDone func(req *bindings.InvokeRequest, err error)) *fireAndForgetQueue {
+	if workers <= 0 {
+		workers = defaultFireAndForgetWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultFireAndForgetQueueSize
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &fireAndForgetQueue{
+		invoke: invoke,
+		onDone: onDone,
+		queue:  make(chan *bindings.InvokeRequest, queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.run()
+	}
+	return q
+}
+
+func (q *fireAndForgetQueue) run() {
+	defer q.wg.Done()
+	for req := range q.queue {
+		_, err := q.invoke(q.ctx, req)
+		if q.onDone != nil {
+			q.onDone(req, err)
+		}
+	}
+}
+
+// enqueue adds the request to the queue without blocking.
+// Returns false if the request was dropped because the queue is full or closed.
+func (q *fireAndForgetQueue) enqueue(req *bindings.InvokeRequest) bool {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	if q.closed {
+		q.dropped.Add(1)
+		return false
+	}
+	select {
+	case q.queue <- req:
+		return true
+	default:
+		q.dropped.Add(1)
+		return false
+	}
+}
+
+// Close stops accepting requests and waits for the queued ones to be sent.
+// Requests that are still pending after the drain timeout are canceled.
+func (q *fireAndForgetQueue) Close() {
+	q.lock.Lock()
+	if q.closed {
+		q.lock.Unlock()
+		return
+	}
+	q.closed = true
+	close(q.queue)
+	q.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(fireAndForgetDrainTimeout):
+		q.cancel()
+		<-done
+	}
+	q.cancel()
+}
+
+// invokeFireAndForget enqueues the request to be sent in background, and returns immediately.
+// The response and any error are not returned to the caller: errors are logged.
+func (h *HTTPSource) invokeFireAndForget(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	h.fireAndForgetOnce.Do(func() {
+		h.fireAndForget = newFireAndForgetQueue(h.metadata.FireAndForgetWorkers, h.metadata.FireAndForgetQueueSize, h.Invoke,
+			func(req *bindings.InvokeRequest, err error) {
+				if err != nil {
+					h.logger.Warnf("Fire-and-forget request failed: %v", err)
+				}
+			})
+	})
+	if h.fireAndForget == nil {
+		// The binding was closed before any fire-and-forget request was sent
+		return nil, errors.New("binding is closed")
+	}
+
+	// Copy the request, as the caller may reuse it after returning
+	bgReq := &bindings.InvokeRequest{
+		Operation: req.Operation,
+		Data:      append([]byte(nil), req.Data...),
+		Metadata:  make(map[string]string, len(req.Metadata)),
+	}
+	for k, v := range req.Metadata {
+		if k != "fireAndForget" {
+			bgReq.Metadata[k] = v
+		}
+	}
+
+	accepted := h.fireAndForget.enqueue(bgReq)
+	if !accepted {
+		h.logger.Warnf("Fire-and-forget queue is full or closed: dropping request (%d dropped in total)", h.fireAndForget.dropped.Load())
+	}
+	return &bindings.InvokeResponse{
+		Metadata: map[string]string{
+			"accepted":             strconv.FormatBool(accepted),
+			"fireAndForgetDropped": strconv.FormatInt(h.fireAndForget.dropped.Load(), 10),
+		},
+	}, nil
+}
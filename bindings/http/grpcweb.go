@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	grpcWebContentType = "application/grpc-web+proto"
+
+	// Flags in the first byte of gRPC-Web frames
+	grpcWebFlagCompressed = 0x01
+	grpcWebFlagTrailers   = 0x80
+
+	grpcWebFrameHeaderLen = 5
+)
+
+// frameGRPCWebMessage wraps the message in a gRPC-Web data frame: a flags byte (uncompressed), followed by the 4-byte big-endian length of the message.
+func frameGRPCWebMessage(msg []byte) []byte {
+	res := make([]byte, grpcWebFrameHeaderLen+len(msg))
+	binary.BigEndian.PutUint32(res[1:grpcWebFrameHeaderLen], uint32(len(msg)))
+	copy(res[grpcWebFrameHeaderLen:], msg)
+	return res
+}
+
+// parseGRPCWebResponse parses the frames in a gRPC-Web response body.
+// It returns the concatenated payload of all data frames, and the trailers, which are sent in the body in a frame with the trailers flag.
+func parseGRPCWebResponse(body []byte) (payload []byte, trailers http.Header, err error) {
+	trailers = http.Header{}
+	for len(body) > 0 {
+		if len(body) < grpcWebFrameHeaderLen {
+			return nil, nil, errors.New("invalid gRPC-Web response: incomplete frame header")
+		}
+		flags := body[0]
+		n := binary.BigEndian.Uint32(body[1:grpcWebFrameHeaderLen])
+		if uint64(len(body)-grpcWebFrameHeaderLen) < uint64(n) {
+			return nil, nil, fmt.Errorf("invalid gRPC-Web response: frame length %d exceeds the remaining %d bytes", n, len(body)-grpcWebFrameHeaderLen)
+		}
+		frame := body[grpcWebFrameHeaderLen : grpcWebFrameHeaderLen+int(n)]
+		body = body[grpcWebFrameHeaderLen+int(n):]
+
+		if flags&grpcWebFlagCompressed != 0 {
+			return nil, nil, errors.New("invalid gRPC-Web response: compressed frames are not supported")
+		}
+		if flags&grpcWebFlagTrailers != 0 {
+			parseGRPCWebTrailers(frame, trailers)
+			continue
+		}
+		payload = append(payload, frame...)
+	}
+	return payload, trailers, nil
+}
+
+// parseGRPCWebTrailers parses the trailers frame, which contains HTTP/1-style header lines.
+func parseGRPCWebTrailers(frame []byte, trailers http.Header) {
+	for _, line := range strings.Split(string(frame), "\r\n") {
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		trailers.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+}
+
+// addGRPCWebStatus adds the gRPC status and message to the response metadata, and returns an error if the status is not OK.
+// The status is read from the trailers in the body, or from the headers for trailers-only responses.
+func addGRPCWebStatus(resp *http.Response, trailers http.Header, metadata map[string]string) error {
+	status := trailers.Get("grpc-status")
+	message := trailers.Get("grpc-message")
+	if status == "" {
+		status = resp.Header.Get("grpc-status")
+		message = resp.Header.Get("grpc-message")
+	}
+	if status == "" {
+		return errors.New("invalid gRPC-Web response: missing grpc-status")
+	}
+	// The message is percent-encoded
+	if decoded, err := url.PathUnescape(message); err == nil {
+		message = decoded
+	}
+
+	metadata["grpc-status"] = status
+	if message != "" {
+		metadata["grpc-message"] = message
+	}
+	if status != "0" {
+		return fmt.Errorf("received gRPC status %s: %s", status, message)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+const (
+	headerOverflowPolicyFail     = "fail"
+	headerOverflowPolicyTruncate = "truncate"
+)
+
+// ErrTooManyResponseHeaders is returned when the response has more headers than maxResponseHeaderCount and headerOverflowPolicy is "fail".
+// The error is a *HeaderCountError with the details.
+var ErrTooManyResponseHeaders = errors.New("too many response headers")
+
+// HeaderCountError is returned when the response has more headers than allowed.
+type HeaderCountError struct {
+	// Number of headers in the response
+	Count int
+	// Maximum number of headers allowed
+	Limit int
+}
+
+func (e *HeaderCountError) Error() string {
+	return fmt.Sprintf("%v: response has %d headers, exceeding the limit of %d", ErrTooManyResponseHeaders, e.Count, e.Limit)
+}
+
+func (e *HeaderCountError) Unwrap() error {
+	return ErrTooManyResponseHeaders
+}
+
+// validateHeaderOverflowPolicy validates the value of headerOverflowPolicy.
+func validateHeaderOverflowPolicy(val string) error {
+	switch val {
+	case "", headerOverflowPolicyFail, headerOverflowPolicyTruncate:
+		return nil
+	default:
+		return fmt.Errorf("invalid value for headerOverflowPolicy: %s (must be %q or %q)", val, headerOverflowPolicyFail, headerOverflowPolicyTruncate)
+	}
+}
+
+// enforceResponseHeaderCount checks the number of distinct headers in the response against maxResponseHeaderCount.
+// With the "truncate" policy, only the first headers in canonical (sorted) order are kept, and truncated is true; otherwise, a *HeaderCountError is returned.
+func (h *HTTPSource) enforceResponseHeaderCount(header http.Header) (truncated bool, err error) {
+	limit := h.metadata.MaxResponseHeaderCount
+	if limit <= 0 || len(header) <= limit {
+		return false, nil
+	}
+	if h.metadata.HeaderOverflowPolicy != headerOverflowPolicyTruncate {
+		return false, &HeaderCountError{Count: len(header), Limit: limit}
+	}
+
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys[limit:] {
+		delete(header, k)
+	}
+	return true, nil
+}
@@ -18,17 +18,34 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
-	"net"
+	"mime"
 	"net/http"
+	"net/http/cookiejar"
+	"net/url"
 	"os"
+	"path"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	krbclient "github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jmespath/go-jmespath"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/publicsuffix"
+	"golang.org/x/oauth2"
+	"golang.org/x/text/language"
 
 	"github.com/dapr/components-contrib/bindings"
 	"github.com/dapr/components-contrib/metadata"
@@ -48,16 +65,54 @@ const (
 	securityToken                   = "securityToken"
 	securityTokenHeader             = "securityTokenHeader"
 	defaultMaxResponseBodySizeBytes = 100 << 20 // 100 MB
+	defaultIdempotencyKeyHeader     = "Idempotency-Key"
+	defaultDialTimeout              = 15 * time.Second
+	defaultTLSHandshakeTimeout      = 15 * time.Second
+
+	defaultMTLSClientCertReloadInterval = time.Minute
+
+	duplicateHeaderPolicyAppend = "append"
+	duplicateHeaderPolicyError  = "error"
+
+	tlsVerifyModeFull             = "full"
+	tlsVerifyModeSkipHostnameOnly = "skipHostnameOnly"
+	tlsVerifyModeNone             = "none"
 )
 
+// ErrTruncatedResponse is returned when the response body is shorter than the advertised Content-Length.
+var ErrTruncatedResponse = errors.New("truncated response")
+
+// Cause of the context cancellation when totalOperationTimeout is exceeded.
+var errTotalOperationTimeout = fmt.Errorf("%w (totalOperationTimeout)", context.DeadlineExceeded)
+
 // HTTPSource is a binding for an http url endpoint invocation
 //
 //revive:disable-next-line
 type HTTPSource struct {
-	metadata      httpMetadata
-	client        *http.Client
-	errorIfNot2XX bool
-	logger        logger.Logger
+	metadata       httpMetadata
+	client         *http.Client
+	errorIfNot2XX  bool
+	logger         logger.Logger
+	retryBudget    *retryBudget
+	errorNotifier  *errorNotifier
+	etags          *etagStore
+	circuitBreaker *circuitBreaker
+	rateLimiter    *rateLimiter
+	responseCache  *responseCache
+	idleReset      *idleReset
+	requestQueue   *requestQueue
+
+	oauth2TokenSource oauth2.TokenSource
+	digestSession     *digestSession
+	kerberosClient    *krbclient.Client
+
+	fireAndForget     *fireAndForgetQueue
+	fireAndForgetOnce sync.Once
+	createAliasOnce   sync.Once
+
+	closed  atomic.Bool
+	closeCh chan struct{}
+	wg      sync.WaitGroup
 }
 
 type httpMetadata struct {
@@ -74,33 +129,414 @@ type httpMetadata struct {
 	// A value <= 0 means no limit.
 	// Default: 100MB
 	MaxResponseBodySize kitmd.ByteSize `mapstructure:"maxResponseBodySize"`
+	// List of patterns the fully-composed request URL must match before the request is sent.
+	// Patterns are globs where "*" matches any sequence of characters, or regular expressions when prefixed with "regex:".
+	// If empty, all URLs are allowed.
+	AllowedURLPatterns []string `mapstructure:"allowedURLPatterns"`
+	// Maximum number of times a request is retried on connection errors and transient failures (by default, 429, 502, 503, 504; see retryOnStatusCodes).
+	// Can be overridden per-request with the "maxRetries" metadata.
+	// Default: 0 (no retries)
+	MaxRetries int `mapstructure:"maxRetries"`
+	// If set, limits the number of retries across all requests to this fraction of the successful requests in the last retryBudgetWindow.
+	// For example, 0.2 allows 1 retry every 5 successful requests.
+	// A value <= 0 disables the retry budget.
+	RetryBudgetRatio float64 `mapstructure:"retryBudgetRatio"`
+	// Sliding window used to compute the retry budget.
+	// Default: 10s
+	RetryBudgetWindow time.Duration `mapstructure:"retryBudgetWindow"`
+	// Minimum number of retries allowed by the retry budget in each retryBudgetWindow, regardless of the number of successful requests.
+	// This allows retrying failures after a cold start or a period without traffic, when there are no recent successful requests.
+	// Default: 10
+	RetryBudgetMinRetries *int `mapstructure:"retryBudgetMinRetries"`
+	// If set, the Content-Type of responses must match this value (ignoring parameters such as charset), or an error is returned.
+	// Supports wildcards, such as "application/*".
+	// Can be overridden per-request with the "expectResponseContentType" metadata.
+	ExpectResponseContentType string `mapstructure:"expectResponseContentType"`
+	// JSON object with named endpoints that can be selected for each request with the "endpoint" metadata property.
+	// Values are either a URL, or an object with "url" and optional default "headers".
+	Endpoints string `mapstructure:"endpoints"`
+	// If true, request and response bodies are logged at debug level, truncated and with sensitive values redacted.
+	// This should not be enabled in production.
+	DebugLogBodies bool `mapstructure:"debugLogBodies"`
+	// Maximum number of bytes of each body to log when debugLogBodies is enabled.
+	// Default: 4096
+	DebugLogBodyMaxBytes int `mapstructure:"debugLogBodyMaxBytes"`
+	// Base URL of a secondary endpoint, which is invoked once when the connection to the endpoint in "url" fails.
+	FallbackURL string `mapstructure:"fallbackURL"`
+	// If set, HTTP/2 connections that receive no frame for this duration are health-checked with a ping frame.
+	// Only applies to HTTP/2 connections.
+	HTTP2ReadIdleTimeout time.Duration `mapstructure:"http2ReadIdleTimeout"`
+	// Timeout after which an HTTP/2 connection is closed if a response to a ping is not received.
+	// Only applies to HTTP/2 connections, when http2ReadIdleTimeout is set.
+	// Default: 15s
+	HTTP2PingTimeout time.Duration `mapstructure:"http2PingTimeout"`
+	// Maximum number of concurrent requests (streams) on the HTTP/2 connection to each host; further requests wait for a stream to complete.
+	// Requires requestProtocol "2". If zero, the limit advertised by the server is used.
+	HTTP2MaxConcurrentStreams int `mapstructure:"http2MaxConcurrentStreams"`
+	// If set, a summary of failed requests is sent to this URL with a POST request, in background.
+	OnErrorWebhookURL string `mapstructure:"onErrorWebhookURL"`
+	// Minimum interval between notifications sent to the error webhook for the same endpoint and kind of error.
+	// Default: 1m
+	OnErrorWebhookInterval time.Duration `mapstructure:"onErrorWebhookInterval"`
+	// If true, the response includes the "rawResponseHeadersJSON" metadata with all response headers as a JSON object, preserving multiple values.
+	// Can be overridden per-request with the "includeRawResponseHeaders" metadata.
+	IncludeRawResponseHeaders bool `mapstructure:"includeRawResponseHeaders"`
+	// If true, the ETag returned by GET requests is stored and sent automatically with If-Match in subsequent PUT, PATCH, and DELETE requests to the same URL.
+	// Can be disabled per-request with the "optimisticConcurrency" metadata.
+	OptimisticConcurrency bool `mapstructure:"optimisticConcurrency"`
+	// Maximum number of ETags stored when optimisticConcurrency is enabled.
+	// Default: 1000
+	OptimisticConcurrencyMaxEntries int `mapstructure:"optimisticConcurrencyMaxEntries"`
+	// Maximum number of requests of a batch that are performed concurrently.
+	// Can be overridden per-request with the "batchConcurrency" metadata.
+	// Default: 4
+	BatchConcurrency int `mapstructure:"batchConcurrency"`
+	// Default value for the Accept-Language header, as a BCP 47 language tag (e.g. "en-US") or a list of tags with weights (e.g. "fr-CH, fr;q=0.9").
+	// Can be overridden per-request with the "acceptLanguage" metadata. An explicit "Accept-Language" header takes precedence.
+	AcceptLanguage string `mapstructure:"acceptLanguage"`
+	// Controls how the server certificate is verified: "full" verifies the certificate chain and the hostname, "skipHostnameOnly" verifies the chain against the configured CAs but not the hostname, and "none" disables verification.
+	// Default: "full"
+	TLSVerifyMode string `mapstructure:"tlsVerifyMode"`
+	// If set, the entire operation, including retries, redirects, and the fallback URL, must complete within this duration.
+	TotalOperationTimeout time.Duration `mapstructure:"totalOperationTimeout"`
+	// Controls which redirects are followed: "any", "sameHost" (only to the original host), "sameScheme" (only to the original host, without downgrading from https), or "none" (the redirect response is returned, without an error).
+	// The Authorization header is never sent to a different host.
+	// Default: "any"
+	RedirectPolicy string `mapstructure:"redirectPolicy"`
+	// If true, an error is returned when the response body is shorter than its Content-Length, for example when the connection is dropped.
+	// Responses without a Content-Length, such as chunked ones, are not checked.
+	// Default: true
+	VerifyContentLength bool `mapstructure:"verifyContentLength"`
+	// Name of the header used to send idempotency keys.
+	// Default: "Idempotency-Key"
+	IdempotencyKeyHeader string `mapstructure:"idempotencyKeyHeader"`
+	// If true, an idempotency key is generated for POST and PATCH requests that don't have one, and it's reused when the request is retried.
+	// A key can also be passed per-request with the "idempotencyKey" metadata.
+	GenerateIdempotencyKey bool `mapstructure:"generateIdempotencyKey"`
+	// If true, empty bodies of 2xx responses (except for HEAD requests) are returned as the JSON literal "null".
+	// Can be overridden per-request with the "emptyBodyAsNull" metadata.
+	EmptyBodyAsNull bool `mapstructure:"emptyBodyAsNull"`
+	// JSON array of client certificates, as objects with "cert" and "key" that are either PEM-encoded or paths to files.
+	// The certificate issued by one of the CAs requested by the server is presented; if none matches, the first one is used.
+	MTLSClientCerts string `mapstructure:"mtlsClientCerts"`
+	// Number of consecutive failed requests (connection errors or 5xx responses) after which the circuit breaker opens, and requests fail fast.
+	// A value <= 0 disables the circuit breaker.
+	CircuitBreakerFailureThreshold int `mapstructure:"circuitBreakerFailureThreshold"`
+	// Time the circuit breaker stays open before becoming half-open and admitting probe requests.
+	// Default: 30s
+	CircuitBreakerOpenDuration time.Duration `mapstructure:"circuitBreakerOpenDuration"`
+	// Maximum number of probe requests admitted concurrently while the circuit breaker is half-open; other requests fail fast.
+	// Default: 1
+	CircuitBreakerHalfOpenMaxConcurrent int `mapstructure:"circuitBreakerHalfOpenMaxConcurrent"`
+	// Number of consecutive successful probe requests required to close the circuit breaker.
+	// Default: 1
+	CircuitBreakerHalfOpenSuccesses int `mapstructure:"circuitBreakerHalfOpenSuccesses"`
+	// Controls what happens when multiple metadata keys set the same header, differing only by case (e.g. "X-Id" and "X-ID"): "append" sends all values, while "error" fails the request.
+	// Default: "append"
+	DuplicateHeaderPolicy string `mapstructure:"duplicateHeaderPolicy"`
+	// If true, responses received over TLS include the certificate chain presented by the server, PEM-encoded, in the "tls.peerCertChainPEM" metadata.
+	ExposePeerCertChain bool `mapstructure:"exposePeerCertChain"`
+	// If set, limits the rate of requests sent by the binding; requests wait until they can be sent.
+	// Requests with the "rateLimitKey" metadata (e.g. a tenant ID) are limited separately for each key.
+	MaxRequestsPerSecond float64 `mapstructure:"maxRequestsPerSecond"`
+	// Maximum number of requests that can be sent in a burst when maxRequestsPerSecond is set.
+	// Default: maxRequestsPerSecond, rounded up
+	RateLimitBurst int `mapstructure:"rateLimitBurst" mapstructurealiases:"burst"`
+	// Maximum number of rate limit keys that are tracked; the least recently used ones are evicted.
+	// Default: 1000
+	RateLimitMaxKeys int `mapstructure:"rateLimitMaxKeys"`
+	// If true, requests and responses use the gRPC-Web protocol: the request body is framed as a gRPC-Web message, and the payload of the response is unframed, with the grpc-status and grpc-message trailers returned as metadata.
+	// Requires the "post" operation. Can be overridden per-request with the "grpcWeb" metadata.
+	GRPCWeb bool `mapstructure:"grpcWeb"`
+	// Categories of network errors that are retried: "connrefused", "connreset", "timeout", "dnsfailure", "eof", "tlshandshake".
+	// If empty, "connrefused", "connreset", "timeout" and "eof" are retried. Errors that don't belong to any category are never retried.
+	RetriableNetworkErrors []string `mapstructure:"retriableNetworkErrors"`
+	// Number of background workers that send requests with the "fireAndForget" metadata.
+	// Default: 4
+	FireAndForgetWorkers int `mapstructure:"fireAndForgetWorkers"`
+	// Maximum number of requests with the "fireAndForget" metadata waiting to be sent; when the queue is full, requests are dropped.
+	// Default: 100
+	FireAndForgetQueueSize int `mapstructure:"fireAndForgetQueueSize"`
+	// If set, the HMAC signature of response bodies is verified with this secret, and responses that don't match are rejected.
+	ResponseHMACSecret string `mapstructure:"responseHmacSecret"`
+	// Header containing the signature of the response body.
+	// Default: "X-Signature"
+	ResponseHMACHeader string `mapstructure:"responseHmacHeader"`
+	// Hash algorithm of the signature: "sha256" or "sha1".
+	// Default: "sha256"
+	ResponseHMACAlgorithm string `mapstructure:"responseHmacAlgorithm"`
+	// If set, the response contains a Unix timestamp in this header, which is included in the signed message.
+	ResponseHMACTimestampHeader string `mapstructure:"responseHmacTimestampHeader"`
+	// Maximum age of the signed timestamp; older responses are rejected.
+	ResponseHMACMaxAge time.Duration `mapstructure:"responseHmacMaxAge"`
+	// Delay before starting a fallback connection to the other address family when dialing hosts with both IPv4 and IPv6 addresses ("Happy Eyeballs").
+	// A negative value disables the fallback. If zero, Go's default of 300ms is used.
+	DialFallbackDelay time.Duration `mapstructure:"dialFallbackDelay"`
+	// Protocol version of requests: "1.0", "1.1", or "2". HTTP/1.0 requests are sent without keep-alive, on a new connection each.
+	// With "2", all requests use HTTP/2, in cleartext (h2c) for http URLs. Otherwise, HTTP/2 is negotiated with the server.
+	RequestProtocol string `mapstructure:"requestProtocol"`
+	// If true, the outcome of each attempt is logged at debug level when retries are enabled.
+	LogRetries bool `mapstructure:"logRetries"`
+	// Policy for the trailing slash of the request path: "keep" leaves it as-is, "add" adds it if missing, and "strip" removes it.
+	// Duplicate slashes in the path are always collapsed.
+	// Default: "keep"
+	TrailingSlash string `mapstructure:"trailingSlash"`
+	// If true, fresh GET and HEAD responses are cached in memory following the rules of RFC 7234, and stale ones are revalidated with conditional requests.
+	// Responses are cached separately for each value of the Authorization, Proxy-Authorization, Cookie, and securityTokenHeader request headers, so they're never shared between callers with different credentials.
+	EnableResponseCache bool `mapstructure:"enableResponseCache"`
+	// Maximum number of responses in the cache; the least recently used ones are evicted.
+	// Default: 1000
+	ResponseCacheMaxEntries int `mapstructure:"responseCacheMaxEntries"`
+	// Freshness lifetime of responses that don't specify one with Cache-Control or Expires.
+	// Default: 0 (such responses are only cached if they can be revalidated)
+	ResponseCacheDefaultTTL time.Duration `mapstructure:"responseCacheDefaultTTL"`
+	// If true, requests without a Content-Type whose body looks binary (not valid UTF-8, or containing NUL bytes) are sent as "application/octet-stream" rather than JSON.
+	AutoOctetStream bool `mapstructure:"autoOctetStream"`
+	// If true, using the deprecated "create" operation (an alias for "post") logs a warning and adds "deprecatedOperation" to the response metadata.
+	WarnOnCreateAlias bool `mapstructure:"warnOnCreateAlias"`
+	// If true, requests without trace context get a new W3C traceparent header, which is returned in the "traceparent" response metadata.
+	OriginateTrace bool `mapstructure:"originateTrace"`
+	// Sampling decision of the traceparent generated by originateTrace.
+	// Default: true
+	OriginateTraceSampled *bool `mapstructure:"originateTraceSampled"`
+	// If set, idle connections are closed before sending a request when no request succeeded for longer than this duration, so a new connection is established.
+	MaxIdleTimeBeforeReset time.Duration `mapstructure:"maxIdleTimeBeforeReset"`
+	// Maximum number of distinct headers in responses. If zero, there's no limit.
+	MaxResponseHeaderCount int `mapstructure:"maxResponseHeaderCount"`
+	// What happens when a response has more headers than maxResponseHeaderCount: "fail" returns a *HeaderCountError, while "truncate" keeps the first headers in canonical order.
+	// Default: "fail"
+	HeaderOverflowPolicy string `mapstructure:"headerOverflowPolicy"`
+	// Number of connections to the target URL to establish during Init, including the TLS handshake, so they can be reused by the first requests.
+	WarmupConnections int `mapstructure:"warmupConnections"`
+	// If true, Init fails if any of the warm-up connections can't be established; otherwise, failures are logged as warnings.
+	WarmupStrict bool `mapstructure:"warmupStrict"`
+	// If true, on a 401 response with a WWW-Authenticate challenge for a scheme that has credentials configured, the request is sent again once with those credentials.
+	AdaptiveAuth bool `mapstructure:"adaptiveAuth"`
+	// Username for the Basic and Digest schemes, when adaptiveAuth or authType is set.
+	AuthUsername string `mapstructure:"authUsername"`
+	// Password for the Basic and Digest schemes, when adaptiveAuth or authType is set.
+	AuthPassword string `mapstructure:"authPassword"`
+	// Token for the Bearer scheme, when adaptiveAuth is enabled.
+	AuthBearerToken string `mapstructure:"authBearerToken"`
+	// Interval at which the progress of streamed request bodies (such as uploadArchive) is logged at debug level. If zero, progress is not logged.
+	UploadProgressInterval time.Duration `mapstructure:"uploadProgressInterval"`
+	// JMESPath expression evaluated against JSON bodies of 2xx responses, for servers that signal errors in the body.
+	// The response is a failure if the result is not null, false, or empty; or, if bodyErrorValue is set, if the result is equal to it.
+	BodyErrorJMESPath string `mapstructure:"bodyErrorJMESPath"`
+	// If set, the response is a failure only if the result of bodyErrorJMESPath is equal to this value.
+	BodyErrorValue string `mapstructure:"bodyErrorValue"`
+	// Maximum number of requests in flight. If zero, there's no limit.
+	MaxConcurrentRequests int `mapstructure:"maxConcurrentRequests"`
+	// Maximum number of requests waiting in a FIFO queue when all maxConcurrentRequests slots are busy; when the queue is full, requests fail with ErrRequestQueueFull.
+	// If zero, requests fail immediately when all slots are busy.
+	RequestQueueSize int `mapstructure:"requestQueueSize"`
+	// Comma-separated list of status codes and ranges that are a success, such as "200-299,404". Other status codes return an error, unless errorIfNot2XX is false.
+	// Can be overridden per-request with the "successStatusCodes" metadata.
+	// Default: all 2xx status codes
+	SuccessStatusCodes string `mapstructure:"successStatusCodes"`
+	// Maximum number of bytes of the response body included in the error returned for unsuccessful status codes. If zero, the body is not included.
+	// Can be overridden per-request with the "errorBodyMaxLength" metadata.
+	ErrorBodyMaxLength int `mapstructure:"errorBodyMaxLength"`
+	// Timeout of each attempt, including connecting, following redirects, and reading the response body.
+	// Unlike responseTimeout, which applies to the entire invocation including retries, this applies to each retry separately. If zero, there's no timeout.
+	RequestTimeout time.Duration `mapstructure:"requestTimeout"`
+	// Timeout for establishing TCP connections.
+	// Default: 15s
+	DialTimeout time.Duration `mapstructure:"dialTimeout"`
+	// Timeout for the TLS handshake.
+	// Default: 15s
+	TLSHandshakeTimeout time.Duration `mapstructure:"tlsHandshakeTimeout"`
+	// Maximum number of idle connections across all hosts. If zero, there's no limit.
+	MaxIdleConns int `mapstructure:"maxIdleConns"`
+	// Maximum number of idle connections kept for each host.
+	// Default: 2
+	MaxIdleConnsPerHost int `mapstructure:"maxIdleConnsPerHost"`
+	// Time after which idle connections are closed. If zero, idle connections are kept until the server closes them.
+	IdleConnTimeout time.Duration `mapstructure:"idleConnTimeout"`
+	// Delay before the first retry, which doubles after each attempt (up to 30s), with random jitter. If zero, retries are sent immediately.
+	RetryBackoff time.Duration `mapstructure:"retryBackoff"`
+	// Comma-separated list of status codes and ranges that are retried, such as "429,500-599".
+	// Default: "429,502,503,504"
+	RetryOnStatusCodes string `mapstructure:"retryOnStatusCodes"`
+	// URL of the proxy all requests are sent through, with the http, https, or socks5 scheme. Credentials can be included in the URL.
+	// If empty, the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables are honored.
+	ProxyURL string `mapstructure:"proxyURL"`
+	// If set, requests are authenticated with a bearer token acquired from this URL with the OAuth2 client credentials flow.
+	// Tokens are cached and refreshed before they expire. An Authorization header set in the request metadata takes precedence.
+	OAuth2TokenURL string `mapstructure:"oauth2TokenURL"`
+	// Client ID for the OAuth2 client credentials flow.
+	OAuth2ClientID string `mapstructure:"oauth2ClientID"`
+	// Client secret for the OAuth2 client credentials flow.
+	OAuth2ClientSecret string `mapstructure:"oauth2ClientSecret"`
+	// Scopes requested for the OAuth2 token.
+	OAuth2Scopes []string `mapstructure:"oauth2Scopes"`
+	// Audience requested for the OAuth2 token, sent as the "audience" parameter.
+	OAuth2Audience string `mapstructure:"oauth2Audience"`
+	// If set together with awsService, requests are signed with AWS Signature Version 4 for this region.
+	AWSRegion string `mapstructure:"awsRegion"`
+	// Name of the AWS service requests are signed for, such as "execute-api" or "es".
+	AWSService string `mapstructure:"awsService"`
+	// AWS access key ID used to sign requests. If empty, credentials are loaded from the environment.
+	AWSAccessKey string `mapstructure:"awsAccessKey"`
+	// AWS secret access key used to sign requests.
+	AWSSecretKey string `mapstructure:"awsSecretKey"`
+	// AWS session token used to sign requests, for temporary credentials.
+	AWSSessionToken string `mapstructure:"awsSessionToken"`
+	// Responses with a body larger than this are written to a file in streamDirectory instead of being returned in the response data.
+	// If zero, responses are always buffered in memory, unless the request sets responseFile.
+	// Bodies written to a file are not limited by maxResponseBodySize, so this can't be larger than it.
+	StreamResponseThreshold kitmd.ByteSize `mapstructure:"streamResponseThreshold"`
+	// Directory where the bodies of responses larger than streamResponseThreshold are written; required when streamResponseThreshold is set.
+	// It must be on a volume shared with the app, which reads the files from it.
+	// The files in the bodyFile and responseFile request metadata are relative to this directory, and can't be outside of it; if empty, those properties are not allowed.
+	StreamDirectory string `mapstructure:"streamDirectory"`
+	// Files of responses larger than streamResponseThreshold are deleted from streamDirectory once they are older than this, including those left by previous runs.
+	// Files written to the path in the responseFile request metadata are never deleted. A value of 0 disables the cleanup.
+	// Default: 1h
+	StreamFileTTL time.Duration `mapstructure:"streamFileTTL"`
+	// What happens when a response body is larger than maxResponseBodySize: "fail" returns a *BodySizeError, while "truncate" returns the first maxResponseBodySize bytes.
+	// Default: "truncate"
+	ResponseBodyOverflowPolicy string `mapstructure:"responseBodyOverflowPolicy"`
+	// If true, PUT, POST, and PATCH request bodies are compressed with gzip. Can be overridden per request with the "compressPayload" metadata property.
+	CompressPayload bool `mapstructure:"compressPayload"`
+	// Path of a Unix domain socket all connections are made to, such as "/var/run/docker.sock". Can also be set with a url like "unix:///var/run/docker.sock".
+	SocketPath string `mapstructure:"socketPath"`
+	// Authentication scheme used for all requests: "basic" sends the credentials with each request, while "digest", "ntlm", and "negotiate" (Kerberos with SPNEGO) answer the challenge of a 401 response.
+	// The credentials are set with username and password; with "ntlm", the username can be in the "DOMAIN\user" format, and with "negotiate", it's the Kerberos principal.
+	AuthType string `mapstructure:"authType"`
+	// Username for authType. Alias of authUsername.
+	Username string `mapstructure:"username"`
+	// Password for authType. Alias of authPassword.
+	Password string `mapstructure:"password"`
+	// If false, redirects are not followed, like with redirectPolicy "none".
+	// Default: true
+	FollowRedirects *bool `mapstructure:"followRedirects"`
+	// Maximum number of redirects followed for a request; more redirects return an error. If zero, the default is used.
+	// Default: 10
+	MaxRedirects int `mapstructure:"maxRedirects"`
+	// Alias of circuitBreakerFailureThreshold.
+	CBFailureThreshold int `mapstructure:"cbFailureThreshold"`
+	// Alias of circuitBreakerOpenDuration.
+	CBOpenDuration time.Duration `mapstructure:"cbOpenDuration"`
+	// Alias of circuitBreakerHalfOpenMaxConcurrent.
+	CBHalfOpenRequests int `mapstructure:"cbHalfOpenRequests"`
+	// Hosts that requests can be sent to with the "urlOverride" metadata, which replaces url. Entries can include a port, and "*.example.com" matches all subdomains.
+	// If empty, urlOverride is not allowed.
+	URLOverrideAllowedHosts []string `mapstructure:"urlOverrideAllowedHosts"`
+	// Interval at which the files in mtlsClientCert and mtlsClientKey are checked for changes, so rotated certificates are used without restarting the component.
+	// The files are also checked when the certificate has expired. A value of 0 disables reloading.
+	// Default: 1m
+	MTLSClientCertReloadInterval time.Duration `mapstructure:"mtlsClientCertReloadInterval"`
+	// Minimum and maximum TLS versions, as "1.0", "1.1", "1.2", or "1.3".
+	// Default: minimum "1.2", maximum is the highest version supported
+	TLSMinVersion string `mapstructure:"tlsMinVersion"`
+	TLSMaxVersion string `mapstructure:"tlsMaxVersion"`
+	// Cipher suites enabled for TLS 1.0-1.2 connections, by their IANA name (e.g. "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"). TLS 1.3 cipher suites are not configurable.
+	// If empty, a safe default list is used.
+	TLSCipherSuites []string `mapstructure:"tlsCipherSuites"`
+	// If true, the server certificate is not verified. Equivalent to setting tlsVerifyMode to "none".
+	InsecureSkipVerify bool `mapstructure:"insecureSkipVerify"`
+	// Server name used for SNI and to verify the hostname of the server certificate, instead of the host of the request URL.
+	ServerName string `mapstructure:"serverName"`
+	// If set, requests are signed with the HMAC of the body computed with this secret, for APIs that verify signed webhooks.
+	RequestHMACSecret string `mapstructure:"requestHmacSecret"`
+	// Header the signature is sent in.
+	// Default: "X-Signature"
+	RequestHMACHeader string `mapstructure:"requestHmacHeader"`
+	// Hash algorithm of the signature: "sha256" or "sha1".
+	// Default: "sha256"
+	RequestHMACAlgorithm string `mapstructure:"requestHmacAlgorithm"`
+	// Encoding of the signature: "hex" or "base64".
+	// Default: "hex"
+	RequestHMACEncoding string `mapstructure:"requestHmacEncoding"`
+	// Message that is signed: "body", "timestamp.body", or a template with the {body} and {timestamp} placeholders, such as "v0:{timestamp}:{body}".
+	// The timestamp is the current time in Unix seconds.
+	// Default: "body"
+	RequestHMACFormat string `mapstructure:"requestHmacFormat"`
+	// Value of the signature header, as a template with the {signature} and {timestamp} placeholders, such as "sha256={signature}" or "t={timestamp},v1={signature}".
+	// Default: "{signature}"
+	RequestHMACSignatureFormat string `mapstructure:"requestHmacSignatureFormat"`
+	// If set, the signed timestamp is sent in this header.
+	RequestHMACTimestampHeader string `mapstructure:"requestHmacTimestampHeader"`
+	// Request headers whose values are part of the response cache key, in addition to the method, the URL, and the credentials, so responses to requests with different values (e.g. "Accept") are cached separately.
+	ResponseCacheKeyHeaders []string `mapstructure:"responseCacheKeyHeaders"`
+	// Maximum time a request waits locally for maxRequestsPerSecond and for a maxConcurrentRequests slot, each, before failing with ErrRateLimitedLocally.
+	// Requests that would wait longer for the rate limiter fail immediately. If zero, requests wait until they time out.
+	RequestQueueTimeout time.Duration `mapstructure:"requestQueueTimeout"`
+	// Static IP addresses for hostnames, as a JSON object or a comma-separated list of "hostname=ip" pairs, used instead of resolving them.
+	// Applies to the hosts that the binding connects to directly, which doesn't include the target of requests sent through a proxy.
+	HostAliases string `mapstructure:"hostAliases"`
+	// Address of the DNS server used to resolve hostnames, as "ip" or "ip:port". If empty, the system resolver is used.
+	DNSServer string `mapstructure:"dnsServer"`
+	// Path of the keytab with the key of the Kerberos principal, for authType "negotiate". If empty, password is used.
+	KerberosKeytab string `mapstructure:"kerberosKeytab"`
+	// Path of the Kerberos configuration file, with the realms and KDCs, for authType "negotiate".
+	// Default: "/etc/krb5.conf"
+	KerberosConfig string `mapstructure:"kerberosConfig"`
+	// Realm of the Kerberos principal, for authType "negotiate".
+	// Default: the default_realm of kerberosConfig
+	KerberosRealm string `mapstructure:"kerberosRealm"`
+	// Service principal name of the server, for authType "negotiate".
+	// Default: "HTTP/" followed by the host of the request
+	KerberosSPN string `mapstructure:"kerberosSPN"`
+	// If true, cookies set by responses are stored in memory and sent with the following requests, so session-based APIs can be used after invoking their login endpoint.
+	MaintainSession bool `mapstructure:"maintainSession"`
 
-	maxResponseBodySizeBytes int64
+	maxResponseBodySizeBytes     int64
+	allowedURLPatterns           []*regexp.Regexp
+	endpoints                    map[string]namedEndpoint
+	retriableNetworkErrors       map[string]struct{}
+	bodyErrorExpr                *jmespath.JMESPath
+	successStatusCodes           []statusCodeRange
+	retryOnStatusCodes           []statusCodeRange
+	streamResponseThresholdBytes int64
+	streamDirectoryPath          string
+	hostAliases                  map[string]string
+	dnsServerAddr                string
 }
 
 // NewHTTP returns a new HTTPSource.
 func NewHTTP(logger logger.Logger) bindings.OutputBinding {
 	return &HTTPSource{
-		logger: logger,
+		logger:  logger,
+		closeCh: make(chan struct{}),
 	}
 }
 
 // Init performs metadata parsing.
-func (h *HTTPSource) Init(_ context.Context, meta bindings.Metadata) error {
+func (h *HTTPSource) Init(ctx context.Context, meta bindings.Metadata) error {
 	h.metadata = httpMetadata{
 		MaxResponseBodySize: kitmd.NewByteSize(defaultMaxResponseBodySizeBytes),
+		VerifyContentLength: true,
+		DialTimeout:         defaultDialTimeout,
+		TLSHandshakeTimeout: defaultTLSHandshakeTimeout,
+		StreamFileTTL:       defaultStreamFileTTL,
+
+		ResponseBodyOverflowPolicy: bodyOverflowPolicyTruncate,
+
+		MTLSClientCertReloadInterval: defaultMTLSClientCertReloadInterval,
 	}
 	err := kitmd.DecodeMetadata(meta.Properties, &h.metadata)
 	if err != nil {
 		return err
 	}
+	err = h.metadata.configureUnixSocket()
+	if err != nil {
+		return err
+	}
 
 	tlsConfig, err := h.addRootCAToCertPool()
 	if err != nil {
 		return err
 	}
 	if h.metadata.MTLSClientCert != "" && h.metadata.MTLSClientKey != "" {
-		err = h.readMTLSClientCertificates(tlsConfig)
+		tlsConfig, err = h.readMTLSClientCertificates(tlsConfig)
+		if err != nil {
+			return err
+		}
+	}
+	if h.metadata.MTLSClientCerts != "" {
+		tlsConfig, err = h.setClientCertificates(tlsConfig)
 		if err != nil {
 			return err
 		}
@@ -112,25 +548,309 @@ func (h *HTTPSource) Init(_ context.Context, meta bindings.Metadata) error {
 		}
 	}
 
+	err = h.metadata.resolveInsecureSkipVerify()
+	if err != nil {
+		return err
+	}
+	tlsConfig, err = h.setTLSVerifyMode(tlsConfig)
+	if err != nil {
+		return err
+	}
+	tlsConfig, err = h.setTLSOptions(tlsConfig)
+	if err != nil {
+		return err
+	}
+
 	h.metadata.maxResponseBodySizeBytes, err = h.metadata.MaxResponseBodySize.GetBytes()
 	if err != nil {
 		return fmt.Errorf("invalid value for maxResponseBodySize: %w", err)
 	}
+	err = validateBodyOverflowPolicy(h.metadata.ResponseBodyOverflowPolicy)
+	if err != nil {
+		return err
+	}
+	h.metadata.streamResponseThresholdBytes, err = h.metadata.StreamResponseThreshold.GetBytes()
+	if err != nil {
+		return fmt.Errorf("invalid value for streamResponseThreshold: %w", err)
+	}
+	err = h.metadata.validateStreamingSettings()
+	if err != nil {
+		return err
+	}
+
+	h.metadata.allowedURLPatterns, err = compileURLPatterns(h.metadata.AllowedURLPatterns)
+	if err != nil {
+		return fmt.Errorf("invalid value for allowedURLPatterns: %w", err)
+	}
+
+	if h.metadata.ExpectResponseContentType != "" {
+		_, err = path.Match(h.metadata.ExpectResponseContentType, "")
+		if err != nil {
+			return fmt.Errorf("invalid value for expectResponseContentType: %w", err)
+		}
+	}
+
+	err = validateAcceptLanguage(h.metadata.AcceptLanguage)
+	if err != nil {
+		return fmt.Errorf("invalid value for acceptLanguage: %w", err)
+	}
+
+	if h.metadata.IdempotencyKeyHeader == "" {
+		h.metadata.IdempotencyKeyHeader = defaultIdempotencyKeyHeader
+	}
+
+	if h.metadata.MaxResponseHeaderCount < 0 {
+		return errors.New("invalid value for maxResponseHeaderCount: must not be negative")
+	}
+	err = validateHeaderOverflowPolicy(h.metadata.HeaderOverflowPolicy)
+	if err != nil {
+		return err
+	}
+
+	if h.metadata.SuccessStatusCodes != "" {
+		h.metadata.successStatusCodes, err = parseStatusCodeRanges(h.metadata.SuccessStatusCodes)
+		if err != nil {
+			return fmt.Errorf("invalid value for successStatusCodes: %w", err)
+		}
+	}
+	if h.metadata.ErrorBodyMaxLength < 0 {
+		return errors.New("invalid value for errorBodyMaxLength: must not be negative")
+	}
+
+	err = h.metadata.compileBodyErrorJMESPath()
+	if err != nil {
+		return err
+	}
+
+	err = validateTrailingSlash(h.metadata.TrailingSlash)
+	if err != nil {
+		return err
+	}
+
+	err = validateRequestProtocol(h.metadata.RequestProtocol)
+	if err != nil {
+		return err
+	}
+	if (h.metadata.RequestProtocol == requestProtocol10 || h.metadata.RequestProtocol == requestProtocol2) && h.metadata.ProxyURL != "" {
+		return fmt.Errorf("proxyURL is not supported with requestProtocol %s", h.metadata.RequestProtocol)
+	}
+
+	err = h.metadata.validateResponseHMAC()
+	if err != nil {
+		return err
+	}
+	err = h.metadata.validateRequestHMAC()
+	if err != nil {
+		return err
+	}
+
+	switch h.metadata.DuplicateHeaderPolicy {
+	case "", duplicateHeaderPolicyAppend, duplicateHeaderPolicyError:
+	default:
+		return fmt.Errorf("invalid value for duplicateHeaderPolicy: %s", h.metadata.DuplicateHeaderPolicy)
+	}
+
+	err = h.metadata.validateRedirectPolicy()
+	if err != nil {
+		return err
+	}
+
+	h.metadata.endpoints, err = parseEndpoints(h.metadata.Endpoints)
+	if err != nil {
+		return fmt.Errorf("invalid value for endpoints: %w", err)
+	}
+
+	if h.metadata.FallbackURL != "" {
+		fu, err := url.Parse(h.metadata.FallbackURL)
+		if err != nil || fu.Scheme == "" || fu.Host == "" {
+			return errors.New("invalid value for fallbackURL: must be an absolute URL")
+		}
+	}
+
+	if h.metadata.OnErrorWebhookURL != "" {
+		wu, err := url.Parse(h.metadata.OnErrorWebhookURL)
+		if err != nil || wu.Scheme == "" || wu.Host == "" {
+			return errors.New("invalid value for onErrorWebhookURL: must be an absolute URL")
+		}
+		h.errorNotifier = newErrorNotifier(h.metadata.OnErrorWebhookURL, h.metadata.OnErrorWebhookInterval, h.logger)
+	}
+
+	if h.metadata.OptimisticConcurrency {
+		h.etags, err = newETagStore(h.metadata.OptimisticConcurrencyMaxEntries)
+		if err != nil {
+			return fmt.Errorf("failed to create ETag store: %w", err)
+		}
+	}
+
+	if h.metadata.EnableResponseCache {
+		h.responseCache, err = newResponseCache(h.metadata.ResponseCacheMaxEntries, h.metadata.ResponseCacheDefaultTTL, h.metadata.ResponseCacheKeyHeaders, h.metadata.SecurityTokenHeader)
+		if err != nil {
+			return fmt.Errorf("failed to create response cache: %w", err)
+		}
+	}
+
+	if h.metadata.MaxRequestsPerSecond < 0 {
+		return errors.New("invalid value for maxRequestsPerSecond: must not be negative")
+	}
+	if h.metadata.MaxRequestsPerSecond > 0 {
+		h.rateLimiter, err = newRateLimiter(h.metadata.MaxRequestsPerSecond, h.metadata.RateLimitBurst, h.metadata.RateLimitMaxKeys, h.metadata.RequestQueueTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to create rate limiter: %w", err)
+		}
+	}
+
+	if h.metadata.RequestQueueTimeout < 0 {
+		return errors.New("invalid value for requestQueueTimeout: must not be negative")
+	}
+	if h.metadata.MaxConcurrentRequests < 0 {
+		return errors.New("invalid value for maxConcurrentRequests: must not be negative")
+	}
+	if h.metadata.RequestQueueSize < 0 {
+		return errors.New("invalid value for requestQueueSize: must not be negative")
+	}
+	if h.metadata.MaxConcurrentRequests > 0 {
+		h.requestQueue = newRequestQueue(h.metadata.MaxConcurrentRequests, h.metadata.RequestQueueSize, h.metadata.RequestQueueTimeout)
+	} else if h.metadata.RequestQueueSize > 0 {
+		return errors.New("requestQueueSize requires maxConcurrentRequests")
+	}
+
+	err = h.metadata.resolveCircuitBreakerAliases()
+	if err != nil {
+		return err
+	}
+	if h.metadata.CircuitBreakerFailureThreshold > 0 {
+		h.circuitBreaker = newCircuitBreaker(
+			h.metadata.CircuitBreakerFailureThreshold,
+			h.metadata.CircuitBreakerOpenDuration,
+			h.metadata.CircuitBreakerHalfOpenMaxConcurrent,
+			h.metadata.CircuitBreakerHalfOpenSuccesses,
+		)
+	}
+
+	h.metadata.retriableNetworkErrors, err = parseNetworkErrorCategories(h.metadata.RetriableNetworkErrors)
+	if err != nil {
+		return fmt.Errorf("invalid value for retriableNetworkErrors: %w", err)
+	}
+
+	if h.metadata.MaxRetries < 0 {
+		return errors.New("invalid value for maxRetries: must not be negative")
+	}
+	if h.metadata.RetryBackoff < 0 {
+		return errors.New("invalid value for retryBackoff: must not be negative")
+	}
+	if h.metadata.RetryOnStatusCodes != "" {
+		h.metadata.retryOnStatusCodes, err = parseStatusCodeRanges(h.metadata.RetryOnStatusCodes)
+		if err != nil {
+			return fmt.Errorf("invalid value for retryOnStatusCodes: %w", err)
+		}
+	}
+	if h.metadata.RetryBudgetRatio > 0 {
+		minRetries := defaultRetryBudgetMinRetries
+		if h.metadata.RetryBudgetMinRetries != nil {
+			minRetries = *h.metadata.RetryBudgetMinRetries
+		}
+		if minRetries < 0 {
+			return errors.New("invalid value for retryBudgetMinRetries: must not be negative")
+		}
+		h.retryBudget = newRetryBudget(h.metadata.RetryBudgetRatio, h.metadata.RetryBudgetWindow, minRetries)
+	}
 
 	// See guidance on proper HTTP client settings here:
 	// https://medium.com/@nate510/don-t-use-go-s-default-http-client-4804cb19f779
-	dialer := &net.Dialer{
-		Timeout: 15 * time.Second,
+	err = h.metadata.validateTransportSettings()
+	if err != nil {
+		return err
+	}
+	err = h.metadata.parseResolverSettings()
+	if err != nil {
+		return err
 	}
+	dialer := h.newDialer()
+	dial := h.dialContext(dialer)
 	netTransport := &http.Transport{
-		Dial:                dialer.Dial,
-		TLSHandshakeTimeout: 15 * time.Second,
+		DialContext:         dial,
+		TLSHandshakeTimeout: h.metadata.TLSHandshakeTimeout,
 		TLSClientConfig:     tlsConfig,
+		MaxIdleConns:        h.metadata.MaxIdleConns,
+		MaxIdleConnsPerHost: h.metadata.MaxIdleConnsPerHost,
+		IdleConnTimeout:     h.metadata.IdleConnTimeout,
+		// Responses are decompressed by the binding, which supports more encodings
+		DisableCompression: true,
+	}
+	dialTLS := dialTLSContext(netTransport, dial, netTransport.TLSHandshakeTimeout)
+	httpsProxied, err := h.configureProxy(netTransport)
+	if err != nil {
+		return err
+	}
+	if !httpsProxied {
+		// A custom TLS dialer can't be used with proxies, as the transport would dial the proxy with it
+		netTransport.DialTLSContext = dialTLS
+	}
+	err = h.configureHTTP2(netTransport)
+	if err != nil {
+		return err
+	}
+	if h.metadata.WarmupConnections < 0 {
+		return errors.New("invalid value for warmupConnections: must not be negative")
+	}
+	if h.metadata.WarmupConnections > max(netTransport.MaxIdleConnsPerHost, http.DefaultMaxIdleConnsPerHost) {
+		// Keep all warmed-up connections in the idle pool
+		netTransport.MaxIdleConnsPerHost = h.metadata.WarmupConnections
 	}
 
 	h.client = &http.Client{
-		Timeout:   0, // no time out here, we use request timeouts instead
-		Transport: netTransport,
+		// The timeout of the entire invocation is responseTimeout, applied to the request context
+		Timeout:       h.metadata.RequestTimeout,
+		Transport:     netTransport,
+		CheckRedirect: h.checkRedirect,
+	}
+	if h.metadata.MaintainSession {
+		// The public suffix list prevents responses from setting cookies for unrelated domains
+		h.client.Jar, err = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+		if err != nil {
+			return fmt.Errorf("failed to create cookie jar: %w", err)
+		}
+	}
+	switch h.metadata.RequestProtocol {
+	case requestProtocol10:
+		h.client.Transport = &http10Transport{
+			next:        netTransport,
+			dialContext: dial,
+			dialTLS:     dialTLS,
+		}
+	case requestProtocol2:
+		h.client.Transport = h.newHTTP2OnlyTransport(netTransport, dial, dialTLS)
+	}
+
+	h.client.Transport, err = h.newSigV4Transport(h.client.Transport)
+	if err != nil {
+		return err
+	}
+	h.client.Transport = h.newHMACSigningTransport(h.client.Transport)
+
+	h.oauth2TokenSource, err = h.newOAuth2TokenSource()
+	if err != nil {
+		return err
+	}
+	err = h.metadata.validateAuthType()
+	if err != nil {
+		return err
+	}
+	switch h.metadata.AuthType {
+	case authSchemeDigest:
+		h.digestSession = &digestSession{}
+	case authSchemeNegotiate:
+		h.kerberosClient, err = h.newKerberosClient()
+		if err != nil {
+			return err
+		}
+	}
+
+	if h.metadata.MaxIdleTimeBeforeReset < 0 {
+		return errors.New("invalid value for maxIdleTimeBeforeReset: must not be negative")
+	}
+	if h.metadata.MaxIdleTimeBeforeReset > 0 {
+		h.idleReset = newIdleReset(h.metadata.MaxIdleTimeBeforeReset, h.client.CloseIdleConnections)
 	}
 
 	if val := meta.Properties["errorIfNot2XX"]; val != "" {
@@ -140,28 +860,82 @@ func (h *HTTPSource) Init(_ context.Context, meta bindings.Metadata) error {
 		h.errorIfNot2XX = true
 	}
 
+	if h.metadata.WarmupConnections > 0 {
+		if h.metadata.RequestProtocol == requestProtocol10 {
+			// With HTTP/1.0, connections are never reused
+			h.logger.Warn("Ignoring warmupConnections, as connections are not reused with requestProtocol 1.0")
+		} else if err = h.warmupConnections(ctx); err != nil {
+			if h.metadata.WarmupStrict {
+				return fmt.Errorf("failed to warm up connections: %w", err)
+			}
+			h.logger.Warnf("Failed to warm up connections: %v", err)
+		}
+	}
+
+	h.startStreamFileCleanup()
+
 	return nil
 }
 
-// readMTLSClientCertificates reads the certificates and key from the metadata and returns a tls.Config.
-func (h *HTTPSource) readMTLSClientCertificates(tlsConfig *tls.Config) error {
+// configureHTTP2 configures the HTTP/2 settings of the transport, if any is set.
+func (h *HTTPSource) configureHTTP2(t *http.Transport) error {
+	if h.metadata.HTTP2ReadIdleTimeout < 0 {
+		return errors.New("invalid value for http2ReadIdleTimeout: must not be negative")
+	}
+	if h.metadata.HTTP2PingTimeout < 0 {
+		return errors.New("invalid value for http2PingTimeout: must not be negative")
+	}
+	if h.metadata.HTTP2MaxConcurrentStreams < 0 {
+		return errors.New("invalid value for http2MaxConcurrentStreams: must not be negative")
+	}
+	if h.metadata.HTTP2MaxConcurrentStreams > 0 && h.metadata.RequestProtocol != requestProtocol2 {
+		// With HTTP/1.1 transports, HTTP/2 is negotiated per connection, and the x/net/http2 client doesn't allow limiting the streams
+		return fmt.Errorf("http2MaxConcurrentStreams requires requestProtocol %q", requestProtocol2)
+	}
+	if h.metadata.HTTP2ReadIdleTimeout == 0 && h.metadata.HTTP2PingTimeout == 0 {
+		// Leave the transport with the default settings
+		return nil
+	}
+
+	h2, err := http2.ConfigureTransports(t)
+	if err != nil {
+		return fmt.Errorf("failed to configure HTTP/2 transport: %w", err)
+	}
+	h2.ReadIdleTimeout = h.metadata.HTTP2ReadIdleTimeout
+	h2.PingTimeout = h.metadata.HTTP2PingTimeout
+	return nil
+}
+
+// readMTLSClientCertificates reads the certificates and key from the metadata, returning the (possibly new) tls.Config.
+// If the certificate and key are paths to files, they are re-read when the files change, unless mtlsClientCertReloadInterval is 0.
+func (h *HTTPSource) readMTLSClientCertificates(tlsConfig *tls.Config) (*tls.Config, error) {
 	clientCertBytes, err := h.getPemBytes(MTLSClientCert, h.metadata.MTLSClientCert)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	clientKeyBytes, err := h.getPemBytes(MTLSClientKey, h.metadata.MTLSClientKey)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	cert, err := tls.X509KeyPair(clientCertBytes, clientKeyBytes)
 	if err != nil {
-		return fmt.Errorf("failed to load client certificate: %w", err)
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
 	}
 	if tlsConfig == nil {
 		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
 	}
 	tlsConfig.Certificates = []tls.Certificate{cert}
-	return nil
+
+	// When mtlsClientCerts is set, the certificate is selected among the candidates instead
+	if h.metadata.MTLSClientCertReloadInterval > 0 && h.metadata.MTLSClientCerts == "" &&
+		!isValidPEM(h.metadata.MTLSClientCert) && !isValidPEM(h.metadata.MTLSClientKey) {
+		reloader, err := newClientCertReloader(h.metadata.MTLSClientCert, h.metadata.MTLSClientKey, h.metadata.MTLSClientCertReloadInterval, h.logger)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = reloader.getClientCertificate
+	}
+	return tlsConfig, nil
 }
 
 // setTLSRenegotiation set TLS renegotiation parameter and returns a tls.Config
@@ -179,6 +953,52 @@ func (h *HTTPSource) setTLSRenegotiation(tlsConfig *tls.Config) error {
 	return nil
 }
 
+// setTLSVerifyMode configures how the server certificate is verified, returning the (possibly new) tls.Config.
+func (h *HTTPSource) setTLSVerifyMode(tlsConfig *tls.Config) (*tls.Config, error) {
+	switch h.metadata.TLSVerifyMode {
+	case "", tlsVerifyModeFull:
+		return tlsConfig, nil
+	case tlsVerifyModeSkipHostnameOnly:
+		h.logger.Warn("TLS hostname verification is disabled: the server certificate chain is verified, but not its hostname")
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		// Verification is performed in VerifyConnection instead, omitting the hostname check
+		roots := tlsConfig.RootCAs
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyCertificateChain(cs.PeerCertificates, roots)
+		}
+		return tlsConfig, nil
+	case tlsVerifyModeNone:
+		h.logger.Warn("TLS certificate verification is disabled: this is insecure and should not be used in production")
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec
+		return tlsConfig, nil
+	default:
+		return nil, fmt.Errorf("invalid value for tlsVerifyMode: %s", h.metadata.TLSVerifyMode)
+	}
+}
+
+// verifyCertificateChain verifies the peer certificate chain against the root CAs, without checking the hostname.
+// If roots is nil, the system pool is used.
+func verifyCertificateChain(certs []*x509.Certificate, roots *x509.CertPool) error {
+	if len(certs) == 0 {
+		return errors.New("server did not present a certificate")
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(opts)
+	return err
+}
+
 // Add Root CA cert to the pool of trusted certificates.
 // This is required for the client to trust the server certificate in case of HTTPS connection.
 func (h *HTTPSource) addRootCAToCertPool() (*tls.Config, error) {
@@ -234,40 +1054,229 @@ func (h *HTTPSource) Operations() []bindings.OperationKind {
 		"delete",
 		"options",
 		"trace",
+		batchOperation,
+		discoverMethodsOperation,
+		uploadArchiveOperation,
+		multipartOperation,
 	}
 }
 
 // Invoke performs an HTTP request to the configured HTTP endpoint.
 func (h *HTTPSource) Invoke(parentCtx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
-	u := h.metadata.URL
-
-	errorIfNot2XX := h.errorIfNot2XX // Default to the component config (default is true)
-
 	if req.Metadata == nil {
 		// Prevent things below from failing if req.Metadata is nil.
 		req.Metadata = make(map[string]string, 0)
 	}
 
-	if req.Metadata["path"] != "" {
-		u = strings.TrimRight(u, "/") + "/" + strings.TrimLeft(req.Metadata["path"], "/")
+	// Skip the request if the skipIf condition holds
+	if expr := req.Metadata["skipIf"]; expr != "" {
+		skip, err := evaluateSkipIf(expr, req.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		if skip {
+			return &bindings.InvokeResponse{
+				Metadata: map[string]string{"skipped": "true"},
+			}, nil
+		}
+	}
+
+	// Requests in fire-and-forget mode are sent in background, and their response is not returned
+	if utils.IsTruthy(req.Metadata["fireAndForget"]) {
+		return h.invokeFireAndForget(req)
+	}
+
+	if h.metadata.TotalOperationTimeout > 0 {
+		var cancel context.CancelFunc
+		parentCtx, cancel = context.WithTimeoutCause(parentCtx, h.metadata.TotalOperationTimeout, errTotalOperationTimeout)
+		defer cancel()
+	}
+
+	switch req.Operation {
+	case batchOperation:
+		return h.invokeBatch(parentCtx, req)
+	case discoverMethodsOperation:
+		return h.discoverMethods(parentCtx, req)
+	}
+
+	// Select the named endpoint if requested, or use the default URL
+	u := h.metadata.URL
+	var endpointHeaders map[string]string
+	if name := req.Metadata["endpoint"]; name != "" {
+		endpoint, ok := h.metadata.endpoints[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown endpoint: %s", name)
+		}
+		u = endpoint.URL
+		endpointHeaders = endpoint.Headers
+	}
+	if override := req.Metadata["urlOverride"]; override != "" {
+		if req.Metadata["endpoint"] != "" {
+			return nil, errors.New("cannot use urlOverride metadata together with endpoint")
+		}
+		overridden, err := h.overrideURL(override)
+		if err != nil {
+			return nil, err
+		}
+		u = overridden
+	}
+
+	u = composeURL(u, req.Metadata["path"])
+
+	spec, err := parseRequestSpec(req.Metadata["requestSpec"])
+	if err != nil {
+		return nil, err
+	}
+	if spec != nil {
+		u, err = spec.applyPathParams(u)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	policy, err := h.responsePolicy(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	maxRetries := h.metadata.MaxRetries
+	if req.Metadata["maxRetries"] != "" {
+		val, err := strconv.Atoi(req.Metadata["maxRetries"])
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("invalid value for maxRetries: %s", req.Metadata["maxRetries"])
+		}
+		maxRetries = val
 	}
-	if req.Metadata["errorIfNot2XX"] != "" {
-		errorIfNot2XX = utils.IsTruthy(req.Metadata["errorIfNot2XX"])
+
+	grpcWeb := h.metadata.GRPCWeb
+	if val := req.Metadata["grpcWeb"]; val != "" {
+		grpcWeb = utils.IsTruthy(val)
 	}
 
-	var body io.Reader
+	compressPayload := h.metadata.CompressPayload
+	if val := req.Metadata["compressPayload"]; val != "" {
+		compressPayload = utils.IsTruthy(val)
+	}
+	if _, ok := req.Metadata["Content-Encoding"]; ok {
+		// The body is already encoded by the caller
+		compressPayload = false
+	}
+
+	var (
+		body     io.Reader
+		reqData  []byte
+		bodyFile string
+		archive  *archiveUpload
+		upload   *uploadProgress
+
+		// multipart/form-data body, encoded while the request is sent
+		form *multipartBody
+	)
 	method := strings.ToUpper(string(req.Operation))
 	// For backward compatibility
-	if method == "CREATE" {
+	usedCreateAlias := method == "CREATE"
+	if usedCreateAlias {
 		method = "POST"
+		if h.metadata.WarnOnCreateAlias {
+			h.createAliasOnce.Do(func() {
+				h.logger.Warn("The \"create\" operation is deprecated and will be removed in a future version: use \"post\" instead")
+			})
+			h.logger.Debug("Invoked deprecated \"create\" operation")
+		}
 	}
 	switch method {
 	case "PUT", "POST", "PATCH":
-		body = bytes.NewBuffer(req.Data)
+		reqData = req.Data
+		// Assemble the body from the "jsonBody.*" metadata, if present
+		jsonBody, err := buildJSONBody(req.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		if jsonBody != nil {
+			if len(req.Data) > 0 {
+				return nil, errors.New("cannot use jsonBody metadata when the request contains data")
+			}
+			reqData = jsonBody
+		}
+		if specBody := spec.body(); len(specBody) > 0 {
+			if len(reqData) > 0 {
+				return nil, errors.New("cannot use the body in requestSpec when the request contains data")
+			}
+			reqData = specBody
+		}
+		// The body is read from a file in streamDirectory, streamed while the request is sent
+		bodyFile = req.Metadata["bodyFile"]
+		if bodyFile != "" {
+			bodyFile, err = h.resolveStreamFile("bodyFile", bodyFile)
+			if err != nil {
+				return nil, err
+			}
+			if len(reqData) > 0 {
+				return nil, errors.New("cannot use bodyFile metadata when the request contains data")
+			}
+			if grpcWeb {
+				return nil, errors.New("grpcWeb is not supported with bodyFile")
+			}
+			break
+		}
+		if grpcWeb {
+			reqData = frameGRPCWebMessage(reqData)
+		}
+		if compressPayload && !grpcWeb && len(reqData) > 0 {
+			// gRPC-Web messages are never compressed, as the protocol has its own compression
+			compressed, gzErr := gzipPayload(reqData)
+			if gzErr != nil {
+				return nil, fmt.Errorf("failed to compress request body: %w", gzErr)
+			}
+			body = bytes.NewBuffer(compressed)
+			break
+		}
+		compressPayload = false
+		body = bytes.NewBuffer(reqData)
+	case "UPLOADARCHIVE":
+		// The body is a tar archive of a local directory, streamed while the request is sent
+		if grpcWeb {
+			return nil, errors.New("grpcWeb is not supported with the uploadArchive operation")
+		}
+		archive, err = newArchiveUpload(req.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		defer archive.close()
+		method = http.MethodPost
+		// The size of the archive is not known in advance
+		upload = newUploadProgress(archive.reader(), -1)
+		body = upload
+	case "MULTIPART":
+		// The body is a multipart/form-data form, built from the parts in the request data
+		if grpcWeb {
+			return nil, errors.New("grpcWeb is not supported with the multipart operation")
+		}
+		form, err = parseMultipartBody(req.Data)
+		if err != nil {
+			return nil, err
+		}
+		method = http.MethodPost
 	case "GET", "HEAD", "DELETE", "OPTIONS", "TRACE":
 	default:
 		return nil, fmt.Errorf("invalid operation: %s", req.Operation)
 	}
+	if grpcWeb && method != http.MethodPost {
+		return nil, errors.New("grpcWeb requires the post operation")
+	}
+	responseFile := req.Metadata["responseFile"]
+	if grpcWeb && (responseFile != "" || h.metadata.streamResponseThresholdBytes > 0) {
+		return nil, errors.New("grpcWeb responses can't be streamed to a file")
+	}
+	if responseFile != "" && h.metadata.ResponseHMACSecret != "" {
+		return nil, errors.New("cannot use responseFile metadata when responseHmacSecret is set")
+	}
+	if responseFile != "" {
+		responseFile, err = h.resolveStreamFile("responseFile", responseFile)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	ctx := parentCtx
 	if h.metadata.ResponseTimeout != nil {
@@ -280,28 +1289,117 @@ func (h *HTTPSource) Invoke(parentCtx context.Context, req *bindings.InvokeReque
 	if err != nil {
 		return nil, err
 	}
+	if archive != nil {
+		archive.start(ctx)
+	}
+	if bodyFile != "" {
+		err = openBodyFile(request, bodyFile)
+		if err != nil {
+			return nil, err
+		}
+		// The transport closes the body, unless the request is never sent
+		defer request.Body.Close()
+	}
+	if form != nil {
+		request.Body = form.reader()
+		request.GetBody = func() (io.ReadCloser, error) {
+			return form.reader(), nil
+		}
+		// The body is replaced when the request is retried, and the last one is closed in case the request is never sent
+		defer func() {
+			request.Body.Close()
+		}()
+	}
+	if upload != nil {
+		upload.report(h.logger, h.metadata.UploadProgressInterval, method, request.URL.Redacted())
+		defer upload.stop()
+	}
+	setRequestProtocol(request, h.metadata.RequestProtocol)
+	h.normalizeURLPath(request.URL)
 
 	// Set default values for Content-Type and Accept headers.
-	if body != nil {
+	defaultContentType := "application/json; charset=utf-8"
+	if grpcWeb {
+		defaultContentType = grpcWebContentType
+		request.Header.Set("X-Grpc-Web", "1")
+	}
+	if body != nil || bodyFile != "" || form != nil {
 		if _, ok := req.Metadata["Content-Type"]; !ok {
-			request.Header.Set("Content-Type", "application/json; charset=utf-8")
+			switch {
+			case archive != nil:
+				request.Header.Set("Content-Type", archive.contentType)
+			case bodyFile != "":
+				request.Header.Set("Content-Type", "application/octet-stream")
+			case form != nil:
+				request.Header.Set("Content-Type", form.contentType)
+			case h.metadata.AutoOctetStream && !grpcWeb && looksBinary(reqData):
+				request.Header.Set("Content-Type", "application/octet-stream")
+			default:
+				request.Header.Set("Content-Type", defaultContentType)
+			}
 		}
 	}
+	if compressPayload && body != nil && bodyFile == "" && archive == nil {
+		request.Header.Set("Content-Encoding", "gzip")
+	}
 	if _, ok := req.Metadata["Accept"]; !ok {
-		request.Header.Set("Accept", "application/json; charset=utf-8")
+		request.Header.Set("Accept", defaultContentType)
+	}
+	// Responses are decompressed by the binding, unless a different Accept-Encoding is set below
+	request.Header.Set("Accept-Encoding", defaultAcceptEncoding)
+
+	// Set the Accept-Language header; an explicit header set below takes precedence.
+	acceptLanguage := h.metadata.AcceptLanguage
+	if val := req.Metadata["acceptLanguage"]; val != "" {
+		err = validateAcceptLanguage(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for acceptLanguage: %w", err)
+		}
+		acceptLanguage = val
+	}
+	if acceptLanguage != "" {
+		request.Header.Set("Accept-Language", acceptLanguage)
+	}
+
+	// Set the default headers of the named endpoint, if any.
+	for k, v := range endpointHeaders {
+		request.Header.Set(k, v)
+	}
+
+	// Set the headers from the requestSpec, which can be overridden by the metadata.
+	if spec != nil {
+		for k, v := range spec.Headers {
+			request.Header.Set(k, v)
+		}
+		spec.applyQuery(request.URL)
+	}
+	if val := req.Metadata["queryParams"]; val != "" {
+		err = applyQueryParams(request.URL, val)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Set security token values if set.
 	if h.metadata.SecurityToken != "" && h.metadata.SecurityTokenHeader != "" {
 		request.Header.Set(h.metadata.SecurityTokenHeader, h.metadata.SecurityToken)
 	}
+	if h.oauth2TokenSource != nil {
+		err = h.setOAuth2Token(request)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = h.setAuthTypeHeader(request)
+	if err != nil {
+		return nil, err
+	}
 
 	// Any metadata keys that start with a capital letter
 	// are treated as request headers
-	for mdKey, mdValue := range req.Metadata {
-		if len(mdKey) > 0 && (mdKey[0] >= 'A' && mdKey[0] <= 'Z') {
-			request.Header.Set(mdKey, mdValue)
-		}
+	err = h.setMetadataHeaders(request.Header, req.Metadata)
+	if err != nil {
+		return nil, err
 	}
 
 	// HTTP binding needs to inject traceparent header for proper tracing stack.
@@ -319,11 +1417,98 @@ func (h *HTTPSource) Invoke(parentCtx context.Context, req *bindings.InvokeReque
 
 		request.Header.Set(TracestateHeaderKey, ts)
 	}
+	// Start a new trace if the request doesn't have any trace context
+	generatedTraceparent := h.originateTrace(request)
 
-	// Send the question
-	resp, err := h.client.Do(request)
-	if err != nil {
-		return nil, err
+	// Set the idempotency key, which is the same for all attempts
+	idempotencyKey := h.setIdempotencyKey(request, req.Metadata["idempotencyKey"])
+
+	// Send If-Match with the last known ETag, if enabled
+	etags := h.etags
+	if val := req.Metadata["optimisticConcurrency"]; val != "" && !utils.IsTruthy(val) {
+		etags = nil
+	}
+	if etags != nil {
+		etags.applyIfMatch(request)
+	}
+
+	// Check the final URL against the allowlist, after all substitutions have been applied.
+	if !h.isURLAllowed(request.URL) {
+		return nil, fmt.Errorf("URL not allowed: %s", request.URL.Redacted())
+	}
+
+	if h.debugLogEnabled() {
+		h.logRequest(request, reqData)
+	}
+
+	var (
+		resp     *http.Response
+		retryRes retryResult
+		attempts int
+		cbStats  *circuitStats
+		// Set if the response is served from the cache, possibly after revalidating it
+		fromCache bool
+	)
+	var (
+		cached      *cacheEntry
+		revalidated bool
+		queued      *queueStats
+	)
+	if h.responseCache != nil {
+		var fresh bool
+		cached, fresh = h.responseCache.lookup(request)
+		if fresh {
+			resp = cached.response(request, h.responseCache.now())
+			fromCache = true
+		} else if cached != nil {
+			revalidated = cached.setConditionalHeaders(request)
+		}
+	}
+
+	// Send the request, unless the response is served from the cache
+	if resp == nil {
+		if h.requestQueue != nil {
+			// The slot is held until the response body has been read
+			stats, qErr := h.requestQueue.acquire(ctx)
+			if qErr != nil {
+				failedRes := &bindings.InvokeResponse{Metadata: map[string]string{}}
+				stats.addMetadata(failedRes.Metadata)
+				return failedRes, fmt.Errorf("failed to send request: %w", qErr)
+			}
+			defer h.requestQueue.release()
+			queued = &stats
+		}
+
+		resp, request, retryRes, attempts, cbStats, err = h.send(ctx, parentCtx, request, req, maxRetries, archive == nil)
+		if err != nil {
+			var circuitErr *CircuitOpenError
+			if errors.As(err, &circuitErr) {
+				// Rejected without sending the request
+				failedRes := &bindings.InvokeResponse{Metadata: map[string]string{}}
+				circuitErr.addMetadata(failedRes.Metadata)
+				return failedRes, err
+			}
+			if attempts == 0 {
+				return nil, err
+			}
+			// Return the retry information with the error
+			retryRes.attempts = attempts
+			failedRes := &bindings.InvokeResponse{Metadata: map[string]string{}}
+			retryRes.addMetadata(failedRes.Metadata)
+			if queued != nil {
+				queued.addMetadata(failedRes.Metadata)
+			}
+			return failedRes, err
+		}
+
+		if revalidated && resp.StatusCode == http.StatusNotModified {
+			// The cached response is still valid
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			cached = h.responseCache.revalidated(request, cached, resp)
+			resp = cached.response(request, h.responseCache.now())
+			fromCache = true
+		}
 	}
 	defer func() {
 		// Drain before closing
@@ -331,19 +1516,65 @@ func (h *HTTPSource) Invoke(parentCtx context.Context, req *bindings.InvokeReque
 		resp.Body.Close()
 	}()
 
-	var respBody io.Reader = resp.Body
-	if h.metadata.maxResponseBodySizeBytes > 0 {
-		respBody = io.LimitReader(resp.Body, h.metadata.maxResponseBodySizeBytes)
+	decoded, contentEncoding, err := decompressResponse(request, resp)
+	if err != nil {
+		return nil, err
+	}
+	// The limit applies to the decompressed body, and only when it's buffered in memory: bodies that may be written to a file are never limited
+	respBody := decoded
+	limited := responseFile == "" && h.metadata.streamResponseThresholdBytes <= 0
+	if limited {
+		respBody, err = h.limitResponseBody(decoded, resp.ContentLength)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Read the response body. For empty responses (e.g. 204 No Content)
-	// `b` will be an empty slice.
-	b, err := io.ReadAll(respBody)
+	// `b` will be an empty slice. Large bodies may be written to a file instead.
+	b, streamed, err := h.readResponseBody(respBody, responseFile)
+	if h.metadata.VerifyContentLength && method != http.MethodHead {
+		read := int64(len(b))
+		if streamed != nil {
+			read = streamed.size
+		}
+		lengthErr := h.checkContentLength(resp, read, limited)
+		if lengthErr != nil {
+			if streamed != nil {
+				_ = os.Remove(streamed.path)
+			}
+			return nil, lengthErr
+		}
+	}
+	if err != nil {
+		return nil, h.wrapTotalTimeoutError(parentCtx, err, attempts)
+	}
+
+	if h.debugLogEnabled() {
+		h.logResponse(request, resp, b)
+	}
+
+	if h.metadata.ResponseHMACSecret != "" {
+		err = h.verifyResponseSignature(resp.Header, b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if etags != nil {
+		etags.track(request, resp)
+	}
+
+	if h.responseCache != nil && !fromCache && streamed == nil {
+		h.responseCache.store(request, resp, b)
+	}
+
+	headersTruncated, err := h.enforceResponseHeaderCount(resp.Header)
 	if err != nil {
 		return nil, err
 	}
 
-	metadata := make(map[string]string, len(resp.Header)+2)
+	metadata := make(map[string]string, len(resp.Header)+5)
 	// Include status code & desc
 	metadata["statusCode"] = strconv.Itoa(resp.StatusCode)
 	metadata["status"] = resp.Status
@@ -354,9 +1585,174 @@ func (h *HTTPSource) Invoke(parentCtx context.Context, req *bindings.InvokeReque
 		metadata[key] = strings.Join(values, ", ")
 	}
 
-	// Create an error for non-200 status codes unless suppressed.
-	if errorIfNot2XX && resp.StatusCode/100 != 2 {
-		err = fmt.Errorf("received status code %d", resp.StatusCode)
+	if headersTruncated {
+		metadata["headersTruncated"] = "true"
+	}
+
+	// Original encoding of responses that were decompressed
+	if contentEncoding != "" {
+		metadata["contentEncoding"] = contentEncoding
+	}
+
+	includeRawHeaders := h.metadata.IncludeRawResponseHeaders
+	if val := req.Metadata["includeRawResponseHeaders"]; val != "" {
+		includeRawHeaders = utils.IsTruthy(val)
+	}
+	if includeRawHeaders {
+		// Unlike the individual header entries, this preserves headers with multiple values
+		rawHeaders, jsonErr := json.Marshal(resp.Header)
+		if jsonErr != nil {
+			return nil, fmt.Errorf("failed to encode response headers: %w", jsonErr)
+		}
+		metadata["rawResponseHeadersJSON"] = string(rawHeaders)
+	}
+
+	// Each metric in Server-Timing is returned individually, in addition to the raw header
+	h.addServerTimingMetadata(resp.Header, metadata)
+
+	// Unframe gRPC-Web responses, returning the payload and adding the status from the trailers to the metadata
+	var grpcErr error
+	if grpcWeb && resp.StatusCode/100 == 2 {
+		payload, trailers, parseErr := parseGRPCWebResponse(b)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		b = payload
+		grpcErr = addGRPCWebStatus(resp, trailers, metadata)
+	}
+
+	if streamed != nil {
+		streamed.addMetadata(metadata)
+	} else if len(b) == 0 {
+		metadata["bodyEmpty"] = "true"
+
+		emptyBodyAsNull := h.metadata.EmptyBodyAsNull
+		if val := req.Metadata["emptyBodyAsNull"]; val != "" {
+			emptyBodyAsNull = utils.IsTruthy(val)
+		}
+		if emptyBodyAsNull && resp.StatusCode/100 == 2 && method != http.MethodHead {
+			b = []byte("null")
+		}
+	}
+
+	if idempotencyKey != "" {
+		metadata["idempotencyKey"] = idempotencyKey
+	}
+	if generatedTraceparent != "" {
+		metadata[TraceparentHeaderKey] = generatedTraceparent
+	}
+
+	// File name suggested by the server, if any
+	if filename := suggestedFilename(resp.Header.Get("Content-Disposition")); filename != "" {
+		metadata["suggestedFilename"] = filename
+	}
+
+	// URL of the last request, after following redirects
+	if resp.Request != nil {
+		metadata["finalURL"] = resp.Request.URL.String()
+		metadata["redirectCount"] = strconv.Itoa(countRedirects(resp))
+	}
+	unfollowedRedirect := h.isUnfollowedRedirect(resp)
+	if unfollowedRedirect {
+		metadata["redirectLocation"] = redirectLocation(resp)
+	}
+
+	if h.metadata.FallbackURL != "" {
+		metadata["servedByURL"] = request.URL.Redacted()
+	}
+
+	if h.metadata.ExposePeerCertChain && resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		var chain bytes.Buffer
+		for _, cert := range resp.TLS.PeerCertificates {
+			_ = pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+		}
+		metadata["tls.peerCertChainPEM"] = chain.String()
+	}
+
+	if archive != nil {
+		archiveErr := archive.close()
+		if archiveErr != nil && !errors.Is(archiveErr, errArchiveRequestCompleted) {
+			return nil, fmt.Errorf("failed to write archive: %w", archiveErr)
+		}
+		archive.addMetadata(metadata)
+	}
+	if upload != nil {
+		upload.stop()
+		upload.addMetadata(metadata)
+	}
+
+	if cbStats != nil {
+		cbStats.addMetadata(metadata)
+	}
+	if queued != nil {
+		queued.addMetadata(metadata)
+	}
+
+	retryRes.attempts = attempts
+	retryRes.addMetadata(metadata)
+
+	if h.responseCache != nil {
+		if fromCache {
+			metadata["fromCache"] = "true"
+			metadata["age"] = resp.Header.Get("Age")
+		}
+		h.responseCache.addMetadata(metadata)
+	}
+
+	if h.retryBudget != nil {
+		metadata["retryBudgetAvailable"] = strconv.Itoa(h.retryBudget.available())
+		if retryRes.budgetExhausted {
+			metadata["retryBudgetExhausted"] = "true"
+		}
+	}
+
+	if usedCreateAlias && h.metadata.WarnOnCreateAlias {
+		metadata["deprecatedOperation"] = string(bindings.CreateOperation)
+	}
+
+	if h.metadata.AdaptiveAuth && resp.StatusCode == http.StatusUnauthorized {
+		addAuthChallengeMetadata(resp, metadata)
+	}
+
+	// For HEAD requests, surface the most commonly-needed headers as dedicated entries
+	if method == http.MethodHead {
+		addHeadMetadata(resp, metadata)
+	}
+
+	// Create an error for unsuccessful status codes unless suppressed.
+	if policy.errorIfNotSuccess && !policy.isSuccess(resp.StatusCode) && !unfollowedRedirect {
+		errBody := policy.errorBody(b)
+		switch {
+		case etags != nil && resp.StatusCode == http.StatusPreconditionFailed:
+			err = fmt.Errorf("%w: received status code %d", ErrPreconditionFailed, resp.StatusCode)
+		case errBody != "":
+			err = fmt.Errorf("received status code %d: %s", resp.StatusCode, errBody)
+		default:
+			err = fmt.Errorf("received status code %d", resp.StatusCode)
+		}
+		if h.errorNotifier != nil {
+			h.errorNotifier.notify(request.URL, resp.StatusCode, err)
+		}
+	}
+
+	if err == nil && policy.errorIfNotSuccess && grpcErr != nil {
+		err = grpcErr
+	}
+
+	// Some servers return a 2xx status code with an error in the body
+	if err == nil && h.metadata.bodyErrorExpr != nil && resp.StatusCode/100 == 2 && method != http.MethodHead {
+		if msg, isErr := h.checkBodyError(b); isErr {
+			metadata["bodyError"] = msg
+			err = fmt.Errorf("%w: %s", ErrBodyIndicatesError, msg)
+			if h.errorNotifier != nil {
+				h.errorNotifier.notify(request.URL, resp.StatusCode, err)
+			}
+		}
+	}
+
+	// Verify the content type of the response, if required.
+	if err == nil && policy.expectContentType != "" && resp.StatusCode != http.StatusNoContent {
+		err = checkResponseContentType(resp.Header.Get("Content-Type"), policy.expectContentType)
 	}
 
 	return &bindings.InvokeResponse{
@@ -365,6 +1761,211 @@ func (h *HTTPSource) Invoke(parentCtx context.Context, req *bindings.InvokeReque
 	}, err
 }
 
+// send sends the request, applying the rate limiter, the circuit breaker, retries, and the fallback URL.
+// Returns the response and the request that was sent, which is different from the original one if the fallback URL was used.
+func (h *HTTPSource) send(ctx context.Context, parentCtx context.Context, request *http.Request, req *bindings.InvokeRequest, maxRetries int, canFallback bool) (*http.Response, *http.Request, retryResult, int, *circuitStats, error) {
+	if h.rateLimiter != nil {
+		err := h.rateLimiter.wait(ctx, req.Metadata["rateLimitKey"])
+		if err != nil {
+			return nil, request, retryResult{}, 0, nil, fmt.Errorf("rate limit wait failed: %w", err)
+		}
+	}
+
+	var cbTicket circuitTicket
+	if h.circuitBreaker != nil {
+		var err error
+		cbTicket, err = h.circuitBreaker.allow()
+		if err != nil {
+			return nil, request, retryResult{}, 0, nil, err
+		}
+	}
+
+	if h.idleReset != nil && h.idleReset.beforeRequest() {
+		h.logger.Debugf("No successful request in the last %v: closing idle connections", h.metadata.MaxIdleTimeBeforeReset)
+	}
+
+	resp, retryRes, err := h.doWithRetries(request, maxRetries)
+	attempts := retryRes.attempts
+	if err != nil && h.metadata.FallbackURL != "" && req.Metadata["endpoint"] == "" && canFallback && isConnectionError(err) {
+		// Connecting to the primary endpoint failed, so try the fallback once
+		h.logger.Warnf("Failed to connect to %s, trying fallback URL: %v", request.URL.Redacted(), err)
+		var fallback *http.Request
+		fallback, err = h.fallbackRequest(request, req.Metadata["path"])
+		if err == nil {
+			request = fallback
+			resp, retryRes, err = h.doWithRetries(request, 0)
+			attempts += retryRes.attempts
+		}
+	}
+
+	if err == nil && h.metadata.answersAuthChallenges() && resp.StatusCode == http.StatusUnauthorized {
+		var scheme string
+		resp, scheme, err = h.retryWithAuth(request, resp)
+		if scheme != "" {
+			retryRes.authRetryScheme = scheme
+			attempts++
+		}
+	}
+
+	// Requests that fail or return a 5xx status code count as failures for the circuit breaker
+	var cbStats *circuitStats
+	if h.circuitBreaker != nil {
+		stats := h.circuitBreaker.record(cbTicket, err == nil && resp.StatusCode < http.StatusInternalServerError)
+		cbStats = &stats
+	}
+	if err != nil {
+		err = h.wrapTotalTimeoutError(parentCtx, err, attempts)
+		if h.errorNotifier != nil {
+			h.errorNotifier.notify(request.URL, 0, err)
+		}
+		return nil, request, retryRes, attempts, cbStats, err
+	}
+	if h.idleReset != nil {
+		h.idleReset.recordSuccess()
+	}
+	return resp, request, retryRes, attempts, cbStats, nil
+}
+
+// setMetadataHeaders sets the request headers from the metadata keys that start with a capital letter.
+// Header names are canonicalized, and keys that canonicalize to the same header (e.g. "X-Id" and "X-ID") are handled according to duplicateHeaderPolicy:
+// with "append", all values are sent, in the order of the metadata keys; with "error", an error is returned.
+func (h *HTTPSource) setMetadataHeaders(header http.Header, md map[string]string) error {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		if len(k) > 0 && (k[0] >= 'A' && k[0] <= 'Z') {
+			keys = append(keys, k)
+		}
+	}
+	// Sort so the order of values is deterministic
+	sort.Strings(keys)
+
+	seen := make(map[string]string, len(keys))
+	for _, k := range keys {
+		name := http.CanonicalHeaderKey(k)
+		prev, dup := seen[name]
+		if !dup {
+			seen[name] = k
+			header.Set(name, md[k])
+			continue
+		}
+		if h.metadata.DuplicateHeaderPolicy == duplicateHeaderPolicyError {
+			return fmt.Errorf("metadata keys %q and %q set the same header %q", prev, k, name)
+		}
+		header.Add(name, md[k])
+	}
+	return nil
+}
+
+// setIdempotencyKey sets the idempotency key header on the request, and returns the key that is used.
+// A key set explicitly as a header takes precedence over the one passed in the metadata; if there's none, a key is generated for POST and PATCH requests when generateIdempotencyKey is enabled.
+func (h *HTTPSource) setIdempotencyKey(request *http.Request, key string) string {
+	if existing := request.Header.Get(h.metadata.IdempotencyKeyHeader); existing != "" {
+		return existing
+	}
+	if key == "" && h.metadata.GenerateIdempotencyKey &&
+		(request.Method == http.MethodPost || request.Method == http.MethodPatch) {
+		key = uuid.New().String()
+	}
+	if key != "" {
+		request.Header.Set(h.metadata.IdempotencyKeyHeader, key)
+	}
+	return key
+}
+
+// checkContentLength returns an error if fewer bytes than the Content-Length of the response were read.
+// If limited is true, bodies that were cut at maxResponseBodySize are not considered truncated.
+func (h *HTTPSource) checkContentLength(resp *http.Response, read int64, limited bool) error {
+	if resp.ContentLength < 0 || read >= resp.ContentLength {
+		return nil
+	}
+	if limited && h.metadata.maxResponseBodySizeBytes > 0 && read == h.metadata.maxResponseBodySizeBytes && h.metadata.ResponseBodyOverflowPolicy != bodyOverflowPolicyFail {
+		return nil
+	}
+	return fmt.Errorf("%w: expected %d got %d bytes", ErrTruncatedResponse, resp.ContentLength, read)
+}
+
+// wrapTotalTimeoutError adds the number of attempts performed to err, if it was caused by the total operation timeout.
+func (h *HTTPSource) wrapTotalTimeoutError(ctx context.Context, err error, attempts int) error {
+	if !errors.Is(context.Cause(ctx), errTotalOperationTimeout) {
+		return err
+	}
+	return fmt.Errorf("total operation timeout of %v exceeded after %d attempt(s): %w", h.metadata.TotalOperationTimeout, attempts, err)
+}
+
+// composeURL appends the path to the base URL, making sure there's exactly one "/" between them.
+func composeURL(base string, path string) string {
+	if path == "" {
+		return base
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// validateAcceptLanguage returns an error if the value is not a valid list of BCP 47 language tags.
+func validateAcceptLanguage(val string) error {
+	if val == "" {
+		return nil
+	}
+	_, _, err := language.ParseAcceptLanguage(val)
+	return err
+}
+
+// looksBinary returns true if the body is not valid UTF-8 or contains NUL bytes, which don't appear in text.
+func looksBinary(body []byte) bool {
+	return len(body) > 0 && (!utf8.Valid(body) || bytes.IndexByte(body, 0) >= 0)
+}
+
+// checkResponseContentType returns an error if the media type in the Content-Type header doesn't match the expected one.
+// The expected value can contain wildcards, such as "application/*".
+func checkResponseContentType(contentType string, expected string) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("unexpected response content type %q: expected %q", contentType, expected)
+	}
+
+	ok, _ := path.Match(strings.ToLower(expected), mediaType)
+	if !ok {
+		return fmt.Errorf("unexpected response content type %q: expected %q", mediaType, expected)
+	}
+	return nil
+}
+
+// addHeadMetadata adds the size and validators of the resource to the response metadata.
+func addHeadMetadata(resp *http.Response, metadata map[string]string) {
+	if resp.ContentLength >= 0 {
+		metadata["contentLength"] = strconv.FormatInt(resp.ContentLength, 10)
+	} else {
+		metadata["contentLength"] = "unknown"
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		metadata["etag"] = etag
+	}
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+		metadata["lastModified"] = lastModified
+	}
+}
+
+// Close stops the background workers of the binding.
+func (h *HTTPSource) Close() error {
+	// Stop the background cleanup of streamed files
+	if h.closed.CompareAndSwap(false, true) && h.closeCh != nil {
+		close(h.closeCh)
+	}
+	h.wg.Wait()
+
+	// Prevent the fire-and-forget queue from being created after the binding is closed
+	h.fireAndForgetOnce.Do(func() {})
+	if h.fireAndForget != nil {
+		h.fireAndForget.Close()
+	}
+	if h.errorNotifier != nil {
+		h.errorNotifier.Close()
+	}
+	if h.kerberosClient != nil {
+		h.kerberosClient.Destroy()
+	}
+	return nil
+}
+
 // GetComponentMetadata returns the metadata of the component.
 func (h *HTTPSource) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
 	metadataStruct := httpMetadata{}
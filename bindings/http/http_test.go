@@ -14,29 +14,56 @@ limitations under the License.
 package http
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/md5" //nolint:gosec
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
+	"unicode/utf16"
 
+	"github.com/andybalholm/brotli"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/md4" //nolint:staticcheck
+	"golang.org/x/net/dns/dnsmessage"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	"github.com/dapr/components-contrib/bindings"
 	"github.com/dapr/components-contrib/metadata"
@@ -55,6 +82,10 @@ func TestOperations(t *testing.T) {
 		"delete",
 		"options",
 		"trace",
+		"batch",
+		"discoverMethods",
+		"uploadArchive",
+		"multipart",
 	}, opers)
 }
 
@@ -1005,3 +1036,5662 @@ func TestMaxBodySizeHonored(t *testing.T) {
 	// Should have only read 1KB
 	assert.Len(t, response.Data, 1<<10)
 }
+
+func TestAllowedURLPatterns(t *testing.T) {
+	handler := NewHTTPHandler()
+	s := httptest.NewServer(handler)
+	defer s.Close()
+
+	t.Run("invalid pattern fails init", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"allowedURLPatterns": "regex:("})
+		require.Error(t, err)
+	})
+
+	tests := map[string]struct {
+		patterns string
+		path     string
+		err      string
+	}{
+		"glob allows composed URL": {
+			patterns: s.URL + "/allowed/*",
+			path:     "/allowed/nested/resource",
+		},
+		"glob rejects composed URL": {
+			patterns: s.URL + "/allowed/*",
+			path:     "/forbidden",
+			err:      "URL not allowed: " + s.URL + "/forbidden",
+		},
+		"regex allows composed URL": {
+			patterns: "regex:" + regexp.QuoteMeta(s.URL) + "/items/[0-9]+",
+			path:     "/items/42",
+		},
+		"regex is anchored": {
+			patterns: "regex:" + regexp.QuoteMeta(s.URL) + "/items/[0-9]+",
+			path:     "/items/42/../../admin",
+			err:      "URL not allowed",
+		},
+		"any of multiple patterns": {
+			patterns: s.URL + "/a," + s.URL + "/b",
+			path:     "/b",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hs, err := InitBinding(s, map[string]string{"allowedURLPatterns": tc.patterns})
+			require.NoError(t, err)
+
+			handler.Path = ""
+			req := TestCase{
+				input:      "GET",
+				operation:  "get",
+				path:       tc.path,
+				statusCode: 200,
+			}.ToInvokeRequest()
+			_, err = hs.Invoke(context.Background(), &req)
+			if tc.err == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tc.path, handler.Path)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				assert.Empty(t, handler.Path, "request should not have been sent")
+			}
+		})
+	}
+}
+
+func TestHeadMetadata(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/known", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1234")
+		w.Header().Set("ETag", `"abc123"`)
+		w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+	})
+	mux.HandleFunc("/unknown", func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	hs, err := InitBinding(s, nil)
+	require.NoError(t, err)
+
+	t.Run("known size and validators", func(t *testing.T) {
+		req := bindings.InvokeRequest{
+			Operation: "head",
+			Metadata:  map[string]string{"path": "/known"},
+		}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Empty(t, res.Data)
+		assert.Equal(t, "1234", res.Metadata["contentLength"])
+		assert.Equal(t, `"abc123"`, res.Metadata["etag"])
+		assert.Equal(t, "Wed, 21 Oct 2015 07:28:00 GMT", res.Metadata["lastModified"])
+		// Raw headers are still included
+		assert.Equal(t, `"abc123"`, res.Metadata["Etag"])
+	})
+
+	t.Run("unknown size", func(t *testing.T) {
+		req := bindings.InvokeRequest{
+			Operation: "head",
+			Metadata:  map[string]string{"path": "/unknown"},
+		}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Equal(t, "unknown", res.Metadata["contentLength"])
+		assert.NotContains(t, res.Metadata, "etag")
+		assert.NotContains(t, res.Metadata, "lastModified")
+	})
+
+	t.Run("not added for GET", func(t *testing.T) {
+		req := bindings.InvokeRequest{
+			Operation: "get",
+			Metadata:  map[string]string{"path": "/unknown"},
+		}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.NotContains(t, res.Metadata, "contentLength")
+	})
+}
+
+// flakyHandler fails the first `failures` requests with the given status code, then succeeds.
+type flakyHandler struct {
+	failures   int
+	statusCode int
+	calls      atomic.Int32
+}
+
+func (h *flakyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := int(h.calls.Add(1))
+	if n <= h.failures {
+		w.WriteHeader(h.statusCode)
+		return
+	}
+	b, _ := io.ReadAll(r.Body)
+	w.Write(b)
+}
+
+func TestRetries(t *testing.T) {
+	t.Run("retries transient failures", func(t *testing.T) {
+		handler := &flakyHandler{failures: 2, statusCode: http.StatusServiceUnavailable}
+		s := httptest.NewServer(handler)
+		defer s.Close()
+
+		hs, err := InitBinding(s, map[string]string{"maxRetries": "2"})
+		require.NoError(t, err)
+
+		req := bindings.InvokeRequest{Operation: "post", Data: []byte("hello")}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(res.Data))
+		assert.Equal(t, int32(3), handler.calls.Load())
+	})
+
+	t.Run("gives up after maxRetries", func(t *testing.T) {
+		handler := &flakyHandler{failures: 5, statusCode: http.StatusBadGateway}
+		s := httptest.NewServer(handler)
+		defer s.Close()
+
+		hs, err := InitBinding(s, map[string]string{"maxRetries": "2"})
+		require.NoError(t, err)
+
+		req := bindings.InvokeRequest{Operation: "get"}
+		_, err = hs.Invoke(context.Background(), &req)
+		require.EqualError(t, err, "received status code 502")
+		assert.Equal(t, int32(3), handler.calls.Load())
+	})
+
+	t.Run("does not retry other status codes", func(t *testing.T) {
+		handler := &flakyHandler{failures: 5, statusCode: http.StatusInternalServerError}
+		s := httptest.NewServer(handler)
+		defer s.Close()
+
+		hs, err := InitBinding(s, map[string]string{"maxRetries": "2"})
+		require.NoError(t, err)
+
+		req := bindings.InvokeRequest{Operation: "get"}
+		_, err = hs.Invoke(context.Background(), &req)
+		require.Error(t, err)
+		assert.Equal(t, int32(1), handler.calls.Load())
+	})
+
+	t.Run("does not retry blocked redirects", func(t *testing.T) {
+		var calls atomic.Int32
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			http.Redirect(w, r, "http://other.example.com/target", http.StatusFound)
+		}))
+		defer s.Close()
+
+		hs, err := InitBinding(s, map[string]string{"maxRetries": "3", "redirectPolicy": "sameHost", "retryBackoff": "1s"})
+		require.NoError(t, err)
+
+		req := bindings.InvokeRequest{Operation: "get"}
+		_, err = hs.Invoke(context.Background(), &req)
+		require.ErrorContains(t, err, `blocked by redirectPolicy "sameHost"`)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("per-request override", func(t *testing.T) {
+		handler := &flakyHandler{failures: 1, statusCode: http.StatusServiceUnavailable}
+		s := httptest.NewServer(handler)
+		defer s.Close()
+
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+
+		req := bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"maxRetries": "1"}}
+		_, err = hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), handler.calls.Load())
+
+		req = bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"maxRetries": "nope"}}
+		_, err = hs.Invoke(context.Background(), &req)
+		require.Error(t, err)
+	})
+
+	t.Run("retry budget suppresses retries", func(t *testing.T) {
+		handler := &flakyHandler{failures: 2, statusCode: http.StatusServiceUnavailable}
+		s := httptest.NewServer(handler)
+		defer s.Close()
+
+		hs, err := InitBinding(s, map[string]string{"maxRetries": "3", "retryBudgetRatio": "0.5", "retryBudgetMinRetries": "0"})
+		require.NoError(t, err)
+
+		// No successful requests yet, so the budget doesn't allow any retry
+		req := bindings.InvokeRequest{Operation: "get"}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.EqualError(t, err, "received status code 503")
+		assert.Equal(t, "true", res.Metadata["retryBudgetExhausted"])
+		assert.Equal(t, "0", res.Metadata["retryBudgetAvailable"])
+		assert.Equal(t, int32(1), handler.calls.Load())
+	})
+
+	t.Run("retry budget allows retries on the first request", func(t *testing.T) {
+		handler := &flakyHandler{failures: 2, statusCode: http.StatusServiceUnavailable}
+		s := httptest.NewServer(handler)
+		defer s.Close()
+
+		hs, err := InitBinding(s, map[string]string{"maxRetries": "3", "retryBudgetRatio": "0.5"})
+		require.NoError(t, err)
+
+		req := bindings.InvokeRequest{Operation: "get"}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Empty(t, res.Metadata["retryBudgetExhausted"])
+		assert.Equal(t, int32(3), handler.calls.Load())
+
+		_, err = InitBinding(s, map[string]string{"retryBudgetRatio": "0.5", "retryBudgetMinRetries": "-1"})
+		require.ErrorContains(t, err, "invalid value for retryBudgetMinRetries")
+	})
+}
+
+func TestRetryBudget(t *testing.T) {
+	now := time.Now()
+	b := newRetryBudget(0.5, 10*time.Second, 0)
+	b.now = func() time.Time { return now }
+
+	assert.False(t, b.tryRetry())
+
+	for i := 0; i < 4; i++ {
+		b.recordSuccess()
+	}
+	assert.Equal(t, 2, b.available())
+	assert.True(t, b.tryRetry())
+	assert.True(t, b.tryRetry())
+	assert.False(t, b.tryRetry())
+
+	// After the window has passed, the budget is reset
+	now = now.Add(11 * time.Second)
+	assert.Equal(t, 0, b.available())
+	b.recordSuccess()
+	b.recordSuccess()
+	assert.Equal(t, 1, b.available())
+
+	t.Run("minimum retries", func(t *testing.T) {
+		b := newRetryBudget(0.5, 10*time.Second, 2)
+		b.now = func() time.Time { return now }
+
+		// Retries are allowed before any successful request
+		assert.Equal(t, 2, b.available())
+		assert.True(t, b.tryRetry())
+		assert.True(t, b.tryRetry())
+		assert.False(t, b.tryRetry())
+
+		// The ratio applies when it allows more retries than the minimum
+		for i := 0; i < 8; i++ {
+			b.recordSuccess()
+		}
+		assert.Equal(t, 2, b.available())
+	})
+
+	t.Run("non-idempotent requests don't use the budget", func(t *testing.T) {
+		var calls atomic.Int32
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			conn.(*net.TCPConn).SetLinger(0)
+			conn.Close()
+		}))
+		defer s.Close()
+		hs, err := InitBinding(s, map[string]string{"maxRetries": "3", "retryBudgetRatio": "0.1", "retryBudgetMinRetries": "1"})
+		require.NoError(t, err)
+
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "post", Data: []byte("data")})
+		require.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Equal(t, 1, hs.(*HTTPSource).retryBudget.available())
+
+		calls.Store(0)
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.ErrorContains(t, err, "retry budget exhausted")
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
+
+func TestExpectResponseContentType(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html></html>`))
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	t.Run("invalid pattern fails init", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"expectResponseContentType": "application/["})
+		require.Error(t, err)
+	})
+
+	tests := map[string]struct {
+		expected string
+		path     string
+		err      string
+	}{
+		"exact match ignoring parameters": {
+			expected: "application/json",
+			path:     "/json",
+		},
+		"wildcard match": {
+			expected: "application/*",
+			path:     "/json",
+		},
+		"mismatch": {
+			expected: "application/json",
+			path:     "/html",
+			err:      `unexpected response content type "text/html": expected "application/json"`,
+		},
+		"wildcard mismatch": {
+			expected: "application/*",
+			path:     "/html",
+			err:      `unexpected response content type "text/html": expected "application/*"`,
+		},
+		"disabled by default": {
+			path: "/html",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hs, err := InitBinding(s, map[string]string{"expectResponseContentType": tc.expected})
+			require.NoError(t, err)
+
+			req := bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": tc.path}}
+			res, err := hs.Invoke(context.Background(), &req)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.err)
+				assert.Equal(t, "200", res.Metadata["statusCode"])
+			}
+		})
+	}
+}
+
+func TestNamedEndpoints(t *testing.T) {
+	defaultHandler := NewHTTPHandler()
+	defaultServer := httptest.NewServer(defaultHandler)
+	defer defaultServer.Close()
+	otherHandler := NewHTTPHandler()
+	otherServer := httptest.NewServer(otherHandler)
+	defer otherServer.Close()
+
+	t.Run("invalid endpoints fail init", func(t *testing.T) {
+		_, err := InitBinding(defaultServer, map[string]string{"endpoints": "not json"})
+		require.Error(t, err)
+		_, err = InitBinding(defaultServer, map[string]string{"endpoints": `{"a": "/relative"}`})
+		require.Error(t, err)
+	})
+
+	endpoints := fmt.Sprintf(`{"plain": %q, "withHeaders": {"url": %q, "headers": {"X-Tenant": "contoso", "X-Overridden": "endpoint"}}}`,
+		otherServer.URL+"/plain", otherServer.URL+"/base")
+	hs, err := InitBinding(defaultServer, map[string]string{"endpoints": endpoints})
+	require.NoError(t, err)
+
+	t.Run("default url when not specified", func(t *testing.T) {
+		req := TestCase{operation: "get", path: "/default", statusCode: 200}.ToInvokeRequest()
+		_, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Equal(t, "/default", defaultHandler.Path)
+	})
+
+	t.Run("named endpoint", func(t *testing.T) {
+		req := TestCase{operation: "get", statusCode: 200, metadata: map[string]string{"endpoint": "plain"}}.ToInvokeRequest()
+		_, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Equal(t, "/plain", otherHandler.Path)
+	})
+
+	t.Run("named endpoint with path and headers", func(t *testing.T) {
+		req := TestCase{
+			operation:  "get",
+			path:       "/items",
+			statusCode: 200,
+			metadata:   map[string]string{"endpoint": "withHeaders", "X-Overridden": "request"},
+		}.ToInvokeRequest()
+		_, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Equal(t, "/base/items", otherHandler.Path)
+		assert.Equal(t, "contoso", otherHandler.Headers["X-Tenant"])
+		assert.Equal(t, "request", otherHandler.Headers["X-Overridden"])
+	})
+
+	t.Run("unknown endpoint", func(t *testing.T) {
+		req := TestCase{operation: "get", statusCode: 200, metadata: map[string]string{"endpoint": "nope"}}.ToInvokeRequest()
+		_, err := hs.Invoke(context.Background(), &req)
+		require.EqualError(t, err, "unknown endpoint: nope")
+	})
+}
+
+func TestRedirectLoop(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b", http.StatusFound)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/a", http.StatusFound)
+	})
+	mux.HandleFunc("/chain/", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/chain/"))
+		if n > 0 {
+			http.Redirect(w, r, "/chain/"+strconv.Itoa(n-1), http.StatusFound)
+			return
+		}
+		w.Write([]byte("done"))
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	hs, err := InitBinding(s, nil)
+	require.NoError(t, err)
+
+	t.Run("loop is detected", func(t *testing.T) {
+		req := bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": "/a"}}
+		_, err := hs.Invoke(context.Background(), &req)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), fmt.Sprintf("redirect loop detected: %[1]s/a -> %[1]s/b -> %[1]s/a", s.URL))
+	})
+
+	t.Run("redirects are followed", func(t *testing.T) {
+		req := bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": "/chain/3"}}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Equal(t, "done", string(res.Data))
+		assert.Equal(t, s.URL+"/chain/0", res.Metadata["finalURL"])
+		assert.Equal(t, "3", res.Metadata["redirectCount"])
+	})
+
+	t.Run("final URL without redirects", func(t *testing.T) {
+		req := bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": "/chain/0"}}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Equal(t, s.URL+"/chain/0", res.Metadata["finalURL"])
+		assert.Equal(t, "0", res.Metadata["redirectCount"])
+	})
+
+	t.Run("long chains are stopped", func(t *testing.T) {
+		req := bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": "/chain/20"}}
+		_, err := hs.Invoke(context.Background(), &req)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "stopped after 10 redirects")
+	})
+}
+
+func TestDebugLogBodies(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/text", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=s3cr3t")
+		w.Write([]byte(`{"token":"abcdef","message":"héllo wörld"}`))
+	})
+	mux.HandleFunc("/binary", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte{0x89, 0x50, 0x4e, 0x47})
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	newBinding := func(t *testing.T, props map[string]string) (*HTTPSource, *strings.Builder) {
+		buf := &strings.Builder{}
+		log := logger.NewLogger("test")
+		log.SetOutput(buf)
+		log.SetOutputLevel(logger.DebugLevel)
+
+		m := bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"url": s.URL}}}
+		for k, v := range props {
+			m.Properties[k] = v
+		}
+		hs := NewHTTP(log).(*HTTPSource)
+		require.NoError(t, hs.Init(context.Background(), m))
+		return hs, buf
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		hs, buf := newBinding(t, nil)
+		req := bindings.InvokeRequest{Operation: "post", Data: []byte(`{"a":1}`), Metadata: map[string]string{"path": "/text"}}
+		_, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.NotContains(t, buf.String(), "HTTP request")
+	})
+
+	t.Run("bodies are logged with redaction", func(t *testing.T) {
+		hs, buf := newBinding(t, map[string]string{
+			"debugLogBodies":      "true",
+			"securityToken":       "abcdef",
+			"securityTokenHeader": "X-Token",
+		})
+		req := bindings.InvokeRequest{
+			Operation: "post",
+			Data:      []byte(`{"auth":"Bearer xyz987"}`),
+			Metadata:  map[string]string{"path": "/text", "Authorization": "Bearer xyz987"},
+		}
+		_, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+
+		out := buf.String()
+		assert.Contains(t, out, "HTTP request: POST "+s.URL+"/text")
+		assert.Contains(t, out, "HTTP response: POST "+s.URL+"/text status=200")
+		assert.Contains(t, out, "héllo wörld")
+		assert.NotContains(t, out, "xyz987")
+		assert.NotContains(t, out, "abcdef")
+		assert.NotContains(t, out, "s3cr3t")
+		assert.Contains(t, out, redactedValue)
+	})
+
+	t.Run("binary bodies are summarized", func(t *testing.T) {
+		hs, buf := newBinding(t, map[string]string{"debugLogBodies": "true"})
+		req := bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": "/binary"}}
+		_, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "<4 bytes, image/png>")
+	})
+
+	t.Run("bodies are truncated", func(t *testing.T) {
+		hs, buf := newBinding(t, map[string]string{"debugLogBodies": "true", "debugLogBodyMaxBytes": "10"})
+		req := bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": "/text"}}
+		_, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "(truncated, 44 bytes total)")
+		assert.NotContains(t, buf.String(), "message")
+	})
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	b := []byte("aé€")
+	assert.Equal(t, "aé€", string(truncateUTF8(b, 10)))
+	assert.Equal(t, "aé", string(truncateUTF8(b, 5)))
+	assert.Equal(t, "aé", string(truncateUTF8(b, 4)))
+	assert.Equal(t, "a", string(truncateUTF8(b, 2)))
+	assert.Equal(t, "", string(truncateUTF8(b, 0)))
+}
+
+func TestFallbackURL(t *testing.T) {
+	handler := NewHTTPHandler()
+	fallback := httptest.NewServer(handler)
+	defer fallback.Close()
+
+	// Get an address that refuses connections
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	deadURL := "http://" + l.Addr().String()
+	l.Close()
+
+	t.Run("invalid fallback URL fails init", func(t *testing.T) {
+		_, err := InitBinding(fallback, map[string]string{"fallbackURL": "/relative"})
+		require.Error(t, err)
+	})
+
+	t.Run("fallback on connection failure", func(t *testing.T) {
+		hs := NewHTTP(logger.NewLogger("test"))
+		err := hs.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"url":         deadURL,
+			"fallbackURL": fallback.URL + "/backup",
+		}}})
+		require.NoError(t, err)
+
+		req := TestCase{input: "hello", operation: "post", path: "/items", statusCode: 200}.ToInvokeRequest()
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Equal(t, "HELLO", string(res.Data))
+		assert.Equal(t, "/backup/items", handler.Path)
+		assert.Equal(t, fallback.URL+"/backup/items", res.Metadata["servedByURL"])
+	})
+
+	t.Run("no fallback on error status", func(t *testing.T) {
+		primaryHandler := NewHTTPHandler()
+		primary := httptest.NewServer(primaryHandler)
+		defer primary.Close()
+
+		hs, err := InitBinding(primary, map[string]string{"fallbackURL": fallback.URL + "/backup"})
+		require.NoError(t, err)
+
+		handler.Path = ""
+		req := TestCase{operation: "get", path: "/items", statusCode: 503}.ToInvokeRequest()
+		res, err := hs.Invoke(context.Background(), &req)
+		require.Error(t, err)
+		assert.Equal(t, "/items", primaryHandler.Path)
+		assert.Empty(t, handler.Path)
+		assert.Equal(t, primary.URL+"/items", res.Metadata["servedByURL"])
+	})
+}
+
+func TestJSONBodyMetadata(t *testing.T) {
+	var contentType string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		io.Copy(w, r.Body)
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, nil)
+	require.NoError(t, err)
+
+	t.Run("body is assembled from metadata", func(t *testing.T) {
+		req := bindings.InvokeRequest{
+			Operation: "post",
+			Metadata: map[string]string{
+				"jsonBody.name":              "widget",
+				"jsonBody.count:int":         "3",
+				"jsonBody.price:float":       "9.5",
+				"jsonBody.active:bool":       "true",
+				"jsonBody.owner.id":          "abc",
+				"jsonBody.tags[0]":           "a",
+				"jsonBody.tags[1]":           "b",
+				"jsonBody.items[0].sku":      "x1",
+				"jsonBody.items[0].qty:int":  "2",
+				"jsonBody.extra:json":        `{"nested":[1,2]}`,
+				"jsonBody.deleted:null":      "",
+				"X-Status-Code":              "200",
+				"some-non-header-metadata":   "ignored",
+				"jsonBodyWithoutDot:ignored": "ignored",
+			},
+		}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"name": "widget",
+			"count": 3,
+			"price": 9.5,
+			"active": true,
+			"owner": {"id": "abc"},
+			"tags": ["a", "b"],
+			"items": [{"sku": "x1", "qty": 2}],
+			"extra": {"nested": [1, 2]},
+			"deleted": null
+		}`, string(res.Data))
+		assert.Equal(t, "application/json; charset=utf-8", contentType)
+	})
+
+	errTests := map[string]map[string]string{
+		"invalid int":           {"jsonBody.count:int": "three"},
+		"invalid bool":          {"jsonBody.ok:bool": "maybe"},
+		"invalid json":          {"jsonBody.obj:json": "{"},
+		"unknown type":          {"jsonBody.obj:date": "2024-01-01"},
+		"empty field":           {"jsonBody.a..b": "x"},
+		"invalid index":         {"jsonBody.a[x]": "x"},
+		"unterminated index":    {"jsonBody.a[0": "x"},
+		"object/array conflict": {"jsonBody.a[0]": "x", "jsonBody.a.b": "y"},
+		"scalar/object conflict": {
+			"jsonBody.a":   "x",
+			"jsonBody.a.b": "y",
+		},
+	}
+	for name, md := range errTests {
+		t.Run(name, func(t *testing.T) {
+			req := bindings.InvokeRequest{Operation: "post", Metadata: md}
+			_, err := hs.Invoke(context.Background(), &req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid metadata key")
+		})
+	}
+
+	t.Run("conflicts with request data", func(t *testing.T) {
+		req := bindings.InvokeRequest{Operation: "post", Data: []byte("{}"), Metadata: map[string]string{"jsonBody.a": "x"}}
+		_, err := hs.Invoke(context.Background(), &req)
+		require.EqualError(t, err, "cannot use jsonBody metadata when the request contains data")
+	})
+}
+
+func TestHTTP2Settings(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})
+	server := httptest.NewUnstartedServer(handler)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	t.Run("negative durations fail init", func(t *testing.T) {
+		_, err := InitBinding(server, map[string]string{"http2ReadIdleTimeout": "-1s"})
+		require.Error(t, err)
+		_, err = InitBinding(server, map[string]string{"http2PingTimeout": "-1s"})
+		require.Error(t, err)
+	})
+
+	t.Run("transport is configured", func(t *testing.T) {
+		hs, err := InitBinding(server, map[string]string{
+			"http2ReadIdleTimeout": "10s",
+			"http2PingTimeout":     "2s",
+		})
+		require.NoError(t, err)
+
+		transport := hs.(*HTTPSource).client.Transport.(*http.Transport)
+		require.Contains(t, transport.TLSNextProto, "h2")
+
+		// Trust the test server's certificate to make a request
+		transport.TLSClientConfig.RootCAs = x509.NewCertPool()
+		transport.TLSClientConfig.RootCAs.AddCert(server.Certificate())
+
+		req := bindings.InvokeRequest{Operation: "get"}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Equal(t, "HTTP/2.0", string(res.Data))
+	})
+}
+
+func TestOnErrorWebhook(t *testing.T) {
+	events := make(chan errorEvent, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev errorEvent
+		_ = json.NewDecoder(r.Body).Decode(&ev)
+		events <- ev
+	}))
+	defer webhook.Close()
+
+	handler := NewHTTPHandler()
+	s := httptest.NewServer(handler)
+	defer s.Close()
+
+	t.Run("invalid URL fails init", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"onErrorWebhookURL": "not-a-url"})
+		require.Error(t, err)
+	})
+
+	hs, err := InitBinding(s, map[string]string{"onErrorWebhookURL": webhook.URL})
+	require.NoError(t, err)
+	defer hs.(*HTTPSource).Close()
+
+	t.Run("failures are notified and deduplicated", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			req := TestCase{operation: "get", path: "/failing", statusCode: 500}.ToInvokeRequest()
+			_, err := hs.Invoke(context.Background(), &req)
+			require.Error(t, err)
+		}
+
+		select {
+		case ev := <-events:
+			assert.Equal(t, s.URL+"/failing", ev.Endpoint)
+			assert.Equal(t, 500, ev.StatusCode)
+			assert.Equal(t, errorCategoryStatus, ev.Category)
+			assert.WithinDuration(t, time.Now(), ev.Timestamp, time.Minute)
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook was not invoked")
+		}
+
+		select {
+		case <-events:
+			t.Fatal("duplicate notification was sent")
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+
+	t.Run("successful requests are not notified", func(t *testing.T) {
+		req := TestCase{operation: "get", path: "/ok", statusCode: 200}.ToInvokeRequest()
+		_, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+
+		select {
+		case <-events:
+			t.Fatal("notification was sent for a successful request")
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+
+	t.Run("close stops the notifier", func(t *testing.T) {
+		require.NoError(t, hs.(*HTTPSource).Close())
+		// Notifying after close must not panic or block
+		req := TestCase{operation: "get", path: "/failing-after-close", statusCode: 500}.ToInvokeRequest()
+		_, err := hs.Invoke(context.Background(), &req)
+		require.Error(t, err)
+	})
+}
+
+func TestRawResponseHeaders(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Set-Cookie", "a=1; Path=/")
+		w.Header().Add("Set-Cookie", "b=2, c=3")
+		w.Header().Set("Content-Type", "text/plain")
+	}))
+	defer s.Close()
+
+	checkHeaders := func(t *testing.T, res *bindings.InvokeResponse) {
+		require.Contains(t, res.Metadata, "rawResponseHeadersJSON")
+		headers := map[string][]string{}
+		require.NoError(t, json.Unmarshal([]byte(res.Metadata["rawResponseHeadersJSON"]), &headers))
+		assert.Equal(t, []string{"a=1; Path=/", "b=2, c=3"}, headers["Set-Cookie"])
+		assert.Equal(t, []string{"text/plain"}, headers["Content-Type"])
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		req := bindings.InvokeRequest{Operation: "get"}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.NotContains(t, res.Metadata, "rawResponseHeadersJSON")
+
+		req = bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"includeRawResponseHeaders": "true"}}
+		res, err = hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		checkHeaders(t, res)
+	})
+
+	t.Run("enabled in component", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"includeRawResponseHeaders": "true"})
+		require.NoError(t, err)
+		req := bindings.InvokeRequest{Operation: "get"}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		checkHeaders(t, res)
+
+		req = bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"includeRawResponseHeaders": "false"}}
+		res, err = hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.NotContains(t, res.Metadata, "rawResponseHeadersJSON")
+	})
+}
+
+func TestOptimisticConcurrency(t *testing.T) {
+	var (
+		lock        sync.Mutex
+		version     = 1
+		lastIfMatch string
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		etag := fmt.Sprintf(`"v%d"`, version)
+		lastIfMatch = r.Header.Get("If-Match")
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("ETag", etag)
+		case http.MethodPut:
+			if lastIfMatch != "" && lastIfMatch != etag {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			version++
+			w.Header().Set("ETag", fmt.Sprintf(`"v%d"`, version))
+		}
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{"optimisticConcurrency": "true"})
+	require.NoError(t, err)
+
+	invoke := func(op string, md map[string]string) error {
+		req := bindings.InvokeRequest{Operation: bindings.OperationKind(op), Metadata: md}
+		_, err := hs.Invoke(context.Background(), &req)
+		return err
+	}
+
+	// PUT without a prior GET sends no If-Match
+	require.NoError(t, invoke("put", map[string]string{"path": "/res"}))
+	assert.Empty(t, lastIfMatch)
+
+	// GET then PUT sends the ETag
+	require.NoError(t, invoke("get", map[string]string{"path": "/res"}))
+	require.NoError(t, invoke("put", map[string]string{"path": "/res"}))
+	assert.Equal(t, `"v2"`, lastIfMatch)
+
+	// The ETag returned by the PUT is used next
+	require.NoError(t, invoke("put", map[string]string{"path": "/res"}))
+	assert.Equal(t, `"v3"`, lastIfMatch)
+
+	// ETags are tracked per URL
+	require.NoError(t, invoke("put", map[string]string{"path": "/other"}))
+	assert.Empty(t, lastIfMatch)
+
+	// Explicit header wins
+	err = invoke("put", map[string]string{"path": "/res", "If-Match": `"v1"`})
+	require.ErrorIs(t, err, ErrPreconditionFailed)
+	assert.Equal(t, "precondition failed: received status code 412", err.Error())
+	assert.Equal(t, `"v1"`, lastIfMatch)
+
+	// Per-request opt-out
+	require.NoError(t, invoke("get", map[string]string{"path": "/res"}))
+	require.NoError(t, invoke("put", map[string]string{"path": "/res", "optimisticConcurrency": "false"}))
+	assert.Empty(t, lastIfMatch)
+}
+
+func TestETagStoreBounded(t *testing.T) {
+	store, err := newETagStore(2)
+	require.NoError(t, err)
+
+	for _, p := range []string{"/a", "/b", "/c"} {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost"+p, nil)
+		store.track(req, &http.Response{StatusCode: 200, Header: http.Header{"Etag": []string{p}}})
+	}
+	assert.Equal(t, 2, store.cache.Len())
+
+	req := httptest.NewRequest(http.MethodPut, "http://localhost/a", nil)
+	store.applyIfMatch(req)
+	assert.Empty(t, req.Header.Get("If-Match"))
+
+	req = httptest.NewRequest(http.MethodPut, "http://localhost/c", nil)
+	store.applyIfMatch(req)
+	assert.Equal(t, "/c", req.Header.Get("If-Match"))
+}
+
+func TestTLSHandshakeHonorsContext(t *testing.T) {
+	// Server that accepts TCP connections but never completes the TLS handshake
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	hs := NewHTTP(logger.NewLogger("test"))
+	err = hs.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"url": "https://" + l.Addr().String(),
+	}}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	req := bindings.InvokeRequest{Operation: "get"}
+	_, err = hs.Invoke(ctx, &req)
+	require.Error(t, err)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestBatch(t *testing.T) {
+	var (
+		inFlight    atomic.Int32
+		maxInFlight atomic.Int32
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Custom", r.Header.Get("X-Custom"))
+		io.Copy(w, r.Body)
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{"batchConcurrency": "2"})
+	require.NoError(t, err)
+
+	t.Run("batch with partial failures", func(t *testing.T) {
+		req := bindings.InvokeRequest{
+			Operation: "batch",
+			Data: []byte(`[
+				{"method": "GET", "path": "/a", "headers": {"x-custom": "one"}},
+				{"method": "post", "path": "/b", "body": "plain text"},
+				{"method": "PUT", "path": "/c", "body": {"json": true}},
+				{"method": "GET", "path": "/fail"},
+				{"method": "GET", "path": "/d"}
+			]`),
+		}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		assert.Equal(t, "5", res.Metadata["batchSize"])
+		assert.Equal(t, "1", res.Metadata["batchFailed"])
+
+		var responses []batchSubResponse
+		require.NoError(t, json.Unmarshal(res.Data, &responses))
+		require.Len(t, responses, 5)
+
+		assert.Equal(t, 200, responses[0].StatusCode)
+		assert.Equal(t, "GET", responses[0].Headers["X-Method"])
+		assert.Equal(t, "one", responses[0].Headers["X-Custom"])
+		assert.Equal(t, "plain text", responses[1].Body)
+		assert.Equal(t, "POST", responses[1].Headers["X-Method"])
+		assert.JSONEq(t, `{"json": true}`, responses[2].Body)
+		assert.Equal(t, 404, responses[3].StatusCode)
+		assert.Equal(t, "received status code 404", responses[3].Error)
+		assert.Equal(t, 200, responses[4].StatusCode)
+		assert.Empty(t, responses[4].Error)
+
+		assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+	})
+
+	t.Run("invalid batches", func(t *testing.T) {
+		for _, data := range []string{`{}`, `[{"path": "/a"}]`, `[{"method": "batch"}]`} {
+			req := bindings.InvokeRequest{Operation: "batch", Data: []byte(data)}
+			_, err := hs.Invoke(context.Background(), &req)
+			require.Error(t, err, data)
+		}
+	})
+
+	t.Run("invalid operation is reported per item", func(t *testing.T) {
+		req := bindings.InvokeRequest{Operation: "batch", Data: []byte(`[{"method": "nope"}]`)}
+		res, err := hs.Invoke(context.Background(), &req)
+		require.NoError(t, err)
+		var responses []batchSubResponse
+		require.NoError(t, json.Unmarshal(res.Data, &responses))
+		assert.Equal(t, "invalid operation: nope", responses[0].Error)
+	})
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Accept-Language")))
+	}))
+	defer s.Close()
+
+	t.Run("invalid component value", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"acceptLanguage": "not a tag!"})
+		require.ErrorContains(t, err, "invalid value for acceptLanguage")
+	})
+
+	hs, err := InitBinding(s, map[string]string{"acceptLanguage": "fr-CH, fr;q=0.9"})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		metadata map[string]string
+		expected string
+		err      string
+	}{
+		"component default":     {expected: "fr-CH, fr;q=0.9"},
+		"per-request override":  {metadata: map[string]string{"acceptLanguage": "de-DE"}, expected: "de-DE"},
+		"explicit header wins":  {metadata: map[string]string{"acceptLanguage": "de-DE", "Accept-Language": "it"}, expected: "it"},
+		"invalid request value": {metadata: map[string]string{"acceptLanguage": "en_US!"}, err: "invalid value for acceptLanguage"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: tc.metadata})
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(res.Data))
+		})
+	}
+
+	t.Run("not set by default", func(t *testing.T) {
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.Empty(t, res.Data)
+	})
+}
+
+func TestTLSVerifyMode(t *testing.T) {
+	handler := http.NewServeMux()
+	handler.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	// The certificate of this server is valid for 127.0.0.1 only
+	server := setupHTTPSServer(t, false, handler)
+	defer server.Close()
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	localhostURL := "https://localhost:" + port
+
+	// This server uses a certificate that is not signed by the CA
+	untrusted := httptest.NewTLSServer(handler)
+	defer untrusted.Close()
+
+	rootCA := filepath.Join(".", "testdata", "ca.pem")
+	tests := map[string]struct {
+		url     string
+		mode    string
+		initErr string
+		err     string
+	}{
+		"full with valid hostname":           {url: server.URL, mode: "full"},
+		"full with mismatched hostname":      {url: localhostURL, mode: "", err: "wanted to match localhost"},
+		"skipHostnameOnly with mismatch":     {url: localhostURL, mode: "skipHostnameOnly"},
+		"skipHostnameOnly with untrusted CA": {url: untrusted.URL, mode: "skipHostnameOnly", err: "certificate signed by unknown authority"},
+		"none with untrusted CA":             {url: untrusted.URL, mode: "none"},
+		"invalid mode":                       {url: server.URL, mode: "partial", initErr: "invalid value for tlsVerifyMode: partial"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hs := NewHTTP(logger.NewLogger("test"))
+			err := hs.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+				"url":           tc.url,
+				"MTLSRootCA":    rootCA,
+				"tlsVerifyMode": tc.mode,
+			}}})
+			if tc.initErr != "" {
+				require.ErrorContains(t, err, tc.initErr)
+				return
+			}
+			require.NoError(t, err)
+
+			res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "ok", string(res.Data))
+		})
+	}
+}
+
+func TestSuggestedFilename(t *testing.T) {
+	tests := map[string]struct {
+		header   string
+		expected string
+	}{
+		"no header":              {header: "", expected: ""},
+		"inline without name":    {header: "inline", expected: ""},
+		"quoted filename":        {header: `attachment; filename="report 2024.pdf"`, expected: "report 2024.pdf"},
+		"unquoted filename":      {header: `attachment; filename=report.pdf`, expected: "report.pdf"},
+		"utf-8 extended":         {header: `attachment; filename="rates.txt"; filename*=UTF-8''%e2%82%ac%20rates.txt`, expected: "€ rates.txt"},
+		"iso-8859-1 extended":    {header: `attachment; filename*=iso-8859-1'en'%A3%20rates.txt`, expected: "£ rates.txt"},
+		"path traversal":         {header: `attachment; filename="../../etc/passwd"`, expected: "passwd"},
+		"unquoted path":          {header: `attachment; filename=../../etc/passwd`, expected: "passwd"},
+		"windows path traversal": {header: `attachment; filename="..\\..\\secret.txt"`, expected: "secret.txt"},
+		"dot dot only":           {header: `attachment; filename=".."`, expected: ""},
+		"control characters":     {header: "attachment; filename*=UTF-8''a%0Ab.txt", expected: "ab.txt"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, suggestedFilename(tc.header))
+		})
+	}
+
+	t.Run("response metadata", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Disposition", `attachment; filename="data.csv"`)
+		}))
+		defer s.Close()
+
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.Equal(t, "data.csv", res.Metadata["suggestedFilename"])
+	})
+}
+
+func TestTotalOperationTimeout(t *testing.T) {
+	var calls atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-time.After(50 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{
+		"maxRetries":            "100",
+		"totalOperationTimeout": "300ms",
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+	require.Error(t, err)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Regexp(t, `^total operation timeout of 300ms exceeded after \d+ attempt\(s\): `, err.Error())
+	assert.Contains(t, err.Error(), fmt.Sprintf("after %d attempt(s)", calls.Load()))
+	assert.Greater(t, calls.Load(), int32(1))
+}
+
+func TestRedirectPolicy(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("other:" + r.Header.Get("Authorization")))
+	}))
+	defer other.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/local", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/target", http.StatusFound)
+	})
+	mux.HandleFunc("/away", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/target", http.StatusFound)
+	})
+	mux.HandleFunc("/target", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("local:" + r.Header.Get("Authorization")))
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"redirectPolicy": "sameOrigin"})
+		require.ErrorContains(t, err, "invalid value for redirectPolicy: sameOrigin")
+	})
+
+	tests := map[string]struct {
+		policy     string
+		path       string
+		expected   string
+		statusCode string
+		err        string
+	}{
+		"any follows same host":              {policy: "", path: "/local", expected: "local:Bearer secret"},
+		"any strips Authorization":           {policy: "any", path: "/away", expected: "other:"},
+		"sameHost follows same host":         {policy: "sameHost", path: "/local", expected: "local:Bearer secret"},
+		"sameHost blocks other host":         {policy: "sameHost", path: "/away", err: `blocked by redirectPolicy "sameHost": host does not match`},
+		"sameScheme blocks other host":       {policy: "sameScheme", path: "/away", err: `blocked by redirectPolicy "sameScheme": host does not match`},
+		"none returns the redirect response": {policy: "none", path: "/local", statusCode: "302"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hs, err := InitBinding(s, map[string]string{
+				"redirectPolicy": tc.policy,
+				"errorIfNot2XX":  "false",
+			})
+			require.NoError(t, err)
+
+			res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{
+				"path":          tc.path,
+				"Authorization": "Bearer secret",
+			}})
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			if tc.statusCode != "" {
+				assert.Equal(t, tc.statusCode, res.Metadata["statusCode"])
+				assert.Equal(t, "/target", res.Metadata["Location"])
+				return
+			}
+			assert.Equal(t, tc.expected, string(res.Data))
+		})
+	}
+
+	t.Run("sameScheme blocks downgrade", func(t *testing.T) {
+		hs := &HTTPSource{metadata: httpMetadata{RedirectPolicy: "sameScheme"}}
+		from, _ := http.NewRequest(http.MethodGet, "https://example.com/a", nil)
+		to, _ := http.NewRequest(http.MethodGet, "http://example.com/b", nil)
+		err := hs.checkRedirect(to, []*http.Request{from})
+		require.ErrorContains(t, err, "downgrade from https is not allowed")
+
+		hs.metadata.RedirectPolicy = "sameHost"
+		require.NoError(t, hs.checkRedirect(to, []*http.Request{from}))
+	})
+}
+
+func TestVerifyContentLength(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Advertise 100 bytes, but drop the connection after 10
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 100\r\nContent-Type: text/plain\r\n\r\n0123456789")
+		buf.Flush()
+	}))
+	defer s.Close()
+
+	t.Run("enabled by default", func(t *testing.T) {
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.ErrorIs(t, err, ErrTruncatedResponse)
+		assert.EqualError(t, err, "truncated response: expected 100 got 10 bytes")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"verifyContentLength": "false"})
+		require.NoError(t, err)
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrTruncatedResponse)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("limited by maxResponseBodySize", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"maxResponseBodySize": "5"})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.Equal(t, "01234", string(res.Data))
+	})
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	var (
+		lock sync.Mutex
+		keys []string
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key")+"|"+r.Header.Get("X-Request-Key"))
+		n := len(keys)
+		lock.Unlock()
+		// Fail the first attempt of each request
+		if n%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer s.Close()
+
+	reset := func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		res := keys
+		keys = nil
+		return res
+	}
+
+	t.Run("generated and reused across retries", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"generateIdempotencyKey": "true", "maxRetries": "1"})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "post", Data: []byte("{}")})
+		require.NoError(t, err)
+		key := res.Metadata["idempotencyKey"]
+		require.NotEmpty(t, key)
+		assert.Equal(t, []string{key + "|", key + "|"}, reset())
+
+		// A new key is generated for the next operation
+		res, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "post", Data: []byte("{}")})
+		require.NoError(t, err)
+		assert.NotEqual(t, key, res.Metadata["idempotencyKey"])
+		reset()
+	})
+
+	t.Run("key passed in metadata", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"idempotencyKeyHeader": "X-Request-Key", "maxRetries": "1"})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "put", Metadata: map[string]string{"idempotencyKey": "abc"}})
+		require.NoError(t, err)
+		assert.Equal(t, "abc", res.Metadata["idempotencyKey"])
+		assert.Equal(t, []string{"|abc", "|abc"}, reset())
+	})
+
+	t.Run("not generated for GET or when disabled", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"generateIdempotencyKey": "true", "errorIfNot2XX": "false"})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.NotContains(t, res.Metadata, "idempotencyKey")
+
+		hs, err = InitBinding(s, map[string]string{"errorIfNot2XX": "false"})
+		require.NoError(t, err)
+		res, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "post"})
+		require.NoError(t, err)
+		assert.NotContains(t, res.Metadata, "idempotencyKey")
+		reset()
+	})
+}
+
+func TestEmptyBody(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/notfound", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/data", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"a":1}`))
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	tests := map[string]struct {
+		props     map[string]string
+		metadata  map[string]string
+		operation string
+		path      string
+		data      string
+		bodyEmpty bool
+	}{
+		"empty body":                      {path: "/empty", data: "", bodyEmpty: true},
+		"non-empty body":                  {path: "/data", data: `{"a":1}`},
+		"null enabled":                    {props: map[string]string{"emptyBodyAsNull": "true"}, path: "/empty", data: "null", bodyEmpty: true},
+		"null enabled with non-empty":     {props: map[string]string{"emptyBodyAsNull": "true"}, path: "/data", data: `{"a":1}`},
+		"null enabled per-request":        {metadata: map[string]string{"emptyBodyAsNull": "true"}, path: "/empty", data: "null", bodyEmpty: true},
+		"null disabled per-request":       {props: map[string]string{"emptyBodyAsNull": "true"}, metadata: map[string]string{"emptyBodyAsNull": "false"}, path: "/empty", data: "", bodyEmpty: true},
+		"null not used for non-2xx":       {props: map[string]string{"emptyBodyAsNull": "true", "errorIfNot2XX": "false"}, path: "/notfound", data: "", bodyEmpty: true},
+		"null not used for HEAD requests": {props: map[string]string{"emptyBodyAsNull": "true"}, operation: "head", path: "/data", data: "", bodyEmpty: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hs, err := InitBinding(s, tc.props)
+			require.NoError(t, err)
+			md := map[string]string{"path": tc.path}
+			for k, v := range tc.metadata {
+				md[k] = v
+			}
+			op := tc.operation
+			if op == "" {
+				op = "get"
+			}
+			res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.OperationKind(op), Metadata: md})
+			require.NoError(t, err)
+			assert.Equal(t, tc.data, string(res.Data))
+			if tc.bodyEmpty {
+				assert.Equal(t, "true", res.Metadata["bodyEmpty"])
+			} else {
+				assert.NotContains(t, res.Metadata, "bodyEmpty")
+			}
+		})
+	}
+}
+
+func TestMTLSClientCertSelection(t *testing.T) {
+	// Create a second CA with its own client certificate
+	newCert := func(t *testing.T, tmpl *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, string, string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		if parent == nil {
+			parent, parentKey = tmpl, key
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+		require.NoError(t, err)
+		cert, err := x509.ParseCertificate(der)
+		require.NoError(t, err)
+		keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		return cert, key,
+			string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+			string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}))
+	}
+	ca2, ca2Key, _, _ := newCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(100),
+		Subject:               pkix.Name{CommonName: "CA2"},
+		NotBefore:             time.Now().Add(-1 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, nil)
+	_, _, client2Cert, client2Key := newCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(101),
+		Subject:      pkix.Name{Organization: []string{"client2"}},
+		NotBefore:    time.Now().Add(-1 * time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}, ca2, ca2Key)
+
+	ca1PEM, err := os.ReadFile(filepath.Join(".", "testdata", "ca.pem"))
+	require.NoError(t, err)
+	ca1Pool := x509.NewCertPool()
+	require.True(t, ca1Pool.AppendCertsFromPEM(ca1PEM))
+	ca2Pool := x509.NewCertPool()
+	ca2Pool.AddCert(ca2)
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.Organization[0]))
+	})
+	serverCert, err := tls.LoadX509KeyPair(filepath.Join(".", "testdata", "server.pem"), filepath.Join(".", "testdata", "server.key"))
+	require.NoError(t, err)
+	newServer := func(t *testing.T, clientCAs *x509.CertPool) *httptest.Server {
+		s := httptest.NewUnstartedServer(handler)
+		s.TLS = &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			ClientCAs:    clientCAs,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			Certificates: []tls.Certificate{serverCert},
+		}
+		s.StartTLS()
+		return s
+	}
+
+	client1, err := json.Marshal([]map[string]string{
+		{"cert": filepath.Join(".", "testdata", "client.pem"), "key": filepath.Join(".", "testdata", "client.key")},
+	})
+	require.NoError(t, err)
+	both, err := json.Marshal([]map[string]string{
+		{"cert": client2Cert, "key": client2Key},
+		{"cert": filepath.Join(".", "testdata", "client.pem"), "key": filepath.Join(".", "testdata", "client.key")},
+	})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		clientCAs *x509.CertPool
+		certs     string
+		expected  string
+		err       bool
+	}{
+		"server requests CA1":          {clientCAs: ca1Pool, certs: string(both), expected: "client"},
+		"server requests CA2":          {clientCAs: ca2Pool, certs: string(both), expected: "client2"},
+		"no match falls back to first": {clientCAs: ca2Pool, certs: string(client1), err: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := newServer(t, tc.clientCAs)
+			defer s.Close()
+			hs, err := InitBindingForHTTPS(s, map[string]string{
+				"MTLSRootCA":      filepath.Join(".", "testdata", "ca.pem"),
+				"mtlsClientCerts": tc.certs,
+			})
+			require.NoError(t, err)
+			res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(res.Data))
+		})
+	}
+
+	t.Run("invalid value", func(t *testing.T) {
+		s := newServer(t, ca1Pool)
+		defer s.Close()
+		_, err := InitBindingForHTTPS(s, map[string]string{"mtlsClientCerts": "not json"})
+		require.ErrorContains(t, err, "invalid value for mtlsClientCerts")
+		_, err = InitBindingForHTTPS(s, map[string]string{"mtlsClientCerts": "[]"})
+		require.ErrorContains(t, err, "no certificates specified")
+	})
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("state transitions", func(t *testing.T) {
+		now := time.Now()
+		cb := newCircuitBreaker(2, time.Minute, 2, 2)
+		cb.now = func() time.Time { return now }
+
+		// Opens after 2 consecutive failures
+		for i := 0; i < 2; i++ {
+			ticket, err := cb.allow()
+			require.NoError(t, err)
+			cb.record(ticket, false)
+		}
+		_, err := cb.allow()
+		require.ErrorIs(t, err, ErrCircuitOpen)
+
+		// Half-open after the open duration, admitting at most 2 concurrent probes
+		now = now.Add(time.Minute)
+		probe1, err := cb.allow()
+		require.NoError(t, err)
+		assert.True(t, probe1.probe)
+		probe2, err := cb.allow()
+		require.NoError(t, err)
+		_, err = cb.allow()
+		require.ErrorIs(t, err, ErrCircuitOpen)
+
+		stats := cb.record(probe1, true)
+		assert.Equal(t, circuitStats{state: circuitHalfOpen, probesInFlight: 1, probeSuccesses: 1}, stats)
+
+		// Closes after 2 successful probes
+		stats = cb.record(probe2, true)
+		assert.Equal(t, circuitClosed, stats.state)
+		_, err = cb.allow()
+		require.NoError(t, err)
+	})
+
+	t.Run("failed probe opens the circuit again", func(t *testing.T) {
+		now := time.Now()
+		cb := newCircuitBreaker(1, time.Minute, 1, 1)
+		cb.now = func() time.Time { return now }
+
+		ticket, _ := cb.allow()
+		cb.record(ticket, false)
+		now = now.Add(time.Minute)
+		probe, err := cb.allow()
+		require.NoError(t, err)
+		assert.Equal(t, circuitOpen, cb.record(probe, false).state)
+		_, err = cb.allow()
+		require.ErrorIs(t, err, ErrCircuitOpen)
+	})
+
+	t.Run("concurrent probes are limited", func(t *testing.T) {
+		now := time.Now()
+		var nowLock sync.Mutex
+		cb := newCircuitBreaker(1, time.Minute, 3, 100)
+		cb.now = func() time.Time {
+			nowLock.Lock()
+			defer nowLock.Unlock()
+			return now
+		}
+		ticket, _ := cb.allow()
+		cb.record(ticket, false)
+		nowLock.Lock()
+		now = now.Add(time.Minute)
+		nowLock.Unlock()
+
+		var (
+			admitted atomic.Int32
+			wg       sync.WaitGroup
+		)
+		tickets := make(chan circuitTicket, 50)
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if ticket, err := cb.allow(); err == nil {
+					admitted.Add(1)
+					tickets <- ticket
+				}
+			}()
+		}
+		wg.Wait()
+		close(tickets)
+		assert.Equal(t, int32(3), admitted.Load())
+		for ticket := range tickets {
+			cb.record(ticket, true)
+		}
+		assert.Equal(t, circuitHalfOpen, cb.state)
+		assert.Equal(t, 0, cb.probesInFlight)
+	})
+
+	t.Run("binding fails fast when open", func(t *testing.T) {
+		var (
+			calls   atomic.Int32
+			healthy atomic.Bool
+		)
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			if !healthy.Load() {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		defer s.Close()
+
+		hs, err := InitBinding(s, map[string]string{
+			"circuitBreakerFailureThreshold": "2",
+			"circuitBreakerOpenDuration":     "100ms",
+		})
+		require.NoError(t, err)
+
+		for _, expectedState := range []string{circuitClosed, circuitOpen} {
+			res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+			require.Error(t, err)
+			require.NotErrorIs(t, err, ErrCircuitOpen)
+			assert.Equal(t, expectedState, res.Metadata["circuitBreakerState"])
+		}
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, int32(2), calls.Load())
+
+		// After the open duration, a successful probe closes the circuit
+		healthy.Store(true)
+		time.Sleep(150 * time.Millisecond)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.Equal(t, circuitClosed, res.Metadata["circuitBreakerState"])
+		assert.Equal(t, int32(3), calls.Load())
+	})
+}
+
+func TestDiscoverMethods(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/allow", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Allow", "GET, head,POST, GET")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/cors", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Methods", "PUT, DELETE")
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/none", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	hs, err := InitBinding(s, nil)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		path     string
+		expected string
+	}{
+		"Allow header":                        {path: "/allow", expected: "GET,HEAD,POST"},
+		"Access-Control-Allow-Methods header": {path: "/cors", expected: "PUT,DELETE"},
+		"no header":                           {path: "/none", expected: ""},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+				Operation: "discoverMethods",
+				Metadata:  map[string]string{"path": tc.path},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "204", res.Metadata["statusCode"])
+			assert.Contains(t, res.Metadata, "allowedMethods")
+			assert.Equal(t, tc.expected, res.Metadata["allowedMethods"])
+		})
+	}
+
+	t.Run("error status", func(t *testing.T) {
+		_, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "discoverMethods",
+			Metadata:  map[string]string{"path": "/missing"},
+		})
+		require.ErrorContains(t, err, "received status code 404")
+	})
+}
+
+func TestDuplicateHeaderPolicy(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(r.Header.Values("X-Id"))
+	}))
+	defer s.Close()
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"duplicateHeaderPolicy": "ignore"})
+		require.ErrorContains(t, err, "invalid value for duplicateHeaderPolicy: ignore")
+	})
+
+	tests := map[string]struct {
+		policy   string
+		metadata map[string]string
+		expected []string
+		err      string
+	}{
+		"single key is canonicalized": {metadata: map[string]string{"X-ID": "1"}, expected: []string{"1"}},
+		"lowercase keys are not headers": {
+			metadata: map[string]string{"X-Id": "1", "x-id": "2"},
+			expected: []string{"1"},
+		},
+		"append by default": {
+			metadata: map[string]string{"X-Id": "1", "X-ID": "2"},
+			expected: []string{"2", "1"},
+		},
+		"append with three keys": {
+			policy:   "append",
+			metadata: map[string]string{"X-Id": "1", "X-ID": "2", "X-iD": "3"},
+			expected: []string{"2", "1", "3"},
+		},
+		"error": {
+			policy:   "error",
+			metadata: map[string]string{"X-Id": "1", "X-ID": "2"},
+			err:      `metadata keys "X-ID" and "X-Id" set the same header "X-Id"`,
+		},
+		"error without duplicates": {
+			policy:   "error",
+			metadata: map[string]string{"X-Id": "1"},
+			expected: []string{"1"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hs, err := InitBinding(s, map[string]string{"duplicateHeaderPolicy": tc.policy})
+			require.NoError(t, err)
+			res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: tc.metadata})
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			var values []string
+			require.NoError(t, json.Unmarshal(res.Data, &values))
+			assert.Equal(t, tc.expected, values)
+		})
+	}
+}
+
+func TestExposePeerCertChain(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	server := setupHTTPSServer(t, false, handler)
+	defer server.Close()
+
+	t.Run("disabled by default", func(t *testing.T) {
+		hs, err := InitBindingForHTTPS(server, map[string]string{"MTLSRootCA": filepath.Join(".", "testdata", "ca.pem")})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.NotContains(t, res.Metadata, "tls.peerCertChainPEM")
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		hs, err := InitBindingForHTTPS(server, map[string]string{
+			"MTLSRootCA":          filepath.Join(".", "testdata", "ca.pem"),
+			"exposePeerCertChain": "true",
+		})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+
+		serverPEM, err := os.ReadFile(filepath.Join(".", "testdata", "server.pem"))
+		require.NoError(t, err)
+		assert.Equal(t, string(serverPEM), res.Metadata["tls.peerCertChainPEM"])
+	})
+
+	t.Run("plain HTTP", func(t *testing.T) {
+		s := httptest.NewServer(handler)
+		defer s.Close()
+		hs, err := InitBinding(s, map[string]string{"exposePeerCertChain": "true"})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.NotContains(t, res.Metadata, "tls.peerCertChainPEM")
+	})
+}
+
+func TestRateLimitKey(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{
+		"maxRequestsPerSecond": "1",
+		"burst":                "1",
+	})
+	require.NoError(t, err)
+
+	invoke := func(key string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		md := map[string]string{}
+		if key != "" {
+			md["rateLimitKey"] = key
+		}
+		_, err := hs.Invoke(ctx, &bindings.InvokeRequest{Operation: "get", Metadata: md})
+		return err
+	}
+
+	// Each key has its own bucket, and so do requests without a key
+	require.NoError(t, invoke("tenant-a"))
+	require.ErrorContains(t, invoke("tenant-a"), "rate limit wait failed")
+	require.NoError(t, invoke("tenant-b"))
+	require.NoError(t, invoke(""))
+	require.Error(t, invoke(""))
+
+	t.Run("keys are bounded", func(t *testing.T) {
+		l, err := newRateLimiter(1, 1, 2, 0)
+		require.NoError(t, err)
+		a := l.limiterFor("a")
+		require.Same(t, a, l.limiterFor("a"))
+		l.limiterFor("b")
+		l.limiterFor("c")
+		assert.Equal(t, 2, l.keyed.Len())
+		// "a" was evicted, so a new bucket is created
+		assert.NotSame(t, a, l.limiterFor("a"))
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"maxRequestsPerSecond": "-1"})
+		require.ErrorContains(t, err, "invalid value for maxRequestsPerSecond")
+	})
+}
+
+func TestGRPCWeb(t *testing.T) {
+	trailersFrame := func(trailers string) []byte {
+		frame := frameGRPCWebMessage([]byte(trailers))
+		frame[0] = grpcWebFlagTrailers
+		return frame
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/grpc-web+proto" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		payload, _, err := parseGRPCWebResponse(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		w.Write(frameGRPCWebMessage(bytes.ToUpper(payload)))
+		w.Write(trailersFrame("grpc-status: 0\r\ngrpc-message: \r\n"))
+	})
+	mux.HandleFunc("/notfound", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		w.Write(trailersFrame("grpc-status: 5\r\ngrpc-message: item%20not%20found\r\n"))
+	})
+	mux.HandleFunc("/trailersonly", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		w.Header().Set("Grpc-Status", "7")
+		w.Header().Set("Grpc-Message", "denied")
+	})
+	mux.HandleFunc("/malformed", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0, 0, 0, 0, 10, 'a'})
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{"grpcWeb": "true"})
+	require.NoError(t, err)
+
+	invoke := func(path string, data string) (*bindings.InvokeResponse, error) {
+		return hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "post",
+			Data:      []byte(data),
+			Metadata:  map[string]string{"path": path},
+		})
+	}
+
+	t.Run("framed request and response", func(t *testing.T) {
+		res, err := invoke("/echo", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "HELLO", string(res.Data))
+		assert.Equal(t, "0", res.Metadata["grpc-status"])
+	})
+
+	t.Run("error status in trailers", func(t *testing.T) {
+		res, err := invoke("/notfound", "")
+		require.EqualError(t, err, "received gRPC status 5: item not found")
+		assert.Equal(t, "5", res.Metadata["grpc-status"])
+		assert.Equal(t, "item not found", res.Metadata["grpc-message"])
+		assert.Empty(t, res.Data)
+	})
+
+	t.Run("trailers-only response", func(t *testing.T) {
+		res, err := invoke("/trailersonly", "")
+		require.EqualError(t, err, "received gRPC status 7: denied")
+		assert.Equal(t, "7", res.Metadata["grpc-status"])
+	})
+
+	t.Run("malformed response", func(t *testing.T) {
+		_, err := invoke("/malformed", "")
+		require.ErrorContains(t, err, "invalid gRPC-Web response: frame length 10 exceeds the remaining 1 bytes")
+	})
+
+	t.Run("requires post", func(t *testing.T) {
+		_, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.ErrorContains(t, err, "grpcWeb requires the post operation")
+	})
+
+	t.Run("status errors can be suppressed", func(t *testing.T) {
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "post",
+			Metadata:  map[string]string{"path": "/notfound", "errorIfNot2XX": "false"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "5", res.Metadata["grpc-status"])
+	})
+}
+
+func TestRetriableNetworkErrors(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected string
+	}{
+		"dns failure":        {err: &url.Error{Op: "Get", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "invalid", IsNotFound: true}}}, expected: "dnsfailure"},
+		"connection refused": {err: &url.Error{Op: "Get", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}, expected: "connrefused"},
+		"connection reset":   {err: &url.Error{Op: "Get", Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}}, expected: "connreset"},
+		"broken pipe":        {err: &net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.EPIPE)}, expected: "connreset"},
+		"timeout":            {err: &url.Error{Op: "Get", Err: &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}}, expected: "timeout"},
+		"eof":                {err: &url.Error{Op: "Get", Err: io.EOF}, expected: "eof"},
+		"unexpected eof":     {err: fmt.Errorf("reading body: %w", io.ErrUnexpectedEOF), expected: "eof"},
+		"tls record header":  {err: &url.Error{Op: "Get", Err: tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}}, expected: "tlshandshake"},
+		"tls certificate":    {err: &url.Error{Op: "Get", Err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}}, expected: "tlshandshake"},
+		"tls alert":          {err: &net.OpError{Op: "remote error", Err: tls.AlertError(42)}, expected: "tlshandshake"},
+		"other":              {err: errors.New("something else"), expected: ""},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, classifyNetworkError(tc.err))
+		})
+	}
+
+	t.Run("invalid category", func(t *testing.T) {
+		_, err := parseNetworkErrorCategories([]string{"eof", "gremlins"})
+		require.ErrorContains(t, err, "unsupported network error category: gremlins")
+	})
+
+	// Get an address where connections are refused
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+
+	attempts := func(t *testing.T, categories string) int {
+		hs := NewHTTP(logger.NewLogger("test")).(*HTTPSource)
+		err := hs.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"url":                    "http://" + addr,
+			"retriableNetworkErrors": categories,
+		}}})
+		require.NoError(t, err)
+		request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+addr, nil)
+		require.NoError(t, err)
+		_, res, err := hs.doWithRetries(request, 2)
+		require.Error(t, err)
+		return res.attempts
+	}
+
+	t.Run("transient errors are retried by default", func(t *testing.T) {
+		assert.Equal(t, 3, attempts(t, ""))
+	})
+	t.Run("unclassified errors are never retried", func(t *testing.T) {
+		hs := &HTTPSource{}
+		assert.False(t, hs.isRetriableNetworkError(&url.Error{Op: "Get", Err: errors.New("stopped after 10 redirects")}))
+		hs.metadata.retriableNetworkErrors, err = parseNetworkErrorCategories([]string{"connrefused", "connreset", "timeout", "dnsfailure", "eof", "tlshandshake"})
+		require.NoError(t, err)
+		assert.False(t, hs.isRetriableNetworkError(&url.Error{Op: "Get", Err: errors.New("stopped after 10 redirects")}))
+	})
+	t.Run("dns failures are not retried by default", func(t *testing.T) {
+		hs := &HTTPSource{}
+		assert.False(t, hs.isRetriableNetworkError(&url.Error{Op: "Get", Err: &net.DNSError{Err: "no such host", Name: "invalid", IsNotFound: true}}))
+	})
+	t.Run("enabled category is retried", func(t *testing.T) {
+		assert.Equal(t, 3, attempts(t, "connreset,connrefused"))
+	})
+	t.Run("other categories are not retried", func(t *testing.T) {
+		assert.Equal(t, 1, attempts(t, "dnsfailure,eof"))
+	})
+
+	t.Run("only idempotent requests are retried", func(t *testing.T) {
+		var calls atomic.Int32
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			// Reset the connection after the request was received
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			conn.(*net.TCPConn).SetLinger(0)
+			conn.Close()
+		}))
+		defer s.Close()
+		hs, err := InitBinding(s, map[string]string{"maxRetries": "2"})
+		require.NoError(t, err)
+
+		for _, tc := range []struct {
+			operation string
+			metadata  map[string]string
+			calls     int32
+		}{
+			{operation: "get", calls: 3},
+			{operation: "put", calls: 3},
+			{operation: "delete", calls: 3},
+			{operation: "post", calls: 1},
+			{operation: "patch", calls: 1},
+			{operation: "post", metadata: map[string]string{"Idempotency-Key": "key"}, calls: 3},
+			{operation: "post", metadata: map[string]string{"idempotencyKey": "key"}, calls: 3},
+		} {
+			calls.Store(0)
+			_, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.OperationKind(tc.operation), Data: []byte("data"), Metadata: tc.metadata})
+			require.Error(t, err, tc.operation)
+			assert.Equal(t, tc.calls, calls.Load(), "%s %v", tc.operation, tc.metadata)
+		}
+	})
+}
+
+func TestFireAndForget(t *testing.T) {
+	var (
+		received atomic.Int32
+		started  = make(chan struct{}, 10)
+		unblock  = make(chan struct{})
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-unblock
+		received.Add(1)
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{
+		"fireAndForgetWorkers":   "1",
+		"fireAndForgetQueueSize": "1",
+	})
+	require.NoError(t, err)
+
+	invoke := func() *bindings.InvokeResponse {
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "post",
+			Data:      []byte("event"),
+			Metadata:  map[string]string{"fireAndForget": "true"},
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	// The first request is picked up by the worker, the second is queued, and the third is dropped
+	assert.Equal(t, "true", invoke().Metadata["accepted"])
+	<-started
+	assert.Equal(t, "true", invoke().Metadata["accepted"])
+	res := invoke()
+	assert.Equal(t, "false", res.Metadata["accepted"])
+	assert.Equal(t, "1", res.Metadata["fireAndForgetDropped"])
+	assert.Equal(t, int32(0), received.Load())
+
+	// Close drains the queue
+	close(unblock)
+	require.NoError(t, hs.(*HTTPSource).Close())
+	assert.Equal(t, int32(2), received.Load())
+
+	// Requests after Close are dropped
+	assert.Equal(t, "false", invoke().Metadata["accepted"])
+
+	t.Run("closed before use", func(t *testing.T) {
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		require.NoError(t, hs.(*HTTPSource).Close())
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"fireAndForget": "true"}})
+		require.ErrorContains(t, err, "binding is closed")
+	})
+}
+
+func TestRequestSpec(t *testing.T) {
+	var (
+		lastReq  *http.Request
+		lastBody []byte
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastReq = r
+		lastBody, _ = io.ReadAll(r.Body)
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, nil)
+	require.NoError(t, err)
+
+	t.Run("applies headers, query, path params, and body", func(t *testing.T) {
+		_, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "post",
+			Metadata: map[string]string{
+				"path":        "/users/{id}/items?a=1",
+				"X-Override":  "flat",
+				"requestSpec": `{"headers":{"X-Spec":"spec","X-Override":"spec"},"query":{"b":"2 3"},"pathParams":{"id":"a/b"},"body":{"k":"v"}}`,
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "/users/a%2Fb/items", lastReq.URL.EscapedPath())
+		assert.Equal(t, "1", lastReq.URL.Query().Get("a"))
+		assert.Equal(t, "2 3", lastReq.URL.Query().Get("b"))
+		assert.Equal(t, "spec", lastReq.Header.Get("X-Spec"))
+		assert.Equal(t, "flat", lastReq.Header.Get("X-Override"))
+		assert.Equal(t, `{"k":"v"}`, string(lastBody))
+	})
+
+	t.Run("string body is sent as-is", func(t *testing.T) {
+		_, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "put",
+			Metadata:  map[string]string{"requestSpec": `{"body":"hello"}`},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(lastBody))
+	})
+
+	t.Run("invalid specs", func(t *testing.T) {
+		for name, spec := range map[string]string{
+			"not JSON":       `headers`,
+			"unknown field":  `{"header":{"a":"b"}}`,
+			"wrong type":     `{"query":{"a":1}}`,
+			"trailing data":  `{} {}`,
+			"missing param":  `{"pathParams":{"nope":"x"}}`,
+			"body with data": `{"body":"x"}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+					Operation: "post",
+					Data:      []byte("data"),
+					Metadata:  map[string]string{"requestSpec": spec},
+				})
+				require.ErrorContains(t, err, "requestSpec")
+			})
+		}
+	})
+}
+
+func TestUploadArchive(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub", "node_modules"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("world"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "debug.log"), []byte("log"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "node_modules", "c.js"), []byte("js"), 0o600))
+
+	var (
+		contentType string
+		received    []byte
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		received, _ = io.ReadAll(r.Body)
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, nil)
+	require.NoError(t, err)
+
+	readTar := func(t *testing.T, r io.Reader) map[string]string {
+		t.Helper()
+		files := map[string]string{}
+		tr := tar.NewReader(r)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return files
+			}
+			require.NoError(t, err)
+			data, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			files[hdr.Name] = string(data)
+		}
+	}
+
+	t.Run("tar.gz with exclusions", func(t *testing.T) {
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "uploadArchive",
+			Metadata: map[string]string{
+				"sourcePath":     dir,
+				"archiveExclude": "*.log,node_modules",
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "application/gzip", contentType)
+		assert.Equal(t, strconv.Itoa(len(received)), res.Metadata["archiveCompressedBytes"])
+
+		gr, err := gzip.NewReader(bytes.NewReader(received))
+		require.NoError(t, err)
+		uncompressed, err := io.ReadAll(gr)
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(len(uncompressed)), res.Metadata["archiveUncompressedBytes"])
+		assert.Equal(t, map[string]string{
+			"a.txt":     "hello",
+			"sub/":      "",
+			"sub/b.txt": "world",
+		}, readTar(t, bytes.NewReader(uncompressed)))
+	})
+
+	t.Run("tar", func(t *testing.T) {
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "uploadArchive",
+			Metadata: map[string]string{
+				"sourcePath":    dir,
+				"archiveFormat": "tar",
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "application/x-tar", contentType)
+		assert.Equal(t, res.Metadata["archiveCompressedBytes"], res.Metadata["archiveUncompressedBytes"])
+		assert.Len(t, readTar(t, bytes.NewReader(received)), 6)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := hs.Invoke(ctx, &bindings.InvokeRequest{
+			Operation: "uploadArchive",
+			Metadata:  map[string]string{"sourcePath": dir},
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		for name, md := range map[string]map[string]string{
+			"missing sourcePath": {},
+			"not a directory":    {"sourcePath": filepath.Join(dir, "a.txt")},
+			"invalid format":     {"sourcePath": dir, "archiveFormat": "zip"},
+			"invalid pattern":    {"sourcePath": dir, "archiveExclude": "["},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "uploadArchive", Metadata: md})
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestResponseHMAC(t *testing.T) {
+	const secret = "s3cr3t"
+	sign := func(msg string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(msg))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	var (
+		signature string
+		timestamp string
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Signature", signature)
+		w.Header().Set("X-Timestamp", timestamp)
+		w.Write([]byte("payload"))
+	}))
+	defer s.Close()
+
+	invoke := func(t *testing.T, props map[string]string) (*bindings.InvokeResponse, error) {
+		t.Helper()
+		props["responseHmacSecret"] = secret
+		hs, err := InitBinding(s, props)
+		require.NoError(t, err)
+		return hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+	}
+
+	t.Run("signature matches", func(t *testing.T) {
+		signature = "sha256=" + sign("payload")
+		res, err := invoke(t, map[string]string{})
+		require.NoError(t, err)
+		assert.Equal(t, "payload", string(res.Data))
+	})
+
+	t.Run("signature mismatch", func(t *testing.T) {
+		signature = sign("other")
+		_, err := invoke(t, map[string]string{})
+		require.ErrorIs(t, err, ErrResponseSignatureMismatch)
+	})
+
+	t.Run("missing signature", func(t *testing.T) {
+		signature = ""
+		_, err := invoke(t, map[string]string{})
+		require.ErrorIs(t, err, ErrResponseSignatureMismatch)
+	})
+
+	t.Run("timestamp", func(t *testing.T) {
+		props := func() map[string]string {
+			return map[string]string{
+				"responseHmacTimestampHeader": "X-Timestamp",
+				"responseHmacMaxAge":          "5m",
+			}
+		}
+
+		timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+		signature = sign(timestamp + ".payload")
+		_, err := invoke(t, props())
+		require.NoError(t, err)
+
+		timestamp = strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+		signature = sign(timestamp + ".payload")
+		_, err = invoke(t, props())
+		require.ErrorIs(t, err, ErrResponseSignatureMismatch)
+		require.ErrorContains(t, err, "older than")
+	})
+
+	t.Run("invalid algorithm", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"responseHmacSecret": secret, "responseHmacAlgorithm": "md5"})
+		require.ErrorContains(t, err, "responseHmacAlgorithm")
+	})
+}
+
+func TestDialFallbackDelay(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer s.Close()
+
+	tests := map[string]struct {
+		value    string
+		expected time.Duration
+	}{
+		"default":  {"", 0},
+		"shorter":  {"50ms", 50 * time.Millisecond},
+		"disabled": {"-1ms", -time.Millisecond},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hs, err := InitBinding(s, map[string]string{"dialFallbackDelay": tc.value})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, hs.(*HTTPSource).newDialer().FallbackDelay)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"dialFallbackDelay": "soon"})
+		require.Error(t, err)
+	})
+}
+
+func TestRequestProtocol(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %t %s", r.Proto, r.Close, body)
+	}
+	s := httptest.NewServer(http.HandlerFunc(handler))
+	defer s.Close()
+
+	tests := map[string]struct {
+		protocol string
+		expected string
+	}{
+		"default": {"", "HTTP/1.1 false data"},
+		"1.1":     {"1.1", "HTTP/1.1 false data"},
+		"1.0":     {"1.0", "HTTP/1.0 true data"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			hs, err := InitBinding(s, map[string]string{"requestProtocol": tc.protocol})
+			require.NoError(t, err)
+			res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+				Operation: "post",
+				Data:      []byte("data"),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(res.Data))
+		})
+	}
+
+	t.Run("1.0 over TLS", func(t *testing.T) {
+		s := setupHTTPSServer(t, false, http.HandlerFunc(handler))
+		defer s.Close()
+		hs, err := InitBindingForHTTPS(s, map[string]string{
+			"MTLSRootCA":      filepath.Join(".", "testdata", "ca.pem"),
+			"requestProtocol": "1.0",
+		})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.Equal(t, "HTTP/1.0 true ", string(res.Data))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"requestProtocol": "3"})
+		require.ErrorContains(t, err, "requestProtocol")
+	})
+}
+
+func TestLogRetries(t *testing.T) {
+	var requests atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer s.Close()
+
+	newBinding := func(t *testing.T, props map[string]string) (*HTTPSource, *strings.Builder) {
+		buf := &strings.Builder{}
+		log := logger.NewLogger("test")
+		log.SetOutput(buf)
+		log.SetOutputLevel(logger.DebugLevel)
+
+		m := bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"url": s.URL}}}
+		for k, v := range props {
+			m.Properties[k] = v
+		}
+		hs := NewHTTP(log).(*HTTPSource)
+		require.NoError(t, hs.Init(context.Background(), m))
+		return hs, buf
+	}
+
+	t.Run("success after retries", func(t *testing.T) {
+		requests.Store(0)
+		hs, buf := newBinding(t, map[string]string{"maxRetries": "3", "logRetries": "true"})
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.Equal(t, "3", res.Metadata["attempts"])
+		assert.Equal(t, "status code 503", res.Metadata["lastRetryReason"])
+
+		logs := buf.String()
+		assert.Contains(t, logs, "Attempt 1 of 4 to GET")
+		assert.Contains(t, logs, "Attempt 2 of 4 to GET")
+		assert.Contains(t, logs, "failed: status code 503; retrying immediately")
+		assert.Contains(t, logs, "Attempt 3 of 4 to GET")
+		assert.Contains(t, logs, "completed: status code 200")
+	})
+
+	t.Run("not logged by default", func(t *testing.T) {
+		requests.Store(0)
+		hs, buf := newBinding(t, map[string]string{"maxRetries": "3"})
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.Equal(t, "3", res.Metadata["attempts"])
+		assert.NotContains(t, buf.String(), "Attempt")
+	})
+
+	t.Run("network error", func(t *testing.T) {
+		hs, _ := newBinding(t, map[string]string{"maxRetries": "1", "logRetries": "true"})
+		hs.metadata.URL = "http://127.0.0.1:1"
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.Error(t, err)
+		require.NotNil(t, res)
+		assert.Equal(t, "2", res.Metadata["attempts"])
+		assert.Equal(t, "network error: connrefused", res.Metadata["lastRetryReason"])
+	})
+}
+
+func TestServerTiming(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Server-Timing", `cache;desc="Cache Read, L1";dur=23.2, db;dur=53`)
+		w.Header().Add("Server-Timing", `missedCache, bad name;dur=1, broken;dur=abc, db;dur=99, app;DUR=47.20;desc=render`)
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, nil)
+	require.NoError(t, err)
+	res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"serverTiming.cache":       "23.2",
+		"serverTiming.cache.desc":  "Cache Read, L1",
+		"serverTiming.db":          "53",
+		"serverTiming.missedCache": "0",
+		"serverTiming.app":         "47.2",
+		"serverTiming.app.desc":    "render",
+	}
+	actual := map[string]string{}
+	for k, v := range res.Metadata {
+		if strings.HasPrefix(k, "serverTiming.") {
+			actual[k] = v
+		}
+	}
+	assert.Equal(t, expected, actual)
+	assert.Contains(t, res.Metadata["Server-Timing"], "cache;desc=")
+}
+
+func TestSkipIf(t *testing.T) {
+	md := map[string]string{
+		"prevStatus": "304",
+		"prevETag":   `"abc"`,
+		"mode":       "dry run",
+		"empty":      "",
+	}
+
+	tests := []struct {
+		expr     string
+		expected bool
+		err      string
+	}{
+		{expr: "${prevStatus}==304", expected: true},
+		{expr: "${prevStatus} == 304.0", expected: true},
+		{expr: "${prevStatus} != 304", expected: false},
+		{expr: "${prevStatus} >= 300", expected: true},
+		{expr: "${prevStatus} < 300", expected: false},
+		{expr: "${mode} == 'dry run'", expected: true},
+		{expr: `${mode} == "live"`, expected: false},
+		{expr: "${missing} == ''", expected: true},
+		{expr: "${prevStatus}", expected: true},
+		{expr: "${empty}", expected: false},
+		{expr: "!${missing}", expected: true},
+		{expr: "${mode} > 3", err: "requires numeric operands"},
+		{expr: "prevStatus", err: "expected a comparison"},
+		{expr: "${prevStatus} ==", err: "missing operand"},
+		{expr: "${prevStatus} == 'abc", err: "unterminated string"},
+		{expr: "${prevStatus} == a b", err: "invalid operand"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			res, err := evaluateSkipIf(tc.expr, md)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+
+	t.Run("invoke", func(t *testing.T) {
+		var requests atomic.Int32
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+		}))
+		defer s.Close()
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "get",
+			Metadata:  map[string]string{"skipIf": "${prevStatus}==304", "prevStatus": "304"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "true", res.Metadata["skipped"])
+		assert.Equal(t, int32(0), requests.Load())
+
+		res, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "get",
+			Metadata:  map[string]string{"skipIf": "${prevStatus}==304", "prevStatus": "200"},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, res.Metadata["skipped"])
+		assert.Equal(t, int32(1), requests.Load())
+
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "get",
+			Metadata:  map[string]string{"skipIf": "nope"},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestURLPathNormalization(t *testing.T) {
+	var lastURI string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastURI = r.RequestURI
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		name          string
+		baseURL       string
+		path          string
+		trailingSlash string
+		expected      string
+	}{
+		{name: "collapse", baseURL: s.URL + "//api/", path: "//v1//items", expected: "/api/v1/items"},
+		{name: "query is not modified", baseURL: s.URL, path: "/a//b?next=//c", expected: "/a/b?next=//c"},
+		{name: "escaped characters are preserved", baseURL: s.URL, path: "/a%2Fb//c", expected: "/a%2Fb/c"},
+		{name: "keep", baseURL: s.URL, path: "/items/", trailingSlash: "keep", expected: "/items/"},
+		{name: "add", baseURL: s.URL, path: "/items?x=1", trailingSlash: "add", expected: "/items/?x=1"},
+		{name: "add to root", baseURL: s.URL, trailingSlash: "add", expected: "/"},
+		{name: "strip", baseURL: s.URL, path: "/items//", trailingSlash: "strip", expected: "/items"},
+		{name: "strip keeps root", baseURL: s.URL + "/", trailingSlash: "strip", expected: "/"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hs, err := InitBinding(s, map[string]string{"url": tc.baseURL, "trailingSlash": tc.trailingSlash})
+			require.NoError(t, err)
+			_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{
+				Operation: "get",
+				Metadata:  map[string]string{"path": tc.path},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, lastURI)
+		})
+	}
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"trailingSlash": "remove"})
+		require.ErrorContains(t, err, "trailingSlash")
+	})
+}
+
+func TestResponseCache(t *testing.T) {
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fresh", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			requests.Add(1)
+		}
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprintf(w, "fresh-%d", requests.Load())
+	})
+	mux.HandleFunc("/expires", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Expires", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		w.Write([]byte("expires"))
+	})
+	mux.HandleFunc("/nostore", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Cache-Control", "no-store, max-age=60")
+		w.Write([]byte("nostore"))
+	})
+	mux.HandleFunc("/etag", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("etag"))
+	})
+	mux.HandleFunc("/vary", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		w.Write([]byte(r.Header.Get("Accept-Language")))
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	newBinding := func(t *testing.T) bindings.OutputBinding {
+		requests.Store(0)
+		hs, err := InitBinding(s, map[string]string{"enableResponseCache": "true"})
+		require.NoError(t, err)
+		return hs
+	}
+	invoke := func(t *testing.T, hs bindings.OutputBinding, op string, md map[string]string) *bindings.InvokeResponse {
+		t.Helper()
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.OperationKind(op), Metadata: md})
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("fresh responses are served from the cache", func(t *testing.T) {
+		hs := newBinding(t)
+		res := invoke(t, hs, "get", map[string]string{"path": "/fresh"})
+		assert.Empty(t, res.Metadata["fromCache"])
+		assert.Equal(t, "0", res.Metadata["cacheHits"])
+		assert.Equal(t, "1", res.Metadata["cacheMisses"])
+
+		// Advance the clock of the cache
+		cache := hs.(*HTTPSource).responseCache
+		cache.now = func() time.Time { return time.Now().Add(30 * time.Second) }
+		res = invoke(t, hs, "get", map[string]string{"path": "/fresh"})
+		assert.Equal(t, "fresh-1", string(res.Data))
+		assert.Equal(t, "true", res.Metadata["fromCache"])
+		assert.Equal(t, "30", res.Metadata["age"])
+		assert.Equal(t, "1", res.Metadata["cacheHits"])
+		assert.Equal(t, int32(1), requests.Load())
+
+		// Stale after max-age
+		cache.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		res = invoke(t, hs, "get", map[string]string{"path": "/fresh"})
+		assert.Equal(t, "fresh-2", string(res.Data))
+		assert.Empty(t, res.Metadata["fromCache"])
+	})
+
+	t.Run("request no-cache bypasses the cache", func(t *testing.T) {
+		hs := newBinding(t)
+		invoke(t, hs, "get", map[string]string{"path": "/fresh"})
+		res := invoke(t, hs, "get", map[string]string{"path": "/fresh", "Cache-Control": "no-cache"})
+		assert.Empty(t, res.Metadata["fromCache"])
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("expires", func(t *testing.T) {
+		hs := newBinding(t)
+		invoke(t, hs, "get", map[string]string{"path": "/expires"})
+		res := invoke(t, hs, "get", map[string]string{"path": "/expires"})
+		assert.Equal(t, "true", res.Metadata["fromCache"])
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("no-store responses are not cached", func(t *testing.T) {
+		hs := newBinding(t)
+		invoke(t, hs, "get", map[string]string{"path": "/nostore"})
+		res := invoke(t, hs, "get", map[string]string{"path": "/nostore"})
+		assert.Empty(t, res.Metadata["fromCache"])
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("stale responses are revalidated", func(t *testing.T) {
+		hs := newBinding(t)
+		invoke(t, hs, "get", map[string]string{"path": "/etag"})
+		res := invoke(t, hs, "get", map[string]string{"path": "/etag"})
+		assert.Equal(t, "etag", string(res.Data))
+		assert.Equal(t, "200", res.Metadata["statusCode"])
+		assert.Equal(t, "true", res.Metadata["fromCache"])
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("vary", func(t *testing.T) {
+		hs := newBinding(t)
+		invoke(t, hs, "get", map[string]string{"path": "/vary", "Accept-Language": "en"})
+		res := invoke(t, hs, "get", map[string]string{"path": "/vary", "Accept-Language": "it"})
+		assert.Equal(t, "it", string(res.Data))
+		assert.Empty(t, res.Metadata["fromCache"])
+		res = invoke(t, hs, "get", map[string]string{"path": "/vary", "Accept-Language": "it"})
+		assert.Equal(t, "it", string(res.Data))
+		assert.Equal(t, "true", res.Metadata["fromCache"])
+	})
+
+	t.Run("unsafe methods invalidate the cache", func(t *testing.T) {
+		hs := newBinding(t)
+		invoke(t, hs, "get", map[string]string{"path": "/fresh"})
+		invoke(t, hs, "post", map[string]string{"path": "/fresh"})
+		res := invoke(t, hs, "get", map[string]string{"path": "/fresh"})
+		assert.Empty(t, res.Metadata["fromCache"])
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("key headers", func(t *testing.T) {
+		requests.Store(0)
+		hs, err := InitBinding(s, map[string]string{"enableResponseCache": "true", "responseCacheKeyHeaders": "x-tenant, Accept"})
+		require.NoError(t, err)
+
+		res := invoke(t, hs, "get", map[string]string{"path": "/fresh", "X-Tenant": "a"})
+		assert.Equal(t, "fresh-1", string(res.Data))
+		res = invoke(t, hs, "get", map[string]string{"path": "/fresh", "X-Tenant": "b"})
+		assert.Equal(t, "fresh-2", string(res.Data))
+		assert.Empty(t, res.Metadata["fromCache"])
+		res = invoke(t, hs, "get", map[string]string{"path": "/fresh", "X-Tenant": "a"})
+		assert.Equal(t, "fresh-1", string(res.Data))
+		assert.Equal(t, "true", res.Metadata["fromCache"])
+
+		// Unsafe methods invalidate the responses for all values of the key headers
+		invoke(t, hs, "post", map[string]string{"path": "/fresh", "X-Tenant": "c"})
+		res = invoke(t, hs, "get", map[string]string{"path": "/fresh", "X-Tenant": "b"})
+		assert.Equal(t, "fresh-3", string(res.Data))
+		assert.Empty(t, res.Metadata["fromCache"])
+	})
+
+	t.Run("credentials are part of the key", func(t *testing.T) {
+		requests.Store(0)
+		hs, err := InitBinding(s, map[string]string{"enableResponseCache": "true", "securityTokenHeader": "X-Api-Key"})
+		require.NoError(t, err)
+
+		res := invoke(t, hs, "get", map[string]string{"path": "/fresh", "Authorization": "Bearer alice"})
+		assert.Equal(t, "fresh-1", string(res.Data))
+		// Responses fetched with the credentials of a caller aren't returned to other callers
+		for _, md := range []map[string]string{
+			{"Authorization": "Bearer bob"},
+			{},
+			{"Authorization": "Bearer alice", "Cookie": "session=bob"},
+			{"Authorization": "Bearer alice", "X-Api-Key": "bob"},
+		} {
+			md["path"] = "/fresh"
+			res = invoke(t, hs, "get", md)
+			assert.Empty(t, res.Metadata["fromCache"], md)
+		}
+		res = invoke(t, hs, "get", map[string]string{"path": "/fresh", "Authorization": "Bearer alice"})
+		assert.Equal(t, "fresh-1", string(res.Data))
+		assert.Equal(t, "true", res.Metadata["fromCache"])
+
+		// Credentials are not stored in clear text
+		for _, k := range hs.(*HTTPSource).responseCache.cache.Keys() {
+			assert.NotContains(t, k, "alice")
+			assert.NotContains(t, k, "bob")
+		}
+
+		// Unsafe methods invalidate the responses for all credentials
+		invoke(t, hs, "post", map[string]string{"path": "/fresh"})
+		res = invoke(t, hs, "get", map[string]string{"path": "/fresh", "Authorization": "Bearer alice"})
+		assert.Empty(t, res.Metadata["fromCache"])
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		res := invoke(t, hs, "get", map[string]string{"path": "/fresh"})
+		assert.NotContains(t, res.Metadata, "cacheHits")
+	})
+}
+
+func TestAutoOctetStream(t *testing.T) {
+	var contentType string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		name     string
+		enabled  string
+		data     []byte
+		md       map[string]string
+		expected string
+	}{
+		{name: "binary", enabled: "true", data: []byte{0x89, 0x50, 0x4e, 0x47, 0x00, 0xff}, expected: "application/octet-stream"},
+		{name: "NUL bytes", enabled: "true", data: []byte("a\x00b"), expected: "application/octet-stream"},
+		{name: "text", enabled: "true", data: []byte(`{"héllo":"wörld"}`), expected: "application/json; charset=utf-8"},
+		{name: "explicit content type", enabled: "true", data: []byte{0xff}, md: map[string]string{"Content-Type": "image/png"}, expected: "image/png"},
+		{name: "disabled", enabled: "false", data: []byte{0xff}, expected: "application/json; charset=utf-8"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hs, err := InitBinding(s, map[string]string{"autoOctetStream": tc.enabled})
+			require.NoError(t, err)
+			_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "post", Data: tc.data, Metadata: tc.md})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, contentType)
+		})
+	}
+}
+
+func TestWarnOnCreateAlias(t *testing.T) {
+	var method string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+	}))
+	defer s.Close()
+
+	newBinding := func(t *testing.T, props map[string]string) (*HTTPSource, *strings.Builder) {
+		buf := &strings.Builder{}
+		log := logger.NewLogger("test")
+		log.SetOutput(buf)
+
+		m := bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"url": s.URL}}}
+		for k, v := range props {
+			m.Properties[k] = v
+		}
+		hs := NewHTTP(log).(*HTTPSource)
+		require.NoError(t, hs.Init(context.Background(), m))
+		return hs, buf
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		hs, buf := newBinding(t, map[string]string{"warnOnCreateAlias": "true"})
+		for i := 0; i < 2; i++ {
+			res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.CreateOperation})
+			require.NoError(t, err)
+			assert.Equal(t, http.MethodPost, method)
+			assert.Equal(t, "create", res.Metadata["deprecatedOperation"])
+		}
+		// The warning is logged once
+		assert.Equal(t, 1, strings.Count(buf.String(), "operation is deprecated"))
+
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "post"})
+		require.NoError(t, err)
+		assert.NotContains(t, res.Metadata, "deprecatedOperation")
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		hs, buf := newBinding(t, nil)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: bindings.CreateOperation})
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, method)
+		assert.NotContains(t, res.Metadata, "deprecatedOperation")
+		assert.NotContains(t, buf.String(), "deprecated")
+	})
+}
+
+func TestOriginateTrace(t *testing.T) {
+	var traceparent string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer s.Close()
+
+	traceparentRe := regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-(0[01])$`)
+
+	t.Run("generated when missing", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"originateTrace": "true"})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		match := traceparentRe.FindStringSubmatch(traceparent)
+		require.NotNil(t, match, traceparent)
+		assert.Equal(t, "01", match[1])
+		assert.Equal(t, traceparent, res.Metadata["traceparent"])
+
+		// A new trace is generated for each request
+		res2, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.NotEqual(t, res.Metadata["traceparent"], res2.Metadata["traceparent"])
+	})
+
+	t.Run("not sampled", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"originateTrace": "true", "originateTraceSampled": "false"})
+		require.NoError(t, err)
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		match := traceparentRe.FindStringSubmatch(traceparent)
+		require.NotNil(t, match, traceparent)
+		assert.Equal(t, "00", match[1])
+	})
+
+	t.Run("existing trace context is passed through", func(t *testing.T) {
+		const tp = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		hs, err := InitBinding(s, map[string]string{"originateTrace": "true"})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "get",
+			Metadata:  map[string]string{"traceparent": tp},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, tp, traceparent)
+		assert.NotContains(t, res.Metadata, "traceparent")
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.Empty(t, traceparent)
+	})
+}
+
+func TestMaxIdleTimeBeforeReset(t *testing.T) {
+	var newConns atomic.Int32
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	s.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	s.Start()
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{"maxIdleTimeBeforeReset": "1m"})
+	require.NoError(t, err)
+	reset := hs.(*HTTPSource).idleReset
+	require.NotNil(t, reset)
+
+	var offset atomic.Int64
+	reset.now = func() time.Time { return time.Now().Add(time.Duration(offset.Load())) }
+
+	invoke := func() {
+		_, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+	}
+
+	// Connections are reused while the client isn't idle for too long
+	invoke()
+	offset.Store(int64(30 * time.Second))
+	invoke()
+	assert.Equal(t, int32(1), newConns.Load())
+
+	// After a long idle gap, the idle connection is closed and a new one is dialed
+	offset.Store(int64(5 * time.Minute))
+	invoke()
+	assert.Equal(t, int32(2), newConns.Load())
+
+	t.Run("disabled by default", func(t *testing.T) {
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		assert.Nil(t, hs.(*HTTPSource).idleReset)
+	})
+}
+
+func TestDecodeResponse(t *testing.T) {
+	type item struct {
+		Name  string `json:"name" xml:"name"`
+		Count int    `json:"count" xml:"count"`
+	}
+	newResp := func(status string, contentType string, body string) *bindings.InvokeResponse {
+		md := map[string]string{"statusCode": status}
+		if contentType != "" {
+			md["Content-Type"] = contentType
+		}
+		return &bindings.InvokeResponse{Data: []byte(body), Metadata: md}
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		var v item
+		require.NoError(t, DecodeResponse(newResp("200", "application/problem+json; charset=utf-8", `{"name":"a","count":2}`), &v))
+		assert.Equal(t, item{Name: "a", Count: 2}, v)
+	})
+
+	t.Run("no content type defaults to JSON", func(t *testing.T) {
+		var v item
+		require.NoError(t, DecodeResponse(newResp("200", "", `{"name":"b"}`), &v))
+		assert.Equal(t, "b", v.Name)
+	})
+
+	t.Run("XML", func(t *testing.T) {
+		var v item
+		require.NoError(t, DecodeResponse(newResp("200", "text/xml", `<item><name>c</name><count>3</count></item>`), &v))
+		assert.Equal(t, item{Name: "c", Count: 3}, v)
+	})
+
+	t.Run("form", func(t *testing.T) {
+		var values url.Values
+		require.NoError(t, DecodeResponse(newResp("200", "application/x-www-form-urlencoded", "a=1&a=2&b=x+y"), &values))
+		assert.Equal(t, []string{"1", "2"}, values["a"])
+
+		var m map[string]string
+		require.NoError(t, DecodeResponse(newResp("200", "application/x-www-form-urlencoded", "a=1&a=2&b=x+y"), &m))
+		assert.Equal(t, map[string]string{"a": "1", "b": "x y"}, m)
+
+		var v item
+		require.Error(t, DecodeResponse(newResp("200", "application/x-www-form-urlencoded", "a=1"), &v))
+	})
+
+	t.Run("empty body", func(t *testing.T) {
+		v := item{Name: "unchanged"}
+		require.NoError(t, DecodeResponse(newResp("204", "", ""), &v))
+		assert.Equal(t, "unchanged", v.Name)
+	})
+
+	t.Run("non-2xx status", func(t *testing.T) {
+		var v item
+		err := DecodeResponse(newResp("404", "application/json", `{"name":"x"}`), &v)
+		require.ErrorIs(t, err, ErrUnexpectedStatus)
+		assert.Empty(t, v.Name)
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		var v item
+		require.ErrorIs(t, DecodeResponse(newResp("200", "image/png", "x"), &v), ErrUnsupportedContentType)
+	})
+
+	t.Run("from Invoke", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name":"d","count":4}`))
+		}))
+		defer s.Close()
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		var v item
+		require.NoError(t, DecodeResponse(res, &v))
+		assert.Equal(t, item{Name: "d", Count: 4}, v)
+	})
+}
+
+func TestMaxResponseHeaderCount(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, k := range []string{"X-D", "X-A", "X-C", "X-B"} {
+			w.Header().Set(k, strings.ToLower(k))
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer s.Close()
+
+	t.Run("within limit", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"maxResponseHeaderCount": "100"})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.Equal(t, "x-d", res.Metadata["X-D"])
+		assert.NotContains(t, res.Metadata, "headersTruncated")
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"maxResponseHeaderCount": "3"})
+		require.NoError(t, err)
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.ErrorIs(t, err, ErrTooManyResponseHeaders)
+		var hcErr *HeaderCountError
+		require.ErrorAs(t, err, &hcErr)
+		// X-A to X-D, plus Content-Length, Content-Type and Date
+		assert.Equal(t, 7, hcErr.Count)
+		assert.Equal(t, 3, hcErr.Limit)
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{
+			"maxResponseHeaderCount": "5",
+			"headerOverflowPolicy":   "truncate",
+		})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(res.Data))
+		assert.Equal(t, "true", res.Metadata["headersTruncated"])
+		// Sorted: Content-Length, Content-Type, Date, X-A, X-B, X-C, X-D
+		assert.Equal(t, "x-a", res.Metadata["X-A"])
+		assert.Equal(t, "x-b", res.Metadata["X-B"])
+		assert.NotContains(t, res.Metadata, "X-C")
+		assert.NotContains(t, res.Metadata, "X-D")
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"headerOverflowPolicy": "drop"})
+		require.ErrorContains(t, err, "headerOverflowPolicy")
+		_, err = InitBinding(s, map[string]string{"maxResponseHeaderCount": "-1"})
+		require.ErrorContains(t, err, "maxResponseHeaderCount")
+	})
+}
+
+func TestWarmupConnections(t *testing.T) {
+	var newConns, headRequests atomic.Int32
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			headRequests.Add(1)
+		}
+	}))
+	s.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	s.Start()
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{"warmupConnections": "3"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), headRequests.Load())
+	assert.Equal(t, int32(3), newConns.Load())
+
+	// The first requests reuse the warmed-up connections
+	for i := 0; i < 3; i++ {
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), newConns.Load())
+
+	t.Run("failures are not fatal by default", func(t *testing.T) {
+		closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		closed.Close()
+
+		buf := &bytes.Buffer{}
+		log := logger.NewLogger("test")
+		log.SetOutput(buf)
+		hs := NewHTTP(log).(*HTTPSource)
+		err := hs.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"url":               closed.URL,
+			"warmupConnections": "2",
+		}}})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "Failed to warm up connections: 2 of 2 warm-up connections failed")
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		closed.Close()
+
+		hs := NewHTTP(logger.NewLogger("test"))
+		err := hs.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"url":               closed.URL,
+			"warmupConnections": "2",
+			"warmupStrict":      "true",
+		}}})
+		require.ErrorContains(t, err, "failed to warm up connections")
+	})
+
+	t.Run("with mTLS", func(t *testing.T) {
+		var tlsHeadRequests atomic.Int32
+		s := setupHTTPSServer(t, true, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				tlsHeadRequests.Add(1)
+			}
+		}))
+		defer s.Close()
+
+		_, err := InitBindingForHTTPS(s, map[string]string{
+			"MTLSRootCA":        filepath.Join(".", "testdata", "ca.pem"),
+			"MTLSClientCert":    filepath.Join(".", "testdata", "client.pem"),
+			"MTLSClientKey":     filepath.Join(".", "testdata", "client.key"),
+			"warmupConnections": "2",
+			"warmupStrict":      "true",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), tlsHeadRequests.Load())
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"warmupConnections": "-1"})
+		require.ErrorContains(t, err, "warmupConnections")
+	})
+}
+
+func TestAdaptiveAuth(t *testing.T) {
+	var requests atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		auth := r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/basic":
+			if user, pass, ok := r.BasicAuth(); ok && user == "user" && pass == "pass" {
+				io.Copy(w, r.Body)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api", Basic realm="files", charset="UTF-8"`)
+		case "/bearer":
+			if auth == "Bearer tkn" {
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+		case "/digest":
+			if strings.HasPrefix(auth, "Digest ") {
+				params := parseAuthChallenges([]string{auth})[0].params
+				ha1 := sha256.Sum256([]byte("user:vault:pass"))
+				ha2 := sha256.Sum256([]byte(r.Method + ":" + r.URL.RequestURI()))
+				expected := sha256.Sum256([]byte(hex.EncodeToString(ha1[:]) + ":n0nce:" + params["nc"] + ":" + params["cnonce"] + ":auth:" + hex.EncodeToString(ha2[:])))
+				if params["response"] == hex.EncodeToString(expected[:]) && params["opaque"] == "op" && params["uri"] == r.URL.RequestURI() {
+					return
+				}
+			}
+			w.Header().Set("WWW-Authenticate", `Digest realm="vault", qop="auth,auth-int", algorithm=SHA-256, nonce="n0nce", opaque="op"`)
+		case "/negotiate":
+			w.Header().Set("WWW-Authenticate", `Negotiate`)
+			w.Header().Add("WWW-Authenticate", `Custom realm="corp"`)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{
+		"adaptiveAuth":    "true",
+		"authUsername":    "user",
+		"authPassword":    "pass",
+		"authBearerToken": "tkn",
+	})
+	require.NoError(t, err)
+
+	invoke := func(path string, data string) (*bindings.InvokeResponse, error) {
+		return hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "post",
+			Data:      []byte(data),
+			Metadata:  map[string]string{"path": path},
+		})
+	}
+
+	tests := []struct {
+		path   string
+		scheme string
+	}{
+		// The first challenge with configured credentials is answered
+		{path: "/basic", scheme: "bearer"},
+		{path: "/bearer", scheme: "bearer"},
+		{path: "/digest", scheme: "digest"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			requests.Store(0)
+			res, err := invoke(tc.path, "hello")
+			if tc.path == "/basic" {
+				// The Bearer token is rejected, and there's no further retry
+				require.ErrorContains(t, err, "received status code 401")
+				assert.Equal(t, "bearer", res.Metadata["authScheme"])
+				assert.Equal(t, "api", res.Metadata["authRealm"])
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, int32(2), requests.Load())
+			assert.Equal(t, "2", res.Metadata["attempts"])
+			assert.Equal(t, tc.scheme, res.Metadata["authRetryScheme"])
+		})
+	}
+
+	t.Run("basic with body", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{
+			"adaptiveAuth": "true",
+			"authUsername": "user",
+			"authPassword": "pass",
+		})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "post",
+			Data:      []byte("hello"),
+			Metadata:  map[string]string{"path": "/basic"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(res.Data))
+		assert.Equal(t, "basic", res.Metadata["authRetryScheme"])
+	})
+
+	t.Run("no matching credentials", func(t *testing.T) {
+		requests.Store(0)
+		res, err := invoke("/negotiate", "")
+		require.ErrorContains(t, err, "received status code 401")
+		assert.Equal(t, int32(1), requests.Load())
+		assert.Equal(t, "negotiate", res.Metadata["authScheme"])
+		assert.NotContains(t, res.Metadata, "authRealm")
+		assert.NotContains(t, res.Metadata, "authRetryScheme")
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"authBearerToken": "tkn"})
+		require.NoError(t, err)
+		requests.Store(0)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "get",
+			Metadata:  map[string]string{"path": "/bearer"},
+		})
+		require.ErrorContains(t, err, "received status code 401")
+		assert.Equal(t, int32(1), requests.Load())
+		assert.NotContains(t, res.Metadata, "authScheme")
+	})
+}
+
+func TestParseAuthChallenges(t *testing.T) {
+	challenges := parseAuthChallenges([]string{
+		`Newauth realm="apps", type=1, title="Login to \"apps\"", Basic realm="simple"`,
+		`Negotiate abc==`,
+	})
+	require.Len(t, challenges, 3)
+	assert.Equal(t, authChallenge{scheme: "newauth", params: map[string]string{"realm": "apps", "type": "1", "title": `Login to "apps"`}}, challenges[0])
+	assert.Equal(t, authChallenge{scheme: "basic", params: map[string]string{"realm": "simple"}}, challenges[1])
+	assert.Equal(t, authChallenge{scheme: "negotiate", params: map[string]string{}}, challenges[2])
+}
+
+func TestUploadProgress(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.bin"), bytes.Repeat([]byte{1}, 64<<10), 0o600))
+
+	var received atomic.Int64
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		received.Store(n)
+		// Respond slowly, so progress is reported while the request is in flight
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer s.Close()
+
+	buf := &bytes.Buffer{}
+	log := logger.NewLogger("test")
+	log.SetOutput(buf)
+	log.SetOutputLevel(logger.DebugLevel)
+	hs := NewHTTP(log).(*HTTPSource)
+	err := hs.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"url":                    s.URL,
+		"uploadProgressInterval": "10ms",
+	}}})
+	require.NoError(t, err)
+
+	res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+		Operation: "uploadArchive",
+		Metadata:  map[string]string{"sourcePath": dir, "archiveFormat": "tar"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatInt(received.Load(), 10), res.Metadata["bytesUploaded"])
+	assert.Equal(t, res.Metadata["archiveCompressedBytes"], res.Metadata["bytesUploaded"])
+	durationMs, err := strconv.Atoi(res.Metadata["uploadDurationMs"])
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, durationMs, 0)
+	assert.Contains(t, buf.String(), "Upload progress of POST "+s.URL+": "+res.Metadata["bytesUploaded"]+" bytes sent in ")
+
+	t.Run("not reported by default", func(t *testing.T) {
+		buf.Reset()
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "uploadArchive",
+			Metadata:  map[string]string{"sourcePath": dir},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, res.Metadata["archiveCompressedBytes"], res.Metadata["bytesUploaded"])
+		assert.NotContains(t, buf.String(), "Upload progress")
+	})
+
+	t.Run("percentage", func(t *testing.T) {
+		u := newUploadProgress(strings.NewReader("0123456789"), 10)
+		_, err := io.CopyN(io.Discard, u, 4)
+		require.NoError(t, err)
+		assert.Contains(t, u.describe(), "4 bytes sent (40.0%)")
+	})
+}
+
+func TestBodyErrorJMESPath(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rpc-error":
+			w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":1}`))
+		case "/rpc-ok":
+			w.Write([]byte(`{"jsonrpc":"2.0","result":19,"error":null,"id":1}`))
+		case "/status-error":
+			w.Write([]byte(`{"status":"error","errors":["bad input"]}`))
+		case "/status-ok":
+			w.Write([]byte(`{"status":"ok","errors":[]}`))
+		case "/text":
+			w.Write([]byte(`error`))
+		case "/not-found":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"missing"}}`))
+		}
+	}))
+	defer s.Close()
+
+	invoke := func(hs bindings.OutputBinding, path string) (*bindings.InvokeResponse, error) {
+		return hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "get",
+			Metadata:  map[string]string{"path": path},
+		})
+	}
+
+	t.Run("truthy result", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"bodyErrorJMESPath": "error.message"})
+		require.NoError(t, err)
+
+		res, err := invoke(hs, "/rpc-error")
+		require.ErrorIs(t, err, ErrBodyIndicatesError)
+		require.ErrorContains(t, err, "Method not found")
+		assert.Equal(t, "200", res.Metadata["statusCode"])
+		assert.Equal(t, "Method not found", res.Metadata["bodyError"])
+		assert.Contains(t, string(res.Data), "-32601")
+
+		res, err = invoke(hs, "/rpc-ok")
+		require.NoError(t, err)
+		assert.NotContains(t, res.Metadata, "bodyError")
+
+		// Non-JSON bodies are not checked
+		_, err = invoke(hs, "/text")
+		require.NoError(t, err)
+
+		// Only 2xx responses are checked
+		res, err = invoke(hs, "/not-found")
+		require.ErrorContains(t, err, "received status code 404")
+		assert.NotErrorIs(t, err, ErrBodyIndicatesError)
+		assert.NotContains(t, res.Metadata, "bodyError")
+	})
+
+	t.Run("non-string result", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"bodyErrorJMESPath": "errors"})
+		require.NoError(t, err)
+
+		res, err := invoke(hs, "/status-error")
+		require.ErrorIs(t, err, ErrBodyIndicatesError)
+		assert.Equal(t, `["bad input"]`, res.Metadata["bodyError"])
+
+		// Empty arrays are false
+		_, err = invoke(hs, "/status-ok")
+		require.NoError(t, err)
+	})
+
+	t.Run("with bodyErrorValue", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{
+			"bodyErrorJMESPath": "status",
+			"bodyErrorValue":    "error",
+		})
+		require.NoError(t, err)
+
+		_, err = invoke(hs, "/status-error")
+		require.ErrorIs(t, err, ErrBodyIndicatesError)
+		_, err = invoke(hs, "/status-ok")
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"bodyErrorJMESPath": "error.["})
+		require.ErrorContains(t, err, "invalid value for bodyErrorJMESPath")
+		_, err = InitBinding(s, map[string]string{"bodyErrorValue": "error"})
+		require.ErrorContains(t, err, "bodyErrorValue requires bodyErrorJMESPath")
+	})
+}
+
+func TestRequestQueue(t *testing.T) {
+	unblock := make(chan struct{})
+	var inFlight atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight.Add(1)
+		<-unblock
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{
+		"maxConcurrentRequests": "1",
+		"requestQueueSize":      "1",
+	})
+	require.NoError(t, err)
+	queue := hs.(*HTTPSource).requestQueue
+	queueLen := func() int {
+		queue.lock.Lock()
+		defer queue.lock.Unlock()
+		return queue.waiters.Len()
+	}
+
+	invoke := func(ctx context.Context, path string) (*bindings.InvokeResponse, error) {
+		return hs.Invoke(ctx, &bindings.InvokeRequest{
+			Operation: "get",
+			Metadata:  map[string]string{"path": path},
+		})
+	}
+
+	type result struct {
+		res *bindings.InvokeResponse
+		err error
+	}
+	first := make(chan result, 1)
+	go func() {
+		res, err := invoke(context.Background(), "/first")
+		first <- result{res, err}
+	}()
+	require.Eventually(t, func() bool { return inFlight.Load() == 1 }, 5*time.Second, 5*time.Millisecond)
+
+	second := make(chan result, 1)
+	go func() {
+		res, err := invoke(context.Background(), "/second")
+		second <- result{res, err}
+	}()
+	require.Eventually(t, func() bool { return queueLen() == 1 }, 5*time.Second, 5*time.Millisecond)
+
+	// The queue is full
+	res, err := invoke(context.Background(), "/third")
+	require.ErrorIs(t, err, ErrRequestQueueFull)
+	assert.Equal(t, "1", res.Metadata["queueDepth"])
+
+	time.Sleep(20 * time.Millisecond)
+	close(unblock)
+
+	r := <-first
+	require.NoError(t, r.err)
+	assert.Equal(t, "0", r.res.Metadata["queueDepth"])
+	assert.Equal(t, "0", r.res.Metadata["queueWaitMs"])
+
+	r = <-second
+	require.NoError(t, r.err)
+	assert.Equal(t, "/second", string(r.res.Data))
+	assert.Equal(t, "0", r.res.Metadata["queueDepth"])
+	waitMs, err := strconv.Atoi(r.res.Metadata["queueWaitMs"])
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, waitMs, 20)
+	assert.Equal(t, int32(2), inFlight.Load())
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"requestQueueSize": "5"})
+		require.ErrorContains(t, err, "requestQueueSize requires maxConcurrentRequests")
+		_, err = InitBinding(s, map[string]string{"maxConcurrentRequests": "-1"})
+		require.ErrorContains(t, err, "maxConcurrentRequests")
+	})
+}
+
+func TestRequestQueueOrder(t *testing.T) {
+	q := newRequestQueue(1, 3, 0)
+	_, err := q.acquire(context.Background())
+	require.NoError(t, err)
+
+	// A waiting request whose context is done leaves the queue
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = q.acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, q.waiters.Len())
+
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			_, err := q.acquire(context.Background())
+			assert.NoError(t, err)
+			order <- i
+			q.release()
+		}(i)
+		// Queue the requests in order
+		require.Eventually(t, func() bool {
+			q.lock.Lock()
+			defer q.lock.Unlock()
+			return q.waiters.Len() == i+1
+		}, 5*time.Second, time.Millisecond)
+	}
+
+	q.release()
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, i, <-order)
+	}
+	// The last release happens after the order is recorded
+	require.Eventually(t, func() bool {
+		q.lock.Lock()
+		defer q.lock.Unlock()
+		return q.active == 0
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestResponsePolicyOverrides(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(code)
+		w.Write([]byte("something went wrong"))
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{
+		"successStatusCodes":        "200-299,404",
+		"errorBodyMaxLength":        "9",
+		"expectResponseContentType": "text/*",
+	})
+	require.NoError(t, err)
+
+	invoke := func(code int, md map[string]string) error {
+		reqMd := map[string]string{"path": "/" + strconv.Itoa(code)}
+		for k, v := range md {
+			reqMd[k] = v
+		}
+		_, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: reqMd})
+		return err
+	}
+
+	// Component configuration
+	require.NoError(t, invoke(200, nil))
+	require.NoError(t, invoke(404, nil))
+	require.EqualError(t, invoke(500, nil), "received status code 500: something...")
+
+	t.Run("successStatusCodes", func(t *testing.T) {
+		require.EqualError(t, invoke(404, map[string]string{"successStatusCodes": "200"}), "received status code 404: something...")
+		require.NoError(t, invoke(500, map[string]string{"successStatusCodes": "200,500-503"}))
+		require.ErrorContains(t, invoke(200, map[string]string{"successStatusCodes": "2xx"}), "invalid value for successStatusCodes")
+	})
+
+	t.Run("errorBodyMaxLength", func(t *testing.T) {
+		require.EqualError(t, invoke(500, map[string]string{"errorBodyMaxLength": "0"}), "received status code 500")
+		require.EqualError(t, invoke(500, map[string]string{"errorBodyMaxLength": "100"}), "received status code 500: something went wrong")
+		require.ErrorContains(t, invoke(500, map[string]string{"errorBodyMaxLength": "-1"}), "invalid value for errorBodyMaxLength")
+	})
+
+	t.Run("expectResponseContentType", func(t *testing.T) {
+		require.ErrorContains(t, invoke(200, map[string]string{"expectResponseContentType": "application/json"}), "unexpected response content type")
+		require.NoError(t, invoke(200, map[string]string{"expectResponseContentType": "text/plain"}))
+	})
+
+	t.Run("errorIfNot2XX", func(t *testing.T) {
+		require.NoError(t, invoke(500, map[string]string{"errorIfNot2XX": "false"}))
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": "/404"}})
+		require.EqualError(t, err, "received status code 404")
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{
+			"path":               "/404",
+			"successStatusCodes": "404",
+		}})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid component metadata", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"successStatusCodes": "299-200"})
+		require.ErrorContains(t, err, "invalid value for successStatusCodes")
+	})
+}
+
+func TestTransportSettings(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer s.Close()
+
+	t.Run("defaults", func(t *testing.T) {
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		client := hs.(*HTTPSource).client
+		transport := client.Transport.(*http.Transport)
+		assert.Equal(t, time.Duration(0), client.Timeout)
+		assert.Equal(t, 15*time.Second, transport.TLSHandshakeTimeout)
+		assert.Equal(t, 0, transport.MaxIdleConns)
+		assert.Equal(t, 0, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, time.Duration(0), transport.IdleConnTimeout)
+		assert.Equal(t, 15*time.Second, hs.(*HTTPSource).newDialer().Timeout)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{
+			"requestTimeout":      "50ms",
+			"dialTimeout":         "2s",
+			"tlsHandshakeTimeout": "3s",
+			"maxIdleConns":        "50",
+			"maxIdleConnsPerHost": "10",
+			"idleConnTimeout":     "90s",
+		})
+		require.NoError(t, err)
+		client := hs.(*HTTPSource).client
+		transport := client.Transport.(*http.Transport)
+		assert.Equal(t, 50*time.Millisecond, client.Timeout)
+		assert.Equal(t, 3*time.Second, transport.TLSHandshakeTimeout)
+		assert.Equal(t, 50, transport.MaxIdleConns)
+		assert.Equal(t, 10, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, 90*time.Second, transport.IdleConnTimeout)
+		assert.Equal(t, 2*time.Second, hs.(*HTTPSource).newDialer().Timeout)
+
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "get",
+			Metadata:  map[string]string{"path": "/slow"},
+		})
+		require.ErrorContains(t, err, "Client.Timeout exceeded")
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for _, key := range []string{"requestTimeout", "dialTimeout", "tlsHandshakeTimeout", "idleConnTimeout"} {
+			_, err := InitBinding(s, map[string]string{key: "-1ms"})
+			require.ErrorContains(t, err, "invalid value for "+key)
+		}
+		for _, key := range []string{"maxIdleConns", "maxIdleConnsPerHost"} {
+			_, err := InitBinding(s, map[string]string{key: "-1"})
+			require.ErrorContains(t, err, "invalid value for "+key)
+		}
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	var requests atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if r.URL.Path == "/recover" && n == 3 {
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{
+		"maxRetries":         "2",
+		"retryBackoff":       "20ms",
+		"retryOnStatusCodes": "500-599",
+	})
+	require.NoError(t, err)
+
+	t.Run("retries with backoff", func(t *testing.T) {
+		requests.Store(0)
+		start := time.Now()
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "get",
+			Metadata:  map[string]string{"path": "/recover"},
+		})
+		require.NoError(t, err)
+		// At least half of 20ms, then half of 40ms
+		assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+		assert.Equal(t, "3", res.Metadata["attempts"])
+		assert.Equal(t, "status code 500", res.Metadata["lastRetryReason"])
+	})
+
+	t.Run("status codes not in retryOnStatusCodes are not retried", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{
+			"maxRetries":         "2",
+			"retryOnStatusCodes": "429",
+		})
+		require.NoError(t, err)
+		requests.Store(0)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.ErrorContains(t, err, "received status code 500")
+		assert.Equal(t, "1", res.Metadata["attempts"])
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("canceled while waiting", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{
+			"maxRetries":         "5",
+			"retryBackoff":       "10s",
+			"retryOnStatusCodes": "500",
+		})
+		require.NoError(t, err)
+		requests.Store(0)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		res, err := hs.Invoke(ctx, &bindings.InvokeRequest{Operation: "get"})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorContains(t, err, "canceled while waiting to retry after status code 500")
+		assert.Equal(t, "1", res.Metadata["attempts"])
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), retryDelay(0, 1))
+		for attempt, maxDelay := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 20: maxRetryBackoff, 100: maxRetryBackoff} {
+			d := retryDelay(100*time.Millisecond, attempt)
+			assert.GreaterOrEqual(t, d, maxDelay/2)
+			assert.LessOrEqual(t, d, maxDelay)
+		}
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"retryBackoff": "-1ms"})
+		require.ErrorContains(t, err, "invalid value for retryBackoff")
+		_, err = InitBinding(s, map[string]string{"retryOnStatusCodes": "5xx"})
+		require.ErrorContains(t, err, "invalid value for retryOnStatusCodes")
+	})
+}
+
+func TestProxy(t *testing.T) {
+	// Proxy that answers plain HTTP requests itself, and tunnels CONNECT requests
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+		if r.Method != http.MethodConnect {
+			fmt.Fprintf(w, "proxied %s %s", r.URL, r.Header.Get("Proxy-Authorization"))
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		go func() {
+			defer upstream.Close()
+			io.Copy(upstream, rw)
+		}()
+		io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	defer proxy.Close()
+
+	invoke := func(t *testing.T, targetURL string, props map[string]string) (*bindings.InvokeResponse, error) {
+		t.Helper()
+		m := map[string]string{"url": targetURL}
+		for k, v := range props {
+			m[k] = v
+		}
+		hs := NewHTTP(logger.NewLogger("test"))
+		err := hs.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: m}})
+		require.NoError(t, err)
+		return hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+	}
+
+	t.Run("proxyURL", func(t *testing.T) {
+		proxied.Store(0)
+		proxyURL, _ := url.Parse(proxy.URL)
+		proxyURL.User = url.UserPassword("user", "pass")
+		res, err := invoke(t, "http://example.invalid/path", map[string]string{"proxyURL": proxyURL.String()})
+		require.NoError(t, err)
+		assert.Equal(t, "proxied http://example.invalid/path Basic dXNlcjpwYXNz", string(res.Data))
+		assert.Equal(t, int32(1), proxied.Load())
+	})
+
+	t.Run("https through CONNECT", func(t *testing.T) {
+		proxied.Store(0)
+		s := setupHTTPSServer(t, false, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("secure"))
+		}))
+		defer s.Close()
+		res, err := invoke(t, s.URL, map[string]string{
+			"proxyURL":   proxy.URL,
+			"MTLSRootCA": filepath.Join(".", "testdata", "ca.pem"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "secure", string(res.Data))
+		assert.Equal(t, int32(1), proxied.Load())
+	})
+
+	t.Run("socks5", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("via socks"))
+		}))
+		defer s.Close()
+		socksAddr, connections := startSOCKS5Server(t)
+		res, err := invoke(t, s.URL, map[string]string{"proxyURL": "socks5://" + socksAddr})
+		require.NoError(t, err)
+		assert.Equal(t, "via socks", string(res.Data))
+		assert.Equal(t, int32(1), connections.Load())
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		proxied.Store(0)
+		t.Setenv("HTTP_PROXY", proxy.URL)
+		t.Setenv("NO_PROXY", "direct.invalid")
+		res, err := invoke(t, "http://example.invalid/env", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "proxied http://example.invalid/env ", string(res.Data))
+
+		// Hosts in NO_PROXY are not proxied
+		_, err = invoke(t, "http://direct.invalid/", nil)
+		require.Error(t, err)
+		assert.Equal(t, int32(1), proxied.Load())
+	})
+
+	t.Run("invalid proxyURL", func(t *testing.T) {
+		hs := NewHTTP(logger.NewLogger("test"))
+		err := hs.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"url":      "http://example.invalid",
+			"proxyURL": "ftp://proxy:21",
+		}}})
+		require.ErrorContains(t, err, "unsupported scheme")
+	})
+}
+
+// startSOCKS5Server starts a minimal SOCKS5 proxy without authentication, supporting only the CONNECT command.
+func startSOCKS5Server(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	var connections atomic.Int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			connections.Add(1)
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 262)
+				// Greeting: version, number of methods, methods
+				if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+					return
+				}
+				if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+					return
+				}
+				conn.Write([]byte{5, 0})
+				// Request: version, command, reserved, address type, address, port
+				if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+					return
+				}
+				var host string
+				switch buf[3] {
+				case 1:
+					if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+						return
+					}
+					host = net.IP(buf[:4]).String()
+				case 3:
+					if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+						return
+					}
+					n := int(buf[0])
+					if _, err := io.ReadFull(conn, buf[:n]); err != nil {
+						return
+					}
+					host = string(buf[:n])
+				default:
+					return
+				}
+				if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+					return
+				}
+				port := int(buf[0])<<8 | int(buf[1])
+				upstream, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+				if err != nil {
+					conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer upstream.Close()
+				conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				go io.Copy(upstream, conn)
+				io.Copy(conn, upstream)
+			}()
+		}
+	}()
+	return ln.Addr().String(), &connections
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	var tokenRequests atomic.Int32
+	var expiresIn atomic.Int32
+	expiresIn.Store(3600)
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := tokenRequests.Add(1)
+		require.NoError(t, r.ParseForm())
+		user, pass, _ := r.BasicAuth()
+		if r.Form.Get("grant_type") != "client_credentials" || user != "client" || pass != "secret" ||
+			r.Form.Get("scope") != "read write" || r.Form.Get("audience") != "api" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_request"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%d}`, n, expiresIn.Load())
+	}))
+	defer tokenServer.Close()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer s.Close()
+
+	props := map[string]string{
+		"oauth2TokenURL":     tokenServer.URL,
+		"oauth2ClientID":     "client",
+		"oauth2ClientSecret": "secret",
+		"oauth2Scopes":       "read,write",
+		"oauth2Audience":     "api",
+	}
+	invoke := func(hs bindings.OutputBinding, md map[string]string) string {
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: md})
+		require.NoError(t, err)
+		return string(res.Data)
+	}
+
+	t.Run("token is cached", func(t *testing.T) {
+		tokenRequests.Store(0)
+		hs, err := InitBinding(s, props)
+		require.NoError(t, err)
+		// Tokens are acquired lazily
+		assert.Equal(t, int32(0), tokenRequests.Load())
+		assert.Equal(t, "Bearer token-1", invoke(hs, nil))
+		assert.Equal(t, "Bearer token-1", invoke(hs, nil))
+		assert.Equal(t, int32(1), tokenRequests.Load())
+
+		// The Authorization header in the metadata takes precedence
+		assert.Equal(t, "Bearer mine", invoke(hs, map[string]string{"Authorization": "Bearer mine"}))
+	})
+
+	t.Run("token is refreshed before expiry", func(t *testing.T) {
+		tokenRequests.Store(0)
+		// Tokens expiring within 10s are refreshed
+		expiresIn.Store(5)
+		defer expiresIn.Store(3600)
+		hs, err := InitBinding(s, props)
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer token-1", invoke(hs, nil))
+		assert.Equal(t, "Bearer token-2", invoke(hs, nil))
+	})
+
+	t.Run("token errors", func(t *testing.T) {
+		badProps := maps.Clone(props)
+		badProps["oauth2ClientSecret"] = "wrong"
+		hs, err := InitBinding(s, badProps)
+		require.NoError(t, err)
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.ErrorContains(t, err, "failed to get OAuth2 token")
+	})
+
+	t.Run("requires client ID", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"oauth2TokenURL": tokenServer.URL})
+		require.ErrorContains(t, err, "oauth2ClientID is required")
+	})
+}
+
+func TestSigV4Signing(t *testing.T) {
+	// Verifies the signature by signing the received request again with the same credentials
+	verifier := v4.NewSigner(credentials.NewStaticCredentials("AKID", "SECRET", "SESSION"))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		signTime, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+
+		// Only the headers that were signed are included, as the transport adds some after signing
+		_, signedHeaders, _ := strings.Cut(auth, "SignedHeaders=")
+		signedHeaders, _, _ = strings.Cut(signedHeaders, ",")
+		check, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+		for _, name := range strings.Split(signedHeaders, ";") {
+			if v := r.Header.Values(name); len(v) > 0 && name != "host" {
+				check.Header[http.CanonicalHeaderKey(name)] = v
+			}
+		}
+		check.ContentLength = r.ContentLength
+		_, err = verifier.Sign(check, bytes.NewReader(body), "execute-api", "us-east-1", signTime)
+		if err != nil || check.Header.Get("Authorization") != auth {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(auth))
+	}))
+	defer s.Close()
+
+	props := map[string]string{
+		"awsRegion":       "us-east-1",
+		"awsService":      "execute-api",
+		"awsAccessKey":    "AKID",
+		"awsSecretKey":    "SECRET",
+		"awsSessionToken": "SESSION",
+	}
+
+	t.Run("requests are signed", func(t *testing.T) {
+		hs, err := InitBinding(s, props)
+		require.NoError(t, err)
+
+		for _, req := range []*bindings.InvokeRequest{
+			{Operation: "get", Metadata: map[string]string{"path": "/items?b=2&a=1"}},
+			{Operation: "post", Data: []byte(`{"name":"item"}`), Metadata: map[string]string{"Content-Type": "application/json"}},
+		} {
+			res, err := hs.Invoke(context.Background(), req)
+			require.NoError(t, err, req.Operation)
+			assert.True(t, strings.HasPrefix(string(res.Data), "AWS4-HMAC-SHA256 Credential=AKID/"), string(res.Data))
+			assert.Contains(t, string(res.Data), "/us-east-1/execute-api/aws4_request")
+		}
+	})
+
+	t.Run("incomplete configuration", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"awsRegion": "us-east-1"})
+		require.ErrorContains(t, err, "awsRegion and awsService must be set together")
+
+		_, err = InitBinding(s, map[string]string{
+			"awsRegion":      "us-east-1",
+			"awsService":     "es",
+			"oauth2TokenURL": "http://localhost/token",
+			"oauth2ClientID": "client",
+		})
+		require.ErrorContains(t, err, "can't be used together")
+	})
+}
+
+func TestStreaming(t *testing.T) {
+	large := bytes.Repeat([]byte("0123456789"), 1000)
+	var attempts atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/echo":
+			// Fails the first attempt, to verify the body is sent again
+			if attempts.Add(1) == 1 {
+				io.Copy(io.Discard, r.Body)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("X-Content-Length", strconv.FormatInt(r.ContentLength, 10))
+			w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+			w.Write(body)
+		case "/large":
+			w.Write(large)
+		default:
+			w.Write([]byte("small"))
+		}
+	}))
+	defer s.Close()
+
+	t.Run("request body from file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "body"), large, 0o600))
+		attempts.Store(0)
+		hs, err := InitBinding(s, map[string]string{"maxRetries": "1", "streamDirectory": dir})
+		require.NoError(t, err)
+
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "post",
+			Metadata:  map[string]string{"path": "/echo", "bodyFile": "body"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, large, res.Data)
+		assert.Equal(t, strconv.Itoa(len(large)), res.Metadata["X-Content-Length"])
+		assert.Equal(t, "application/octet-stream", res.Metadata["X-Content-Type"])
+		assert.Equal(t, int32(2), attempts.Load())
+
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "post",
+			Data:      []byte("data"),
+			Metadata:  map[string]string{"path": "/echo", "bodyFile": "body"},
+		})
+		require.ErrorContains(t, err, "cannot use bodyFile metadata when the request contains data")
+
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "post",
+			Metadata:  map[string]string{"path": "/echo", "bodyFile": "missing"},
+		})
+		require.ErrorContains(t, err, "invalid bodyFile")
+	})
+
+	t.Run("files must be inside streamDirectory", func(t *testing.T) {
+		dir := t.TempDir()
+		outside := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0o600))
+		require.NoError(t, os.Symlink(filepath.Join(outside, "secret"), filepath.Join(dir, "link")))
+		require.NoError(t, os.Symlink(outside, filepath.Join(dir, "linkdir")))
+		require.NoError(t, os.Symlink(filepath.Join(outside, "new"), filepath.Join(dir, "dangling")))
+
+		hs, err := InitBinding(s, map[string]string{"streamDirectory": dir})
+		require.NoError(t, err)
+
+		for _, name := range []string{
+			filepath.Join(outside, "secret"),
+			"../" + filepath.Base(outside) + "/secret",
+			"sub/../../secret",
+			"link",
+			"linkdir/secret",
+		} {
+			_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{
+				Operation: "post",
+				Metadata:  map[string]string{"path": "/echo", "bodyFile": name},
+			})
+			require.ErrorContains(t, err, "invalid bodyFile", name)
+		}
+		for _, name := range []string{
+			filepath.Join(outside, "out"),
+			"../out",
+			"linkdir/out",
+			"dangling",
+		} {
+			_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{
+				Operation: "get",
+				Metadata:  map[string]string{"responseFile": name},
+			})
+			require.ErrorContains(t, err, "invalid responseFile", name)
+		}
+		entries, err := os.ReadDir(outside)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+
+		// Without streamDirectory, files can't be used at all
+		hs, err = InitBinding(s, nil)
+		require.NoError(t, err)
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "post",
+			Metadata:  map[string]string{"path": "/echo", "bodyFile": "body"},
+		})
+		require.ErrorContains(t, err, "cannot use bodyFile metadata: streamDirectory is not set")
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"responseFile": "out"}})
+		require.ErrorContains(t, err, "cannot use responseFile metadata: streamDirectory is not set")
+	})
+
+	t.Run("responses above the threshold are streamed", func(t *testing.T) {
+		dir := t.TempDir()
+		hs, err := InitBinding(s, map[string]string{"streamResponseThreshold": "1Ki", "streamDirectory": dir})
+		require.NoError(t, err)
+
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		assert.Equal(t, "small", string(res.Data))
+		assert.Empty(t, res.Metadata["responseFile"])
+
+		res, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": "/large"}})
+		require.NoError(t, err)
+		assert.Empty(t, res.Data)
+		assert.Empty(t, res.Metadata["bodyEmpty"])
+		assert.Equal(t, strconv.Itoa(len(large)), res.Metadata["responseSize"])
+		assert.True(t, filepath.IsLocal(res.Metadata["responseFile"]), res.Metadata["responseFile"])
+		written, err := os.ReadFile(filepath.Join(dir, res.Metadata["responseFile"]))
+		require.NoError(t, err)
+		assert.Equal(t, large, written)
+	})
+
+	t.Run("expired responses are deleted", func(t *testing.T) {
+		dir := t.TempDir()
+		// Left behind by a previous run
+		old := filepath.Join(dir, "dapr-http-response-old")
+		require.NoError(t, os.WriteFile(old, large, 0o600))
+		require.NoError(t, os.Chtimes(old, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour)))
+		other := filepath.Join(dir, "other")
+		require.NoError(t, os.WriteFile(other, large, 0o600))
+		require.NoError(t, os.Chtimes(other, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour)))
+
+		hs, err := InitBinding(s, map[string]string{"streamResponseThreshold": "1Ki", "streamDirectory": dir, "streamFileTTL": "100ms"})
+		require.NoError(t, err)
+		defer hs.(*HTTPSource).Close()
+		assert.NoFileExists(t, old)
+		assert.FileExists(t, other)
+
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": "/large"}})
+		require.NoError(t, err)
+		path := filepath.Join(dir, res.Metadata["responseFile"])
+		assert.FileExists(t, path)
+		assert.Eventually(t, func() bool {
+			_, err := os.Stat(path)
+			return errors.Is(err, os.ErrNotExist)
+		}, 5*time.Second, 50*time.Millisecond)
+		assert.FileExists(t, other)
+	})
+
+	t.Run("response written to responseFile", func(t *testing.T) {
+		dir, err := filepath.EvalSymlinks(t.TempDir())
+		require.NoError(t, err)
+		path := filepath.Join(dir, "out")
+		hs, err := InitBinding(s, map[string]string{"streamDirectory": dir})
+		require.NoError(t, err)
+
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"responseFile": "out"}})
+		require.NoError(t, err)
+		assert.Empty(t, res.Data)
+		assert.Equal(t, "out", res.Metadata["responseFile"])
+		assert.Equal(t, "5", res.Metadata["responseSize"])
+		written, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "small", string(written))
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"streamDirectory": filepath.Join(t.TempDir(), "missing")})
+		require.ErrorContains(t, err, "invalid value for streamDirectory")
+
+		_, err = InitBinding(s, map[string]string{"streamResponseThreshold": "1Ki"})
+		require.ErrorContains(t, err, "streamResponseThreshold requires streamDirectory to be set")
+
+		_, err = InitBinding(s, map[string]string{"streamResponseThreshold": "1Ki", "streamDirectory": t.TempDir(), "streamFileTTL": "-1s"})
+		require.ErrorContains(t, err, "invalid value for streamFileTTL")
+
+		_, err = InitBinding(s, map[string]string{"streamResponseThreshold": "1Ki", "streamDirectory": t.TempDir(), "responseHmacSecret": "secret"})
+		require.ErrorContains(t, err, "can't be used together with responseHmacSecret")
+	})
+}
+
+func TestMaxResponseBodySizeExceeded(t *testing.T) {
+	body := strings.Repeat("x", 2048)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// Flushing before writing the body forces chunked encoding, without a Content-Length
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+		}
+		w.Write([]byte(body))
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{"maxResponseBodySize": "1Ki", "responseBodyOverflowPolicy": "fail"})
+	require.NoError(t, err)
+
+	t.Run("content length exceeds limit", func(t *testing.T) {
+		_, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.ErrorIs(t, err, ErrResponseBodyTooLarge)
+		var sizeErr *BodySizeError
+		require.ErrorAs(t, err, &sizeErr)
+		assert.Equal(t, int64(1024), sizeErr.Limit)
+		assert.Equal(t, int64(2048), sizeErr.ContentLength)
+		assert.ErrorContains(t, err, "response has 2048 bytes, exceeding the limit of 1024 bytes")
+	})
+
+	t.Run("chunked body exceeds limit", func(t *testing.T) {
+		_, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": "/chunked"}})
+		require.ErrorIs(t, err, ErrResponseBodyTooLarge)
+		assert.ErrorContains(t, err, "response exceeds the limit of 1024 bytes")
+	})
+
+	t.Run("body at the limit", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"maxResponseBodySize": "2Ki"})
+		require.NoError(t, err)
+		for _, path := range []string{"/", "/chunked"} {
+			res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": path}})
+			require.NoError(t, err, path)
+			assert.Equal(t, body, string(res.Data), path)
+		}
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"responseBodyOverflowPolicy": "ignore"})
+		require.ErrorContains(t, err, "invalid value for responseBodyOverflowPolicy")
+	})
+
+	t.Run("streamed bodies are not limited", func(t *testing.T) {
+		dir := t.TempDir()
+		hs, err := InitBinding(s, map[string]string{"maxResponseBodySize": "1Ki", "responseBodyOverflowPolicy": "fail", "streamResponseThreshold": "512", "streamDirectory": dir})
+		require.NoError(t, err)
+		for _, path := range []string{"/", "/chunked"} {
+			res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": path}})
+			require.NoError(t, err, path)
+			assert.Equal(t, "2048", res.Metadata["responseSize"], path)
+			written, err := os.ReadFile(filepath.Join(dir, res.Metadata["responseFile"]))
+			require.NoError(t, err, path)
+			assert.Equal(t, body, string(written), path)
+		}
+
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"responseFile": "out"}})
+		require.NoError(t, err)
+		assert.Equal(t, "2048", res.Metadata["responseSize"])
+	})
+
+	t.Run("threshold larger than limit", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"maxResponseBodySize": "1Ki", "streamResponseThreshold": "2Ki", "streamDirectory": t.TempDir()})
+		require.ErrorContains(t, err, "invalid value for streamResponseThreshold: must not be larger than maxResponseBodySize")
+	})
+}
+
+func TestCompression(t *testing.T) {
+	const payload = `{"message":"hello, compressed world"}`
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		if r.URL.Path == "/echo" {
+			// Returns the request body as received, with its Content-Encoding
+			w.Header().Set("X-Content-Encoding", r.Header.Get("Content-Encoding"))
+			io.Copy(w, r.Body)
+			return
+		}
+
+		var buf bytes.Buffer
+		var zw io.WriteCloser
+		switch r.URL.Path {
+		case "/gzip":
+			zw = gzip.NewWriter(&buf)
+		case "/deflate":
+			zw = zlib.NewWriter(&buf)
+		case "/rawdeflate":
+			zw, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+		case "/br":
+			zw = brotli.NewWriter(&buf)
+		}
+		zw.Write([]byte(payload))
+		zw.Close()
+		encoding := strings.TrimPrefix(r.URL.Path, "/")
+		if encoding == "rawdeflate" {
+			encoding = "deflate"
+		}
+		w.Header().Set("Content-Encoding", encoding)
+		w.Write(buf.Bytes())
+	}))
+	defer s.Close()
+
+	t.Run("responses are decompressed", func(t *testing.T) {
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		for _, path := range []string{"/gzip", "/deflate", "/rawdeflate", "/br"} {
+			res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": path}})
+			require.NoError(t, err, path)
+			assert.Equal(t, payload, string(res.Data), path)
+			assert.Equal(t, "gzip, deflate, br", res.Metadata["X-Accept-Encoding"], path)
+			assert.Equal(t, strings.TrimPrefix(strings.TrimPrefix(path, "/"), "raw"), res.Metadata["contentEncoding"], path)
+			assert.Empty(t, res.Metadata["Content-Encoding"], path)
+		}
+	})
+
+	t.Run("explicit Accept-Encoding is not decompressed", func(t *testing.T) {
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": "/gzip", "Accept-Encoding": "gzip"}})
+		require.NoError(t, err)
+		assert.Equal(t, "gzip", res.Metadata["Content-Encoding"])
+		assert.Empty(t, res.Metadata["contentEncoding"])
+		zr, err := gzip.NewReader(bytes.NewReader(res.Data))
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, payload, string(decoded))
+	})
+
+	t.Run("maxResponseBodySize applies to the decompressed body", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"maxResponseBodySize": "10", "responseBodyOverflowPolicy": "fail"})
+		require.NoError(t, err)
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": "/gzip"}})
+		require.ErrorIs(t, err, ErrResponseBodyTooLarge)
+	})
+
+	t.Run("request payload is compressed", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"compressPayload": "true"})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "post",
+			Data:      []byte(payload),
+			Metadata:  map[string]string{"path": "/echo"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "gzip", res.Metadata["X-Content-Encoding"])
+		zr, err := gzip.NewReader(bytes.NewReader(res.Data))
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, payload, string(decoded))
+
+		// Disabled per request
+		res, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "post",
+			Data:      []byte(payload),
+			Metadata:  map[string]string{"path": "/echo", "compressPayload": "false"},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, res.Metadata["X-Content-Encoding"])
+		assert.Equal(t, payload, string(res.Data))
+	})
+}
+
+func TestUnixSocket(t *testing.T) {
+	// Socket paths are limited to about 100 characters, so t.TempDir() can't always be used
+	dir, err := os.MkdirTemp("", "dapr-http")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "test.sock")
+
+	l, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Method + " " + r.URL.RequestURI()))
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	initBinding := func(props map[string]string) (bindings.OutputBinding, error) {
+		hs := NewHTTP(logger.NewLogger("test"))
+		err := hs.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: props}})
+		return hs, err
+	}
+
+	t.Run("unix scheme", func(t *testing.T) {
+		hs, err := initBinding(map[string]string{"url": "unix://" + socketPath})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "get",
+			Metadata:  map[string]string{"path": "/v1.43/containers/json?all=1"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "GET /v1.43/containers/json?all=1", string(res.Data))
+	})
+
+	t.Run("socketPath", func(t *testing.T) {
+		hs, err := initBinding(map[string]string{"url": "http://docker/v1.43", "socketPath": socketPath})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "post",
+			Metadata:  map[string]string{"path": "/containers/create"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "POST /v1.43/containers/create", string(res.Data))
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := initBinding(map[string]string{"url": "unix://"})
+		require.ErrorContains(t, err, "requires the path of the socket")
+
+		_, err = initBinding(map[string]string{"url": "unix://" + socketPath, "socketPath": socketPath})
+		require.ErrorContains(t, err, "socketPath can't be set")
+
+		_, err = initBinding(map[string]string{"url": "http://localhost", "socketPath": socketPath, "proxyURL": "http://proxy:3128"})
+		require.ErrorContains(t, err, "proxyURL can't be used with a Unix domain socket")
+	})
+}
+
+func TestRequestProtocolHTTP2(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})
+	invoke := func(t *testing.T, hs bindings.OutputBinding) (string, error) {
+		t.Helper()
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "post", Data: []byte("{}")})
+		if err != nil {
+			return "", err
+		}
+		return string(res.Data), nil
+	}
+	initTLSBinding := func(t *testing.T, s *httptest.Server) (bindings.OutputBinding, error) {
+		t.Helper()
+		ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw})
+		return InitBinding(s, map[string]string{"requestProtocol": "2", "MTLSRootCA": string(ca)})
+	}
+
+	t.Run("h2c", func(t *testing.T) {
+		s := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+		defer s.Close()
+		hs, err := InitBinding(s, map[string]string{"requestProtocol": "2"})
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			proto, err := invoke(t, hs)
+			require.NoError(t, err)
+			assert.Equal(t, "HTTP/2.0", proto)
+		}
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		s := httptest.NewUnstartedServer(handler)
+		s.EnableHTTP2 = true
+		s.StartTLS()
+		defer s.Close()
+		hs, err := initTLSBinding(t, s)
+		require.NoError(t, err)
+		proto, err := invoke(t, hs)
+		require.NoError(t, err)
+		assert.Equal(t, "HTTP/2.0", proto)
+	})
+
+	t.Run("server without HTTP/2 support", func(t *testing.T) {
+		s := httptest.NewTLSServer(handler)
+		defer s.Close()
+		hs, err := initTLSBinding(t, s)
+		require.NoError(t, err)
+		_, err = invoke(t, hs)
+		require.ErrorContains(t, err, "doesn't support HTTP/2")
+
+		s2 := httptest.NewServer(handler)
+		defer s2.Close()
+		hs, err = InitBinding(s2, map[string]string{"requestProtocol": "2"})
+		require.NoError(t, err)
+		_, err = invoke(t, hs)
+		require.Error(t, err)
+	})
+
+	t.Run("proxy not supported", func(t *testing.T) {
+		s := httptest.NewServer(handler)
+		defer s.Close()
+		_, err := InitBinding(s, map[string]string{"requestProtocol": "2", "proxyURL": "http://proxy:3128"})
+		require.ErrorContains(t, err, "proxyURL is not supported with requestProtocol 2")
+	})
+
+	t.Run("http2MaxConcurrentStreams", func(t *testing.T) {
+		var active, maxActive atomic.Int32
+		s := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := active.Add(1)
+			defer active.Add(-1)
+			for {
+				m := maxActive.Load()
+				if n <= m || maxActive.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			w.Write([]byte(r.Proto))
+		}), &http2.Server{}))
+		defer s.Close()
+		hs, err := InitBinding(s, map[string]string{"requestProtocol": "2", "http2MaxConcurrentStreams": "2"})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				proto, err := invoke(t, hs)
+				assert.NoError(t, err)
+				assert.Equal(t, "HTTP/2.0", proto)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(2), maxActive.Load())
+
+		_, err = InitBinding(s, map[string]string{"http2MaxConcurrentStreams": "2"})
+		require.ErrorContains(t, err, "http2MaxConcurrentStreams requires requestProtocol")
+		_, err = InitBinding(s, map[string]string{"requestProtocol": "2", "http2MaxConcurrentStreams": "-1"})
+		require.ErrorContains(t, err, "invalid value for http2MaxConcurrentStreams")
+	})
+}
+
+func TestAuthType(t *testing.T) {
+	var (
+		requests atomic.Int32
+		nonce    atomic.Value
+		lastNC   atomic.Value
+	)
+	nonce.Store("n1")
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/basic":
+			if user, pass, ok := r.BasicAuth(); ok && user == "user" && pass == "pass" {
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Basic realm="files"`)
+		case "/digest":
+			n := nonce.Load().(string)
+			if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Digest ") {
+				params := parseAuthChallenges([]string{auth})[0].params
+				ha1 := md5.Sum([]byte("user:vault:pass"))
+				ha2 := md5.Sum([]byte(r.Method + ":" + r.URL.RequestURI()))
+				expected := md5.Sum([]byte(hex.EncodeToString(ha1[:]) + ":" + n + ":" + params["nc"] + ":" + params["cnonce"] + ":auth:" + hex.EncodeToString(ha2[:])))
+				if params["response"] == hex.EncodeToString(expected[:]) && params["nonce"] == n {
+					lastNC.Store(params["nc"])
+					return
+				}
+			}
+			w.Header().Set("WWW-Authenticate", `Digest realm="vault", qop="auth", nonce="`+n+`"`)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer s.Close()
+
+	invoke := func(hs bindings.OutputBinding, path string) (*bindings.InvokeResponse, error) {
+		return hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": path}})
+	}
+
+	t.Run("basic", func(t *testing.T) {
+		requests.Store(0)
+		hs, err := InitBinding(s, map[string]string{"authType": "basic", "username": "user", "password": "pass"})
+		require.NoError(t, err)
+		_, err = invoke(hs, "/basic")
+		require.NoError(t, err)
+		// Credentials are sent without waiting for a challenge
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("digest", func(t *testing.T) {
+		requests.Store(0)
+		hs, err := InitBinding(s, map[string]string{"authType": "digest", "username": "user", "password": "pass"})
+		require.NoError(t, err)
+
+		// The first request receives the challenge
+		res, err := invoke(hs, "/digest")
+		require.NoError(t, err)
+		assert.Equal(t, "digest", res.Metadata["authRetryScheme"])
+		assert.Equal(t, int32(2), requests.Load())
+		assert.Equal(t, "00000001", lastNC.Load())
+
+		// The following requests reuse the nonce
+		res, err = invoke(hs, "/digest")
+		require.NoError(t, err)
+		assert.Empty(t, res.Metadata["authRetryScheme"])
+		assert.Equal(t, int32(3), requests.Load())
+		assert.Equal(t, "00000002", lastNC.Load())
+
+		// A new challenge is answered when the nonce changes
+		nonce.Store("n2")
+		res, err = invoke(hs, "/digest")
+		require.NoError(t, err)
+		assert.Equal(t, "digest", res.Metadata["authRetryScheme"])
+		assert.Equal(t, int32(5), requests.Load())
+		assert.Equal(t, "00000001", lastNC.Load())
+
+		// Other challenges are not answered without adaptiveAuth
+		_, err = invoke(hs, "/basic")
+		require.ErrorContains(t, err, "received status code 401")
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"authType": "hoba", "username": "user"})
+		require.ErrorContains(t, err, "invalid value for authType")
+
+		_, err = InitBinding(s, map[string]string{"authType": "basic"})
+		require.ErrorContains(t, err, "username is required")
+
+		_, err = InitBinding(s, map[string]string{"authType": "basic", "username": "user", "authUsername": "other"})
+		require.ErrorContains(t, err, "can't be set to different values")
+	})
+}
+
+func TestMultipartOperation(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Returns a summary of the parts
+		mr, err := r.MultipartReader()
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, "%s\n", r.Method)
+		for {
+			p, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			content, _ := io.ReadAll(p)
+			fmt.Fprintf(w, "%s|%s|%s|%s\n", p.FormName(), p.FileName(), p.Header.Get("Content-Type"), content)
+		}
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, nil)
+	require.NoError(t, err)
+
+	t.Run("fields and files", func(t *testing.T) {
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: multipartOperation,
+			Data: []byte(`[
+				{"name": "description", "value": "monthly \"report\""},
+				{"name": "attachment", "data": "aGVsbG8=", "filename": "hello.txt", "contentType": "text/plain"},
+				{"name": "report", "data": "YSxiCjEsMg==", "filename": "report.csv", "contentType": "text/csv"},
+				{"name": "raw", "data": "AAE="}
+			]`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "POST\n"+
+			"description|||monthly \"report\"\n"+
+			"attachment|hello.txt|text/plain|hello\n"+
+			"report|report.csv|text/csv|a,b\n1,2\n"+
+			"raw|raw|application/octet-stream|\x00\x01\n", string(res.Data))
+	})
+
+	t.Run("invalid parts", func(t *testing.T) {
+		for data, msg := range map[string]string{
+			`{"name": "x"}`:    "must be a JSON array of parts",
+			`[]`:               "no parts",
+			`[{"value": "x"}]`: "name is required",
+			`[{"name": "x"}]`:  "exactly one of value and data must be set",
+			`[{"name": "x", "value": "", "data": ""}]`: "exactly one of value and data must be set",
+		} {
+			_, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: multipartOperation, Data: []byte(data)})
+			require.ErrorContains(t, err, msg, data)
+		}
+	})
+
+	t.Run("body is sent again on retries", func(t *testing.T) {
+		handler := &flakyHandler{failures: 1, statusCode: http.StatusServiceUnavailable}
+		s := httptest.NewServer(handler)
+		defer s.Close()
+		hs, err := InitBinding(s, map[string]string{"maxRetries": "1"})
+		require.NoError(t, err)
+
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: multipartOperation,
+			Data:      []byte(`[{"name": "field", "value": "hello"}]`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), handler.calls.Load())
+		assert.Contains(t, string(res.Data), "Content-Disposition: form-data; name=\"field\"\r\n\r\nhello\r\n")
+	})
+}
+
+func TestRedirectSettings(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// /hop/N redirects to /hop/N-1, until /hop/0
+		n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hop/"))
+		if err != nil || n == 0 {
+			w.Write([]byte("done"))
+			return
+		}
+		http.Redirect(w, r, "/hop/"+strconv.Itoa(n-1), http.StatusFound)
+	}))
+	defer s.Close()
+
+	invoke := func(hs bindings.OutputBinding, hops int) (*bindings.InvokeResponse, error) {
+		return hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: map[string]string{"path": "/hop/" + strconv.Itoa(hops)}})
+	}
+
+	t.Run("followRedirects false returns the redirect", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"followRedirects": "false"})
+		require.NoError(t, err)
+		res, err := invoke(hs, 2)
+		require.NoError(t, err)
+		assert.Equal(t, "302", res.Metadata["statusCode"])
+		assert.Equal(t, "/hop/1", res.Metadata["Location"])
+		assert.Equal(t, s.URL+"/hop/1", res.Metadata["redirectLocation"])
+		assert.Equal(t, "0", res.Metadata["redirectCount"])
+	})
+
+	t.Run("maxRedirects", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"maxRedirects": "2"})
+		require.NoError(t, err)
+		res, err := invoke(hs, 2)
+		require.NoError(t, err)
+		assert.Equal(t, "done", string(res.Data))
+		assert.Equal(t, "2", res.Metadata["redirectCount"])
+
+		_, err = invoke(hs, 3)
+		require.ErrorContains(t, err, "stopped after 2 redirects")
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"maxRedirects": "-1"})
+		require.ErrorContains(t, err, "invalid value for maxRedirects")
+
+		_, err = InitBinding(s, map[string]string{"followRedirects": "false", "redirectPolicy": "sameHost"})
+		require.ErrorContains(t, err, "followRedirects can't be false")
+	})
+}
+
+func TestCircuitBreakerAliases(t *testing.T) {
+	var requests atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	hs, err := InitBinding(s, map[string]string{
+		"cbFailureThreshold": "2",
+		"cbOpenDuration":     "1m",
+		"cbHalfOpenRequests": "3",
+	})
+	require.NoError(t, err)
+	cb := hs.(*HTTPSource).circuitBreaker
+	require.NotNil(t, cb)
+	assert.Equal(t, time.Minute, cb.openDuration)
+	assert.Equal(t, 3, cb.halfOpenMaxConcurrent)
+
+	for i := 0; i < 2; i++ {
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.ErrorContains(t, err, "received status code 503")
+	}
+
+	// The circuit is open, so the request fails fast with a distinct error code
+	res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	var circuitErr *CircuitOpenError
+	require.ErrorAs(t, err, &circuitErr)
+	assert.Greater(t, circuitErr.RetryAfter, 50*time.Second)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, ErrorCodeCircuitOpen, res.Metadata["errorCode"])
+	assert.Equal(t, "open", res.Metadata["circuitBreakerState"])
+	retryAfter, err := strconv.Atoi(res.Metadata["retryAfterMs"])
+	require.NoError(t, err)
+	assert.Greater(t, retryAfter, 50000)
+
+	t.Run("conflicting values", func(t *testing.T) {
+		_, err := InitBinding(s, map[string]string{"cbFailureThreshold": "2", "circuitBreakerFailureThreshold": "3"})
+		require.ErrorContains(t, err, "can't be set to different values")
+	})
+}
+
+func TestQueryParamsAndURLOverride(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host + r.URL.RequestURI()))
+	})
+	s := httptest.NewServer(handler)
+	defer s.Close()
+	other := httptest.NewServer(handler)
+	defer other.Close()
+	otherURL, _ := url.Parse(other.URL)
+
+	invoke := func(hs bindings.OutputBinding, md map[string]string) (string, error) {
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get", Metadata: md})
+		if err != nil {
+			return "", err
+		}
+		return string(res.Data), nil
+	}
+
+	t.Run("queryParams", func(t *testing.T) {
+		hs := NewHTTP(logger.NewLogger("test"))
+		err := hs.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{"url": s.URL + "/items?page=1&sort=name"}}})
+		require.NoError(t, err)
+		host := strings.TrimPrefix(s.URL, "http://")
+
+		tests := map[string]string{
+			`{"page": "2", "tag": ["a", "b"], "limit": 10, "all": true}`: "/items?all=true&limit=10&page=2&sort=name&tag=a&tag=b",
+			"page=3&q=a+b":  "/items?page=3&q=a+b&sort=name",
+			"?filter=x%26y": "/items?filter=x%26y&page=1&sort=name",
+		}
+		for params, expected := range tests {
+			res, err := invoke(hs, map[string]string{"queryParams": params})
+			require.NoError(t, err, params)
+			assert.Equal(t, host+expected, res, params)
+		}
+
+		_, err = invoke(hs, map[string]string{"queryParams": `{"a": {"b": 1}}`})
+		require.ErrorContains(t, err, "invalid value for queryParams")
+	})
+
+	t.Run("urlOverride", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"urlOverrideAllowedHosts": "api.example.com," + otherURL.Host})
+		require.NoError(t, err)
+
+		res, err := invoke(hs, map[string]string{"urlOverride": other.URL + "/v2", "path": "/users"})
+		require.NoError(t, err)
+		assert.Equal(t, otherURL.Host+"/v2/users", res)
+
+		// The port must match when the allowed host includes one
+		_, err = invoke(hs, map[string]string{"urlOverride": "http://" + otherURL.Hostname() + ":1/"})
+		require.ErrorContains(t, err, "is not in urlOverrideAllowedHosts")
+
+		_, err = invoke(hs, map[string]string{"urlOverride": "ftp://api.example.com/"})
+		require.ErrorContains(t, err, "unsupported scheme")
+	})
+
+	t.Run("urlOverride requires an allowlist", func(t *testing.T) {
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		_, err = invoke(hs, map[string]string{"urlOverride": other.URL})
+		require.ErrorContains(t, err, "urlOverrideAllowedHosts is not set")
+	})
+
+	t.Run("host matching", func(t *testing.T) {
+		allowed := []string{"*.example.com", "API.internal", "localhost:8080"}
+		for host, expected := range map[string]bool{
+			"a.example.com":     true,
+			"a.b.example.com":   true,
+			"example.com":       false,
+			"badexample.com":    false,
+			"api.internal:9000": true,
+			"localhost:8080":    true,
+			"localhost:8081":    false,
+		} {
+			assert.Equal(t, expected, isHostAllowed(&url.URL{Host: host}, allowed), host)
+		}
+	})
+}
+
+func TestMTLSClientCertReload(t *testing.T) {
+	newCert := func(t *testing.T, org string) ([]byte, []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      pkix.Name{Organization: []string{org}},
+			NotBefore:    time.Now().Add(-1 * time.Hour),
+			NotAfter:     time.Now().Add(24 * time.Hour),
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+		require.NoError(t, err)
+		keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})
+	}
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	// Files are written with increasing modification times, as rotations in the same clock tick may otherwise go unnoticed
+	mtime := time.Now().Add(-1 * time.Hour)
+	writeFiles := func(t *testing.T, cert []byte, key []byte) {
+		mtime = mtime.Add(time.Minute)
+		require.NoError(t, os.WriteFile(certPath, cert, 0o600))
+		require.NoError(t, os.WriteFile(keyPath, key, 0o600))
+		require.NoError(t, os.Chtimes(certPath, mtime, mtime))
+		require.NoError(t, os.Chtimes(keyPath, mtime, mtime))
+	}
+
+	// The server closes every connection, so each request performs a new handshake
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.Organization[0]))
+	}))
+	serverCert, err := tls.LoadX509KeyPair(filepath.Join(".", "testdata", "server.pem"), filepath.Join(".", "testdata", "server.key"))
+	require.NoError(t, err)
+	s.TLS = &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		ClientAuth:   tls.RequireAnyClientCert,
+		Certificates: []tls.Certificate{serverCert},
+	}
+	s.StartTLS()
+	defer s.Close()
+
+	invoke := func(t *testing.T, hs bindings.OutputBinding) string {
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.NoError(t, err)
+		return string(res.Data)
+	}
+
+	certA, keyA := newCert(t, "a")
+	certB, keyB := newCert(t, "b")
+	certC, keyC := newCert(t, "c")
+
+	t.Run("rotated certificate is used", func(t *testing.T) {
+		writeFiles(t, certA, keyA)
+		hs, err := InitBindingForHTTPS(s, map[string]string{
+			"MTLSRootCA":                   filepath.Join(".", "testdata", "ca.pem"),
+			"MTLSClientCert":               certPath,
+			"MTLSClientKey":                keyPath,
+			"mtlsClientCertReloadInterval": "1ns",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "a", invoke(t, hs))
+
+		writeFiles(t, certB, keyB)
+		assert.Equal(t, "b", invoke(t, hs))
+
+		// A certificate that doesn't match the key is ignored, and the previous one is kept
+		writeFiles(t, certC, keyB)
+		assert.Equal(t, "b", invoke(t, hs))
+		writeFiles(t, certC, keyC)
+		assert.Equal(t, "c", invoke(t, hs))
+	})
+
+	t.Run("reloading disabled", func(t *testing.T) {
+		writeFiles(t, certA, keyA)
+		hs, err := InitBindingForHTTPS(s, map[string]string{
+			"MTLSRootCA":                   filepath.Join(".", "testdata", "ca.pem"),
+			"MTLSClientCert":               certPath,
+			"MTLSClientKey":                keyPath,
+			"mtlsClientCertReloadInterval": "0",
+		})
+		require.NoError(t, err)
+		writeFiles(t, certB, keyB)
+		assert.Equal(t, "a", invoke(t, hs))
+	})
+
+	t.Run("expired certificate is reloaded before the interval", func(t *testing.T) {
+		writeFiles(t, certA, keyA)
+		r, err := newClientCertReloader(certPath, keyPath, time.Hour, logger.NewLogger("test"))
+		require.NoError(t, err)
+		now := time.Now()
+		r.now = func() time.Time { return now }
+
+		writeFiles(t, certB, keyB)
+		cert, err := r.getClientCertificate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "a", cert.Leaf.Subject.Organization[0])
+
+		now = now.Add(48 * time.Hour)
+		cert, err = r.getClientCertificate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "b", cert.Leaf.Subject.Organization[0])
+	})
+}
+
+func TestTLSOptions(t *testing.T) {
+	s := setupHTTPSServer(t, false, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(tls.VersionName(r.TLS.Version) + " " + tls.CipherSuiteName(r.TLS.CipherSuite) + " " + r.TLS.ServerName))
+	}))
+	defer s.Close()
+	rootCA := filepath.Join(".", "testdata", "ca.pem")
+
+	invoke := func(t *testing.T, props map[string]string) (string, error) {
+		hs, err := InitBindingForHTTPS(s, props)
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		if err != nil {
+			return "", err
+		}
+		return string(res.Data), nil
+	}
+
+	t.Run("versions and cipher suites", func(t *testing.T) {
+		res, err := invoke(t, map[string]string{"MTLSRootCA": rootCA, "tlsMinVersion": "1.3"})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(res, "TLS 1.3 "), res)
+
+		res, err = invoke(t, map[string]string{
+			"MTLSRootCA":      rootCA,
+			"tlsMaxVersion":   "TLS1.2",
+			"tlsCipherSuites": "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "TLS 1.2 TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384 ", res)
+	})
+
+	t.Run("server name", func(t *testing.T) {
+		// The certificate isn't valid for the server name, so verification fails unless it's skipped
+		_, err := invoke(t, map[string]string{"MTLSRootCA": rootCA, "serverName": "api.example.com"})
+		require.ErrorContains(t, err, "api.example.com")
+
+		res, err := invoke(t, map[string]string{"serverName": "api.example.com", "insecureSkipVerify": "true"})
+		require.NoError(t, err)
+		assert.True(t, strings.HasSuffix(res, " api.example.com"), res)
+	})
+
+	t.Run("insecureSkipVerify", func(t *testing.T) {
+		_, err := invoke(t, nil)
+		require.Error(t, err)
+		_, err = invoke(t, map[string]string{"insecureSkipVerify": "true"})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		tests := map[string]struct {
+			props map[string]string
+			err   string
+		}{
+			"min version":         {props: map[string]string{"tlsMinVersion": "1.4"}, err: "invalid value for tlsMinVersion"},
+			"max version":         {props: map[string]string{"tlsMaxVersion": "ssl3"}, err: "invalid value for tlsMaxVersion"},
+			"max lower than min":  {props: map[string]string{"tlsMinVersion": "1.3", "tlsMaxVersion": "1.2"}, err: "is lower than the minimum TLS version"},
+			"cipher suite":        {props: map[string]string{"tlsCipherSuites": "TLS_FOO"}, err: "unknown cipher suite"},
+			"conflicting options": {props: map[string]string{"insecureSkipVerify": "true", "tlsVerifyMode": "full"}, err: "insecureSkipVerify cannot be used"},
+		}
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := InitBindingForHTTPS(s, tc.props)
+				require.ErrorContains(t, err, tc.err)
+			})
+		}
+	})
+}
+
+func TestRequestHMACSigning(t *testing.T) {
+	const secret = "s3cr3t"
+	sign := func(msg string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(msg))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	// The server verifies the Slack-style signature, and returns the headers it received
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts := r.Header.Get("X-Timestamp")
+		w.Header().Set("X-Signature", r.Header.Get("X-Signature"))
+		w.Header().Set("X-Valid", strconv.FormatBool(r.Header.Get("X-Signature") == "v0="+sign("v0:"+ts+":"+string(body))))
+		w.Header().Set("X-Timestamp", ts)
+	}))
+	defer s.Close()
+
+	invoke := func(t *testing.T, props map[string]string, data string) *bindings.InvokeResponse {
+		hs, err := InitBinding(s, props)
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "post", Data: []byte(data)})
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("body", func(t *testing.T) {
+		res := invoke(t, map[string]string{"requestHmacSecret": secret, "requestHmacSignatureFormat": "sha256={signature}"}, `{"a":1}`)
+		assert.Equal(t, "sha256="+sign(`{"a":1}`), res.Metadata["X-Signature"])
+	})
+
+	t.Run("timestamp and body", func(t *testing.T) {
+		res := invoke(t, map[string]string{
+			"requestHmacSecret":          secret,
+			"requestHmacFormat":          "timestamp.body",
+			"requestHmacSignatureFormat": "t={timestamp},v1={signature}",
+		}, "hello")
+		ts, sig, ok := strings.Cut(strings.TrimPrefix(res.Metadata["X-Signature"], "t="), ",v1=")
+		require.True(t, ok, res.Metadata["X-Signature"])
+		assert.Equal(t, sign(ts+".hello"), sig)
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		require.NoError(t, err)
+		assert.InDelta(t, time.Now().Unix(), sec, 60)
+	})
+
+	t.Run("template and timestamp header", func(t *testing.T) {
+		res := invoke(t, map[string]string{
+			"requestHmacSecret":          secret,
+			"requestHmacFormat":          "v0:{timestamp}:{body}",
+			"requestHmacSignatureFormat": "v0={signature}",
+			"requestHmacTimestampHeader": "X-Timestamp",
+		}, "hello")
+		assert.Equal(t, "true", res.Metadata["X-Valid"])
+		assert.NotEmpty(t, res.Metadata["X-Timestamp"])
+	})
+
+	t.Run("base64 and empty body", func(t *testing.T) {
+		res := invoke(t, map[string]string{"requestHmacSecret": secret, "requestHmacEncoding": "base64"}, "")
+		mac := hmac.New(sha256.New, []byte(secret))
+		assert.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), res.Metadata["X-Signature"])
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		tests := map[string]struct {
+			props map[string]string
+			err   string
+		}{
+			"algorithm":        {props: map[string]string{"requestHmacAlgorithm": "md5"}, err: "invalid value for requestHmacAlgorithm"},
+			"encoding":         {props: map[string]string{"requestHmacEncoding": "base32"}, err: "invalid value for requestHmacEncoding"},
+			"format":           {props: map[string]string{"requestHmacFormat": "{timestamp}"}, err: "invalid value for requestHmacFormat"},
+			"signature format": {props: map[string]string{"requestHmacSignatureFormat": "sha256="}, err: "invalid value for requestHmacSignatureFormat"},
+		}
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				tc.props["requestHmacSecret"] = secret
+				_, err := InitBinding(s, tc.props)
+				require.ErrorContains(t, err, tc.err)
+			})
+		}
+	})
+}
+
+func TestRequestQueueTimeout(t *testing.T) {
+	t.Run("rate limiter", func(t *testing.T) {
+		l, err := newRateLimiter(10, 1, 0, 50*time.Millisecond)
+		require.NoError(t, err)
+		require.NoError(t, l.wait(context.Background(), ""))
+		// The next token is available after 100ms, which is more than the timeout
+		err = l.wait(context.Background(), "")
+		require.ErrorIs(t, err, ErrRateLimitedLocally)
+
+		// The rejected request didn't consume a token
+		l, err = newRateLimiter(10, 1, 0, 150*time.Millisecond)
+		require.NoError(t, err)
+		require.NoError(t, l.wait(context.Background(), ""))
+		require.NoError(t, l.wait(context.Background(), ""))
+	})
+
+	t.Run("request queue", func(t *testing.T) {
+		q := newRequestQueue(1, 1, 20*time.Millisecond)
+		_, err := q.acquire(context.Background())
+		require.NoError(t, err)
+		stats, err := q.acquire(context.Background())
+		require.ErrorIs(t, err, ErrRateLimitedLocally)
+		assert.GreaterOrEqual(t, stats.wait, 20*time.Millisecond)
+		assert.Equal(t, 0, q.waiters.Len())
+		q.release()
+		assert.Equal(t, 0, q.active)
+	})
+
+	t.Run("invoke", func(t *testing.T) {
+		release := make(chan struct{})
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer s.Close()
+		defer close(release)
+
+		hs, err := InitBinding(s, map[string]string{
+			"maxConcurrentRequests": "1",
+			"requestQueueSize":      "1",
+			"requestQueueTimeout":   "20ms",
+		})
+		require.NoError(t, err)
+		go hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.Eventually(t, func() bool {
+			q := hs.(*HTTPSource).requestQueue
+			q.lock.Lock()
+			defer q.lock.Unlock()
+			return q.active == 1
+		}, 5*time.Second, time.Millisecond)
+
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.ErrorIs(t, err, ErrRateLimitedLocally)
+		require.ErrorContains(t, err, "rate limited locally")
+
+		_, err = InitBinding(s, map[string]string{"requestQueueTimeout": "-1s"})
+		require.ErrorContains(t, err, "invalid value for requestQueueTimeout")
+	})
+}
+
+func TestHostAliasesAndDNSServer(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host))
+	}))
+	defer s.Close()
+	serverURL, _ := url.Parse(s.URL)
+	port := serverURL.Port()
+
+	invoke := func(t *testing.T, props map[string]string) (string, error) {
+		hs, err := InitBinding(s, props)
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		if err != nil {
+			return "", err
+		}
+		return string(res.Data), nil
+	}
+
+	t.Run("host aliases", func(t *testing.T) {
+		for _, aliases := range []string{"other.test=10.0.0.1, API.test=127.0.0.1", `{"api.test": "127.0.0.1"}`} {
+			res, err := invoke(t, map[string]string{"url": "http://api.test:" + port + "/", "hostAliases": aliases})
+			require.NoError(t, err, aliases)
+			assert.Equal(t, "api.test:"+port, res)
+		}
+	})
+
+	t.Run("dns server", func(t *testing.T) {
+		// Minimal DNS server that answers A queries for api.test with the loopback address
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer pc.Close()
+		var queries atomic.Int32
+		go func() {
+			buf := make([]byte, 512)
+			for {
+				n, addr, err := pc.ReadFrom(buf)
+				if err != nil {
+					return
+				}
+				var msg dnsmessage.Message
+				if msg.Unpack(buf[:n]) != nil || len(msg.Questions) == 0 {
+					continue
+				}
+				queries.Add(1)
+				q := msg.Questions[0]
+				msg.Header.Response = true
+				msg.Header.Authoritative = true
+				if q.Name.String() != "api.test." {
+					msg.Header.RCode = dnsmessage.RCodeNameError
+				} else if q.Type == dnsmessage.TypeA {
+					msg.Answers = []dnsmessage.Resource{{
+						Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+						Body:   &dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}},
+					}}
+				}
+				out, err := msg.Pack()
+				if err == nil {
+					pc.WriteTo(out, addr)
+				}
+			}
+		}()
+
+		res, err := invoke(t, map[string]string{"url": "http://api.test:" + port + "/", "dnsServer": pc.LocalAddr().String()})
+		require.NoError(t, err)
+		assert.Equal(t, "api.test:"+port, res)
+		assert.Positive(t, queries.Load())
+
+		_, err = invoke(t, map[string]string{"url": "http://unknown.test:" + port + "/", "dnsServer": pc.LocalAddr().String()})
+		var dnsErr *net.DNSError
+		require.ErrorAs(t, err, &dnsErr)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		tests := map[string]struct {
+			props map[string]string
+			err   string
+		}{
+			"alias format":      {props: map[string]string{"hostAliases": "api.test"}, err: "invalid value for hostAliases"},
+			"alias IP":          {props: map[string]string{"hostAliases": "api.test=example.com"}, err: "is not a valid IP address"},
+			"alias JSON":        {props: map[string]string{"hostAliases": "{"}, err: "invalid value for hostAliases"},
+			"dns server":        {props: map[string]string{"dnsServer": "dns.example.com:53"}, err: "invalid value for dnsServer"},
+			"alias with socket": {props: map[string]string{"hostAliases": "a=127.0.0.1", "socketPath": "/tmp/x.sock"}, err: "Unix domain socket"},
+		}
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := InitBinding(s, tc.props)
+				require.ErrorContains(t, err, tc.err)
+			})
+		}
+	})
+
+	t.Run("dns server address", func(t *testing.T) {
+		for val, expected := range map[string]string{
+			"10.0.0.1":       "10.0.0.1:53",
+			"10.0.0.1:5353":  "10.0.0.1:5353",
+			"[fd00::1]:5353": "[fd00::1]:5353",
+			"fd00::1":        "[fd00::1]:53",
+		} {
+			addr, err := normalizeDNSServer(val)
+			require.NoError(t, err, val)
+			assert.Equal(t, expected, addr, val)
+		}
+	})
+}
+
+func TestNTLMAuth(t *testing.T) {
+	utf16LE := func(s string) []byte {
+		u := utf16.Encode([]rune(s))
+		b := make([]byte, 2*len(u))
+		for i, c := range u {
+			binary.LittleEndian.PutUint16(b[2*i:], c)
+		}
+		return b
+	}
+	// NTOWFv2 from MS-NLMP, to verify the response of the client
+	ntowfv2 := func(user string, password string, domain string) []byte {
+		h := md4.New()
+		h.Write(utf16LE(password))
+		mac := hmac.New(md5.New, h.Sum(nil))
+		mac.Write(utf16LE(strings.ToUpper(user) + domain))
+		return mac.Sum(nil)
+	}
+	// Unicode, NTLM, extended session security, target info, 128-bit and 56-bit encryption
+	const ntlmChallengeFlags = 0x00000001 | 0x00000200 | 0x00080000 | 0x00800000 | 0x20000000 | 0x80000000
+
+	// The server performs the NTLM handshake, which must complete on the same connection
+	const password = "Passw0rd"
+	serverChallenge := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	var lock sync.Mutex
+	negotiated := map[string]bool{}
+	challengeMsg := func() []byte {
+		targetName := utf16LE("DOMAIN")
+		targetInfo := []byte{2, 0, 12, 0}
+		targetInfo = append(targetInfo, targetName...)
+		targetInfo = append(targetInfo, 7, 0, 8, 0)
+		targetInfo = binary.LittleEndian.AppendUint64(targetInfo, uint64(time.Now().UnixNano()/100+116444736000000000))
+		targetInfo = append(targetInfo, 0, 0, 0, 0)
+		msg := make([]byte, 48, 48+len(targetName)+len(targetInfo))
+		copy(msg, "NTLMSSP\x00")
+		binary.LittleEndian.PutUint32(msg[8:], 2)
+		binary.LittleEndian.PutUint16(msg[12:], uint16(len(targetName)))
+		binary.LittleEndian.PutUint16(msg[14:], uint16(len(targetName)))
+		binary.LittleEndian.PutUint32(msg[16:], 48)
+		binary.LittleEndian.PutUint32(msg[20:], ntlmChallengeFlags)
+		copy(msg[24:], serverChallenge)
+		binary.LittleEndian.PutUint16(msg[40:], uint16(len(targetInfo)))
+		binary.LittleEndian.PutUint16(msg[42:], uint16(len(targetInfo)))
+		binary.LittleEndian.PutUint32(msg[44:], uint32(48+len(targetName)))
+		msg = append(msg, targetName...)
+		return append(msg, targetInfo...)
+	}
+	field := func(msg []byte, pos int) []byte {
+		l := binary.LittleEndian.Uint16(msg[pos:])
+		offset := binary.LittleEndian.Uint32(msg[pos+4:])
+		return msg[offset : offset+uint32(l)]
+	}
+	fromUTF16 := func(b []byte) string {
+		u := make([]uint16, len(b)/2)
+		for i := range u {
+			u[i] = binary.LittleEndian.Uint16(b[2*i:])
+		}
+		return string(utf16.Decode(u))
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "NTLM ")
+		msg, _ := base64.StdEncoding.DecodeString(token)
+		lock.Lock()
+		defer lock.Unlock()
+		switch {
+		case !ok:
+			w.Header().Add("WWW-Authenticate", "Negotiate")
+			w.Header().Add("WWW-Authenticate", "NTLM")
+			w.WriteHeader(http.StatusUnauthorized)
+		case len(msg) >= 12 && binary.LittleEndian.Uint32(msg[8:]) == 1:
+			negotiated[r.RemoteAddr] = true
+			w.Header().Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString(challengeMsg()))
+			w.WriteHeader(http.StatusUnauthorized)
+		case len(msg) >= 64 && binary.LittleEndian.Uint32(msg[8:]) == 3 && negotiated[r.RemoteAddr]:
+			ntResponse := field(msg, 20)
+			domain := fromUTF16(field(msg, 28))
+			user := fromUTF16(field(msg, 36))
+			mac := hmac.New(md5.New, ntowfv2(user, password, domain))
+			mac.Write(serverChallenge)
+			mac.Write(ntResponse[16:])
+			if !hmac.Equal(mac.Sum(nil), ntResponse[:16]) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `%s\%s %s`, domain, user, body)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer s.Close()
+
+	t.Run("handshake", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"authType": "ntlm", "username": `DOMAIN\jdoe`, "password": password})
+		require.NoError(t, err)
+		res, err := hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "post", Data: []byte("hello")})
+		require.NoError(t, err)
+		assert.Equal(t, `DOMAIN\jdoe hello`, string(res.Data))
+		assert.Equal(t, "ntlm", res.Metadata["authRetryScheme"])
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"authType": "ntlm", "username": `DOMAIN\jdoe`, "password": "wrong"})
+		require.NoError(t, err)
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.ErrorContains(t, err, "401")
+	})
+}
+
+func TestNegotiateAuth(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Set("WWW-Authenticate", "Negotiate")
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer s.Close()
+
+	dir := t.TempDir()
+	krb5Conf := filepath.Join(dir, "krb5.conf")
+	// The KDC isn't reachable, so tickets can't be obtained
+	require.NoError(t, os.WriteFile(krb5Conf, []byte(`[libdefaults]
+  default_realm = EXAMPLE.COM
+  dns_lookup_kdc = false
+[realms]
+  EXAMPLE.COM = {
+    kdc = 127.0.0.1:1
+  }
+`), 0o600))
+
+	t.Run("ticket can't be obtained", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{
+			"authType":       "negotiate",
+			"username":       "svc-dapr",
+			"password":       "secret",
+			"kerberosConfig": krb5Conf,
+		})
+		require.NoError(t, err)
+		defer hs.(*HTTPSource).Close()
+		assert.Equal(t, "EXAMPLE.COM", hs.(*HTTPSource).kerberosClient.Credentials.Domain())
+
+		// The 401 response is returned
+		_, err = hs.Invoke(context.Background(), &bindings.InvokeRequest{Operation: "get"})
+		require.ErrorContains(t, err, "401")
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		tests := map[string]struct {
+			props map[string]string
+			err   string
+		}{
+			"no credentials":   {props: map[string]string{"username": "svc", "kerberosConfig": krb5Conf}, err: "kerberosKeytab or password is required"},
+			"missing config":   {props: map[string]string{"username": "svc", "password": "x", "kerberosConfig": filepath.Join(dir, "missing.conf")}, err: "failed to load kerberosConfig"},
+			"missing keytab":   {props: map[string]string{"username": "svc", "kerberosKeytab": filepath.Join(dir, "missing.keytab"), "kerberosConfig": krb5Conf}, err: "failed to load kerberosKeytab"},
+			"missing username": {props: map[string]string{"password": "x", "kerberosConfig": krb5Conf}, err: "username is required"},
+		}
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				tc.props["authType"] = "negotiate"
+				_, err := InitBinding(s, tc.props)
+				require.ErrorContains(t, err, tc.err)
+			})
+		}
+	})
+}
+
+func TestMaintainSession(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123", Path: "/"})
+			return
+		}
+		c, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(c.Value))
+	}))
+	defer s.Close()
+
+	invoke := func(t *testing.T, hs bindings.OutputBinding, path string) (*bindings.InvokeResponse, error) {
+		return hs.Invoke(context.Background(), &bindings.InvokeRequest{
+			Operation: "get",
+			Metadata:  map[string]string{"path": path},
+		})
+	}
+
+	t.Run("cookies are replayed", func(t *testing.T) {
+		hs, err := InitBinding(s, map[string]string{"maintainSession": "true"})
+		require.NoError(t, err)
+		_, err = invoke(t, hs, "/login")
+		require.NoError(t, err)
+		res, err := invoke(t, hs, "/me")
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", string(res.Data))
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		hs, err := InitBinding(s, nil)
+		require.NoError(t, err)
+		_, err = invoke(t, hs, "/login")
+		require.NoError(t, err)
+		_, err = invoke(t, hs, "/me")
+		require.ErrorContains(t, err, "401")
+	})
+
+	t.Run("redirect to the same page after login", func(t *testing.T) {
+		// Without a session, the page redirects to the login, which sets the cookie and redirects back to the page
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c, err := r.Cookie("session")
+			switch {
+			case r.URL.Path == "/login":
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123", Path: "/"})
+				http.Redirect(w, r, "/page", http.StatusFound)
+			case err != nil:
+				http.Redirect(w, r, "/login", http.StatusFound)
+			default:
+				w.Write([]byte(c.Value))
+			}
+		}))
+		defer s.Close()
+
+		hs, err := InitBinding(s, map[string]string{"maintainSession": "true"})
+		require.NoError(t, err)
+		res, err := invoke(t, hs, "/page")
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", string(res.Data))
+		assert.Equal(t, "2", res.Metadata["redirectCount"])
+
+		// Without the cookie jar, the same redirects are a loop
+		hs, err = InitBinding(s, nil)
+		require.NoError(t, err)
+		_, err = invoke(t, hs, "/page")
+		require.ErrorContains(t, err, fmt.Sprintf("redirect loop detected: %[1]s/page -> %[1]s/login -> %[1]s/page", s.URL))
+	})
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// idleReset closes the idle connections of the client when no request succeeded for longer than maxIdle.
+// This avoids sending the first request after a long idle period on a connection that was silently dropped by the server.
+type idleReset struct {
+	maxIdle   time.Duration
+	closeIdle func()
+	now       func() time.Time
+
+	// Time of the last successful request, in Unix nanoseconds
+	lastSuccess atomic.Int64
+}
+
+func newIdleReset(maxIdle time.Duration, closeIdle func()) *idleReset {
+	r := &idleReset{
+		maxIdle:   maxIdle,
+		closeIdle: closeIdle,
+		now:       time.Now,
+	}
+	r.lastSuccess.Store(r.now().UnixNano())
+	return r
+}
+
+// beforeRequest closes the idle connections if the client has been idle for too long.
+// Returns true if the connections were closed.
+func (r *idleReset) beforeRequest() bool {
+	now := r.now().UnixNano()
+	last := r.lastSuccess.Load()
+	if time.Duration(now-last) <= r.maxIdle {
+		return false
+	}
+	// Only one of the concurrent requests resets the connections
+	if !r.lastSuccess.CompareAndSwap(last, now) {
+		return false
+	}
+	r.closeIdle()
+	return true
+}
+
+// recordSuccess records the time of a successful request.
+func (r *idleReset) recordSuccess() {
+	r.lastSuccess.Store(r.now().UnixNano())
+}
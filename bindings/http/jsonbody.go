@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prefix for metadata keys that are assembled into a JSON request body.
+// Keys are in the format "jsonBody.<path>[:<type>]", where path is a dotted path that can contain array indexes (e.g. "items[0].name").
+const jsonBodyMetadataPrefix = "jsonBody."
+
+// Maximum array index allowed in jsonBody paths, to prevent allocating huge arrays.
+const jsonBodyMaxArrayIndex = 1000
+
+// jsonPathElem is an element of a path in a jsonBody metadata key.
+type jsonPathElem struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// buildJSONBody builds a JSON object from the "jsonBody.*" metadata keys.
+// Returns nil if there's no such key.
+func buildJSONBody(md map[string]string) ([]byte, error) {
+	keys := make([]string, 0)
+	for k := range md {
+		if strings.HasPrefix(k, jsonBodyMetadataPrefix) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	// Sort keys so errors are deterministic
+	sort.Strings(keys)
+
+	var root any = map[string]any{}
+	for _, k := range keys {
+		spec := strings.TrimPrefix(k, jsonBodyMetadataPrefix)
+		path, typ, _ := strings.Cut(spec, ":")
+
+		elems, err := parseJSONBodyPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metadata key %q: %w", k, err)
+		}
+		val, err := convertJSONBodyValue(md[k], typ)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metadata key %q: %w", k, err)
+		}
+		root, err = setJSONBodyValue(root, elems, val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metadata key %q: %w", k, err)
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+// parseJSONBodyPath parses a path such as "a.b[0].c" into its elements.
+func parseJSONBodyPath(path string) ([]jsonPathElem, error) {
+	if path == "" {
+		return nil, errors.New("path is empty")
+	}
+
+	res := []jsonPathElem{}
+	for _, part := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name == "" {
+			return nil, errors.New("path contains an empty field name")
+		}
+		res = append(res, jsonPathElem{key: name})
+
+		for rest != "" {
+			idx, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, errors.New("path contains an unterminated array index")
+			}
+			n, err := strconv.Atoi(idx)
+			if err != nil || n < 0 || n > jsonBodyMaxArrayIndex {
+				return nil, fmt.Errorf("path contains an invalid array index %q", idx)
+			}
+			res = append(res, jsonPathElem{index: n, isIndex: true})
+
+			if after == "" {
+				break
+			}
+			if after[0] != '[' {
+				return nil, fmt.Errorf("unexpected characters after array index: %q", after)
+			}
+			rest = after[1:]
+		}
+	}
+	return res, nil
+}
+
+// convertJSONBodyValue converts the value according to the type hint.
+func convertJSONBodyValue(val string, typ string) (any, error) {
+	switch strings.ToLower(typ) {
+	case "", "string":
+		return val, nil
+	case "int":
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not a valid int", val)
+		}
+		return n, nil
+	case "float", "number":
+		n, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not a valid number", val)
+		}
+		return n, nil
+	case "bool":
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not a valid bool", val)
+		}
+		return b, nil
+	case "null":
+		return nil, nil
+	case "json":
+		var v any
+		err := json.Unmarshal([]byte(val), &v)
+		if err != nil {
+			return nil, fmt.Errorf("value is not valid JSON: %w", err)
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %q", typ)
+	}
+}
+
+// setJSONBodyValue sets the value at the path inside the container, returning the (possibly re-allocated) container.
+func setJSONBodyValue(container any, path []jsonPathElem, val any) (any, error) {
+	elem := path[0]
+
+	if elem.isIndex {
+		if container == nil {
+			container = []any{}
+		}
+		arr, ok := container.([]any)
+		if !ok {
+			return nil, errors.New("path conflicts with a value that is not an array")
+		}
+		for len(arr) <= elem.index {
+			arr = append(arr, nil)
+		}
+		if len(path) == 1 {
+			arr[elem.index] = val
+			return arr, nil
+		}
+		child, err := setJSONBodyValue(arr[elem.index], path[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		arr[elem.index] = child
+		return arr, nil
+	}
+
+	if container == nil {
+		container = map[string]any{}
+	}
+	obj, ok := container.(map[string]any)
+	if !ok {
+		return nil, errors.New("path conflicts with a value that is not an object")
+	}
+	if len(path) == 1 {
+		if _, exists := obj[elem.key]; exists {
+			return nil, fmt.Errorf("field %q is set more than once", elem.key)
+		}
+		obj[elem.key] = val
+		return obj, nil
+	}
+	child, err := setJSONBodyValue(obj[elem.key], path[1:], val)
+	if err != nil {
+		return nil, err
+	}
+	obj[elem.key] = child
+	return obj, nil
+}
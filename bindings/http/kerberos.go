@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	krbclient "github.com/jcmturner/gokrb5/v8/client"
+	krbconfig "github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+)
+
+const defaultKerberosConfig = "/etc/krb5.conf"
+
+// newKerberosClient returns the Kerberos client used with authType "negotiate".
+// The client logs in to the KDC when the first service ticket is requested, using the keytab if set, or the password.
+func (h *HTTPSource) newKerberosClient() (*krbclient.Client, error) {
+	if h.metadata.KerberosKeytab == "" && h.metadata.AuthPassword == "" {
+		return nil, errors.New("kerberosKeytab or password is required with authType negotiate")
+	}
+
+	configPath := h.metadata.KerberosConfig
+	if configPath == "" {
+		configPath = defaultKerberosConfig
+	}
+	cfg, err := krbconfig.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kerberosConfig: %w", err)
+	}
+	realm := h.metadata.KerberosRealm
+	if realm == "" {
+		realm = cfg.LibDefaults.DefaultRealm
+	}
+	if realm == "" {
+		return nil, errors.New("kerberosRealm is required when kerberosConfig has no default_realm")
+	}
+
+	// FAST pre-authentication is disabled, as it's not supported by Active Directory
+	if h.metadata.KerberosKeytab != "" {
+		kt, err := keytab.Load(h.metadata.KerberosKeytab)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kerberosKeytab: %w", err)
+		}
+		return krbclient.NewWithKeytab(h.metadata.AuthUsername, realm, kt, cfg, krbclient.DisablePAFXFAST(true)), nil
+	}
+	return krbclient.NewWithPassword(h.metadata.AuthUsername, realm, h.metadata.AuthPassword, cfg, krbclient.DisablePAFXFAST(true)), nil
+}
+
+// negotiateHandshake answers a 401 response with a Negotiate challenge, sending the request again with a SPNEGO token containing a Kerberos service ticket.
+// The service principal is kerberosSPN, or "HTTP/" followed by the host of the request.
+// Returns false if the response has no Negotiate challenge. If a ticket can't be obtained, the response is returned.
+func (h *HTTPSource) negotiateHandshake(request *http.Request, resp *http.Response) (*http.Response, bool, error) {
+	if _, ok := authChallengeToken(resp.Header.Values("WWW-Authenticate"), authSchemeNegotiate); !ok {
+		return resp, false, nil
+	}
+
+	spn := h.metadata.KerberosSPN
+	if spn == "" {
+		spn = "HTTP/" + request.URL.Hostname()
+	}
+	// The header is set on a separate request, so the original one is modified only if the token is obtained
+	r := &http.Request{Header: http.Header{}, URL: request.URL}
+	err := spnego.SetSPNEGOHeader(h.kerberosClient, r, spn)
+	if err != nil {
+		h.logger.Warnf("Cannot answer Negotiate challenge from %s: %v", request.URL.Redacted(), err)
+		return resp, true, nil
+	}
+	resp, err = h.resendWithAuthorization(request, resp, r.Header.Get("Authorization"))
+	return resp, true, err
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	bhttp "github.com/dapr/components-contrib/bindings/http"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+	kitmd "github.com/dapr/kit/metadata"
+)
+
+const (
+	defaultErrorBackoff = time.Second
+	maxErrorBackoff     = time.Minute
+)
+
+// LongPoll is an input binding that repeatedly sends GET requests with the HTTP binding, and delivers each response to the app.
+type LongPoll struct {
+	metadata longPollMetadata
+	client   *bhttp.HTTPSource
+	logger   logger.Logger
+	closed   atomic.Bool
+	closeCh  chan struct{}
+	wg       sync.WaitGroup
+}
+
+type longPollMetadata struct {
+	// Path appended to the URL for long-poll requests.
+	PollPath string `mapstructure:"pollPath"`
+	// Delay between a successful long-poll request and the next one.
+	// Default: 0 (poll again immediately)
+	PollInterval time.Duration `mapstructure:"pollInterval"`
+	// Initial delay after a failed long-poll request, which doubles after each consecutive failure, up to 1 minute.
+	// Default: 1s
+	ErrorBackoff time.Duration `mapstructure:"errorBackoff"`
+}
+
+// NewLongPoll returns a new long-poll input binding.
+func NewLongPoll(logger logger.Logger) bindings.InputBinding {
+	return &LongPoll{
+		client:  bhttp.NewHTTP(logger).(*bhttp.HTTPSource),
+		logger:  logger,
+		closeCh: make(chan struct{}),
+	}
+}
+
+// Init performs metadata parsing, and initializes the HTTP binding that sends the requests with the same metadata.
+func (l *LongPoll) Init(ctx context.Context, meta bindings.Metadata) error {
+	l.metadata = longPollMetadata{
+		ErrorBackoff: defaultErrorBackoff,
+	}
+	err := kitmd.DecodeMetadata(meta.Properties, &l.metadata)
+	if err != nil {
+		return err
+	}
+	if l.metadata.PollInterval < 0 {
+		return errors.New("invalid value for pollInterval: must not be negative")
+	}
+	if l.metadata.ErrorBackoff <= 0 {
+		return errors.New("invalid value for errorBackoff: must be positive")
+	}
+
+	err = l.client.Init(ctx, meta)
+	if err != nil {
+		return fmt.Errorf("long-poll binding: %w", err)
+	}
+	return nil
+}
+
+// Read starts long-polling the endpoint in background, delivering each response to the handler.
+// It stops when the context is canceled or the binding is closed.
+func (l *LongPoll) Read(ctx context.Context, handler bindings.Handler) error {
+	if l.closed.Load() {
+		return errors.New("binding is closed")
+	}
+
+	// Stop when the binding is closed, canceling any pending request
+	ctx, cancel := context.WithCancel(ctx)
+	l.wg.Add(2)
+	go func() {
+		defer l.wg.Done()
+		defer cancel()
+		select {
+		case <-ctx.Done():
+		case <-l.closeCh:
+		}
+	}()
+	go func() {
+		defer l.wg.Done()
+		defer cancel()
+		l.poll(ctx, handler)
+	}()
+	return nil
+}
+
+// poll sends GET requests in a loop until the context is canceled.
+// After a successful poll, the next one is sent after pollInterval; after an error, the delay grows exponentially, starting from errorBackoff.
+func (l *LongPoll) poll(ctx context.Context, handler bindings.Handler) {
+	errorDelay := l.metadata.ErrorBackoff
+
+	for {
+		err := l.pollOnce(ctx, handler)
+		if ctx.Err() != nil {
+			return
+		}
+
+		delay := l.metadata.PollInterval
+		if err != nil {
+			l.logger.Warnf("Long-poll request failed, retrying in %v: %v", errorDelay, err)
+			delay = errorDelay
+			errorDelay = min(errorDelay*2, maxErrorBackoff)
+		} else {
+			errorDelay = l.metadata.ErrorBackoff
+		}
+
+		if delay > 0 {
+			t := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C:
+			}
+		}
+	}
+}
+
+// pollOnce sends a single request and delivers the response to the handler.
+func (l *LongPoll) pollOnce(ctx context.Context, handler bindings.Handler) error {
+	res, err := l.client.Invoke(ctx, &bindings.InvokeRequest{
+		Operation: bindings.GetOperation,
+		Metadata:  map[string]string{"path": l.metadata.PollPath},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = handler(ctx, &bindings.ReadResponse{
+		Data:     res.Data,
+		Metadata: res.Metadata,
+	})
+	return err
+}
+
+// Close stops polling, waiting for the request being handled, and closes the HTTP binding.
+func (l *LongPoll) Close() error {
+	if l.closed.CompareAndSwap(false, true) {
+		close(l.closeCh)
+	}
+	l.wg.Wait()
+	return l.client.Close()
+}
+
+// GetComponentMetadata returns the metadata of the component, which includes the metadata of the HTTP binding.
+func (l *LongPoll) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
+	metadataInfo = l.client.GetComponentMetadata()
+	metadataStruct := longPollMetadata{}
+	metadata.GetMetadataInfoFromStructType(reflect.TypeOf(metadataStruct), &metadataInfo, metadata.BindingType)
+	return
+}
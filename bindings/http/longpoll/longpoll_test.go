@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package longpoll
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func TestRead(t *testing.T) {
+	var requests atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		assert.Equal(t, "/events", r.URL.Path)
+		// The second request fails, and is retried after the backoff
+		if n == 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, "event-%d", n)
+	}))
+	defer s.Close()
+
+	m := bindings.Metadata{Base: metadata.Base{
+		Properties: map[string]string{
+			"url":          s.URL,
+			"pollPath":     "/events",
+			"errorBackoff": "10ms",
+		},
+	}}
+	b := NewLongPoll(logger.NewLogger("test"))
+	require.NoError(t, b.Init(context.Background(), m))
+
+	events := make(chan string, 100)
+	err := b.Read(context.Background(), func(ctx context.Context, res *bindings.ReadResponse) ([]byte, error) {
+		events <- string(res.Data)
+		assert.Equal(t, "200", res.Metadata["statusCode"])
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "event-1", <-events)
+	assert.Equal(t, "event-3", <-events)
+	assert.Equal(t, "event-4", <-events)
+
+	// Closing stops polling
+	require.NoError(t, b.Close())
+	// A request canceled by Close may still reach the server
+	time.Sleep(20 * time.Millisecond)
+	n := requests.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, n, requests.Load())
+	require.ErrorContains(t, b.Read(context.Background(), nil), "binding is closed")
+
+	t.Run("stops when the context is canceled", func(t *testing.T) {
+		b := NewLongPoll(logger.NewLogger("test"))
+		require.NoError(t, b.Init(context.Background(), m))
+		defer b.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		received := make(chan struct{}, 100)
+		err := b.Read(ctx, func(ctx context.Context, res *bindings.ReadResponse) ([]byte, error) {
+			received <- struct{}{}
+			return nil, nil
+		})
+		require.NoError(t, err)
+		<-received
+		cancel()
+		b.(*LongPoll).wg.Wait()
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		b := NewLongPoll(logger.NewLogger("test"))
+		err := b.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"url":          s.URL,
+			"errorBackoff": "0",
+		}}})
+		require.ErrorContains(t, err, "invalid value for errorBackoff")
+	})
+}
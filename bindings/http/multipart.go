@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+const multipartOperation bindings.OperationKind = "multipart"
+
+// multipartPart is a part of a multipart/form-data body, in the request data of the multipart operation.
+// Exactly one of Value and Data must be set.
+type multipartPart struct {
+	// Name of the form field
+	Name string `json:"name"`
+	// Value of a text field
+	Value *string `json:"value,omitempty"`
+	// Content of a file, base64-encoded in JSON
+	Data []byte `json:"data,omitempty"`
+	// File name sent with the part; defaults to Name
+	Filename string `json:"filename,omitempty"`
+	// Content type of the part; defaults to "application/octet-stream" for files
+	ContentType string `json:"contentType,omitempty"`
+}
+
+func (p multipartPart) validate() error {
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	if (p.Value != nil) == (p.Data != nil) {
+		return errors.New("exactly one of value and data must be set")
+	}
+	return nil
+}
+
+// Replaces the characters that must be escaped in the quoted values of Content-Disposition, like mime/multipart does.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// multipartBody is a multipart/form-data body built from the parts in the request data of the multipart operation.
+// The body is encoded while the request is sent, rather than being buffered in memory.
+type multipartBody struct {
+	parts    []multipartPart
+	boundary string
+	// Value of the Content-Type header, which includes the boundary
+	contentType string
+}
+
+// parseMultipartBody parses and validates the JSON array of parts in the request data.
+func parseMultipartBody(data []byte) (*multipartBody, error) {
+	var parts []multipartPart
+	err := json.Unmarshal(data, &parts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request data for the multipart operation: must be a JSON array of parts: %w", err)
+	}
+	if len(parts) == 0 {
+		return nil, errors.New("invalid request data for the multipart operation: no parts")
+	}
+	for i, p := range parts {
+		err = p.validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid part %d of the multipart operation: %w", i, err)
+		}
+	}
+
+	// The boundary is generated once, so the body is the same when the request is retried
+	mw := multipart.NewWriter(io.Discard)
+	return &multipartBody{
+		parts:       parts,
+		boundary:    mw.Boundary(),
+		contentType: mw.FormDataContentType(),
+	}, nil
+}
+
+// reader returns a reader of the encoded body, which is written by a background goroutine as it's read.
+// Each call encodes the body again, so it can be used for GetBody. The reader must be closed.
+func (b *multipartBody) reader() io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		mw := multipart.NewWriter(pw)
+		// The boundary was generated by mime/multipart, so it's always valid
+		_ = mw.SetBoundary(b.boundary)
+		for _, p := range b.parts {
+			err := writeMultipartPart(mw, p)
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.CloseWithError(mw.Close())
+	}()
+	return pr
+}
+
+func writeMultipartPart(mw *multipart.Writer, p multipartPart) error {
+	header := textproto.MIMEHeader{}
+	if p.Value != nil && p.Filename == "" {
+		// Text field
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(p.Name)))
+		if p.ContentType != "" {
+			header.Set("Content-Type", p.ContentType)
+		}
+		w, err := mw.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, *p.Value)
+		return err
+	}
+
+	filename := p.Filename
+	if filename == "" {
+		filename = p.Name
+	}
+	contentType := p.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(p.Name), quoteEscaper.Replace(filename)))
+	header.Set("Content-Type", contentType)
+	w, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	if p.Value != nil {
+		_, err = io.WriteString(w, *p.Value)
+	} else {
+		_, err = w.Write(p.Data)
+	}
+	return err
+}
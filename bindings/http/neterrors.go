@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// Categories of network errors, used in retriableNetworkErrors.
+const (
+	netErrConnRefused  = "connrefused"
+	netErrConnReset    = "connreset"
+	netErrTimeout      = "timeout"
+	netErrDNSFailure   = "dnsfailure"
+	netErrEOF          = "eof"
+	netErrTLSHandshake = "tlshandshake"
+)
+
+// Categories of network errors that are retried when retriableNetworkErrors is empty.
+// DNS failures and TLS errors other than timeouts usually fail in the same way on every attempt, so they are retried only when listed explicitly.
+var defaultRetriableNetworkErrors = map[string]struct{}{
+	netErrConnRefused: {},
+	netErrConnReset:   {},
+	netErrTimeout:     {},
+	netErrEOF:         {},
+}
+
+// parseNetworkErrorCategories parses the list of retriable network error categories.
+// Returns nil if the list is empty, meaning that the default categories are retriable.
+func parseNetworkErrorCategories(categories []string) (map[string]struct{}, error) {
+	res := make(map[string]struct{}, len(categories))
+	for _, c := range categories {
+		c = strings.ToLower(strings.TrimSpace(c))
+		switch c {
+		case "":
+			continue
+		case netErrConnRefused, netErrConnReset, netErrTimeout, netErrDNSFailure, netErrEOF, netErrTLSHandshake:
+			res[c] = struct{}{}
+		default:
+			return nil, fmt.Errorf("unsupported network error category: %s", c)
+		}
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return res, nil
+}
+
+// classifyNetworkError returns the category of a network error, or an empty string if it doesn't belong to any.
+func classifyNetworkError(err error) string {
+	var (
+		dnsErr    *net.DNSError
+		recordErr tls.RecordHeaderError
+		alertErr  tls.AlertError
+		certErr   *tls.CertificateVerificationError
+		netErr    net.Error
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		return netErrDNSFailure
+	case errors.As(err, &recordErr), errors.As(err, &alertErr), errors.As(err, &certErr):
+		return netErrTLSHandshake
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return netErrConnRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return netErrConnReset
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return netErrEOF
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return netErrTimeout
+	default:
+		return ""
+	}
+}
+
+// isRetriableNetworkError returns true if the error belongs to one of the categories in retriableNetworkErrors, or in defaultRetriableNetworkErrors if none is configured.
+// Errors that don't belong to any category are never retriable.
+func (h *HTTPSource) isRetriableNetworkError(err error) bool {
+	categories := h.metadata.retriableNetworkErrors
+	if categories == nil {
+		categories = defaultRetriableNetworkErrors
+	}
+	_, ok := categories[classifyNetworkError(err)]
+	return ok
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/go-ntlmssp"
+)
+
+// ntlmHandshake answers a 401 response with an NTLM challenge: the request is sent again with a NEGOTIATE_MESSAGE, and then with the AUTHENTICATE_MESSAGE that answers the challenge of the server.
+// The messages are created by go-ntlmssp, which supports NTLMv2 only. The username can be in the "DOMAIN\user" format.
+// NTLM authenticates the connection, so the handshake relies on both requests reusing the same keep-alive connection.
+// Returns false if the response has no NTLM challenge. If the challenge can't be answered, the last response is returned.
+func (h *HTTPSource) ntlmHandshake(request *http.Request, resp *http.Response) (*http.Response, bool, error) {
+	if _, ok := authChallengeToken(resp.Header.Values("WWW-Authenticate"), authSchemeNTLM); !ok {
+		return resp, false, nil
+	}
+	user, domain, domainNeeded := ntlmssp.GetDomain(h.metadata.AuthUsername)
+	negotiate, err := ntlmssp.NewNegotiateMessage(domain, "")
+	if err != nil {
+		h.logger.Debugf("Cannot answer NTLM challenge from %s: %v", request.URL.Redacted(), err)
+		return resp, true, nil
+	}
+	resp, err = h.resendWithAuthorization(request, resp, "NTLM "+base64.StdEncoding.EncodeToString(negotiate))
+	if err != nil {
+		return nil, true, err
+	}
+
+	token, _ := authChallengeToken(resp.Header.Values("WWW-Authenticate"), authSchemeNTLM)
+	if resp.StatusCode != http.StatusUnauthorized || token == "" {
+		return resp, true, nil
+	}
+	msg, err := base64.StdEncoding.DecodeString(token)
+	if err == nil {
+		msg, err = ntlmssp.ProcessChallenge(msg, user, h.metadata.AuthPassword, domainNeeded)
+	}
+	if err != nil {
+		h.logger.Debugf("Cannot answer NTLM challenge from %s: %v", request.URL.Redacted(), err)
+		return resp, true, nil
+	}
+	resp, err = h.resendWithAuthorization(request, resp, "NTLM "+base64.StdEncoding.EncodeToString(msg))
+	return resp, true, err
+}
+
+// authChallengeToken returns the token of the first challenge for the scheme in the WWW-Authenticate header values, such as "NTLM" or "Negotiate".
+// Returns an empty string if the challenge has no token, and false if there's no challenge for the scheme.
+func authChallengeToken(values []string, scheme string) (string, bool) {
+	for _, val := range values {
+		for _, part := range strings.Split(val, ",") {
+			name, token, _ := strings.Cut(strings.TrimSpace(part), " ")
+			if strings.EqualFold(name, scheme) {
+				return strings.TrimSpace(token), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2"
+	ccreds "golang.org/x/oauth2/clientcredentials"
+)
+
+// newOAuth2TokenSource returns a token source that acquires tokens with the OAuth2 client credentials flow, if oauth2TokenURL is set.
+// Tokens are cached, and a new one is requested shortly before the current one expires.
+// Token requests are sent with the binding's HTTP client, so they use the same TLS and proxy settings.
+func (h *HTTPSource) newOAuth2TokenSource() (oauth2.TokenSource, error) {
+	if h.metadata.OAuth2TokenURL == "" {
+		return nil, nil
+	}
+	if _, err := url.Parse(h.metadata.OAuth2TokenURL); err != nil {
+		return nil, errors.New("invalid value for oauth2TokenURL: failed to parse URL")
+	}
+	if h.metadata.OAuth2ClientID == "" {
+		return nil, errors.New("oauth2ClientID is required when oauth2TokenURL is set")
+	}
+
+	conf := &ccreds.Config{
+		ClientID:     h.metadata.OAuth2ClientID,
+		ClientSecret: h.metadata.OAuth2ClientSecret,
+		TokenURL:     h.metadata.OAuth2TokenURL,
+	}
+	for _, s := range h.metadata.OAuth2Scopes {
+		if s != "" {
+			conf.Scopes = append(conf.Scopes, s)
+		}
+	}
+	if h.metadata.OAuth2Audience != "" {
+		conf.EndpointParams = url.Values{"audience": []string{h.metadata.OAuth2Audience}}
+	}
+
+	// The context is used for all token requests, so it must not be canceled
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, h.client)
+	return conf.TokenSource(ctx), nil
+}
+
+// setOAuth2Token sets the Authorization header with the current OAuth2 token, acquiring a new one if needed.
+func (h *HTTPSource) setOAuth2Token(request *http.Request) error {
+	token, err := h.oauth2TokenSource.Token()
+	if err != nil {
+		return fmt.Errorf("failed to get OAuth2 token: %w", err)
+	}
+	token.SetAuthHeader(request)
+	return nil
+}
@@ -0,0 +1,299 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/http2"
+)
+
+const (
+	requestProtocol10 = "1.0"
+	requestProtocol11 = "1.1"
+	requestProtocol2  = "2"
+)
+
+// validateRequestProtocol validates the value of requestProtocol.
+func validateRequestProtocol(val string) error {
+	switch val {
+	case "", requestProtocol10, requestProtocol11, requestProtocol2:
+		return nil
+	default:
+		return fmt.Errorf("invalid value for requestProtocol: %s (must be %q, %q, or %q)", val, requestProtocol10, requestProtocol11, requestProtocol2)
+	}
+}
+
+// setRequestProtocol sets the protocol version of the request.
+// HTTP/1.0 requests are sent without keep-alive.
+func setRequestProtocol(request *http.Request, protocol string) {
+	switch protocol {
+	case requestProtocol10:
+		request.Proto = "HTTP/1.0"
+		request.ProtoMajor = 1
+		request.ProtoMinor = 0
+		request.Close = true
+	case requestProtocol11:
+		request.Proto = "HTTP/1.1"
+		request.ProtoMajor = 1
+		request.ProtoMinor = 1
+	}
+}
+
+// http10Transport sends HTTP/1.0 requests on a new connection each, as http.Transport always sends requests as HTTP/1.1.
+// Other requests are sent with the next transport.
+type http10Transport struct {
+	next        http.RoundTripper
+	dialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	dialTLS     func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+func (t *http10Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.ProtoMajor != 1 || req.ProtoMinor != 0 {
+		return t.next.RoundTrip(req)
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.ContentLength <= 0 {
+		// HTTP/1.0 doesn't support chunked bodies
+		req.Body.Close()
+		return nil, errors.New("requestProtocol 1.0 requires a request body of known length")
+	}
+
+	addr := req.URL.Host
+	if req.URL.Port() == "" {
+		if req.URL.Scheme == "https" {
+			addr = net.JoinHostPort(req.URL.Hostname(), "443")
+		} else {
+			addr = net.JoinHostPort(req.URL.Hostname(), "80")
+		}
+	}
+	dial := t.dialContext
+	if req.URL.Scheme == "https" {
+		dial = t.dialTLS
+	}
+	ctx := req.Context()
+	conn, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Close the connection if the context is canceled before the response body is closed
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+
+	bw := bufio.NewWriter(&protoRewriter{w: conn, proto: req.Proto})
+	err = req.Write(bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		stop()
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		stop()
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		return nil, err
+	}
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		state := tlsConn.ConnectionState()
+		resp.TLS = &state
+	}
+	resp.Body = &connBody{ReadCloser: resp.Body, conn: conn, stop: stop}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the next transport.
+// HTTP/1.0 connections are never reused, so they're never idle.
+func (t *http10Transport) CloseIdleConnections() {
+	if ci, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		ci.CloseIdleConnections()
+	}
+}
+
+// protoRewriter replaces the protocol version in the request line written by http.Request.Write, which is always HTTP/1.1.
+type protoRewriter struct {
+	w     io.Writer
+	proto string
+	line  []byte
+	done  bool
+}
+
+func (p *protoRewriter) Write(b []byte) (int, error) {
+	if p.done {
+		return p.w.Write(b)
+	}
+	p.line = append(p.line, b...)
+	i := bytes.Index(p.line, []byte("\r\n"))
+	if i < 0 {
+		return len(b), nil
+	}
+	p.done = true
+	line := append(bytes.TrimSuffix(p.line[:i], []byte("HTTP/1.1")), p.proto...)
+	line = append(line, p.line[i:]...)
+	_, err := p.w.Write(line)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// connBody closes the connection when the response body is closed.
+type connBody struct {
+	io.ReadCloser
+	conn net.Conn
+	stop func() bool
+	once sync.Once
+}
+
+func (b *connBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.stop()
+		b.conn.Close()
+	})
+	return err
+}
+
+// http2OnlyTransport sends all requests with HTTP/2: https URLs over TLS, negotiated with ALPN, and http URLs in cleartext (h2c) with prior knowledge.
+// Requests fail if the server doesn't support HTTP/2, rather than falling back to HTTP/1.1.
+type http2OnlyTransport struct {
+	tls *http2.Transport
+	h2c *http2.Transport
+
+	// If positive, the number of concurrent streams to each host is limited to this value
+	maxStreams  int
+	streamsLock sync.Mutex
+	streams     map[string]chan struct{}
+}
+
+// newHTTP2OnlyTransport returns a transport that always uses HTTP/2, with the dialers and TLS configuration of t.
+func (h *HTTPSource) newHTTP2OnlyTransport(t *http.Transport, dialContext func(ctx context.Context, network, addr string) (net.Conn, error), dialTLS func(ctx context.Context, network, addr string) (net.Conn, error)) *http2OnlyTransport {
+	// The TLS dialer reads the configuration of t, so it must offer HTTP/2 with ALPN.
+	// HTTP/1.1 is offered too, so servers without HTTP/2 complete the handshake and the error is clearer.
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	t.TLSClientConfig.NextProtos = []string{http2.NextProtoTLS, "http/1.1"}
+
+	newTransport := func(dial func(ctx context.Context, network, addr string) (net.Conn, error)) *http2.Transport {
+		return &http2.Transport{
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dial(ctx, network, addr)
+			},
+			AllowHTTP:          true,
+			DisableCompression: t.DisableCompression,
+			ReadIdleTimeout:    h.metadata.HTTP2ReadIdleTimeout,
+			PingTimeout:        h.metadata.HTTP2PingTimeout,
+			// Requests wait for a free stream rather than opening new connections, so each host uses a single connection
+			StrictMaxConcurrentStreams: h.metadata.HTTP2MaxConcurrentStreams > 0,
+		}
+	}
+	return &http2OnlyTransport{
+		maxStreams: h.metadata.HTTP2MaxConcurrentStreams,
+		streams:    make(map[string]chan struct{}),
+		tls: newTransport(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialTLS(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			if tlsConn, ok := conn.(*tls.Conn); ok && tlsConn.ConnectionState().NegotiatedProtocol != http2.NextProtoTLS {
+				conn.Close()
+				return nil, fmt.Errorf("server at %s doesn't support HTTP/2", addr)
+			}
+			return conn, nil
+		}),
+		h2c: newTransport(dialContext),
+	}
+}
+
+func (t *http2OnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.maxStreams <= 0 {
+		return t.roundTrip(req)
+	}
+
+	// Wait for a free stream on the connection to the host, which is released when the response body is closed
+	sem := t.hostStreams(req.URL.Host)
+	select {
+	case sem <- struct{}{}:
+	case <-req.Context().Done():
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, req.Context().Err()
+	}
+	release := func() { <-sem }
+	resp, err := t.roundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &streamBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+func (t *http2OnlyTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "https" {
+		return t.tls.RoundTrip(req)
+	}
+	return t.h2c.RoundTrip(req)
+}
+
+// hostStreams returns the semaphore limiting the concurrent streams to a host.
+func (t *http2OnlyTransport) hostStreams(host string) chan struct{} {
+	t.streamsLock.Lock()
+	defer t.streamsLock.Unlock()
+	sem, ok := t.streams[host]
+	if !ok {
+		sem = make(chan struct{}, t.maxStreams)
+		t.streams[host] = sem
+	}
+	return sem
+}
+
+func (t *http2OnlyTransport) CloseIdleConnections() {
+	t.tls.CloseIdleConnections()
+	t.h2c.CloseIdleConnections()
+}
+
+// streamBody releases the stream when the response body is closed.
+type streamBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *streamBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
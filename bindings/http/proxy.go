@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// configureProxy sets the proxy of the transport.
+// If proxyURL is set, all requests are sent through it; otherwise, the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables are honored (requests to localhost are never proxied).
+// Returns true if HTTPS requests may be sent through a proxy.
+func (h *HTTPSource) configureProxy(t *http.Transport) (bool, error) {
+	if h.metadata.SocketPath != "" {
+		// Connections to a Unix domain socket are never proxied
+		return false, nil
+	}
+	if h.metadata.ProxyURL != "" {
+		u, err := url.Parse(h.metadata.ProxyURL)
+		if err != nil {
+			return false, errors.New("invalid value for proxyURL: failed to parse URL")
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return false, fmt.Errorf("invalid value for proxyURL: unsupported scheme %q (must be http, https, or socks5)", u.Scheme)
+		}
+		if u.Host == "" {
+			return false, errors.New("invalid value for proxyURL: missing host")
+		}
+		t.Proxy = http.ProxyURL(u)
+		return true, nil
+	}
+
+	// Read the environment at initialization, rather than once per process as http.ProxyFromEnvironment does
+	cfg := httpproxy.FromEnvironment()
+	if cfg.HTTPProxy == "" && cfg.HTTPSProxy == "" {
+		return false, nil
+	}
+	proxyFunc := cfg.ProxyFunc()
+	t.Proxy = func(r *http.Request) (*url.URL, error) {
+		return proxyFunc(r.URL)
+	}
+	return cfg.HTTPSProxy != "", nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"golang.org/x/time/rate"
+)
+
+const defaultRateLimitMaxKeys = 1000
+
+// ErrRateLimitedLocally is returned when a request would wait longer than requestQueueTimeout for the client-side rate limiter or for a concurrency slot.
+var ErrRateLimitedLocally = errors.New("rate limited locally")
+
+// rateLimiter is a client-side token-bucket rate limiter.
+// Requests with a rate limit key have a separate bucket for each key; all other requests share a single bucket.
+type rateLimiter struct {
+	limit rate.Limit
+	burst int
+	// Maximum time a request can wait; if zero, requests wait until the context is done
+	timeout time.Duration
+
+	shared *rate.Limiter
+	// Buckets for each key; the least recently used keys are evicted when the maximum number is reached
+	keyed *lru.Cache[string, *rate.Limiter]
+	lock  sync.Mutex
+}
+
+func newRateLimiter(requestsPerSecond float64, burst int, maxKeys int, timeout time.Duration) (*rateLimiter, error) {
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(requestsPerSecond)))
+	}
+	if maxKeys <= 0 {
+		maxKeys = defaultRateLimitMaxKeys
+	}
+	keyed, err := lru.New[string, *rate.Limiter](maxKeys)
+	if err != nil {
+		return nil, err
+	}
+	return &rateLimiter{
+		limit:   rate.Limit(requestsPerSecond),
+		burst:   burst,
+		timeout: timeout,
+		shared:  rate.NewLimiter(rate.Limit(requestsPerSecond), burst),
+		keyed:   keyed,
+	}, nil
+}
+
+// wait blocks until the request can be sent according to the bucket for the key, or the shared one if key is empty.
+// If the request would have to wait longer than the timeout, it fails immediately with ErrRateLimitedLocally.
+func (l *rateLimiter) wait(ctx context.Context, key string) error {
+	limiter := l.limiterFor(key)
+	if l.timeout <= 0 {
+		return limiter.Wait(ctx)
+	}
+
+	r := limiter.Reserve()
+	delay := r.Delay()
+	if delay > l.timeout {
+		r.Cancel()
+		return fmt.Errorf("%w: the request would wait %v, more than requestQueueTimeout", ErrRateLimitedLocally, delay.Round(time.Millisecond))
+	}
+	if delay == 0 {
+		return nil
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
+
+// limiterFor returns the bucket for the key, creating it if needed.
+func (l *rateLimiter) limiterFor(key string) *rate.Limiter {
+	if key == "" {
+		return l.shared
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	limiter, ok := l.keyed.Get(key)
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.keyed.Add(key, limiter)
+	}
+	return limiter
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Same as the default limit of net/http.
+const defaultMaxRedirects = 10
+
+// Values for the redirectPolicy metadata property.
+const (
+	redirectPolicyAny        = "any"
+	redirectPolicySameHost   = "sameHost"
+	redirectPolicySameScheme = "sameScheme"
+	redirectPolicyNone       = "none"
+)
+
+// validateRedirectPolicy returns an error if the redirect settings are not supported.
+// When followRedirects is false, the redirect policy is set to "none".
+func (m *httpMetadata) validateRedirectPolicy() error {
+	switch m.RedirectPolicy {
+	case "", redirectPolicyAny, redirectPolicySameHost, redirectPolicySameScheme, redirectPolicyNone:
+	default:
+		return fmt.Errorf("invalid value for redirectPolicy: %s", m.RedirectPolicy)
+	}
+	if m.MaxRedirects < 0 {
+		return errors.New("invalid value for maxRedirects: must not be negative")
+	}
+	if m.FollowRedirects != nil && !*m.FollowRedirects {
+		if m.RedirectPolicy != "" && m.RedirectPolicy != redirectPolicyNone {
+			return fmt.Errorf("followRedirects can't be false with redirectPolicy %q", m.RedirectPolicy)
+		}
+		m.RedirectPolicy = redirectPolicyNone
+	}
+	return nil
+}
+
+// checkRedirect is the redirect policy of the HTTP client.
+// In addition to capping the number of redirects, it detects cycles and returns an error that names them.
+// Redirects back to a URL that was already requested are not a cycle if the cookies changed, such as after a login that sets a session cookie.
+func (h *HTTPSource) checkRedirect(req *http.Request, via []*http.Request) error {
+	err := h.checkRedirectPolicy(req, via)
+	if err != nil {
+		return err
+	}
+
+	// Never forward credentials to a different host
+	if !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+		req.Header.Del("Authorization")
+	}
+
+	target := req.URL.String()
+	cookie := h.redirectCookieHeader(req)
+	for i, prev := range via {
+		if prev.URL.String() == target && prev.Header.Get("Cookie") == cookie {
+			cycle := make([]string, 0, len(via)-i+1)
+			for _, r := range via[i:] {
+				cycle = append(cycle, r.URL.Redacted())
+			}
+			cycle = append(cycle, req.URL.Redacted())
+			return fmt.Errorf("redirect loop detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	maxRedirects := h.metadata.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+	if len(via) > maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	return nil
+}
+
+// redirectCookieHeader returns the Cookie header that is sent with the redirect.
+// The client adds the cookies from the jar after checkRedirect, while the previous requests already contain them.
+func (h *HTTPSource) redirectCookieHeader(req *http.Request) string {
+	if h.client == nil || h.client.Jar == nil {
+		return req.Header.Get("Cookie")
+	}
+	r := &http.Request{Header: http.Header{}}
+	if cookie := req.Header.Get("Cookie"); cookie != "" {
+		r.Header.Set("Cookie", cookie)
+	}
+	for _, c := range h.client.Jar.Cookies(req.URL) {
+		r.AddCookie(c)
+	}
+	return r.Header.Get("Cookie")
+}
+
+// checkRedirectPolicy returns an error if the redirect is not allowed by the configured policy.
+// With the "none" policy, the redirect response is returned to the caller.
+func (h *HTTPSource) checkRedirectPolicy(req *http.Request, via []*http.Request) error {
+	policy := h.metadata.RedirectPolicy
+	if policy == redirectPolicyNone {
+		return http.ErrUseLastResponse
+	}
+	if policy != redirectPolicySameHost && policy != redirectPolicySameScheme {
+		return nil
+	}
+
+	original := via[0].URL
+	if !strings.EqualFold(req.URL.Host, original.Host) {
+		return fmt.Errorf("redirect from %s to %s blocked by redirectPolicy %q: host does not match", original.Redacted(), req.URL.Redacted(), policy)
+	}
+	prev := via[len(via)-1].URL
+	if policy == redirectPolicySameScheme && prev.Scheme == "https" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect from %s to %s blocked by redirectPolicy %q: downgrade from https is not allowed", prev.Redacted(), req.URL.Redacted(), policy)
+	}
+	return nil
+}
+
+// countRedirects returns the number of redirects that were followed to obtain the response.
+func countRedirects(resp *http.Response) int {
+	n := 0
+	// Each request created by a redirect references the response that caused it
+	for r := resp.Request; r != nil && r.Response != nil; r = r.Response.Request {
+		n++
+	}
+	return n
+}
+
+// isUnfollowedRedirect returns true if the response is a redirect that was not followed because redirectPolicy is "none".
+// These responses are returned to the caller with the target of the redirect, rather than as errors.
+func (h *HTTPSource) isUnfollowedRedirect(resp *http.Response) bool {
+	return h.metadata.RedirectPolicy == redirectPolicyNone &&
+		resp.StatusCode >= 300 && resp.StatusCode < 400 &&
+		resp.Header.Get("Location") != ""
+}
+
+// redirectLocation returns the target of a redirect response, resolved against the URL of the request.
+func redirectLocation(resp *http.Response) string {
+	loc, err := resp.Location()
+	if err != nil {
+		return resp.Header.Get("Location")
+	}
+	return loc.String()
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRequestQueueFull is returned when all of the maxConcurrentRequests slots are busy and the request queue is full.
+var ErrRequestQueueFull = errors.New("request queue full")
+
+// requestQueue limits the number of requests in flight.
+// When all slots are busy, requests wait in a bounded FIFO queue, and are admitted in order as slots are released.
+type requestQueue struct {
+	maxConcurrent int
+	queueSize     int
+	// Maximum time a request can wait in the queue; if zero, requests wait until the context is done
+	timeout time.Duration
+
+	lock    sync.Mutex
+	active  int
+	waiters list.List // Of chan struct{}, closed when the slot is handed over
+}
+
+// queueStats describes how a request was admitted.
+type queueStats struct {
+	// Number of requests waiting in the queue when the request arrived
+	depth int
+	// Time spent waiting in the queue
+	wait time.Duration
+}
+
+func newRequestQueue(maxConcurrent int, queueSize int, timeout time.Duration) *requestQueue {
+	return &requestQueue{
+		maxConcurrent: maxConcurrent,
+		queueSize:     queueSize,
+		timeout:       timeout,
+	}
+}
+
+// acquire waits for a slot, and returns ErrRequestQueueFull if the queue is full, ErrRateLimitedLocally if the timeout expires while waiting, or the context error if the context is done while waiting.
+// If no error is returned, release must be invoked when the request completes.
+func (q *requestQueue) acquire(ctx context.Context) (queueStats, error) {
+	q.lock.Lock()
+	if q.active < q.maxConcurrent {
+		q.active++
+		q.lock.Unlock()
+		return queueStats{}, nil
+	}
+	stats := queueStats{depth: q.waiters.Len()}
+	if stats.depth >= q.queueSize {
+		q.lock.Unlock()
+		return stats, ErrRequestQueueFull
+	}
+	ch := make(chan struct{})
+	el := q.waiters.PushBack(ch)
+	q.lock.Unlock()
+
+	var timeoutCh <-chan time.Time
+	if q.timeout > 0 {
+		t := time.NewTimer(q.timeout)
+		defer t.Stop()
+		timeoutCh = t.C
+	}
+
+	start := time.Now()
+	var err error
+	select {
+	case <-ch:
+		stats.wait = time.Since(start)
+		return stats, nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timeoutCh:
+		err = fmt.Errorf("%w: no request slot available within requestQueueTimeout", ErrRateLimitedLocally)
+	}
+
+	stats.wait = time.Since(start)
+	q.lock.Lock()
+	select {
+	case <-ch:
+		// The slot was handed over concurrently: pass it on
+		q.lock.Unlock()
+		q.release()
+	default:
+		q.waiters.Remove(el)
+		q.lock.Unlock()
+	}
+	return stats, err
+}
+
+// release frees the slot, handing it over to the first request in the queue, if any.
+func (q *requestQueue) release() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if front := q.waiters.Front(); front != nil {
+		q.waiters.Remove(front)
+		close(front.Value.(chan struct{}))
+		return
+	}
+	q.active--
+}
+
+// addMetadata adds the queue depth and wait time to the response metadata.
+func (s queueStats) addMetadata(metadata map[string]string) {
+	metadata["queueDepth"] = strconv.Itoa(s.depth)
+	metadata["queueWaitMs"] = strconv.FormatInt(s.wait.Milliseconds(), 10)
+}
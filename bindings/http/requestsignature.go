@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRequestHMACHeader = "X-Signature"
+
+	requestHMACFormatBody          = "body"
+	requestHMACFormatTimestampBody = "timestamp.body"
+
+	hmacEncodingHex    = "hex"
+	hmacEncodingBase64 = "base64"
+
+	hmacPlaceholderBody      = "{body}"
+	hmacPlaceholderTimestamp = "{timestamp}"
+	hmacPlaceholderSignature = "{signature}"
+)
+
+// validateRequestHMAC validates the metadata for signing requests, and sets the defaults.
+func (m *httpMetadata) validateRequestHMAC() error {
+	if m.RequestHMACSecret == "" {
+		return nil
+	}
+	if m.RequestHMACHeader == "" {
+		m.RequestHMACHeader = defaultRequestHMACHeader
+	}
+	switch strings.ToLower(m.RequestHMACAlgorithm) {
+	case "":
+		m.RequestHMACAlgorithm = hmacAlgorithmSHA256
+	case hmacAlgorithmSHA256, hmacAlgorithmSHA1:
+		m.RequestHMACAlgorithm = strings.ToLower(m.RequestHMACAlgorithm)
+	default:
+		return fmt.Errorf("invalid value for requestHmacAlgorithm: %s (must be %q or %q)", m.RequestHMACAlgorithm, hmacAlgorithmSHA256, hmacAlgorithmSHA1)
+	}
+	switch strings.ToLower(m.RequestHMACEncoding) {
+	case "":
+		m.RequestHMACEncoding = hmacEncodingHex
+	case hmacEncodingHex, hmacEncodingBase64:
+		m.RequestHMACEncoding = strings.ToLower(m.RequestHMACEncoding)
+	default:
+		return fmt.Errorf("invalid value for requestHmacEncoding: %s (must be %q or %q)", m.RequestHMACEncoding, hmacEncodingHex, hmacEncodingBase64)
+	}
+
+	switch m.RequestHMACFormat {
+	case "", requestHMACFormatBody:
+		m.RequestHMACFormat = hmacPlaceholderBody
+	case requestHMACFormatTimestampBody:
+		m.RequestHMACFormat = hmacPlaceholderTimestamp + "." + hmacPlaceholderBody
+	default:
+		if strings.Count(m.RequestHMACFormat, hmacPlaceholderBody) != 1 {
+			return fmt.Errorf("invalid value for requestHmacFormat: must contain %s exactly once", hmacPlaceholderBody)
+		}
+	}
+	if m.RequestHMACSignatureFormat == "" {
+		m.RequestHMACSignatureFormat = hmacPlaceholderSignature
+	} else if !strings.Contains(m.RequestHMACSignatureFormat, hmacPlaceholderSignature) {
+		return fmt.Errorf("invalid value for requestHmacSignatureFormat: must contain %s", hmacPlaceholderSignature)
+	}
+	return nil
+}
+
+// hmacSigningTransport is a http.RoundTripper that adds the HMAC signature of the body to every request.
+// Requests are signed when they are sent, so retried requests get a fresh timestamp.
+type hmacSigningTransport struct {
+	next     http.RoundTripper
+	metadata *httpMetadata
+	now      func() time.Time
+}
+
+// newHMACSigningTransport returns a transport that signs requests before passing them to next, if requestHmacSecret is set.
+func (h *HTTPSource) newHMACSigningTransport(next http.RoundTripper) http.RoundTripper {
+	if h.metadata.RequestHMACSecret == "" {
+		return next
+	}
+	return &hmacSigningTransport{
+		next:     next,
+		metadata: &h.metadata,
+		now:      time.Now,
+	}
+}
+
+func (t *hmacSigningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it's given
+	signed := req.Clone(req.Context())
+
+	sig, ts, err := t.sign(req)
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	value := strings.ReplaceAll(t.metadata.RequestHMACSignatureFormat, hmacPlaceholderTimestamp, ts)
+	value = strings.ReplaceAll(value, hmacPlaceholderSignature, sig)
+	signed.Header.Set(t.metadata.RequestHMACHeader, value)
+	if t.metadata.RequestHMACTimestampHeader != "" {
+		signed.Header.Set(t.metadata.RequestHMACTimestampHeader, ts)
+	}
+	return t.next.RoundTrip(signed)
+}
+
+// sign computes the encoded signature of the request, and returns it with the signed timestamp in Unix seconds.
+// The signed message is built from requestHmacFormat, replacing {timestamp} and {body}.
+func (t *hmacSigningTransport) sign(req *http.Request) (sig string, ts string, err error) {
+	var newHash func() hash.Hash
+	switch t.metadata.RequestHMACAlgorithm {
+	case hmacAlgorithmSHA1:
+		newHash = sha1.New
+	default:
+		newHash = sha256.New
+	}
+	mac := hmac.New(newHash, []byte(t.metadata.RequestHMACSecret))
+
+	ts = strconv.FormatInt(t.now().Unix(), 10)
+	prefix, suffix, _ := strings.Cut(t.metadata.RequestHMACFormat, hmacPlaceholderBody)
+	mac.Write([]byte(strings.ReplaceAll(prefix, hmacPlaceholderTimestamp, ts)))
+
+	switch {
+	case req.Body == nil || req.Body == http.NoBody:
+		// Empty body
+	case req.GetBody != nil:
+		// The body is read from a copy, so it can still be sent
+		rc, err := req.GetBody()
+		if err != nil {
+			return "", "", err
+		}
+		defer rc.Close()
+		_, err = io.Copy(mac, rc)
+		if err != nil {
+			return "", "", err
+		}
+	default:
+		return "", "", errors.New("streamed request bodies can't be signed")
+	}
+
+	mac.Write([]byte(strings.ReplaceAll(suffix, hmacPlaceholderTimestamp, ts)))
+
+	if t.metadata.RequestHMACEncoding == hmacEncodingBase64 {
+		return base64.StdEncoding.EncodeToString(mac.Sum(nil)), ts, nil
+	}
+	return hex.EncodeToString(mac.Sum(nil)), ts, nil
+}
+
+func (t *hmacSigningTransport) CloseIdleConnections() {
+	if c, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// requestSpec is the value of the "requestSpec" metadata property, which describes parts of the request as a single JSON object.
+// Flat metadata takes precedence: headers set with capital-letter metadata keys override the ones in the spec, and the request data can't be combined with body.
+type requestSpec struct {
+	// Request headers.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Query string parameters, which are added to the ones already in the URL.
+	Query map[string]string `json:"query,omitempty"`
+	// Values that replace "{name}" placeholders in the URL, escaped.
+	PathParams map[string]string `json:"pathParams,omitempty"`
+	// Request body: a string is sent as-is, while any other JSON value is sent encoded as JSON.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// parseRequestSpec parses the requestSpec metadata property.
+// Returns nil if the value is empty.
+func parseRequestSpec(val string) (*requestSpec, error) {
+	if val == "" {
+		return nil, nil
+	}
+	dec := json.NewDecoder(strings.NewReader(val))
+	dec.DisallowUnknownFields()
+	spec := &requestSpec{}
+	err := dec.Decode(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid requestSpec: must be a JSON object with headers, query, pathParams, and body: %w", err)
+	}
+	if dec.More() {
+		return nil, errors.New("invalid requestSpec: unexpected data after the JSON object")
+	}
+	return spec, nil
+}
+
+// applyPathParams replaces the "{name}" placeholders in the URL with the escaped values.
+func (s *requestSpec) applyPathParams(u string) (string, error) {
+	for k, v := range s.PathParams {
+		placeholder := "{" + k + "}"
+		if !strings.Contains(u, placeholder) {
+			return "", fmt.Errorf("invalid requestSpec: path parameter %q is not in the URL", k)
+		}
+		u = strings.ReplaceAll(u, placeholder, url.PathEscape(v))
+	}
+	return u, nil
+}
+
+// applyQuery adds the query string parameters to the URL.
+func (s *requestSpec) applyQuery(u *url.URL) {
+	if len(s.Query) == 0 {
+		return
+	}
+	q := u.Query()
+	for k, v := range s.Query {
+		q.Add(k, v)
+	}
+	u.RawQuery = q.Encode()
+}
+
+// body returns the request body in the spec, or nil if there's none.
+func (s *requestSpec) body() []byte {
+	if s == nil || len(s.Body) == 0 || bytes.Equal(s.Body, []byte("null")) {
+		return nil
+	}
+	var str string
+	if json.Unmarshal(s.Body, &str) == nil {
+		return []byte(str)
+	}
+	return s.Body
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const defaultDNSPort = "53"
+
+// parseResolverSettings validates hostAliases and dnsServer.
+func (m *httpMetadata) parseResolverSettings() error {
+	var err error
+	m.hostAliases, err = parseHostAliases(m.HostAliases)
+	if err != nil {
+		return fmt.Errorf("invalid value for hostAliases: %w", err)
+	}
+	if len(m.hostAliases) > 0 && m.SocketPath != "" {
+		return errors.New("hostAliases can't be used with a Unix domain socket")
+	}
+
+	if m.DNSServer != "" {
+		if m.SocketPath != "" {
+			return errors.New("dnsServer can't be used with a Unix domain socket")
+		}
+		m.dnsServerAddr, err = normalizeDNSServer(m.DNSServer)
+		if err != nil {
+			return fmt.Errorf("invalid value for dnsServer: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseHostAliases parses a JSON object, or a comma-separated list of "hostname=ip" pairs.
+// Hostnames are lowercased.
+func parseHostAliases(val string) (map[string]string, error) {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return nil, nil
+	}
+
+	raw := map[string]string{}
+	if strings.HasPrefix(val, "{") {
+		err := json.Unmarshal([]byte(val), &raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a JSON object of hostnames to IP addresses: %w", err)
+		}
+	} else {
+		for _, pair := range strings.Split(val, ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			host, ip, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("entry %q must be in the format hostname=ip", pair)
+			}
+			raw[strings.TrimSpace(host)] = strings.TrimSpace(ip)
+		}
+	}
+
+	aliases := make(map[string]string, len(raw))
+	for host, ip := range raw {
+		if host == "" {
+			return nil, errors.New("hostname must not be empty")
+		}
+		if net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("%q is not a valid IP address for host %s", ip, host)
+		}
+		aliases[strings.ToLower(host)] = ip
+	}
+	return aliases, nil
+}
+
+// normalizeDNSServer returns the address of the DNS server, adding the default port if missing.
+func normalizeDNSServer(val string) (string, error) {
+	host, port, err := net.SplitHostPort(val)
+	if err != nil {
+		// No port
+		host = strings.Trim(val, "[]")
+		port = defaultDNSPort
+	}
+	if net.ParseIP(host) == nil {
+		return "", fmt.Errorf("%q is not an IP address", host)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// newResolver returns a resolver that sends all queries to the DNS server.
+func newResolver(dnsServerAddr string, dialer *net.Dialer) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, dnsServerAddr)
+		},
+	}
+}
+
+// withHostAliases returns a dial function that connects to the IP address configured in hostAliases for the host, if any.
+// The original address is still used for the Host header, and for TLS (SNI and certificate verification).
+func (h *HTTPSource) withHostAliases(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if len(h.metadata.hostAliases) == 0 {
+		return dial
+	}
+	aliases := h.metadata.hostAliases
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err == nil {
+			if ip, ok := aliases[strings.ToLower(host)]; ok {
+				addr = net.JoinHostPort(ip, port)
+			}
+		}
+		return dial(ctx, network, addr)
+	}
+}
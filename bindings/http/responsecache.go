@@ -0,0 +1,354 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+const defaultResponseCacheMaxEntries = 1000
+
+// Request headers carrying credentials, which are always part of the cache key, so responses obtained with the credentials of a caller are never returned to requests with different credentials.
+var responseCacheCredentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// responseCache is a private HTTP cache for GET and HEAD responses, which follows the freshness rules of RFC 7234.
+// Stale responses with a validator (ETag or Last-Modified) are revalidated with a conditional request.
+type responseCache struct {
+	cache      *lru.Cache[string, *cacheEntry]
+	defaultTTL time.Duration
+	now        func() time.Time
+	// Request headers whose values are part of the cache key
+	keyHeaders []string
+	// Request headers with credentials, whose values are part of the cache key as hashes
+	credentialHeaders []string
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// cacheEntry is a response stored in the cache.
+type cacheEntry struct {
+	statusCode int
+	status     string
+	header     http.Header
+	body       []byte
+	// Values of the request headers listed in Vary
+	vary map[string]string
+
+	// Time the response was received, and its age at that time
+	responseTime time.Time
+	initialAge   time.Duration
+	freshness    time.Duration
+	// If true, the response must be revalidated before each use
+	noCache bool
+}
+
+// newResponseCache returns a new response cache.
+// The values of the key headers, and those of the headers with credentials, including securityTokenHeader, are part of the cache key.
+func newResponseCache(maxEntries int, defaultTTL time.Duration, keyHeaders []string, securityTokenHeader string) (*responseCache, error) {
+	if maxEntries <= 0 {
+		maxEntries = defaultResponseCacheMaxEntries
+	}
+	cache, err := lru.New[string, *cacheEntry](maxEntries)
+	if err != nil {
+		return nil, err
+	}
+	c := &responseCache{
+		cache:             cache,
+		defaultTTL:        defaultTTL,
+		now:               time.Now,
+		credentialHeaders: responseCacheCredentialHeaders,
+	}
+	if securityTokenHeader != "" {
+		c.credentialHeaders = append([]string{http.CanonicalHeaderKey(securityTokenHeader)}, responseCacheCredentialHeaders...)
+	}
+	for _, name := range keyHeaders {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			c.keyHeaders = append(c.keyHeaders, http.CanonicalHeaderKey(name))
+		}
+	}
+	return c, nil
+}
+
+// lookup returns the cached response for the request, if any, and whether it's fresh.
+// Stale responses are returned so they can be revalidated.
+func (c *responseCache) lookup(request *http.Request) (entry *cacheEntry, fresh bool) {
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		return nil, false
+	}
+	reqCC := parseCacheControl(request.Header)
+	if _, ok := reqCC["no-store"]; ok {
+		return nil, false
+	}
+
+	entry, ok := c.cache.Get(c.key(request.Method, request))
+	if !ok || !entry.matchesVary(request) {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	_, reqNoCache := reqCC["no-cache"]
+	fresh = !entry.noCache && !reqNoCache && entry.age(c.now()) < entry.freshness
+	if fresh {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return entry, fresh
+}
+
+// store adds the response to the cache, if it's cacheable.
+// Successful requests with other methods invalidate the responses cached for the URL.
+func (c *responseCache) store(request *http.Request, resp *http.Response, body []byte) {
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		if resp.StatusCode < 400 {
+			c.invalidate(request)
+		}
+		return
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent, http.StatusMultipleChoices,
+		http.StatusMovedPermanently, http.StatusNotFound, http.StatusGone:
+	default:
+		return
+	}
+
+	reqCC := parseCacheControl(request.Header)
+	respCC := parseCacheControl(resp.Header)
+	if _, ok := reqCC["no-store"]; ok {
+		return
+	}
+	if _, ok := respCC["no-store"]; ok {
+		return
+	}
+	varyHeaders := resp.Header.Values("Vary")
+	vary := map[string]string{}
+	for _, v := range varyHeaders {
+		for _, name := range strings.Split(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "*" {
+				return
+			}
+			if name != "" {
+				vary[name] = request.Header.Get(name)
+			}
+		}
+	}
+
+	now := c.now()
+	entry := &cacheEntry{
+		statusCode:   resp.StatusCode,
+		status:       resp.Status,
+		header:       resp.Header.Clone(),
+		body:         body,
+		vary:         vary,
+		responseTime: now,
+	}
+	_, entry.noCache = respCC["no-cache"]
+	entry.updateFreshness(respCC, c.defaultTTL)
+
+	if entry.freshness <= 0 && !entry.hasValidator() {
+		// Would never be usable
+		return
+	}
+	c.cache.Add(c.key(request.Method, request), entry)
+}
+
+// revalidated updates the cached entry with the headers of a 304 Not Modified response, and returns the new entry.
+// Entries are not modified in place, as they may be in use by other requests.
+func (c *responseCache) revalidated(request *http.Request, entry *cacheEntry, resp *http.Response) *cacheEntry {
+	updated := *entry
+	updated.header = entry.header.Clone()
+	for k, v := range resp.Header {
+		updated.header[k] = v
+	}
+	updated.responseTime = c.now()
+	respCC := parseCacheControl(updated.header)
+	_, updated.noCache = respCC["no-cache"]
+	updated.updateFreshness(respCC, c.defaultTTL)
+	c.cache.Add(c.key(request.Method, request), &updated)
+	return &updated
+}
+
+// invalidate removes the GET and HEAD responses cached for the URL of the request, whatever the values of the key headers and credentials.
+func (c *responseCache) invalidate(request *http.Request) {
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		prefix := responseCacheURLKey(method, request)
+		c.cache.Remove(prefix)
+		for _, k := range c.cache.Keys() {
+			if strings.HasPrefix(k, prefix+"\n") {
+				c.cache.Remove(k)
+			}
+		}
+	}
+}
+
+// addMetadata adds the hit and miss counts to the response metadata.
+func (c *responseCache) addMetadata(metadata map[string]string) {
+	metadata["cacheHits"] = strconv.FormatInt(c.hits.Load(), 10)
+	metadata["cacheMisses"] = strconv.FormatInt(c.misses.Load(), 10)
+}
+
+// updateFreshness computes the freshness lifetime and the initial age of the response.
+// The freshness is set by max-age, or by Expires relative to Date, falling back to the default TTL.
+func (e *cacheEntry) updateFreshness(cc map[string]string, defaultTTL time.Duration) {
+	date, dateErr := http.ParseTime(e.header.Get("Date"))
+	e.initialAge = 0
+	if dateErr == nil && e.responseTime.After(date) {
+		e.initialAge = e.responseTime.Sub(date)
+	}
+	if age, err := strconv.Atoi(e.header.Get("Age")); err == nil && time.Duration(age)*time.Second > e.initialAge {
+		e.initialAge = time.Duration(age) * time.Second
+	}
+
+	if maxAge, ok := cc["max-age"]; ok {
+		sec, err := strconv.Atoi(maxAge)
+		if err != nil || sec < 0 {
+			sec = 0
+		}
+		e.freshness = time.Duration(sec) * time.Second
+		return
+	}
+	if val := e.header.Get("Expires"); val != "" {
+		// Invalid dates, such as "0", mean that the response is already expired
+		expires, err := http.ParseTime(val)
+		if err != nil {
+			e.freshness = 0
+			return
+		}
+		if dateErr != nil {
+			date = e.responseTime
+		}
+		e.freshness = expires.Sub(date)
+		return
+	}
+	e.freshness = defaultTTL
+}
+
+// age returns the current age of the response.
+func (e *cacheEntry) age(now time.Time) time.Duration {
+	return e.initialAge + now.Sub(e.responseTime)
+}
+
+func (e *cacheEntry) hasValidator() bool {
+	return e.header.Get("ETag") != "" || e.header.Get("Last-Modified") != ""
+}
+
+// matchesVary returns true if the request headers listed in Vary have the same values as in the request that was cached.
+func (e *cacheEntry) matchesVary(request *http.Request) bool {
+	for name, val := range e.vary {
+		if request.Header.Get(name) != val {
+			return false
+		}
+	}
+	return true
+}
+
+// setConditionalHeaders adds the validators of the entry to the request, unless the request already contains conditional headers.
+// Returns true if the headers were added.
+func (e *cacheEntry) setConditionalHeaders(request *http.Request) bool {
+	if !e.hasValidator() || request.Header.Get("If-None-Match") != "" || request.Header.Get("If-Modified-Since") != "" {
+		return false
+	}
+	if etag := e.header.Get("ETag"); etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := e.header.Get("Last-Modified"); lastModified != "" {
+		request.Header.Set("If-Modified-Since", lastModified)
+	}
+	return true
+}
+
+// response returns the cached response for the request, with the current Age header.
+func (e *cacheEntry) response(request *http.Request, now time.Time) *http.Response {
+	header := e.header.Clone()
+	header.Set("Age", strconv.Itoa(int(e.age(now).Seconds())))
+	body := e.body
+	if request.Method == http.MethodHead {
+		body = nil
+	}
+	return &http.Response{
+		Status:        e.status,
+		StatusCode:    e.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(e.body)),
+		Request:       request,
+	}
+}
+
+// parseCacheControl parses the directives in the Cache-Control headers.
+// Directive names are lowercased, and values are unquoted.
+func parseCacheControl(header http.Header) map[string]string {
+	res := map[string]string{}
+	for _, val := range header.Values("Cache-Control") {
+		for _, d := range strings.Split(val, ",") {
+			k, v, _ := strings.Cut(strings.TrimSpace(d), "=")
+			k = strings.ToLower(strings.TrimSpace(k))
+			if k == "" {
+				continue
+			}
+			if _, ok := res[k]; !ok {
+				res[k] = strings.Trim(strings.TrimSpace(v), `"`)
+			}
+		}
+	}
+	return res
+}
+
+// key returns the cache key of the request: the method, the URL, the values of the key headers, and the hashes of the credentials in the request, if any.
+func (c *responseCache) key(method string, request *http.Request) string {
+	var b strings.Builder
+	b.WriteString(responseCacheURLKey(method, request))
+	for _, name := range c.keyHeaders {
+		b.WriteString("\n")
+		b.WriteString(name)
+		b.WriteString(": ")
+		b.WriteString(strings.Join(request.Header.Values(name), ", "))
+	}
+	// Credentials are hashed, so the keys don't contain them in clear text
+	for _, name := range c.credentialHeaders {
+		values := request.Header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		sum := sha256.Sum256([]byte(strings.Join(values, ", ")))
+		b.WriteString("\n")
+		b.WriteString(name)
+		b.WriteString(": sha256:")
+		b.WriteString(hex.EncodeToString(sum[:]))
+	}
+	return b.String()
+}
+
+func responseCacheURLKey(method string, request *http.Request) string {
+	u := *request.URL
+	u.Fragment = ""
+	return method + " " + u.String()
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/dapr/kit/utils"
+)
+
+// statusCodeRange is an inclusive range of status codes.
+type statusCodeRange struct {
+	min, max int
+}
+
+// responsePolicy determines whether a response is returned as an error.
+// Each setting is resolved independently with the same precedence: the request metadata, then the component metadata, then the built-in default.
+type responsePolicy struct {
+	// If true, responses with a status code that is not a success return an error
+	errorIfNotSuccess bool
+	// Status codes that are a success; if empty, all 2xx codes
+	successCodes []statusCodeRange
+	// Maximum number of bytes of the response body included in errors for unsuccessful status codes; 0 to omit the body
+	errorBodyMaxLength int
+	// Expected content type of responses, if any
+	expectContentType string
+}
+
+// parseStatusCodeRanges parses a comma-separated list of status codes and ranges, such as "200-299,404".
+func parseStatusCodeRanges(val string) ([]statusCodeRange, error) {
+	var res []statusCodeRange
+	for _, part := range strings.Split(val, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		minCode, err := parseStatusCode(lo)
+		if err != nil {
+			return nil, err
+		}
+		maxCode := minCode
+		if isRange {
+			maxCode, err = parseStatusCode(hi)
+			if err != nil {
+				return nil, err
+			}
+			if maxCode < minCode {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+		}
+		res = append(res, statusCodeRange{min: minCode, max: maxCode})
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no status codes in %q", val)
+	}
+	return res, nil
+}
+
+func parseStatusCode(val string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q", val)
+	}
+	return code, nil
+}
+
+// responsePolicy returns the policy for the request, applying the overrides in the request metadata.
+func (h *HTTPSource) responsePolicy(md map[string]string) (responsePolicy, error) {
+	p := responsePolicy{
+		errorIfNotSuccess:  h.errorIfNot2XX,
+		successCodes:       h.metadata.successStatusCodes,
+		errorBodyMaxLength: h.metadata.ErrorBodyMaxLength,
+		expectContentType:  h.metadata.ExpectResponseContentType,
+	}
+
+	if val := md["errorIfNot2XX"]; val != "" {
+		p.errorIfNotSuccess = utils.IsTruthy(val)
+	}
+	if val := md["successStatusCodes"]; val != "" {
+		codes, err := parseStatusCodeRanges(val)
+		if err != nil {
+			return p, fmt.Errorf("invalid value for successStatusCodes: %w", err)
+		}
+		p.successCodes = codes
+	}
+	if val := md["errorBodyMaxLength"]; val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("invalid value for errorBodyMaxLength: %s", val)
+		}
+		p.errorBodyMaxLength = n
+	}
+	if val := md["expectResponseContentType"]; val != "" {
+		_, err := path.Match(val, "")
+		if err != nil {
+			return p, fmt.Errorf("invalid value for expectResponseContentType: %w", err)
+		}
+		p.expectContentType = val
+	}
+	return p, nil
+}
+
+// isSuccess returns true if the status code is a success according to the policy.
+func (p responsePolicy) isSuccess(code int) bool {
+	if len(p.successCodes) == 0 {
+		return code/100 == 2
+	}
+	for _, r := range p.successCodes {
+		if code >= r.min && code <= r.max {
+			return true
+		}
+	}
+	return false
+}
+
+// errorBody returns the body to include in the error for an unsuccessful status code, truncated to errorBodyMaxLength bytes.
+// Returns an empty string if the body is not included.
+func (p responsePolicy) errorBody(body []byte) string {
+	if p.errorBodyMaxLength <= 0 || len(body) == 0 {
+		return ""
+	}
+	if len(body) <= p.errorBodyMaxLength {
+		return strings.ToValidUTF8(string(body), "")
+	}
+	// Drop any incomplete character at the end
+	return strings.ToValidUTF8(string(body[:p.errorBodyMaxLength]), "") + "..."
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultResponseHMACHeader = "X-Signature"
+
+	hmacAlgorithmSHA256 = "sha256"
+	hmacAlgorithmSHA1   = "sha1"
+)
+
+// ErrResponseSignatureMismatch is returned when the HMAC signature of the response body is missing or doesn't match, or when the signed timestamp is too old.
+var ErrResponseSignatureMismatch = errors.New("response signature mismatch")
+
+// validateResponseHMAC validates the metadata for verifying the signature of responses, and sets the defaults.
+func (m *httpMetadata) validateResponseHMAC() error {
+	if m.ResponseHMACSecret == "" {
+		return nil
+	}
+	if m.ResponseHMACHeader == "" {
+		m.ResponseHMACHeader = defaultResponseHMACHeader
+	}
+	switch strings.ToLower(m.ResponseHMACAlgorithm) {
+	case "":
+		m.ResponseHMACAlgorithm = hmacAlgorithmSHA256
+	case hmacAlgorithmSHA256, hmacAlgorithmSHA1:
+		m.ResponseHMACAlgorithm = strings.ToLower(m.ResponseHMACAlgorithm)
+	default:
+		return fmt.Errorf("invalid value for responseHmacAlgorithm: %s (must be %q or %q)", m.ResponseHMACAlgorithm, hmacAlgorithmSHA256, hmacAlgorithmSHA1)
+	}
+	if m.ResponseHMACMaxAge < 0 {
+		return errors.New("invalid value for responseHmacMaxAge: must not be negative")
+	}
+	if m.ResponseHMACMaxAge > 0 && m.ResponseHMACTimestampHeader == "" {
+		return errors.New("responseHmacMaxAge requires responseHmacTimestampHeader")
+	}
+	return nil
+}
+
+// verifyResponseSignature verifies the HMAC signature of the response body.
+// If a timestamp header is configured, the signed message is the timestamp, a ".", and the body; the timestamp is in Unix seconds, and is rejected if older than the max age.
+// The signature can be hex or base64-encoded, optionally with the algorithm as prefix (e.g. "sha256=...").
+func (h *HTTPSource) verifyResponseSignature(header http.Header, body []byte) error {
+	sig := strings.TrimSpace(header.Get(h.metadata.ResponseHMACHeader))
+	if sig == "" {
+		return fmt.Errorf("%w: missing %s header", ErrResponseSignatureMismatch, h.metadata.ResponseHMACHeader)
+	}
+	sig = strings.TrimPrefix(sig, h.metadata.ResponseHMACAlgorithm+"=")
+
+	var newHash func() hash.Hash
+	switch h.metadata.ResponseHMACAlgorithm {
+	case hmacAlgorithmSHA1:
+		newHash = sha1.New
+	default:
+		newHash = sha256.New
+	}
+	mac := hmac.New(newHash, []byte(h.metadata.ResponseHMACSecret))
+
+	if h.metadata.ResponseHMACTimestampHeader != "" {
+		ts := strings.TrimSpace(header.Get(h.metadata.ResponseHMACTimestampHeader))
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid or missing %s header", ErrResponseSignatureMismatch, h.metadata.ResponseHMACTimestampHeader)
+		}
+		if h.metadata.ResponseHMACMaxAge > 0 && time.Since(time.Unix(sec, 0)) > h.metadata.ResponseHMACMaxAge {
+			return fmt.Errorf("%w: timestamp is older than responseHmacMaxAge", ErrResponseSignatureMismatch)
+		}
+		mac.Write([]byte(ts + "."))
+	}
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	actual, err := hex.DecodeString(sig)
+	if err != nil {
+		actual, err = base64.StdEncoding.DecodeString(sig)
+		if err != nil {
+			return fmt.Errorf("%w: signature is not hex or base64-encoded", ErrResponseSignatureMismatch)
+		}
+	}
+	if !hmac.Equal(expected, actual) {
+		return ErrResponseSignatureMismatch
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRetryBudgetWindow     = 10 * time.Second
+	defaultRetryBudgetMinRetries = 10
+	retryBudgetBuckets           = 10
+	// Maximum delay between retries, when retryBackoff is set
+	maxRetryBackoff = 30 * time.Second
+)
+
+// retryResult contains information on the retries performed while executing a request.
+type retryResult struct {
+	// Number of attempts, including the initial one
+	attempts int
+	// If true, a retry was suppressed because the retry budget was exhausted
+	budgetExhausted bool
+	// Reason of the last retry, if any
+	lastRetryReason string
+	// Scheme of the credentials the request was sent again with after a 401 response, when adaptiveAuth is enabled
+	authRetryScheme string
+}
+
+// addMetadata adds the number of attempts and the reason of the last retry to the response metadata.
+func (r retryResult) addMetadata(metadata map[string]string) {
+	metadata["attempts"] = strconv.Itoa(r.attempts)
+	if r.lastRetryReason != "" {
+		metadata["lastRetryReason"] = r.lastRetryReason
+	}
+	if r.authRetryScheme != "" {
+		metadata["authRetryScheme"] = r.authRetryScheme
+	}
+}
+
+// doWithRetries sends the request, retrying it up to maxRetries times on connection errors and retriable status codes.
+// Retries are subject to the component-wide retry budget, if configured.
+func (h *HTTPSource) doWithRetries(request *http.Request, maxRetries int) (*http.Response, retryResult, error) {
+	var res retryResult
+	for {
+		res.attempts++
+		resp, err := h.client.Do(request)
+
+		retriable := h.isRetriable(request, resp, err)
+		if h.retryBudget != nil && err == nil && !retriable {
+			h.retryBudget.recordSuccess()
+		}
+		if !retriable || res.attempts > maxRetries {
+			if h.metadata.LogRetries && maxRetries > 0 {
+				h.logger.Debugf("Attempt %d of %d to %s %s completed: %s", res.attempts, maxRetries+1, request.Method, request.URL.Redacted(), attemptOutcome(resp, err))
+			}
+			return resp, res, err
+		}
+
+		// The budget is only consumed by requests that can be retried, so non-idempotent requests don't use up the minimum retries
+		if h.retryBudget != nil && !h.retryBudget.tryRetry() {
+			res.budgetExhausted = true
+			if err != nil {
+				err = fmt.Errorf("%w (retry suppressed: retry budget exhausted)", err)
+			}
+			return resp, res, err
+		}
+
+		res.lastRetryReason = attemptOutcome(resp, err)
+		delay := retryDelay(h.metadata.RetryBackoff, res.attempts)
+		if h.metadata.LogRetries {
+			if delay > 0 {
+				h.logger.Debugf("Attempt %d of %d to %s %s failed: %s; retrying in %v", res.attempts, maxRetries+1, request.Method, request.URL.Redacted(), res.lastRetryReason, delay)
+			} else {
+				h.logger.Debugf("Attempt %d of %d to %s %s failed: %s; retrying immediately", res.attempts, maxRetries+1, request.Method, request.URL.Redacted(), res.lastRetryReason)
+			}
+		}
+
+		// Discard the response before retrying
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if delay > 0 {
+			t := time.NewTimer(delay)
+			select {
+			case <-request.Context().Done():
+				t.Stop()
+				return nil, res, fmt.Errorf("canceled while waiting to retry after %s: %w", res.lastRetryReason, request.Context().Err())
+			case <-t.C:
+			}
+		}
+
+		// Reset the body so it can be sent again
+		if request.GetBody != nil {
+			request.Body, err = request.GetBody()
+			if err != nil {
+				return nil, res, err
+			}
+		}
+	}
+}
+
+// retryDelay returns the delay before the retry that follows the given attempt: the backoff doubles after each attempt, up to maxRetryBackoff.
+// The delay is randomized between half and the full value, so clients that failed at the same time don't retry in lockstep.
+func retryDelay(backoff time.Duration, attempt int) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+	delay := maxRetryBackoff
+	if attempt <= 30 {
+		delay = min(backoff<<(attempt-1), maxRetryBackoff)
+	}
+	half := delay / 2
+	//nolint:gosec
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// attemptOutcome describes the result of an attempt: the status code of the response, or the category of the network error.
+// Errors are not included as-is, as they may contain the full URL of the request.
+func attemptOutcome(resp *http.Response, err error) string {
+	if err != nil {
+		if category := classifyNetworkError(err); category != "" {
+			return "network error: " + category
+		}
+		return "network error"
+	}
+	return "status code " + strconv.Itoa(resp.StatusCode)
+}
+
+// isRetriable returns true if the request failed with a retriable network error or a status code that indicates a transient failure.
+// Network errors are only retried for idempotent requests, as the server may have processed the request before the connection failed.
+func (h *HTTPSource) isRetriable(request *http.Request, resp *http.Response, err error) bool {
+	// Do not retry if the context was canceled or if the body cannot be re-sent
+	if request.Context().Err() != nil {
+		return false
+	}
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		// Only transport failures are retried: errors returned by the redirect policy or while signing the request, which are wrapped in a *url.Error too, fail in the same way on every attempt
+		return h.isIdempotent(request) && h.isRetriableNetworkError(err)
+	}
+	if len(h.metadata.retryOnStatusCodes) > 0 {
+		for _, r := range h.metadata.retryOnStatusCodes {
+			if resp.StatusCode >= r.min && resp.StatusCode <= r.max {
+				return true
+			}
+		}
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// isIdempotent returns true if sending the request more than once has the same effect as sending it once: this is the case for GET, HEAD, OPTIONS, PUT, and DELETE requests, and for requests with an idempotency key.
+func (h *HTTPSource) isIdempotent(request *http.Request) bool {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return request.Header.Get(h.metadata.IdempotencyKeyHeader) != ""
+	}
+}
+
+// retryBudget limits the number of retries across all requests to a fraction of the successful requests in a sliding window.
+// This prevents retries from amplifying the load on a failing endpoint.
+// A minimum number of retries is always allowed in the window, so failures are retried even when there are no recent successful requests.
+type retryBudget struct {
+	ratio      float64
+	minRetries int
+	bucketSize time.Duration
+	now        func() time.Time
+
+	lock      sync.Mutex
+	successes [retryBudgetBuckets]int
+	retries   [retryBudgetBuckets]int
+	// Index of the bucket for each slot, used to expire stale buckets
+	epochs [retryBudgetBuckets]int64
+}
+
+func newRetryBudget(ratio float64, window time.Duration, minRetries int) *retryBudget {
+	if window <= 0 {
+		window = defaultRetryBudgetWindow
+	}
+	return &retryBudget{
+		ratio:      ratio,
+		minRetries: minRetries,
+		bucketSize: window / retryBudgetBuckets,
+		now:        time.Now,
+	}
+}
+
+// slot returns the index of the bucket for the current time, resetting it if it's stale.
+// Must be invoked while holding the lock.
+func (b *retryBudget) slot() int {
+	epoch := b.now().UnixNano() / int64(b.bucketSize)
+	i := int(epoch % retryBudgetBuckets)
+	if b.epochs[i] != epoch {
+		b.epochs[i] = epoch
+		b.successes[i] = 0
+		b.retries[i] = 0
+	}
+	return i
+}
+
+// totals returns the number of successes and retries in the window.
+// Must be invoked while holding the lock.
+func (b *retryBudget) totals() (successes int, retries int) {
+	minEpoch := b.now().UnixNano()/int64(b.bucketSize) - retryBudgetBuckets + 1
+	for i := 0; i < retryBudgetBuckets; i++ {
+		if b.epochs[i] >= minEpoch {
+			successes += b.successes[i]
+			retries += b.retries[i]
+		}
+	}
+	return successes, retries
+}
+
+func (b *retryBudget) recordSuccess() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.successes[b.slot()]++
+}
+
+// tryRetry returns true if a retry is allowed by the budget, and records it.
+func (b *retryBudget) tryRetry() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	i := b.slot()
+	if b.availableLocked() <= 0 {
+		return false
+	}
+	b.retries[i]++
+	return true
+}
+
+// available returns the number of retries currently allowed by the budget.
+func (b *retryBudget) available() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.availableLocked()
+}
+
+func (b *retryBudget) availableLocked() int {
+	successes, retries := b.totals()
+	allowed := max(int(math.Floor(float64(successes)*b.ratio)), b.minRetries)
+	if allowed <= retries {
+		return 0
+	}
+	return allowed - retries
+}
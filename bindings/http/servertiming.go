@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// serverTimingMetric is a metric in the Server-Timing header.
+type serverTimingMetric struct {
+	name string
+	dur  string
+	desc string
+}
+
+// addServerTimingMetadata parses the Server-Timing headers of the response, and adds each metric to the metadata as "serverTiming.<name>", with the duration in milliseconds, and "serverTiming.<name>.desc", with the description, if any.
+// Malformed entries are skipped; if a metric is reported more than once, the first one is used.
+func (h *HTTPSource) addServerTimingMetadata(header http.Header, metadata map[string]string) {
+	for _, val := range header.Values("Server-Timing") {
+		for _, entry := range splitQuoted(val, ',') {
+			m, ok := parseServerTimingMetric(entry)
+			if !ok {
+				h.logger.Debugf("Ignoring malformed Server-Timing entry: %q", entry)
+				continue
+			}
+			key := "serverTiming." + m.name
+			if _, ok := metadata[key]; ok {
+				continue
+			}
+			metadata[key] = m.dur
+			if m.desc != "" {
+				metadata[key+".desc"] = m.desc
+			}
+		}
+	}
+}
+
+// parseServerTimingMetric parses an entry of the Server-Timing header, in the format `name;dur=123.4;desc="Description"`.
+// Per the spec, unknown parameters are ignored, and only the first occurrence of each parameter is used.
+func parseServerTimingMetric(entry string) (serverTimingMetric, bool) {
+	parts := splitQuoted(entry, ';')
+	m := serverTimingMetric{name: strings.TrimSpace(parts[0])}
+	if !isToken(m.name) {
+		return m, false
+	}
+
+	var hasDur, hasDesc bool
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		k = strings.ToLower(strings.TrimSpace(k))
+		v = strings.TrimSpace(v)
+		if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+			v = unquote(v[1 : len(v)-1])
+		}
+		switch {
+		case k == "dur" && !hasDur:
+			hasDur = true
+			dur, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return m, false
+			}
+			m.dur = strconv.FormatFloat(dur, 'f', -1, 64)
+		case k == "desc" && !hasDesc:
+			hasDesc = true
+			m.desc = v
+		}
+	}
+	if !hasDur {
+		m.dur = "0"
+	}
+	return m, true
+}
+
+// splitQuoted splits s on sep, ignoring separators inside quoted strings.
+// Empty parts are omitted, except for the first one.
+func splitQuoted(s string, sep byte) []string {
+	var (
+		res     []string
+		start   int
+		quoted  bool
+		escaped bool
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && s[i] == '\\':
+			escaped = true
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && s[i] == sep:
+			if part := s[start:i]; len(res) == 0 || strings.TrimSpace(part) != "" {
+				res = append(res, part)
+			}
+			start = i + 1
+		}
+	}
+	if part := s[start:]; len(res) == 0 || strings.TrimSpace(part) != "" {
+		res = append(res, part)
+	}
+	return res
+}
+
+// unquote removes the backslash escapes from the content of a quoted string.
+func unquote(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// isToken returns true if s is a valid HTTP token.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
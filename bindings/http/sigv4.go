@@ -0,0 +1,43 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	awsAuth "github.com/dapr/components-contrib/common/authentication/aws"
+)
+
+// newSigV4Transport returns a transport that signs requests before passing them to next, if awsRegion and awsService are set.
+// Credentials are loaded with the shared AWS auth provider: when awsAccessKey and awsSecretKey are empty, they are read from the environment, shared configuration files, or the instance role.
+func (h *HTTPSource) newSigV4Transport(next http.RoundTripper) (http.RoundTripper, error) {
+	if h.metadata.AWSRegion == "" && h.metadata.AWSService == "" {
+		return next, nil
+	}
+	if h.metadata.AWSRegion == "" || h.metadata.AWSService == "" {
+		return nil, errors.New("awsRegion and awsService must be set together")
+	}
+	if h.metadata.OAuth2TokenURL != "" {
+		return nil, errors.New("awsRegion and oauth2TokenURL can't be used together")
+	}
+
+	sess, err := awsAuth.GetClient(h.metadata.AWSAccessKey, h.metadata.AWSSecretKey, h.metadata.AWSSessionToken, h.metadata.AWSRegion, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS credentials: %w", err)
+	}
+
+	return awsAuth.NewSigV4Transport(next, sess.Config.Credentials, h.metadata.AWSRegion, h.metadata.AWSService), nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// skipIfOperators are the comparison operators supported in skipIf expressions.
+// Two-character operators are listed first so they're matched before "<" and ">".
+var skipIfOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// evaluateSkipIf evaluates a skipIf expression against the request metadata.
+// Supported expressions are:
+//
+//   - `${key}`: true if the metadata key is present and not empty; `!${key}` negates it
+//   - `a == b` and `a != b`: compares the operands as numbers if both are numeric, or as strings otherwise
+//   - `a < b`, `a <= b`, `a > b`, `a >= b`: compares the operands as numbers, which is an error if any isn't numeric
+//
+// Operands are metadata references (`${key}`, empty if not present), quoted strings, or literals.
+func evaluateSkipIf(expr string, md map[string]string) (bool, error) {
+	expr = strings.TrimSpace(expr)
+	op, left, right, found := splitSkipIfExpression(expr)
+	if !found {
+		// Presence check
+		negate := strings.HasPrefix(expr, "!")
+		if negate {
+			expr = strings.TrimSpace(expr[1:])
+		}
+		key, ok := parseSkipIfReference(expr)
+		if !ok {
+			return false, fmt.Errorf("invalid skipIf expression %q: expected a comparison or a ${key} reference", expr)
+		}
+		return (md[key] != "") != negate, nil
+	}
+
+	l, err := resolveSkipIfOperand(left, md)
+	if err != nil {
+		return false, err
+	}
+	r, err := resolveSkipIfOperand(right, md)
+	if err != nil {
+		return false, err
+	}
+
+	lNum, lErr := strconv.ParseFloat(l, 64)
+	rNum, rErr := strconv.ParseFloat(r, 64)
+	numeric := lErr == nil && rErr == nil
+	switch op {
+	case "==":
+		if numeric {
+			return lNum == rNum, nil
+		}
+		return l == r, nil
+	case "!=":
+		if numeric {
+			return lNum != rNum, nil
+		}
+		return l != r, nil
+	}
+
+	if !numeric {
+		return false, fmt.Errorf("invalid skipIf expression %q: operator %s requires numeric operands, got %q and %q", expr, op, l, r)
+	}
+	switch op {
+	case "<":
+		return lNum < rNum, nil
+	case "<=":
+		return lNum <= rNum, nil
+	case ">":
+		return lNum > rNum, nil
+	default: // ">="
+		return lNum >= rNum, nil
+	}
+}
+
+// splitSkipIfExpression finds the first comparison operator outside of quoted strings.
+func splitSkipIfExpression(expr string) (op string, left string, right string, found bool) {
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		default:
+			for _, op := range skipIfOperators {
+				if strings.HasPrefix(expr[i:], op) {
+					return op, strings.TrimSpace(expr[:i]), strings.TrimSpace(expr[i+len(op):]), true
+				}
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+// resolveSkipIfOperand returns the value of an operand: a metadata reference, a quoted string, or a literal.
+func resolveSkipIfOperand(operand string, md map[string]string) (string, error) {
+	if operand == "" {
+		return "", fmt.Errorf("invalid skipIf expression: missing operand")
+	}
+	if key, ok := parseSkipIfReference(operand); ok {
+		return md[key], nil
+	}
+	if q := operand[0]; q == '"' || q == '\'' {
+		if len(operand) < 2 || operand[len(operand)-1] != q {
+			return "", fmt.Errorf("invalid skipIf expression: unterminated string %s", operand)
+		}
+		return operand[1 : len(operand)-1], nil
+	}
+	if strings.ContainsAny(operand, " \t${}\"'") {
+		return "", fmt.Errorf("invalid skipIf expression: invalid operand %q", operand)
+	}
+	return operand, nil
+}
+
+// parseSkipIfReference returns the key in a `${key}` reference.
+func parseSkipIfReference(s string) (string, bool) {
+	if !strings.HasPrefix(s, "${") || !strings.HasSuffix(s, "}") {
+		return "", false
+	}
+	key := strings.TrimSpace(s[2 : len(s)-1])
+	if key == "" || strings.ContainsAny(key, "${}") {
+		return "", false
+	}
+	return key, true
+}
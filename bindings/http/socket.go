@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+const (
+	unixSocketScheme = "unix://"
+	// Host used in the URL of requests sent to a Unix domain socket, which is only used for the Host header
+	unixSocketHost = "http://localhost"
+)
+
+// configureUnixSocket sets socketPath from a url with the "unix://" scheme, such as "unix:///var/run/docker.sock".
+// The url is then replaced with a http URL, as the socket path is not part of the requests; the path of each request is set with the "path" metadata property.
+func (m *httpMetadata) configureUnixSocket() error {
+	if len(m.URL) >= len(unixSocketScheme) && strings.EqualFold(m.URL[:len(unixSocketScheme)], unixSocketScheme) {
+		if m.SocketPath != "" {
+			return errors.New("socketPath can't be set when url has the unix:// scheme")
+		}
+		m.SocketPath = m.URL[len(unixSocketScheme):]
+		if m.SocketPath == "" {
+			return errors.New("invalid url: the unix:// scheme requires the path of the socket")
+		}
+		m.URL = unixSocketHost
+	}
+	if m.SocketPath != "" && m.ProxyURL != "" {
+		return errors.New("proxyURL can't be used with a Unix domain socket")
+	}
+	return nil
+}
+
+// dialContext returns the function that establishes connections.
+// When socketPath is set, all connections are made to the Unix domain socket, regardless of the address of the request.
+func (h *HTTPSource) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if h.metadata.SocketPath == "" {
+		return h.withHostAliases(dialer.DialContext)
+	}
+	socketPath := h.metadata.SocketPath
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", socketPath)
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Prefix of the names of the files created for responses larger than streamResponseThreshold.
+	streamedResponseFilePrefix = "dapr-http-response-"
+	defaultStreamFileTTL       = time.Hour
+	// Maximum interval between checks for expired files in streamDirectory.
+	maxStreamFileCleanupInterval = 5 * time.Minute
+)
+
+// resolveStreamFile returns the path of a file named in the bodyFile or responseFile metadata property (key), which must be inside streamDirectory.
+// Names must be relative, and symbolic links are resolved before checking that the file is inside the directory, so callers can't read or overwrite arbitrary files of the host.
+func (h *HTTPSource) resolveStreamFile(key string, name string) (string, error) {
+	base := h.metadata.streamDirectoryPath
+	if base == "" {
+		return "", fmt.Errorf("cannot use %s metadata: streamDirectory is not set", key)
+	}
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid %s: must be a relative path inside streamDirectory", key)
+	}
+
+	p := filepath.Join(base, name)
+	resolved, err := filepath.EvalSymlinks(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Files that don't exist yet are resolved through their parent directory
+		if _, lerr := os.Lstat(p); lerr == nil {
+			return "", fmt.Errorf("invalid %s: %s is a broken symbolic link", key, name)
+		}
+		var dir string
+		dir, err = filepath.EvalSymlinks(filepath.Dir(p))
+		resolved = filepath.Join(dir, filepath.Base(p))
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", key, err)
+	}
+	rel, err := filepath.Rel(base, resolved)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("invalid %s: must be a relative path inside streamDirectory", key)
+	}
+	return resolved, nil
+}
+
+// openBodyFile opens the file in the bodyFile metadata property, which is streamed as the request body instead of being loaded in memory.
+// The request is updated with the size of the file, and with a GetBody function that opens the file again, so the request can be retried.
+func openBodyFile(request *http.Request, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("invalid bodyFile: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("invalid bodyFile: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		f.Close()
+		return fmt.Errorf("invalid bodyFile: %s is not a regular file", path)
+	}
+
+	request.Body = f
+	request.ContentLength = info.Size()
+	request.GetBody = func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}
+	if request.ContentLength == 0 {
+		request.Body = http.NoBody
+		f.Close()
+	}
+	return nil
+}
+
+// streamedResponse is a response body that was written to a file rather than returned in the response data.
+type streamedResponse struct {
+	path string
+	// Path of the file relative to streamDirectory, which is returned to the caller
+	name string
+	size int64
+}
+
+func (s *streamedResponse) addMetadata(md map[string]string) {
+	md["responseFile"] = s.name
+	md["responseSize"] = strconv.FormatInt(s.size, 10)
+}
+
+// readResponseBody reads the response body in memory, unless it's written to a file.
+// If responseFile is set, the body is always written to that path.
+// Otherwise, if streamResponseThreshold is set, bodies larger than the threshold are written to a new file in streamDirectory.
+// Only the first streamResponseThreshold bytes are ever buffered in memory.
+func (h *HTTPSource) readResponseBody(r io.Reader, responseFile string) ([]byte, *streamedResponse, error) {
+	threshold := h.metadata.streamResponseThresholdBytes
+	if responseFile == "" && threshold <= 0 {
+		b, err := io.ReadAll(r)
+		return b, nil, err
+	}
+
+	var (
+		f   *os.File
+		err error
+	)
+	if responseFile != "" {
+		f, err = os.Create(responseFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create responseFile: %w", err)
+		}
+	} else {
+		b, err := io.ReadAll(io.LimitReader(r, threshold+1))
+		if err != nil || int64(len(b)) <= threshold {
+			return b, nil, err
+		}
+		f, err = os.CreateTemp(h.metadata.streamDirectoryPath, streamedResponseFilePrefix+"*")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create file for streamed response: %w", err)
+		}
+		// Write the part that was already read before the rest of the body
+		r = io.MultiReader(bytes.NewReader(b), r)
+	}
+
+	n, err := io.Copy(f, r)
+	closeErr := f.Close()
+	if err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write streamed response: %w", closeErr)
+	}
+	if err != nil {
+		// Don't leave a partial file behind
+		_ = os.Remove(f.Name())
+		return nil, nil, err
+	}
+	// The app has the directory mounted at a path that may be different, so it's returned the name of the file in the directory
+	name, err := filepath.Rel(h.metadata.streamDirectoryPath, f.Name())
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return nil, nil, err
+	}
+	return nil, &streamedResponse{path: f.Name(), name: name, size: n}, nil
+}
+
+// startStreamFileCleanup deletes the files of streamed responses that are older than streamFileTTL, and keeps doing so periodically until the binding is closed.
+// Files left behind by previous runs of the component are deleted too.
+func (h *HTTPSource) startStreamFileCleanup() {
+	if h.metadata.streamResponseThresholdBytes <= 0 || h.metadata.StreamFileTTL <= 0 {
+		return
+	}
+	h.removeExpiredStreamFiles()
+
+	interval := min(h.metadata.StreamFileTTL/2, maxStreamFileCleanupInterval)
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-h.closeCh:
+				return
+			case <-t.C:
+				h.removeExpiredStreamFiles()
+			}
+		}
+	}()
+}
+
+// removeExpiredStreamFiles deletes the files of streamed responses in streamDirectory that were last modified more than streamFileTTL ago.
+func (h *HTTPSource) removeExpiredStreamFiles() {
+	entries, err := os.ReadDir(h.metadata.streamDirectoryPath)
+	if err != nil {
+		h.logger.Warnf("Failed to list streamDirectory to delete expired responses: %v", err)
+		return
+	}
+	expiry := time.Now().Add(-h.metadata.StreamFileTTL)
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasPrefix(e.Name(), streamedResponseFilePrefix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(expiry) {
+			continue
+		}
+		err = os.Remove(filepath.Join(h.metadata.streamDirectoryPath, e.Name()))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			h.logger.Warnf("Failed to delete expired response %s: %v", e.Name(), err)
+		}
+	}
+}
+
+// validateStreamingSettings returns an error if the streaming settings are invalid, or can't be used with other features that need the full response body.
+func (m *httpMetadata) validateStreamingSettings() error {
+	if m.streamResponseThresholdBytes < 0 {
+		return errors.New("invalid value for streamResponseThreshold: must not be negative")
+	}
+	if m.streamResponseThresholdBytes > 0 && m.StreamDirectory == "" {
+		return errors.New("streamResponseThreshold requires streamDirectory to be set")
+	}
+	if m.maxResponseBodySizeBytes > 0 && m.streamResponseThresholdBytes > m.maxResponseBodySizeBytes {
+		// Bodies up to the threshold are buffered in memory, and they'd be above the limit
+		return errors.New("invalid value for streamResponseThreshold: must not be larger than maxResponseBodySize")
+	}
+	if m.StreamFileTTL < 0 {
+		return errors.New("invalid value for streamFileTTL: must not be negative")
+	}
+	if m.StreamDirectory != "" {
+		// Symbolic links are resolved, so paths of files can be compared with it
+		dir, err := filepath.Abs(m.StreamDirectory)
+		if err == nil {
+			dir, err = filepath.EvalSymlinks(dir)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid value for streamDirectory: %w", err)
+		}
+		m.streamDirectoryPath = dir
+	}
+	if m.streamResponseThresholdBytes > 0 && m.ResponseHMACSecret != "" {
+		return errors.New("streamResponseThreshold can't be used together with responseHmacSecret")
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// tlsVersions maps the values accepted by tlsMinVersion and tlsMaxVersion to TLS versions.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// resolveInsecureSkipVerify maps insecureSkipVerify to the "none" tlsVerifyMode.
+func (m *httpMetadata) resolveInsecureSkipVerify() error {
+	if !m.InsecureSkipVerify {
+		return nil
+	}
+	switch m.TLSVerifyMode {
+	case "":
+		m.TLSVerifyMode = tlsVerifyModeNone
+	case tlsVerifyModeNone:
+		// Nop
+	default:
+		return fmt.Errorf("insecureSkipVerify cannot be used with tlsVerifyMode %q", m.TLSVerifyMode)
+	}
+	return nil
+}
+
+// setTLSOptions applies tlsMinVersion, tlsMaxVersion, tlsCipherSuites, and serverName, returning the (possibly new) tls.Config.
+func (h *HTTPSource) setTLSOptions(tlsConfig *tls.Config) (*tls.Config, error) {
+	if h.metadata.TLSMinVersion == "" && h.metadata.TLSMaxVersion == "" && len(h.metadata.TLSCipherSuites) == 0 && h.metadata.ServerName == "" {
+		return tlsConfig, nil
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if h.metadata.TLSMinVersion != "" {
+		v, err := parseTLSVersion(h.metadata.TLSMinVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for tlsMinVersion: %w", err)
+		}
+		if v < tls.VersionTLS12 {
+			h.logger.Warnf("TLS versions older than 1.2 are enabled with tlsMinVersion %s: this is insecure and should only be used with legacy servers", h.metadata.TLSMinVersion)
+		}
+		tlsConfig.MinVersion = v
+	}
+	if h.metadata.TLSMaxVersion != "" {
+		v, err := parseTLSVersion(h.metadata.TLSMaxVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for tlsMaxVersion: %w", err)
+		}
+		if v < tlsConfig.MinVersion {
+			return nil, fmt.Errorf("tlsMaxVersion %s is lower than the minimum TLS version", h.metadata.TLSMaxVersion)
+		}
+		tlsConfig.MaxVersion = v
+	}
+
+	if len(h.metadata.TLSCipherSuites) > 0 {
+		suites, err := h.parseCipherSuites(h.metadata.TLSCipherSuites)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.CipherSuites = suites
+	}
+
+	if h.metadata.ServerName != "" {
+		tlsConfig.ServerName = h.metadata.ServerName
+	}
+	return tlsConfig, nil
+}
+
+// parseTLSVersion parses a TLS version such as "1.2" or "TLS1.2".
+func parseTLSVersion(val string) (uint16, error) {
+	v := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(val)), "TLS")
+	version, ok := tlsVersions[v]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version %q (must be one of 1.0, 1.1, 1.2, 1.3)", val)
+	}
+	return version, nil
+}
+
+// parseCipherSuites returns the IDs of the cipher suites with the given names, as returned by tls.CipherSuiteName.
+// Insecure cipher suites are allowed, with a warning.
+func (h *HTTPSource) parseCipherSuites(names []string) ([]uint16, error) {
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, insecure, ok := findCipherSuite(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid value for tlsCipherSuites: unknown cipher suite %q", name)
+		}
+		if insecure {
+			h.logger.Warnf("Insecure TLS cipher suite %s is enabled: this should only be used with legacy servers", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func findCipherSuite(name string) (id uint16, insecure bool, ok bool) {
+	for _, s := range tls.CipherSuites() {
+		if strings.EqualFold(s.Name, name) {
+			return s.ID, false, true
+		}
+	}
+	for _, s := range tls.InsecureCipherSuites() {
+		if strings.EqualFold(s.Name, name) {
+			return s.ID, true, true
+		}
+	}
+	return 0, false, false
+}
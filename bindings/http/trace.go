@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// originateTrace sets a new W3C traceparent header on requests that don't have one, if originateTrace is enabled.
+// Returns the generated traceparent, or an empty string if none was generated.
+func (h *HTTPSource) originateTrace(request *http.Request) string {
+	if !h.metadata.OriginateTrace || request.Header.Get(TraceparentHeaderKey) != "" {
+		return ""
+	}
+	tp, err := newTraceparent(h.metadata.OriginateTraceSampled == nil || *h.metadata.OriginateTraceSampled)
+	if err != nil {
+		h.logger.Warnf("Failed to generate traceparent: %v", err)
+		return ""
+	}
+	request.Header.Set(TraceparentHeaderKey, tp)
+	return tp
+}
+
+// newTraceparent returns a traceparent value with a random trace ID and parent ID, in the format "00-<trace-id>-<parent-id>-<flags>".
+func newTraceparent(sampled bool) (string, error) {
+	// The trace ID and parent ID must not be all zeros; the probability of generating them is negligible, but they're checked anyway
+	var traceID [16]byte
+	var parentID [8]byte
+	for traceID == [16]byte{} {
+		_, err := rand.Read(traceID[:])
+		if err != nil {
+			return "", err
+		}
+	}
+	for parentID == [8]byte{} {
+		_, err := rand.Read(parentID[:])
+		if err != nil {
+			return "", err
+		}
+	}
+	flags := "00"
+	if sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(traceID[:]) + "-" + hex.EncodeToString(parentID[:]) + "-" + flags, nil
+}
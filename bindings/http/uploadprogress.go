@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dapr/kit/logger"
+)
+
+// uploadProgress counts the bytes read from a streamed request body, and optionally logs the progress periodically.
+// Streamed bodies can't be sent again, so they are never retried and the count is never reset.
+type uploadProgress struct {
+	r io.Reader
+	// Size of the body, or -1 if unknown
+	total int64
+
+	n        atomic.Int64
+	started  time.Time
+	finished atomic.Int64 // Unix nanoseconds of when the body was read entirely, or 0
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func newUploadProgress(r io.Reader, total int64) *uploadProgress {
+	return &uploadProgress{
+		r:       r,
+		total:   total,
+		started: time.Now(),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+func (u *uploadProgress) Read(p []byte) (int, error) {
+	n, err := u.r.Read(p)
+	u.n.Add(int64(n))
+	if errors.Is(err, io.EOF) {
+		u.finished.CompareAndSwap(0, time.Now().UnixNano())
+	}
+	return n, err
+}
+
+// report logs the progress at every interval, until stop is invoked.
+// If the interval is not positive, no progress is reported, and this is a no-op.
+func (u *uploadProgress) report(log logger.Logger, interval time.Duration, method string, target string) {
+	if interval <= 0 {
+		return
+	}
+	u.wg.Add(1)
+	go func() {
+		defer u.wg.Done()
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-u.stopCh:
+				return
+			case <-t.C:
+				log.Debugf("Upload progress of %s %s: %s", method, target, u.describe())
+			}
+		}
+	}()
+}
+
+// describe returns the number of bytes sent, and the percentage if the size is known.
+func (u *uploadProgress) describe() string {
+	n := u.n.Load()
+	res := strconv.FormatInt(n, 10) + " bytes sent"
+	if u.total > 0 {
+		res += " (" + strconv.FormatFloat(float64(n)*100/float64(u.total), 'f', 1, 64) + "%)"
+	}
+	res += " in " + time.Since(u.started).Round(time.Millisecond).String()
+	return res
+}
+
+// stop stops reporting the progress.
+func (u *uploadProgress) stop() {
+	u.stopOnce.Do(func() {
+		close(u.stopCh)
+	})
+	u.wg.Wait()
+}
+
+// addMetadata adds the number of bytes uploaded and the duration of the upload to the response metadata.
+// If the body wasn't read entirely (e.g. the server responded early), the duration is until now.
+func (u *uploadProgress) addMetadata(metadata map[string]string) {
+	end := time.Now()
+	if finished := u.finished.Load(); finished > 0 {
+		end = time.Unix(0, finished)
+	}
+	metadata["bytesUploaded"] = strconv.FormatInt(u.n.Load(), 10)
+	metadata["uploadDurationMs"] = strconv.FormatInt(end.Sub(u.started).Milliseconds(), 10)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// overrideURL validates the URL in the urlOverride metadata, which replaces the configured URL.
+// The host must be in urlOverrideAllowedHosts; if that's empty, overrides are not allowed.
+func (h *HTTPSource) overrideURL(override string) (string, error) {
+	if len(h.metadata.URLOverrideAllowedHosts) == 0 {
+		return "", errors.New("urlOverride is not allowed: urlOverrideAllowedHosts is not set")
+	}
+	u, err := url.Parse(override)
+	if err != nil || u.Host == "" {
+		return "", errors.New("invalid value for urlOverride: must be an absolute URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid value for urlOverride: unsupported scheme %q", u.Scheme)
+	}
+	if !isHostAllowed(u, h.metadata.URLOverrideAllowedHosts) {
+		return "", fmt.Errorf("urlOverride not allowed: host %s is not in urlOverrideAllowedHosts", u.Host)
+	}
+	return override, nil
+}
+
+// isHostAllowed returns true if the host of the URL matches one of the allowed hosts.
+// Entries with a port must match the host and port; entries starting with "*." match all subdomains.
+func isHostAllowed(u *url.URL, allowed []string) bool {
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" {
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+		if strings.Contains(a, ":") {
+			host = strings.ToLower(u.Host)
+		}
+		if suffix, ok := strings.CutPrefix(a, "*"); ok && strings.HasPrefix(suffix, ".") {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+		} else if host == a {
+			return true
+		}
+	}
+	return false
+}
+
+// applyQueryParams sets the query parameters in the queryParams metadata on the URL, replacing parameters with the same name.
+// The value is either a JSON object, whose values are strings or arrays of strings, or a URL-encoded query string.
+func applyQueryParams(u *url.URL, val string) error {
+	params, err := parseQueryParams(val)
+	if err != nil {
+		return fmt.Errorf("invalid value for queryParams: %w", err)
+	}
+	q := u.Query()
+	for k, v := range params {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+	return nil
+}
+
+func parseQueryParams(val string) (url.Values, error) {
+	val = strings.TrimSpace(val)
+	if !strings.HasPrefix(val, "{") {
+		return url.ParseQuery(strings.TrimPrefix(val, "?"))
+	}
+
+	var obj map[string]json.RawMessage
+	err := json.Unmarshal([]byte(val), &obj)
+	if err != nil {
+		return nil, err
+	}
+	res := make(url.Values, len(obj))
+	for k, raw := range obj {
+		var (
+			str  string
+			list []string
+		)
+		switch {
+		case json.Unmarshal(raw, &str) == nil:
+			res[k] = []string{str}
+		case json.Unmarshal(raw, &list) == nil:
+			res[k] = list
+		default:
+			// Numbers and booleans are used as they appear in the JSON
+			s := string(raw)
+			if strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[") || s == "null" {
+				return nil, fmt.Errorf("value of %q must be a string, a number, a boolean, or an array of strings", k)
+			}
+			res[k] = []string{s}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	trailingSlashKeep  = "keep"
+	trailingSlashAdd   = "add"
+	trailingSlashStrip = "strip"
+)
+
+// validateTrailingSlash validates the value of trailingSlash.
+func validateTrailingSlash(val string) error {
+	switch val {
+	case "", trailingSlashKeep, trailingSlashAdd, trailingSlashStrip:
+		return nil
+	default:
+		return fmt.Errorf("invalid value for trailingSlash: %s (must be %q, %q, or %q)", val, trailingSlashKeep, trailingSlashAdd, trailingSlashStrip)
+	}
+}
+
+// normalizeURLPath collapses duplicate slashes in the path of the URL, and adds or removes the trailing slash according to the trailingSlash policy.
+// The scheme, host, and query are not modified.
+func (h *HTTPSource) normalizeURLPath(u *url.URL) {
+	p := u.EscapedPath()
+	for strings.Contains(p, "//") {
+		p = strings.ReplaceAll(p, "//", "/")
+	}
+
+	switch h.metadata.TrailingSlash {
+	case trailingSlashAdd:
+		if !strings.HasSuffix(p, "/") {
+			p += "/"
+		}
+	case trailingSlashStrip:
+		// The root path is kept
+		if len(p) > 1 {
+			p = strings.TrimRight(p, "/")
+		}
+	}
+
+	if p == u.EscapedPath() {
+		return
+	}
+	unescaped, err := url.PathUnescape(p)
+	if err != nil {
+		// Cannot happen, as the path was escaped by the URL
+		return
+	}
+	u.Path = unescaped
+	u.RawPath = p
+}
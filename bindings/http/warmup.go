@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Maximum time to wait for the warm-up requests to complete
+const warmupTimeout = 15 * time.Second
+
+// warmupConnections establishes connections to the target URL during Init, so the first requests don't pay for the TCP and TLS handshakes.
+// It sends concurrent HEAD requests through the client, so the connections are dialed with the same TLS (SNI, mTLS) settings as regular requests, and are then kept in the idle pool.
+// Any response from the server is a success, regardless of the status code.
+func (h *HTTPSource) warmupConnections(ctx context.Context) error {
+	n := h.metadata.WarmupConnections
+	ctx, cancel := context.WithTimeout(ctx, warmupTimeout)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		lock  sync.Mutex
+		errs  []error
+		start = make(chan struct{})
+	)
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			<-start
+			err := h.warmupConnection(ctx)
+			if err != nil {
+				lock.Lock()
+				errs = append(errs, err)
+				lock.Unlock()
+			}
+		}()
+	}
+	// Release all requests at once, so each one needs a new connection
+	close(start)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d warm-up connections failed: %w", len(errs), n, errors.Join(errs...))
+	}
+	return nil
+}
+
+func (h *HTTPSource) warmupConnection(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, h.metadata.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	// Drain the body so the connection is returned to the idle pool
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
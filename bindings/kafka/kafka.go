@@ -15,33 +15,60 @@ package kafka
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/dapr/components-contrib/bindings"
 	"github.com/dapr/components-contrib/common/component/kafka"
 	contribMetadata "github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/pubsub"
 	"github.com/dapr/kit/logger"
+	kitmd "github.com/dapr/kit/metadata"
+	"github.com/dapr/kit/ptr"
 )
 
 const (
 	publishTopic = "publishTopic"
 	topics       = "topics"
+
+	defaultMaxBatchWait = time.Second
 )
 
 type Binding struct {
 	kafka        *kafka.Kafka
 	publishTopic string
 	topics       []string
+	readMetadata readMetadata
 	logger       logger.Logger
 	closeCh      chan struct{}
 	closed       atomic.Bool
 	wg           sync.WaitGroup
 }
 
+// readMetadata contains the properties of the input binding that are not used by the Kafka pubsub component.
+type readMetadata struct {
+	// If greater than 1, messages are delivered in batches of up to maxBatchSize messages of the same partition.
+	MaxBatchSize int `mapstructure:"maxBatchSize"`
+	// Maximum time to wait for a batch to be filled before delivering it.
+	MaxBatchWait time.Duration `mapstructure:"maxBatchWait"`
+	// Number of messages of each partition that are delivered concurrently.
+	// Messages with the same key are always delivered in order. Can't be greater than 1 when maxBatchSize is greater than 1.
+	PartitionConcurrency int `mapstructure:"partitionConcurrency"`
+}
+
+// batchEntry is a message of a batch delivered to the application.
+type batchEntry struct {
+	// Data is embedded as-is if it's valid JSON, or as a string otherwise.
+	Data     json.RawMessage   `json:"data"`
+	Metadata map[string]string `json:"metadata"`
+}
+
 // NewKafka returns a new kafka binding instance.
 func NewKafka(logger logger.Logger) bindings.InputOutputBinding {
 	k := kafka.NewKafka(logger)
@@ -55,7 +82,12 @@ func NewKafka(logger logger.Logger) bindings.InputOutputBinding {
 }
 
 func (b *Binding) Init(ctx context.Context, metadata bindings.Metadata) error {
-	err := b.kafka.Init(ctx, metadata.Properties)
+	err := b.parseReadMetadata(metadata.Properties)
+	if err != nil {
+		return err
+	}
+
+	err = b.kafka.Init(ctx, metadata.Properties)
 	if err != nil {
 		return err
 	}
@@ -73,6 +105,32 @@ func (b *Binding) Init(ctx context.Context, metadata bindings.Metadata) error {
 	return nil
 }
 
+// parseReadMetadata parses the batch and concurrency settings of the input binding.
+func (b *Binding) parseReadMetadata(meta map[string]string) error {
+	b.readMetadata = readMetadata{
+		MaxBatchWait: defaultMaxBatchWait,
+	}
+	err := kitmd.DecodeMetadata(meta, &b.readMetadata)
+	if err != nil {
+		return err
+	}
+	if b.readMetadata.MaxBatchSize < 0 {
+		return errors.New("kafka binding error: maxBatchSize must not be negative")
+	}
+	if b.readMetadata.MaxBatchWait < time.Millisecond {
+		return errors.New("kafka binding error: maxBatchWait must be at least 1ms")
+	}
+	if b.readMetadata.PartitionConcurrency < 0 {
+		return errors.New("kafka binding error: partitionConcurrency must not be negative")
+	}
+	if b.readMetadata.PartitionConcurrency > 1 && b.readMetadata.MaxBatchSize > 1 {
+		// Batches of a partition are always delivered one at a time
+		return errors.New("kafka binding error: partitionConcurrency can't be used with a maxBatchSize greater than 1")
+	}
+
+	return nil
+}
+
 func (b *Binding) Operations() []bindings.OperationKind {
 	return []bindings.OperationKind{bindings.CreateOperation}
 }
@@ -115,6 +173,17 @@ func (b *Binding) Read(ctx context.Context, handler bindings.Handler) error {
 	handlerConfig := kafka.SubscriptionHandlerConfig{
 		IsBulkSubscribe: false,
 		Handler:         adaptHandler(handler),
+		Concurrency:     b.readMetadata.PartitionConcurrency,
+	}
+	if b.readMetadata.MaxBatchSize > 1 {
+		handlerConfig = kafka.SubscriptionHandlerConfig{
+			IsBulkSubscribe: true,
+			SubscribeConfig: pubsub.BulkSubscribeConfig{
+				MaxMessagesCount:   b.readMetadata.MaxBatchSize,
+				MaxAwaitDurationMs: int(b.readMetadata.MaxBatchWait.Milliseconds()),
+			},
+			BulkHandler: adaptBulkHandler(handler),
+		}
 	}
 
 	b.kafka.Subscribe(ctx, handlerConfig, b.topics...)
@@ -133,9 +202,45 @@ func adaptHandler(handler bindings.Handler) kafka.EventHandler {
 	}
 }
 
+// adaptBulkHandler delivers the messages of a batch to the application as a JSON array of entries, in offset order.
+// If the application returns an error, none of the messages of the batch is marked as consumed.
+func adaptBulkHandler(handler bindings.Handler) kafka.BulkEventHandler {
+	return func(ctx context.Context, event *kafka.KafkaBulkMessage) ([]pubsub.BulkSubscribeResponseEntry, error) {
+		entries := make([]batchEntry, len(event.Entries))
+		for i, entry := range event.Entries {
+			entries[i] = batchEntry{
+				Data:     batchEntryData(entry.Event),
+				Metadata: entry.Metadata,
+			}
+		}
+		data, err := json.Marshal(entries)
+		if err != nil {
+			return nil, fmt.Errorf("kafka binding error: error marshalling batch: %w", err)
+		}
+
+		_, err = handler(ctx, &bindings.ReadResponse{
+			Data:        data,
+			Metadata:    event.Metadata,
+			ContentType: ptr.Of("application/json"),
+		})
+		return nil, err
+	}
+}
+
+// batchEntryData returns the value of a message as JSON.
+func batchEntryData(value []byte) json.RawMessage {
+	if len(value) > 0 && json.Valid(value) {
+		return value
+	}
+	// Invalid UTF-8 sequences are replaced when marshalling the string, so batches are not suitable for binary values
+	data, _ := json.Marshal(string(value))
+	return data
+}
+
 // GetComponentMetadata returns the metadata of the component.
 func (b *Binding) GetComponentMetadata() (metadataInfo contribMetadata.MetadataMap) {
 	metadataStruct := kafka.KafkaMetadata{}
 	contribMetadata.GetMetadataInfoFromStructType(reflect.TypeOf(metadataStruct), &metadataInfo, contribMetadata.BindingType)
+	contribMetadata.GetMetadataInfoFromStructType(reflect.TypeOf(readMetadata{}), &metadataInfo, contribMetadata.BindingType)
 	return
 }
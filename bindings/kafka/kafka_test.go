@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/common/component/kafka"
+)
+
+func TestAdaptBulkHandler(t *testing.T) {
+	event := &kafka.KafkaBulkMessage{
+		Topic: "topic",
+		Entries: []kafka.KafkaBulkMessageEntry{
+			{EntryId: "0", Event: []byte(`{"a":1}`), Metadata: map[string]string{"__offset": "10"}},
+			{EntryId: "1", Event: []byte("hello"), Metadata: map[string]string{"__offset": "11"}},
+		},
+	}
+
+	t.Run("batch is delivered as a JSON array", func(t *testing.T) {
+		var res *bindings.ReadResponse
+		handler := adaptBulkHandler(func(ctx context.Context, msg *bindings.ReadResponse) ([]byte, error) {
+			res = msg
+			return nil, nil
+		})
+
+		_, err := handler(context.Background(), event)
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		assert.JSONEq(t, `[
+			{"data": {"a": 1}, "metadata": {"__offset": "10"}},
+			{"data": "hello", "metadata": {"__offset": "11"}}
+		]`, string(res.Data))
+		require.NotNil(t, res.ContentType)
+		assert.Equal(t, "application/json", *res.ContentType)
+	})
+
+	t.Run("handler error fails the batch", func(t *testing.T) {
+		handler := adaptBulkHandler(func(ctx context.Context, msg *bindings.ReadResponse) ([]byte, error) {
+			return nil, errors.New("handler error")
+		})
+
+		responses, err := handler(context.Background(), event)
+		require.Error(t, err)
+		assert.Empty(t, responses)
+	})
+}
+
+func TestInitReadMetadata(t *testing.T) {
+	b := &Binding{}
+
+	t.Run("defaults", func(t *testing.T) {
+		err := b.parseReadMetadata(map[string]string{})
+		require.NoError(t, err)
+		assert.Equal(t, readMetadata{MaxBatchWait: defaultMaxBatchWait}, b.readMetadata)
+	})
+
+	t.Run("batch and concurrency settings", func(t *testing.T) {
+		err := b.parseReadMetadata(map[string]string{
+			"maxBatchSize": "100",
+			"maxBatchWait": "250ms",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 100, b.readMetadata.MaxBatchSize)
+		assert.Equal(t, "250ms", b.readMetadata.MaxBatchWait.String())
+
+		err = b.parseReadMetadata(map[string]string{
+			"partitionConcurrency": "8",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 8, b.readMetadata.PartitionConcurrency)
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		require.Error(t, b.parseReadMetadata(map[string]string{"maxBatchSize": "-1"}))
+		require.Error(t, b.parseReadMetadata(map[string]string{"maxBatchWait": "0"}))
+		require.Error(t, b.parseReadMetadata(map[string]string{"partitionConcurrency": "-1"}))
+		err := b.parseReadMetadata(map[string]string{"maxBatchSize": "100", "partitionConcurrency": "8"})
+		require.ErrorContains(t, err, "partitionConcurrency can't be used with a maxBatchSize greater than 1")
+	})
+}
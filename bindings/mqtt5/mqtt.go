@@ -0,0 +1,451 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+	"github.com/dapr/kit/retry"
+)
+
+// MQTT allows sending and receiving data to/from an MQTT broker using MQTT 5.
+type MQTT struct {
+	producer     *autopaho.ConnectionManager
+	producerLock sync.RWMutex
+	metadata     mqtt5Metadata
+	logger       logger.Logger
+	isSubscribed atomic.Bool
+	readHandler  bindings.Handler
+	backOff      backoff.BackOff
+	closeCh      chan struct{}
+	closed       atomic.Bool
+	wg           sync.WaitGroup
+}
+
+// NewMQTT returns a new MQTT instance.
+func NewMQTT(logger logger.Logger) bindings.InputOutputBinding {
+	return &MQTT{
+		logger:  logger,
+		closeCh: make(chan struct{}),
+	}
+}
+
+// Init does MQTT connection parsing.
+func (m *MQTT) Init(ctx context.Context, metadata bindings.Metadata) (err error) {
+	m.metadata, err = parseMQTTMetaData(metadata)
+	if err != nil {
+		return err
+	}
+
+	// TODO: Make the backoff configurable for constant or exponential
+	m.backOff = backoff.NewConstantBackOff(5 * time.Second)
+
+	return nil
+}
+
+func (m *MQTT) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{
+		bindings.CreateOperation,
+	}
+}
+
+func (m *MQTT) getProducer() (*autopaho.ConnectionManager, error) {
+	// Get the producer from the cache
+	m.producerLock.RLock()
+	producer := m.producer
+	m.producerLock.RUnlock()
+	if producer != nil {
+		return producer, nil
+	}
+
+	// Must create a new producer
+	m.producerLock.Lock()
+	defer m.producerLock.Unlock()
+
+	// Check again in case another goroutine created it in the meanwhile
+	producer = m.producer
+	if producer != nil {
+		return producer, nil
+	}
+
+	// mqtt broker allows only one connection at a given time from a clientID.
+	producerClientID := fmt.Sprintf("%s-producer", m.metadata.ClientID)
+	p, err := m.connect(producerClientID, false)
+	if err != nil {
+		return nil, err
+	}
+	m.producer = p
+
+	return p, nil
+}
+
+func (m *MQTT) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	producer, err := m.getProducer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create producer connection: %w", err)
+	}
+
+	publish, err := m.newPublish(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The connection manager returns an error rather than waiting while it's reconnecting to the broker.
+	// To mitigate sporadic failures on the Dapr side, this implementation retries 3 times at
+	// a fixed 200ms interval, as the MQTT 3 binding does.
+	bo := backoff.WithMaxRetries(
+		backoff.NewConstantBackOff(200*time.Millisecond), 3,
+	)
+	bo = backoff.WithContext(bo, ctx)
+
+	return nil, retry.NotifyRecover(func() error {
+		publishCtx, cancel := context.WithTimeout(ctx, defaultWait)
+		defer cancel()
+		_, err := producer.Publish(publishCtx, publish)
+		if err != nil {
+			return fmt.Errorf("failed to publish: %w", err)
+		}
+		return nil
+	}, bo, func(err error, _ time.Duration) {
+		m.logger.Debugf("Could not publish MQTT message. Retrying...: %v", err)
+	}, func() {
+		m.logger.Debug("Successfully published MQTT message after it previously failed")
+	})
+}
+
+// newPublish returns the message to publish for the request.
+// The MQTT 5 properties are set from the request metadata, and any other metadata is sent as user properties.
+func (m *MQTT) newPublish(req *bindings.InvokeRequest) (*paho.Publish, error) {
+	publish := &paho.Publish{
+		QoS:        m.metadata.Qos,
+		Retain:     m.metadata.Retain,
+		Topic:      m.metadata.Topic,
+		Payload:    req.Data,
+		Properties: &paho.PublishProperties{},
+	}
+
+	for k, v := range req.Metadata {
+		switch k {
+		case mqttTopic:
+			// If user does not specify a topic, publish via the component's default topic.
+			if v != "" {
+				publish.Topic = v
+			}
+		case mqttResponseTopic:
+			publish.Properties.ResponseTopic = v
+		case mqttCorrelationData:
+			publish.Properties.CorrelationData = []byte(v)
+		case mqttContentType:
+			publish.Properties.ContentType = v
+		case mqttMessageExpiryInterval:
+			expiry, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid metadata property %s: must be a number of seconds: %w", mqttMessageExpiryInterval, err)
+			}
+			interval := uint32(expiry)
+			publish.Properties.MessageExpiry = &interval
+		default:
+			publish.Properties.User.Add(k, v)
+		}
+	}
+
+	return publish, nil
+}
+
+func (m *MQTT) Read(ctx context.Context, handler bindings.Handler) error {
+	if m.closed.Load() {
+		return errors.New("error: binding is closed")
+	}
+
+	// If the subscription is already active, wait 2s before retrying (in case we're still disconnecting), otherwise return an error
+	if !m.isSubscribed.CompareAndSwap(false, true) {
+		m.logger.Debug("Subscription is already active; waiting 2s before retrying…")
+		time.Sleep(2 * time.Second)
+		if !m.isSubscribed.CompareAndSwap(false, true) {
+			return errors.New("the subscription is already active")
+		}
+	}
+
+	m.logger.Infof("Subscribing to topic %s (qos: %d)", m.metadata.Topic, m.metadata.Qos)
+
+	// Store the handler in the object
+	m.readHandler = handler
+
+	// mqtt broker allows only one connection at a given time from a clientID
+	consumerClientID := fmt.Sprintf("%s-consumer", m.metadata.ClientID)
+
+	// Establish the connection
+	// This will also create the subscription in the OnConnectionUp handler
+	consumer, err := m.connect(consumerClientID, true)
+	if err != nil {
+		m.isSubscribed.Store(false)
+		return err
+	}
+
+	// In background, watch for contexts cancelation and stop the connection
+	// However, do not call "unsubscribe" which would cause the broker to stop tracking the last message received by this consumer group
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		select {
+		case <-ctx.Done():
+			// nop
+		case <-m.closeCh:
+			// nop
+		}
+
+		m.logger.Infof("Disconnecting and stopping subscription to topic %s", m.metadata.Topic)
+
+		// Disconnect and then release the "lock"
+		m.disconnect(consumer)
+		m.isSubscribed.Store(false)
+	}()
+
+	return nil
+}
+
+func (m *MQTT) connect(clientID string, isSubscriber bool) (*autopaho.ConnectionManager, error) {
+	cfg, err := m.createClientConfig(clientID, isSubscriber)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use a background context here so that the connection is not tied to the context of the first request
+	// The connection manager is stopped by disconnect
+	cm, err := autopaho.NewConnection(context.Background(), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultWait)
+	defer cancel()
+	err = cm.AwaitConnection(ctx)
+	if err != nil {
+		m.disconnect(cm)
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	return cm, nil
+}
+
+func (m *MQTT) disconnect(cm *autopaho.ConnectionManager) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultWait)
+	defer cancel()
+	err := cm.Disconnect(ctx)
+	if err != nil {
+		m.logger.Warnf("Error disconnecting from broker: %v", err)
+	}
+}
+
+func (m *MQTT) newTLSConfig() *tls.Config {
+	tlsConfig := new(tls.Config)
+
+	if m.metadata.ClientCert != "" && m.metadata.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(m.metadata.ClientCert), []byte(m.metadata.ClientKey))
+		if err != nil {
+			m.logger.Warnf("Unable to load client certificate and key pair. Err: %v", err)
+			return tlsConfig
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if m.metadata.CaCert != "" {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if ok := tlsConfig.RootCAs.AppendCertsFromPEM([]byte(m.metadata.CaCert)); !ok {
+			m.logger.Warnf("Unable to load CA certificate.")
+		}
+	}
+
+	return tlsConfig
+}
+
+// Returns the configuration of the connection for both publisher and subscriber
+func (m *MQTT) createClientConfig(clientID string, isSubscriber bool) (autopaho.ClientConfig, error) {
+	uri, err := url.Parse(m.metadata.Url)
+	if err != nil {
+		return autopaho.ClientConfig{}, err
+	}
+
+	cfg := autopaho.ClientConfig{
+		ServerUrls:                    []*url.URL{{Scheme: uri.Scheme, Host: uri.Host, Path: uri.Path}},
+		TlsCfg:                        m.newTLSConfig(),
+		KeepAlive:                     defaultKeepAlive,
+		CleanStartOnInitialConnection: m.metadata.CleanSession,
+		// Configure reconnections
+		ConnectRetryDelay: 20 * time.Second,
+		ConnectTimeout:    defaultWait,
+		OnConnectError: func(err error) {
+			m.logger.Errorf("Error connecting to broker with client ID '%s': %v", clientID, err)
+		},
+		ClientConfig: paho.ClientConfig{
+			ClientID: clientID,
+			OnClientError: func(err error) {
+				m.logger.Errorf("Connection with broker with client ID '%s' lost; error: %v", clientID, err)
+			},
+			OnServerDisconnect: func(d *paho.Disconnect) {
+				m.logger.Errorf("Broker disconnected client ID '%s' with reason code %d", clientID, d.ReasonCode)
+			},
+		},
+	}
+
+	// As with a persistent session in MQTT 3, the session doesn't expire when the connection is closed
+	if !m.metadata.CleanSession {
+		cfg.SessionExpiryInterval = math.MaxUint32
+	}
+
+	cfg.ConnectUsername = uri.User.Username()
+	password, _ := uri.User.Password()
+	if password != "" {
+		cfg.ConnectPassword = []byte(password)
+	}
+
+	if isSubscriber {
+		// Disable automatic ACKs as we need to do it manually
+		cfg.EnableManualAcknowledgment = true
+		cfg.OnPublishReceived = []func(paho.PublishReceived) (bool, error){
+			m.handleMessage,
+		}
+
+		// On (re-)connection, add the topic subscription
+		cfg.OnConnectionUp = func(cm *autopaho.ConnectionManager, _ *paho.Connack) {
+			ctx, cancel := context.WithTimeout(context.Background(), defaultWait)
+			defer cancel()
+			_, err := cm.Subscribe(ctx, &paho.Subscribe{
+				Subscriptions: []paho.SubscribeOptions{
+					{Topic: m.metadata.Topic, QoS: m.metadata.Qos},
+				},
+			})
+
+			// Nothing we can do in case of errors besides logging them
+			// If we get here, the connection is almost likely broken anyways, so the client will attempt a reconnection soon if it hasn't already
+			if err != nil {
+				m.logger.Errorf("Error starting subscriptions in the OnConnectionUp handler: %v", err)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+func (m *MQTT) handleMessage(pr paho.PublishReceived) (bool, error) {
+	// Handlers must not block the client, so messages are processed in background
+	// Acknowledgements are still sent to the broker in the order in which messages were received
+	go func() {
+		msg := pr.Packet
+		bo := m.backOff
+		if m.metadata.BackOffMaxRetries >= 0 {
+			bo = backoff.WithMaxRetries(bo, uint64(m.metadata.BackOffMaxRetries))
+		}
+
+		err := retry.NotifyRecover(
+			func() error {
+				m.logger.Debugf("Processing MQTT message %s/%d", msg.Topic, msg.PacketID)
+				// Use a background context here so that the context is not tied to the
+				// first Invoke first created the producer.
+				_, err := m.readHandler(context.Background(), &bindings.ReadResponse{
+					Data:     msg.Payload,
+					Metadata: readMetadata(msg),
+				})
+				if err != nil {
+					return err
+				}
+
+				// Ack the message on success
+				return pr.Client.Ack(msg)
+			},
+			bo,
+			func(err error, d time.Duration) {
+				m.logger.Errorf("Error processing MQTT message: %s/%d. Retrying…", msg.Topic, msg.PacketID)
+			},
+			func() {
+				m.logger.Infof("Successfully processed MQTT message after it previously failed: %s/%d", msg.Topic, msg.PacketID)
+			},
+		)
+		if err != nil {
+			m.logger.Errorf("Failed processing MQTT message: %s/%d: %v", msg.Topic, msg.PacketID, err)
+		}
+	}()
+
+	return true, nil
+}
+
+// readMetadata returns the metadata of a received message, with its MQTT 5 properties and user properties.
+// User properties don't override the topic and the other properties.
+func readMetadata(msg *paho.Publish) map[string]string {
+	md := map[string]string{}
+	if msg.Properties != nil {
+		for _, p := range msg.Properties.User {
+			md[p.Key] = p.Value
+		}
+		if msg.Properties.ResponseTopic != "" {
+			md[mqttResponseTopic] = msg.Properties.ResponseTopic
+		}
+		if len(msg.Properties.CorrelationData) > 0 {
+			md[mqttCorrelationData] = string(msg.Properties.CorrelationData)
+		}
+		if msg.Properties.ContentType != "" {
+			md[mqttContentType] = msg.Properties.ContentType
+		}
+		// The broker sets the remaining expiry interval of the message
+		if msg.Properties.MessageExpiry != nil {
+			md[mqttMessageExpiryInterval] = strconv.FormatUint(uint64(*msg.Properties.MessageExpiry), 10)
+		}
+	}
+	md[mqttTopic] = msg.Topic
+	return md
+}
+
+func (m *MQTT) Close() error {
+	m.producerLock.Lock()
+	defer m.producerLock.Unlock()
+
+	if m.closed.CompareAndSwap(false, true) {
+		close(m.closeCh)
+	}
+
+	if m.producer != nil {
+		m.disconnect(m.producer)
+		m.producer = nil
+	}
+
+	m.wg.Wait()
+
+	return nil
+}
+
+// GetComponentMetadata returns the metadata of the component.
+func (m *MQTT) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
+	metadataStruct := mqtt5Metadata{}
+	metadata.GetMetadataInfoFromStructType(reflect.TypeOf(metadataStruct), &metadataInfo, metadata.BindingType)
+	return
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mqtt
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	mdata "github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+const (
+	// Environment variable containing the host name for MQTT integration tests
+	// To run using docker:
+	//   Create mosquitto.conf with content:
+	//     listener 1883
+	//     allow_anonymous true
+	//   And run:
+	//nolint:misspell
+	//     docker run -d -v mosquitto.conf:/mosquitto/config/mosquitto.conf --name test-mqtt -p 1883:1883 eclipse-mosquitto:2
+	// In that case the connection string will be: mqtt://127.0.0.1:1883
+	testMQTTConnectionStringEnvKey = "DAPR_TEST_MQTT_URL"
+)
+
+func getConnectionString() string {
+	return os.Getenv(testMQTTConnectionStringEnvKey)
+}
+
+func TestRequestReply(t *testing.T) {
+	url := getConnectionString()
+	if url == "" {
+		t.Skipf("MQTT connection string configuration must be set in environment variable '%s' (example 'mqtt://localhost:1883')", testMQTTConnectionStringEnvKey)
+	}
+
+	newBinding := func(topic string) *MQTT {
+		b := NewMQTT(logger.NewLogger("test")).(*MQTT)
+		err := b.Init(context.Background(), bindings.Metadata{Base: mdata.Base{
+			Name: "testQueue",
+			Properties: map[string]string{
+				"consumerID":        uuid.NewString(),
+				"url":               url,
+				"topic":             topic,
+				"cleanSession":      "true",
+				"backOffMaxRetries": "0",
+			},
+		}})
+		require.NoError(t, err)
+		return b
+	}
+
+	requestTopic := "/app/requests/" + uuid.NewString()
+	responseTopic := "/app/responses/" + uuid.NewString()
+
+	// The responder replies to the response topic of each request, with its correlation data
+	responder := newBinding(requestTopic)
+	defer responder.Close()
+	err := responder.Read(context.Background(), func(ctx context.Context, r *bindings.ReadResponse) ([]byte, error) {
+		_, err := responder.Invoke(ctx, &bindings.InvokeRequest{
+			Data: append([]byte("reply to "), r.Data...),
+			Metadata: map[string]string{
+				mqttTopic:           r.Metadata[mqttResponseTopic],
+				mqttCorrelationData: r.Metadata[mqttCorrelationData],
+				"tenant":            r.Metadata["tenant"],
+			},
+		})
+		return nil, err
+	})
+	require.NoError(t, err)
+
+	requester := newBinding(responseTopic)
+	defer requester.Close()
+	responses := make(chan *bindings.ReadResponse, 1)
+	err = requester.Read(context.Background(), func(ctx context.Context, r *bindings.ReadResponse) ([]byte, error) {
+		responses <- r
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	_, err = requester.Invoke(context.Background(), &bindings.InvokeRequest{
+		Data: []byte("hello"),
+		Metadata: map[string]string{
+			mqttTopic:                 requestTopic,
+			mqttResponseTopic:         responseTopic,
+			mqttCorrelationData:       "request-1",
+			mqttMessageExpiryInterval: "60",
+			"tenant":                  "contoso",
+		},
+	})
+	require.NoError(t, err)
+
+	select {
+	case r := <-responses:
+		assert.Equal(t, "reply to hello", string(r.Data))
+		assert.Equal(t, responseTopic, r.Metadata[mqttTopic])
+		assert.Equal(t, "request-1", r.Metadata[mqttCorrelationData])
+		assert.Equal(t, "contoso", r.Metadata["tenant"])
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the response")
+	}
+}
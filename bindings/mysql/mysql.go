@@ -42,6 +42,9 @@ const (
 	queryOperation bindings.OperationKind = "query"
 	closeOperation bindings.OperationKind = "close"
 
+	// Executes the statements in the request data in a single transaction.
+	transactionOperation bindings.OperationKind = "transaction"
+
 	// configurations to connect to Mysql, either a data source name represent by URL.
 	connectionURLKey = "url"
 
@@ -158,6 +161,11 @@ func (m *Mysql) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 		return nil, errors.New("component is closed")
 	}
 
+	// The statements of a transaction are in the request data rather than in the metadata
+	if req.Operation == transactionOperation {
+		return m.invokeTransaction(ctx, req)
+	}
+
 	if req.Metadata == nil {
 		return nil, errors.New("metadata required")
 	}
@@ -205,8 +213,8 @@ func (m *Mysql) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 		resp.Data = d
 
 	default:
-		return nil, fmt.Errorf("invalid operation type: %s. Expected %s, %s, or %s",
-			req.Operation, execOperation, queryOperation, closeOperation)
+		return nil, fmt.Errorf("invalid operation type: %s. Expected %s, %s, %s, or %s",
+			req.Operation, execOperation, queryOperation, transactionOperation, closeOperation)
 	}
 
 	endTime := time.Now().UTC()
@@ -221,6 +229,7 @@ func (m *Mysql) Operations() []bindings.OperationKind {
 	return []bindings.OperationKind{
 		execOperation,
 		queryOperation,
+		transactionOperation,
 		closeOperation,
 	}
 }
@@ -265,6 +274,84 @@ func (m *Mysql) exec(ctx context.Context, sql string, params ...any) (int64, err
 	return res.RowsAffected()
 }
 
+// transactionStatement is a statement of the transaction operation.
+type transactionStatement struct {
+	SQL    string `json:"sql"`
+	Params []any  `json:"params"`
+}
+
+// invokeTransaction executes the statements in the request data, in order, in a single transaction.
+// If any statement fails, the transaction is rolled back; otherwise, the response data contains the number of rows affected by each statement.
+func (m *Mysql) invokeTransaction(ctx context.Context, req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	var statements []transactionStatement
+	err := json.Unmarshal(req.Data, &statements)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request data for the %s operation: failed to unserialize into an array of statements: %w", transactionOperation, err)
+	}
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("the %s operation requires at least one statement", transactionOperation)
+	}
+	for i, stmt := range statements {
+		if stmt.SQL == "" {
+			return nil, fmt.Errorf("statement %d of the transaction is missing the %s property", i, commandSQLKey)
+		}
+	}
+
+	startTime := time.Now().UTC()
+	rowsAffected, err := m.execTransaction(ctx, statements)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, r := range rowsAffected {
+		total += r
+	}
+	data, err := json.Marshal(rowsAffected)
+	if err != nil {
+		return nil, err
+	}
+
+	endTime := time.Now().UTC()
+	return &bindings.InvokeResponse{
+		Data: data,
+		Metadata: map[string]string{
+			respOpKey:           string(transactionOperation),
+			respStartTimeKey:    startTime.Format(time.RFC3339Nano),
+			respRowsAffectedKey: strconv.FormatInt(total, 10),
+			respEndTimeKey:      endTime.Format(time.RFC3339Nano),
+			respDurationKey:     endTime.Sub(startTime).String(),
+		},
+	}, nil
+}
+
+func (m *Mysql) execTransaction(ctx context.Context, statements []transactionStatement) ([]int64, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	// Rollback is a no-op if the transaction was committed
+	defer tx.Rollback()
+
+	rowsAffected := make([]int64, len(statements))
+	for i, stmt := range statements {
+		res, err := tx.ExecContext(ctx, stmt.SQL, stmt.Params...)
+		if err != nil {
+			return nil, fmt.Errorf("error executing statement %d of the transaction: %w", i, err)
+		}
+		rowsAffected[i], err = res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("error getting rows affected by statement %d of the transaction: %w", i, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+	return rowsAffected, nil
+}
+
 func initDB(url, pemPath string) (*sql.DB, error) {
 	conf, err := mysql.ParseDSN(url)
 	if err != nil {
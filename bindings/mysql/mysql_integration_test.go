@@ -37,8 +37,9 @@ func TestOperations(t *testing.T) {
 		b := NewMysql(logger.NewLogger("test"))
 		require.NotNil(t, b)
 		l := b.Operations()
-		assert.Len(t, l, 3)
+		assert.Len(t, l, 4)
 		assert.Contains(t, l, execOperation)
+		assert.Contains(t, l, transactionOperation)
 		assert.Contains(t, l, closeOperation)
 		assert.Contains(t, l, queryOperation)
 	})
@@ -154,6 +154,61 @@ func TestInvoke(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("transaction operation succeeds", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO foo \\(id, v1\\) VALUES \\(\\?, \\?\\)").WithArgs(float64(1), "test-1").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("UPDATE foo SET v1 = \\?").WithArgs("test-2").WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+		req := &bindings.InvokeRequest{
+			Data: []byte(`[
+				{"sql": "INSERT INTO foo (id, v1) VALUES (?, ?)", "params": [1, "test-1"]},
+				{"sql": "UPDATE foo SET v1 = ?", "params": ["test-2"]}
+			]`),
+			Operation: transactionOperation,
+		}
+		resp, err := m.Invoke(context.Background(), req)
+		require.NoError(t, err)
+		assert.JSONEq(t, `[1, 3]`, string(resp.Data))
+		assert.Equal(t, "4", resp.Metadata[respRowsAffectedKey])
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("transaction operation rolls back on failure", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO foo \\(id, v1\\) VALUES \\(\\?, \\?\\)").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("UPDATE foo SET v1 = \\?").WillReturnError(errors.New("update failed"))
+		mock.ExpectRollback()
+		req := &bindings.InvokeRequest{
+			Data: []byte(`[
+				{"sql": "INSERT INTO foo (id, v1) VALUES (?, ?)", "params": [1, "test-1"]},
+				{"sql": "UPDATE foo SET v1 = ?", "params": ["test-2"]}
+			]`),
+			Operation: transactionOperation,
+		}
+		resp, err := m.Invoke(context.Background(), req)
+		assert.Nil(t, resp)
+		require.Error(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("transaction operation with invalid statements", func(t *testing.T) {
+		for name, data := range map[string]string{
+			"not JSON":    `INSERT INTO foo (id) VALUES (1)`,
+			"empty list":  `[]`,
+			"missing sql": `[{"params": [1]}]`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				req := &bindings.InvokeRequest{
+					Data:      []byte(data),
+					Operation: transactionOperation,
+				}
+				resp, err := m.Invoke(context.Background(), req)
+				assert.Nil(t, resp)
+				require.Error(t, err)
+			})
+		}
+	})
+
 	t.Run("close operation", func(t *testing.T) {
 		mock.ExpectClose()
 		req := &bindings.InvokeRequest{
@@ -38,8 +38,22 @@ const (
 
 	commandSQLKey  = "sql"
 	commandArgsKey = "params"
+
+	// Format of the rows returned by the query operation: "array" (the default), with the values of each row in an array, or "object", with the values keyed by column name.
+	rowFormatKey    = "rowFormat"
+	rowFormatArray  = "array"
+	rowFormatObject = "object"
+	// Response metadata with the JSON-encoded list of columns returned by the query operation.
+	columnsKey = "columns"
 )
 
+// column is the metadata of a column returned by the query operation.
+type column struct {
+	Name string `json:"name"`
+	// Name of the PostgreSQL data type, or its OID if the type is unknown.
+	Type string `json:"type"`
+}
+
 // Postgres represents PostgreSQL output binding.
 type Postgres struct {
 	logger logger.Logger
@@ -144,11 +158,21 @@ func (p *Postgres) Invoke(ctx context.Context, req *bindings.InvokeRequest) (res
 		resp.Metadata["rows-affected"] = strconv.FormatInt(r, 10) // 0 if error
 
 	case queryOperation:
-		d, err := p.query(ctx, sql, args...)
+		rowFormat := req.Metadata[rowFormatKey]
+		switch rowFormat {
+		case "":
+			rowFormat = rowFormatArray
+		case rowFormatArray, rowFormatObject:
+			// Nop
+		default:
+			return nil, fmt.Errorf("invalid metadata property %s: must be %s or %s", rowFormatKey, rowFormatArray, rowFormatObject)
+		}
+		d, columns, err := p.query(ctx, rowFormat, sql, args...)
 		if err != nil {
 			return nil, err
 		}
 		resp.Data = d
+		resp.Metadata[columnsKey] = columns
 
 	default:
 		return nil, fmt.Errorf(
@@ -180,27 +204,57 @@ func (p *Postgres) Close() error {
 	return nil
 }
 
-func (p *Postgres) query(ctx context.Context, sql string, args ...any) (result []byte, err error) {
+// query executes the query, and returns the rows in the given format and the JSON-encoded list of columns.
+func (p *Postgres) query(ctx context.Context, rowFormat string, sql string, args ...any) (result []byte, columns string, err error) {
 	rows, err := p.db.Query(ctx, sql, args...)
 	if err != nil {
-		return nil, fmt.Errorf("error executing query: %w", err)
+		return nil, "", fmt.Errorf("error executing query: %w", err)
+	}
+	defer rows.Close()
+
+	fields := rows.FieldDescriptions()
+	cols := make([]column, len(fields))
+	typeMap := rows.Conn().TypeMap()
+	for i, f := range fields {
+		cols[i] = column{
+			Name: f.Name,
+			Type: strconv.FormatUint(uint64(f.DataTypeOID), 10),
+		}
+		if t, ok := typeMap.TypeForOID(f.DataTypeOID); ok {
+			cols[i].Type = t.Name
+		}
 	}
 
 	rs := make([]any, 0)
 	for rows.Next() {
 		val, rowErr := rows.Values()
 		if rowErr != nil {
-			return nil, fmt.Errorf("error reading result '%v': %w", rows.Err(), rowErr)
+			return nil, "", fmt.Errorf("error reading result '%v': %w", rows.Err(), rowErr)
+		}
+		if rowFormat == rowFormatObject {
+			obj := make(map[string]any, len(val))
+			for i, v := range val {
+				obj[cols[i].Name] = v
+			}
+			rs = append(rs, obj)
+		} else {
+			rs = append(rs, val) //nolint:asasalint
 		}
-		rs = append(rs, val) //nolint:asasalint
+	}
+	if err = rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("error reading results: %w", err)
 	}
 
 	result, err = json.Marshal(rs)
 	if err != nil {
-		return nil, fmt.Errorf("error serializing results: %w", err)
+		return nil, "", fmt.Errorf("error serializing results: %w", err)
+	}
+	colsJSON, err := json.Marshal(cols)
+	if err != nil {
+		return nil, "", fmt.Errorf("error serializing columns: %w", err)
 	}
 
-	return result, nil
+	return result, string(colsJSON), nil
 }
 
 func (p *Postgres) exec(ctx context.Context, sql string, args ...any) (result int64, err error) {
@@ -109,6 +109,19 @@ func TestPostgresIntegration(t *testing.T) {
 		assertResponse(t, res, err)
 	})
 
+	t.Run("Invoke select with params and row objects", func(t *testing.T) {
+		req.Operation = queryOperation
+		req.Metadata[commandSQLKey] = "SELECT id, v1 FROM foo WHERE id = $1 AND v1 = $2"
+		req.Metadata[commandArgsKey] = `[1, "test-1"]`
+		req.Metadata[rowFormatKey] = rowFormatObject
+		res, err := b.Invoke(ctx, req)
+		assertResponse(t, res, err)
+		assert.JSONEq(t, `[{"id": 1, "v1": "test-1"}]`, string(res.Data))
+		assert.JSONEq(t, `[{"name": "id", "type": "int8"}, {"name": "v1", "type": "varchar"}]`, res.Metadata[columnsKey])
+		delete(req.Metadata, commandArgsKey)
+		delete(req.Metadata, rowFormatKey)
+	})
+
 	t.Run("Invoke delete", func(t *testing.T) {
 		req.Operation = execOperation
 		req.Metadata[commandSQLKey] = testDelete
@@ -57,6 +57,12 @@ const (
 
 	errorChannelConnection = "channel/connection is not open"
 	defaultReconnectWait   = 5 * time.Second
+
+	defaultPublisherConfirmTimeout  = 5 * time.Second
+	defaultDeadLetterExchangeFormat = "dlx-%s"
+	defaultDeadLetterQueueFormat    = "dlq-%s"
+	rabbitMQDeadLetterExchangeKey   = "x-dead-letter-exchange"
+	fanoutExchangeKind              = "fanout"
 )
 
 var errClosed = errors.New("component is stopped")
@@ -92,6 +98,15 @@ type rabbitMQMetadata struct {
 	ClientCert       string         `mapstructure:"clientCert"`
 	ClientKey        string         `mapstructure:"clientKey"`
 	ExternalSasl     bool           `mapstructure:"externalSasl"`
+
+	// If true, Invoke waits for the broker to confirm that it took responsibility for the message, and fails otherwise.
+	PublisherConfirm        bool          `mapstructure:"publisherConfirm"`
+	PublisherConfirmTimeout time.Duration `mapstructure:"publisherConfirmTimeout"`
+
+	// If true, messages rejected by the input binding are routed to the dead letter queue instead of being requeued.
+	EnableDeadLetter   bool   `mapstructure:"enableDeadLetter"`
+	DeadLetterExchange string `mapstructure:"deadLetterExchange"`
+	DeadLetterQueue    string `mapstructure:"deadLetterQueue"`
 }
 
 // NewRabbitMQ returns a new rabbitmq instance.
@@ -140,6 +155,12 @@ func (r *RabbitMQ) reconnectWhenNecessary() {
 			r.channelMutex.Lock()
 			if r.connection != nil && !r.connection.IsClosed() {
 				ch, err := r.connection.Channel()
+				if err == nil {
+					err = r.enableConfirms(ch)
+					if err != nil {
+						ch.Close()
+					}
+				}
 				if err == nil {
 					r.notifyRabbitChannelClose = make(chan *amqp.Error, 1)
 					ch.NotifyClose(r.notifyRabbitChannelClose)
@@ -171,6 +192,18 @@ func (r *RabbitMQ) reconnectWhenNecessary() {
 	}
 }
 
+// enableConfirms puts the channel in confirm mode if publisherConfirm is enabled.
+func (r *RabbitMQ) enableConfirms(ch *amqp.Channel) error {
+	if !r.metadata.PublisherConfirm {
+		return nil
+	}
+	err := ch.Confirm(false)
+	if err != nil {
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	return nil
+}
+
 func dial(uri string) (conn *amqp.Connection, ch *amqp.Channel, err error) {
 	conn, err = amqp.Dial(uri)
 	if err != nil {
@@ -252,17 +285,31 @@ func (r *RabbitMQ) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bi
 		pub.Priority = priority
 	}
 
-	err = ch.PublishWithContext(ctx, "", r.metadata.QueueName, false, false, pub)
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, "", r.metadata.QueueName, false, false, pub)
 	if err != nil {
 		return nil, fmt.Errorf("failed to publish message: %w", err)
 	}
 
+	// confirm is nil if publisher confirms are not enabled
+	if confirm != nil {
+		confirmCtx, cancel := context.WithTimeout(ctx, r.metadata.PublisherConfirmTimeout)
+		defer cancel()
+		ok, err := confirm.WaitContext(confirmCtx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to receive publisher confirmation: %w", err)
+		}
+		if !ok {
+			return nil, errors.New("message was not confirmed by the broker")
+		}
+	}
+
 	return nil, nil
 }
 
 func (r *RabbitMQ) parseMetadata(meta bindings.Metadata) error {
 	m := rabbitMQMetadata{
-		ReconnectWait: defaultReconnectWait,
+		ReconnectWait:           defaultReconnectWait,
+		PublisherConfirmTimeout: defaultPublisherConfirmTimeout,
 	}
 
 	decodeErr := kitmd.DecodeMetadata(meta.Properties, &m)
@@ -278,6 +325,19 @@ func (r *RabbitMQ) parseMetadata(meta bindings.Metadata) error {
 		return errors.New("missing queue Name")
 	}
 
+	if m.PublisherConfirmTimeout <= 0 {
+		return errors.New("publisherConfirmTimeout must be greater than 0")
+	}
+
+	if m.EnableDeadLetter {
+		if m.DeadLetterExchange == "" {
+			m.DeadLetterExchange = fmt.Sprintf(defaultDeadLetterExchangeFormat, m.QueueName)
+		}
+		if m.DeadLetterQueue == "" {
+			m.DeadLetterQueue = fmt.Sprintf(defaultDeadLetterQueueFormat, m.QueueName)
+		}
+	}
+
 	if val := meta.Properties[maxPriority]; val != "" {
 		parsedVal, err := strconv.ParseUint(val, defaultBase, defaultBitSize)
 		if err != nil {
@@ -363,7 +423,38 @@ func (r *RabbitMQ) declareQueue(channel *amqp.Channel) (amqp.Queue, error) {
 		args[rabbitMQMaxPriorityKey] = *r.metadata.MaxPriority
 	}
 
-	return channel.QueueDeclare(r.metadata.QueueName, r.metadata.Durable, r.metadata.DeleteWhenUnused, r.metadata.Exclusive, false, args)
+	if r.metadata.EnableDeadLetter {
+		err := r.declareDeadLetter(channel)
+		if err != nil {
+			return amqp.Queue{}, err
+		}
+		args[rabbitMQDeadLetterExchangeKey] = r.metadata.DeadLetterExchange
+	}
+
+	q, err := channel.QueueDeclare(r.metadata.QueueName, r.metadata.Durable, r.metadata.DeleteWhenUnused, r.metadata.Exclusive, false, args)
+	// RabbitMQ doesn't allow changing the arguments of an existing queue, and closes the channel
+	var amqpErr *amqp.Error
+	if r.metadata.EnableDeadLetter && errors.As(err, &amqpErr) && amqpErr.Code == amqp.PreconditionFailed {
+		return q, fmt.Errorf("queue %s already exists without the dead letter exchange %s, and its arguments can't be changed: delete the queue (after consuming its messages) so that it's declared again with enableDeadLetter: %w", r.metadata.QueueName, r.metadata.DeadLetterExchange, err)
+	}
+	return q, err
+}
+
+// declareDeadLetter declares the dead letter exchange, and the durable dead letter queue bound to it.
+func (r *RabbitMQ) declareDeadLetter(channel *amqp.Channel) error {
+	err := channel.ExchangeDeclare(r.metadata.DeadLetterExchange, fanoutExchangeKind, true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to declare dead letter exchange %s: %w", r.metadata.DeadLetterExchange, err)
+	}
+	_, err = channel.QueueDeclare(r.metadata.DeadLetterQueue, true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to declare dead letter queue %s: %w", r.metadata.DeadLetterQueue, err)
+	}
+	err = channel.QueueBind(r.metadata.DeadLetterQueue, "", r.metadata.DeadLetterExchange, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to bind dead letter queue %s: %w", r.metadata.DeadLetterQueue, err)
+	}
+	return nil
 }
 
 func (r *RabbitMQ) Read(ctx context.Context, handler bindings.Handler) error {
@@ -484,7 +575,8 @@ func (r *RabbitMQ) handleMessage(ctx context.Context, handler bindings.Handler,
 				Metadata: metadata,
 			})
 			if err != nil {
-				ch.Nack(d.DeliveryTag, false, true)
+				// With a dead letter exchange, rejected messages are dead-lettered instead of being requeued
+				ch.Nack(d.DeliveryTag, false, !r.metadata.EnableDeadLetter)
 			} else {
 				ch.Ack(d.DeliveryTag, false)
 			}
@@ -521,9 +613,16 @@ func (r *RabbitMQ) connect() error {
 		return err
 	}
 
+	err = r.enableConfirms(ch)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
 	ch.Qos(r.metadata.PrefetchCount, 0, true)
 	q, err := r.declareQueue(ch)
 	if err != nil {
+		conn.Close()
 		return err
 	}
 
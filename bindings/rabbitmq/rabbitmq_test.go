@@ -233,3 +233,52 @@ func TestParseMetadataWithInvalidMaxPriority(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMetadataPublisherConfirmAndDeadLetter(t *testing.T) {
+	const queueName = "test-queue"
+	const host = "test-host"
+
+	t.Run("defaults", func(t *testing.T) {
+		m := bindings.Metadata{}
+		m.Properties = map[string]string{"queueName": queueName, "host": host}
+		r := RabbitMQ{logger: logger.NewLogger("test")}
+		err := r.parseMetadata(m)
+		require.NoError(t, err)
+		assert.False(t, r.metadata.PublisherConfirm)
+		assert.Equal(t, defaultPublisherConfirmTimeout, r.metadata.PublisherConfirmTimeout)
+		assert.False(t, r.metadata.EnableDeadLetter)
+		assert.Empty(t, r.metadata.DeadLetterExchange)
+		assert.Empty(t, r.metadata.DeadLetterQueue)
+	})
+
+	t.Run("default dead letter names", func(t *testing.T) {
+		m := bindings.Metadata{}
+		m.Properties = map[string]string{"queueName": queueName, "host": host, "publisherConfirm": "true", "publisherConfirmTimeout": "2s", "enableDeadLetter": "true"}
+		r := RabbitMQ{logger: logger.NewLogger("test")}
+		err := r.parseMetadata(m)
+		require.NoError(t, err)
+		assert.True(t, r.metadata.PublisherConfirm)
+		assert.Equal(t, 2*time.Second, r.metadata.PublisherConfirmTimeout)
+		assert.True(t, r.metadata.EnableDeadLetter)
+		assert.Equal(t, "dlx-"+queueName, r.metadata.DeadLetterExchange)
+		assert.Equal(t, "dlq-"+queueName, r.metadata.DeadLetterQueue)
+	})
+
+	t.Run("custom dead letter names", func(t *testing.T) {
+		m := bindings.Metadata{}
+		m.Properties = map[string]string{"queueName": queueName, "host": host, "enableDeadLetter": "true", "deadLetterExchange": "my-dlx", "deadLetterQueue": "my-dlq"}
+		r := RabbitMQ{logger: logger.NewLogger("test")}
+		err := r.parseMetadata(m)
+		require.NoError(t, err)
+		assert.Equal(t, "my-dlx", r.metadata.DeadLetterExchange)
+		assert.Equal(t, "my-dlq", r.metadata.DeadLetterQueue)
+	})
+
+	t.Run("invalid publisherConfirmTimeout", func(t *testing.T) {
+		m := bindings.Metadata{}
+		m.Properties = map[string]string{"queueName": queueName, "host": host, "publisherConfirm": "true", "publisherConfirmTimeout": "0"}
+		r := RabbitMQ{logger: logger.NewLogger("test")}
+		err := r.parseMetadata(m)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+)
+
+const (
+	// Keys of the request metadata for streams.
+	maxLenKey = "maxLen"
+	startKey  = "start"
+	countKey  = "count"
+
+	// By default, xread returns the entries from the start of the stream.
+	defaultStreamStart = "0"
+)
+
+// streamEntry is an entry of a stream returned by the xread operation.
+type streamEntry struct {
+	ID     string            `json:"id"`
+	Values map[string]string `json:"values"`
+}
+
+func (r *Redis) expire(ctx context.Context, requestMetadata map[string]string, key string) error {
+	ttl, ok, err := metadata.TryGetTTL(requestMetadata)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("redis binding: missing %s in request metadata", metadata.TTLMetadataKey)
+	}
+	return r.client.DoWrite(ctx, "EXPIRE", key, int(ttl.Seconds()))
+}
+
+func (r *Redis) hset(ctx context.Context, req *bindings.InvokeRequest, key string) error {
+	fields, err := parseFields(req.Data)
+	if err != nil {
+		return err
+	}
+	args := make([]any, 0, 2+2*len(fields))
+	args = append(args, "HSET", key)
+	for field, value := range fields {
+		args = append(args, field, value)
+	}
+	err = r.client.DoWrite(ctx, args...)
+	if err != nil {
+		return err
+	}
+	return r.expireKeyIfRequested(ctx, req.Metadata, key)
+}
+
+func (r *Redis) hgetall(ctx context.Context, key string) (*bindings.InvokeResponse, error) {
+	res, err := r.client.DoRead(ctx, "HGETALL", key)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := toFieldMap(res)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return &bindings.InvokeResponse{Data: data}, nil
+}
+
+func (r *Redis) lpush(ctx context.Context, req *bindings.InvokeRequest, key string) error {
+	err := r.client.DoWrite(ctx, "LPUSH", key, req.Data)
+	if err != nil {
+		return err
+	}
+	return r.expireKeyIfRequested(ctx, req.Metadata, key)
+}
+
+func (r *Redis) rpop(ctx context.Context, key string) (*bindings.InvokeResponse, error) {
+	res, err := r.client.DoRead(ctx, "RPOP", key)
+	if err != nil {
+		if err.Error() == "redis: nil" {
+			return &bindings.InvokeResponse{}, nil
+		}
+		return nil, err
+	}
+	return &bindings.InvokeResponse{Data: []byte(toString(res))}, nil
+}
+
+func (r *Redis) xadd(ctx context.Context, req *bindings.InvokeRequest, key string) (*bindings.InvokeResponse, error) {
+	fields, err := parseFields(req.Data)
+	if err != nil {
+		return nil, err
+	}
+	// If set, the stream is trimmed to approximately maxLen entries
+	var maxLen int64
+	if val := req.Metadata[maxLenKey]; val != "" {
+		maxLen, err = strconv.ParseInt(val, 10, 64)
+		if err != nil || maxLen < 0 {
+			return nil, fmt.Errorf("redis binding: invalid %s in request metadata: %s", maxLenKey, val)
+		}
+	}
+	values := make(map[string]any, len(fields))
+	for field, value := range fields {
+		values[field] = value
+	}
+	id, err := r.client.XAdd(ctx, key, maxLen, values)
+	if err != nil {
+		return nil, err
+	}
+	err = r.expireKeyIfRequested(ctx, req.Metadata, key)
+	if err != nil {
+		return nil, err
+	}
+	return &bindings.InvokeResponse{Data: []byte(id)}, nil
+}
+
+func (r *Redis) xread(ctx context.Context, requestMetadata map[string]string, key string) (*bindings.InvokeResponse, error) {
+	args := []any{"XREAD"}
+	if val := requestMetadata[countKey]; val != "" {
+		count, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("redis binding: invalid %s in request metadata: %s", countKey, val)
+		}
+		args = append(args, "COUNT", count)
+	}
+	// Entries with an ID greater than start are returned
+	start := requestMetadata[startKey]
+	if start == "" {
+		start = defaultStreamStart
+	}
+	args = append(args, "STREAMS", key, start)
+
+	entries := []streamEntry{}
+	res, err := r.client.DoRead(ctx, args...)
+	if err != nil && err.Error() != "redis: nil" {
+		return nil, err
+	}
+	if err == nil {
+		entries, err = toStreamEntries(res)
+		if err != nil {
+			return nil, err
+		}
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	return &bindings.InvokeResponse{Data: data}, nil
+}
+
+// parseFields parses the fields of a hash or stream entry from a JSON object.
+// String values are stored as they are, while other values are stored as JSON.
+func parseFields(data []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("redis binding: data must be a JSON object with the fields: %w", err)
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("redis binding: data must contain at least one field")
+	}
+	fields := make(map[string]string, len(raw))
+	for field, value := range raw {
+		var s string
+		if json.Unmarshal(value, &s) == nil {
+			fields[field] = s
+		} else {
+			fields[field] = string(value)
+		}
+	}
+	return fields, nil
+}
+
+// toFieldMap converts the fields of a hash or stream entry returned by Redis, which are a flat list of fields and values in RESP2 and a map in RESP3.
+func toFieldMap(res any) (map[string]string, error) {
+	switch v := res.(type) {
+	case []any:
+		if len(v)%2 != 0 {
+			return nil, errors.New("redis binding: unexpected number of fields in response")
+		}
+		fields := make(map[string]string, len(v)/2)
+		for i := 0; i < len(v); i += 2 {
+			fields[toString(v[i])] = toString(v[i+1])
+		}
+		return fields, nil
+	case map[any]any:
+		fields := make(map[string]string, len(v))
+		for field, value := range v {
+			fields[toString(field)] = toString(value)
+		}
+		return fields, nil
+	default:
+		return nil, fmt.Errorf("redis binding: unexpected response type %T", res)
+	}
+}
+
+// toStreamEntries converts the response of XREAD for a single stream, which is a list of streams in RESP2 and a map of streams in RESP3.
+func toStreamEntries(res any) ([]streamEntry, error) {
+	var items []any
+	switch v := res.(type) {
+	case []any:
+		for _, stream := range v {
+			s, ok := stream.([]any)
+			if !ok || len(s) != 2 {
+				return nil, errors.New("redis binding: unexpected stream in response")
+			}
+			list, _ := s[1].([]any)
+			items = append(items, list...)
+		}
+	case map[any]any:
+		for _, list := range v {
+			l, _ := list.([]any)
+			items = append(items, l...)
+		}
+	default:
+		return nil, fmt.Errorf("redis binding: unexpected response type %T", res)
+	}
+
+	entries := make([]streamEntry, len(items))
+	for i, item := range items {
+		e, ok := item.([]any)
+		if !ok || len(e) != 2 {
+			return nil, errors.New("redis binding: unexpected stream entry in response")
+		}
+		values, err := toFieldMap(e[1])
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = streamEntry{ID: toString(e[0]), Values: values}
+	}
+	return entries, nil
+}
+
+func toString(v any) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case []byte:
+		return string(s)
+	default:
+		return fmt.Sprint(v)
+	}
+}
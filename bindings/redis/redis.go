@@ -35,6 +35,20 @@ type Redis struct {
 const (
 	// IncrementOperation is the operation to increment a key.
 	IncrementOperation bindings.OperationKind = "increment"
+	// ExpireOperation is the operation to set the TTL of a key.
+	ExpireOperation bindings.OperationKind = "expire"
+	// HSetOperation is the operation to set fields of a hash.
+	HSetOperation bindings.OperationKind = "hset"
+	// HGetAllOperation is the operation to get all fields of a hash.
+	HGetAllOperation bindings.OperationKind = "hgetall"
+	// LPushOperation is the operation to push an element to the head of a list.
+	LPushOperation bindings.OperationKind = "lpush"
+	// RPopOperation is the operation to pop an element from the tail of a list.
+	RPopOperation bindings.OperationKind = "rpop"
+	// XAddOperation is the operation to add an entry to a stream.
+	XAddOperation bindings.OperationKind = "xadd"
+	// XReadOperation is the operation to read entries from a stream.
+	XReadOperation bindings.OperationKind = "xread"
 )
 
 // NewRedis returns a new redis bindings instance.
@@ -71,6 +85,13 @@ func (r *Redis) Operations() []bindings.OperationKind {
 		bindings.DeleteOperation,
 		bindings.GetOperation,
 		IncrementOperation,
+		ExpireOperation,
+		HSetOperation,
+		HGetAllOperation,
+		LPushOperation,
+		RPopOperation,
+		XAddOperation,
+		XReadOperation,
 	}
 }
 
@@ -132,6 +153,20 @@ func (r *Redis) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*bindi
 			if err != nil {
 				return nil, err
 			}
+		case ExpireOperation:
+			return nil, r.expire(ctx, req.Metadata, key)
+		case HSetOperation:
+			return nil, r.hset(ctx, req, key)
+		case HGetAllOperation:
+			return r.hgetall(ctx, key)
+		case LPushOperation:
+			return nil, r.lpush(ctx, req, key)
+		case RPopOperation:
+			return r.rpop(ctx, key)
+		case XAddOperation:
+			return r.xadd(ctx, req, key)
+		case XReadOperation:
+			return r.xread(ctx, req.Metadata, key)
 		default:
 			return nil, fmt.Errorf("invalid operation type: %s", req.Operation)
 		}
@@ -15,6 +15,7 @@ package redis
 
 import (
 	"context"
+	"strconv"
 	"testing"
 	"time"
 
@@ -244,6 +245,152 @@ func TestIncrement(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestHashes(t *testing.T) {
+	s, c := setupMiniredis()
+	defer s.Close()
+
+	bind := &Redis{
+		client: c,
+		logger: logger.NewLogger("test"),
+	}
+	_, err := bind.Invoke(context.TODO(), &bindings.InvokeRequest{
+		Data:      []byte(`{"name": "dapr", "count": 2, "tags": ["a", "b"]}`),
+		Metadata:  map[string]string{"key": "hashKey", metadata.TTLMetadataKey: "5"},
+		Operation: HSetOperation,
+	})
+	require.NoError(t, err)
+
+	rgetRep, err := c.DoRead(context.Background(), "TTL", "hashKey")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), rgetRep)
+
+	res, err := bind.Invoke(context.TODO(), &bindings.InvokeRequest{
+		Metadata:  map[string]string{"key": "hashKey"},
+		Operation: HGetAllOperation,
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "dapr", "count": "2", "tags": "[\"a\", \"b\"]"}`, string(res.Data))
+
+	res, err = bind.Invoke(context.TODO(), &bindings.InvokeRequest{
+		Metadata:  map[string]string{"key": "missingKey"},
+		Operation: HGetAllOperation,
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(res.Data))
+
+	_, err = bind.Invoke(context.TODO(), &bindings.InvokeRequest{
+		Data:      []byte(`"not an object"`),
+		Metadata:  map[string]string{"key": "hashKey"},
+		Operation: HSetOperation,
+	})
+	require.Error(t, err)
+}
+
+func TestLists(t *testing.T) {
+	s, c := setupMiniredis()
+	defer s.Close()
+
+	bind := &Redis{
+		client: c,
+		logger: logger.NewLogger("test"),
+	}
+	for _, data := range []string{"first", "second"} {
+		_, err := bind.Invoke(context.TODO(), &bindings.InvokeRequest{
+			Data:      []byte(data),
+			Metadata:  map[string]string{"key": "listKey"},
+			Operation: LPushOperation,
+		})
+		require.NoError(t, err)
+	}
+
+	for _, expected := range []string{"first", "second", ""} {
+		res, err := bind.Invoke(context.TODO(), &bindings.InvokeRequest{
+			Metadata:  map[string]string{"key": "listKey"},
+			Operation: RPopOperation,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(res.Data))
+	}
+}
+
+func TestStreams(t *testing.T) {
+	s, c := setupMiniredis()
+	defer s.Close()
+
+	bind := &Redis{
+		client: c,
+		logger: logger.NewLogger("test"),
+	}
+	ids := make([]string, 3)
+	for i := range ids {
+		res, err := bind.Invoke(context.TODO(), &bindings.InvokeRequest{
+			Data:      []byte(`{"n": ` + strconv.Itoa(i) + `}`),
+			Metadata:  map[string]string{"key": "streamKey"},
+			Operation: XAddOperation,
+		})
+		require.NoError(t, err)
+		ids[i] = string(res.Data)
+	}
+
+	t.Run("read from the start", func(t *testing.T) {
+		res, err := bind.Invoke(context.TODO(), &bindings.InvokeRequest{
+			Metadata:  map[string]string{"key": "streamKey", "count": "2"},
+			Operation: XReadOperation,
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `[
+			{"id": "`+ids[0]+`", "values": {"n": "0"}},
+			{"id": "`+ids[1]+`", "values": {"n": "1"}}
+		]`, string(res.Data))
+	})
+
+	t.Run("read after an entry", func(t *testing.T) {
+		res, err := bind.Invoke(context.TODO(), &bindings.InvokeRequest{
+			Metadata:  map[string]string{"key": "streamKey", "start": ids[1]},
+			Operation: XReadOperation,
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"id": "`+ids[2]+`", "values": {"n": "2"}}]`, string(res.Data))
+	})
+
+	t.Run("no entries", func(t *testing.T) {
+		res, err := bind.Invoke(context.TODO(), &bindings.InvokeRequest{
+			Metadata:  map[string]string{"key": "streamKey", "start": ids[2]},
+			Operation: XReadOperation,
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `[]`, string(res.Data))
+	})
+}
+
+func TestExpire(t *testing.T) {
+	s, c := setupMiniredis()
+	defer s.Close()
+
+	bind := &Redis{
+		client: c,
+		logger: logger.NewLogger("test"),
+	}
+	err := c.DoWrite(context.Background(), "SET", testKey, testData)
+	require.NoError(t, err)
+
+	_, err = bind.Invoke(context.TODO(), &bindings.InvokeRequest{
+		Metadata:  map[string]string{"key": testKey},
+		Operation: ExpireOperation,
+	})
+	require.Error(t, err)
+
+	_, err = bind.Invoke(context.TODO(), &bindings.InvokeRequest{
+		Metadata:  map[string]string{"key": testKey, metadata.TTLMetadataKey: "3"},
+		Operation: ExpireOperation,
+	})
+	require.NoError(t, err)
+
+	rgetRep, err := c.DoRead(context.Background(), "TTL", testKey)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), rgetRep)
+}
+
 func setupMiniredis() (*miniredis.Miniredis, rediscomponent.RedisClient) {
 	s, err := miniredis.Run()
 	if err != nil {
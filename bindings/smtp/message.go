@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smtp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"gopkg.in/gomail.v2"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/kit/utils"
+)
+
+const (
+	// If true, the request data is a JSON object with the body of the message and its attachments and inline images.
+	metadataMultipart = "multipart"
+	// If true, the body is a Go template, which is rendered with the request metadata.
+	metadataBodyTemplate = "bodyTemplate"
+
+	bodyTypeHTML  = "text/html"
+	bodyTypePlain = "text/plain"
+)
+
+// multipartPayload is the request data of a multipart message.
+type multipartPayload struct {
+	Body string `json:"body"`
+	// Content type of the body: "text/html" (the default) or "text/plain".
+	BodyType    string       `json:"bodyType"`
+	Attachments []attachment `json:"attachments"`
+	// Images referenced in the HTML body with "cid:" followed by their filename.
+	InlineImages []attachment `json:"inlineImages"`
+}
+
+type attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	// Base64-encoded content of the file.
+	Data string `json:"data"`
+}
+
+// setBody sets the body of the message from the request, with its attachments and inline images if the request is multipart.
+func setBody(msg *gomail.Message, req *bindings.InvokeRequest) error {
+	if !utils.IsTruthy(req.Metadata[metadataMultipart]) {
+		body, err := strconv.Unquote(string(req.Data))
+		if err != nil {
+			// When data arrives over gRPC it's not quoted. Unquoting the original data will result in an error.
+			// Instead of unquoting it we'll just use the raw string as that one's already in the right format.
+			body = string(req.Data)
+		}
+		body, err = renderBody(body, bodyTypeHTML, req.Metadata)
+		if err != nil {
+			return err
+		}
+		msg.SetBody(bodyTypeHTML, body)
+		return nil
+	}
+
+	var payload multipartPayload
+	err := json.Unmarshal(req.Data, &payload)
+	if err != nil {
+		return fmt.Errorf("smtp binding error: invalid multipart message: %w", err)
+	}
+	switch payload.BodyType {
+	case "":
+		payload.BodyType = bodyTypeHTML
+	case bodyTypeHTML, bodyTypePlain:
+		// Nop
+	default:
+		return fmt.Errorf("smtp binding error: unsupported bodyType %q (must be %s or %s)", payload.BodyType, bodyTypeHTML, bodyTypePlain)
+	}
+	body, err := renderBody(payload.Body, payload.BodyType, req.Metadata)
+	if err != nil {
+		return err
+	}
+	msg.SetBody(payload.BodyType, body)
+
+	for _, a := range payload.Attachments {
+		setting, err := a.fileSetting()
+		if err != nil {
+			return err
+		}
+		msg.Attach(a.Filename, setting...)
+	}
+	for _, a := range payload.InlineImages {
+		setting, err := a.fileSetting()
+		if err != nil {
+			return err
+		}
+		msg.Embed(a.Filename, setting...)
+	}
+	return nil
+}
+
+// renderBody renders the body as a Go template with the request metadata, if bodyTemplate is set.
+// HTML bodies are rendered with html/template, so the values are escaped.
+func renderBody(body string, bodyType string, metadata map[string]string) (string, error) {
+	if !utils.IsTruthy(metadata[metadataBodyTemplate]) {
+		return body, nil
+	}
+
+	var (
+		sb  strings.Builder
+		err error
+	)
+	if bodyType == bodyTypeHTML {
+		var tpl *htmltemplate.Template
+		tpl, err = htmltemplate.New("body").Option("missingkey=error").Parse(body)
+		if err == nil {
+			err = tpl.Execute(&sb, metadata)
+		}
+	} else {
+		var tpl *texttemplate.Template
+		tpl, err = texttemplate.New("body").Option("missingkey=error").Parse(body)
+		if err == nil {
+			err = tpl.Execute(&sb, metadata)
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("smtp binding error: failed to render body template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// fileSetting returns the settings to add the decoded file to the message, rather than reading it from disk.
+func (a attachment) fileSetting() ([]gomail.FileSetting, error) {
+	if a.Filename == "" {
+		return nil, errors.New("smtp binding error: attachments and inline images must have a filename")
+	}
+	data, err := base64.StdEncoding.DecodeString(a.Data)
+	if err != nil {
+		return nil, fmt.Errorf("smtp binding error: invalid base64 data for file %s: %w", a.Filename, err)
+	}
+
+	settings := []gomail.FileSetting{
+		gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		}),
+	}
+	if a.ContentType != "" {
+		settings = append(settings, gomail.SetHeader(map[string][]string{"Content-Type": {a.ContentType}}))
+	}
+	return settings, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smtp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/gomail.v2"
+
+	"github.com/dapr/components-contrib/bindings"
+)
+
+func renderMessage(t *testing.T, req *bindings.InvokeRequest) (string, error) {
+	t.Helper()
+
+	msg := gomail.NewMessage()
+	err := setBody(msg, req)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	_, err = msg.WriteTo(&buf)
+	require.NoError(t, err)
+	return buf.String(), nil
+}
+
+func TestSetBody(t *testing.T) {
+	t.Run("HTML body", func(t *testing.T) {
+		out, err := renderMessage(t, &bindings.InvokeRequest{Data: []byte(`"<b>Hello</b>"`)})
+		require.NoError(t, err)
+		assert.Contains(t, out, "Content-Type: text/html")
+		assert.Contains(t, out, "<b>Hello</b>")
+	})
+
+	t.Run("body template", func(t *testing.T) {
+		out, err := renderMessage(t, &bindings.InvokeRequest{
+			Data: []byte(`<p>Hello {{.name}}</p>`),
+			Metadata: map[string]string{
+				"bodyTemplate": "true",
+				"name":         "<Dapr>",
+			},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out, "<p>Hello &lt;Dapr&gt;</p>")
+	})
+
+	t.Run("body template with missing value", func(t *testing.T) {
+		_, err := renderMessage(t, &bindings.InvokeRequest{
+			Data:     []byte(`<p>Hello {{.name}}</p>`),
+			Metadata: map[string]string{"bodyTemplate": "true"},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("multipart message", func(t *testing.T) {
+		attachment := base64.StdEncoding.EncodeToString([]byte("report content"))
+		image := base64.StdEncoding.EncodeToString([]byte("image content"))
+		out, err := renderMessage(t, &bindings.InvokeRequest{
+			Data: []byte(`{
+				"body": "Hello {{.name}}",
+				"bodyType": "text/plain",
+				"attachments": [{"filename": "report.txt", "contentType": "text/plain", "data": "` + attachment + `"}],
+				"inlineImages": [{"filename": "logo.png", "data": "` + image + `"}]
+			}`),
+			Metadata: map[string]string{
+				"multipart":    "true",
+				"bodyTemplate": "true",
+				"name":         "Dapr",
+			},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out, "Content-Type: multipart/mixed")
+		assert.Contains(t, out, "Content-Type: multipart/related")
+		assert.Contains(t, out, "Hello Dapr")
+		assert.Contains(t, out, `Content-Disposition: attachment; filename="report.txt"`)
+		assert.Contains(t, out, attachment)
+		assert.Contains(t, out, "Content-ID: <logo.png>")
+		assert.Contains(t, out, image)
+	})
+
+	t.Run("invalid multipart messages", func(t *testing.T) {
+		for name, data := range map[string]string{
+			"not JSON":            `<b>Hello</b>`,
+			"invalid bodyType":    `{"body": "Hello", "bodyType": "text/markdown"}`,
+			"missing filename":    `{"body": "Hello", "attachments": [{"data": "aGVsbG8="}]}`,
+			"invalid base64 data": `{"body": "Hello", "inlineImages": [{"filename": "logo.png", "data": "not base64!"}]}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := renderMessage(t, &bindings.InvokeRequest{
+					Data:     []byte(data),
+					Metadata: map[string]string{"multipart": "true"},
+				})
+				require.Error(t, err)
+			})
+		}
+	})
+}
@@ -111,15 +111,9 @@ func (s *Mailer) Invoke(_ context.Context, req *bindings.InvokeRequest) (*bindin
 	msg.SetHeader("Subject", metadata.Subject)
 	msg.SetHeader("X-priority", strconv.Itoa(metadata.Priority))
 
-	body, err := strconv.Unquote(string(req.Data))
-
+	err = setBody(msg, req)
 	if err != nil {
-		// When data arrives over gRPC it's not quoted. Unquoting the original data will result in an error.
-		// Instead of unquoting it we'll just use the raw string as that one's already in the right format.
-
-		msg.SetBody("text/html", string(req.Data))
-	} else {
-		msg.SetBody("text/html", body)
+		return nil, err
 	}
 
 	// Send message
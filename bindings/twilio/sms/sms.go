@@ -38,6 +38,17 @@ const (
 	authToken     = "authToken"
 	timeout       = "timeout"
 	twilioURLBase = "https://api.twilio.com/2010-04-01/Accounts/"
+
+	// Request metadata with a comma-separated list of URLs of media to send with the message (MMS).
+	mediaURL       = "mediaUrl"
+	channel        = "channel"
+	statusCallback = "statusCallback"
+
+	channelSMS      = "sms"
+	channelWhatsApp = "whatsapp"
+	// Maximum number of media URLs per message.
+	// See: https://www.twilio.com/docs/messaging/api/message-resource#request-body-parameters
+	maxMediaURLs = 10
 )
 
 type SMS struct {
@@ -52,6 +63,12 @@ type twilioMetadata struct {
 	AccountSid string        `mapstructure:"accountSid"`
 	AuthToken  string        `mapstructure:"authToken"`
 	Timeout    time.Duration `mapstructure:"timeout"`
+
+	// Messaging channel: "sms" (the default, which includes MMS) or "whatsapp".
+	// With "whatsapp", the "whatsapp:" prefix is added to the sender and recipient numbers.
+	Channel string `mapstructure:"channel"`
+	// URL that Twilio calls when the status of a message changes.
+	StatusCallback string `mapstructure:"statusCallback"`
 }
 
 func NewSMS(logger logger.Logger) bindings.OutputBinding {
@@ -82,6 +99,13 @@ func (t *SMS) Init(_ context.Context, meta bindings.Metadata) error {
 	if twilioM.AuthToken == "" {
 		return errors.New(`"authToken" is a required field`)
 	}
+	if twilioM.Channel == "" {
+		twilioM.Channel = channelSMS
+	}
+	err = validateChannel(twilioM.Channel)
+	if err != nil {
+		return err
+	}
 
 	t.metadata = twilioM
 	t.httpClient.Timeout = twilioM.Timeout
@@ -103,12 +127,37 @@ func (t *SMS) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*binding
 		toNumberValue = toNumberFromRequest
 	}
 
+	channelValue := t.metadata.Channel
+	if val := req.Metadata[channel]; val != "" {
+		err := validateChannel(val)
+		if err != nil {
+			return nil, err
+		}
+		channelValue = val
+	}
+
 	body := commonutils.Unquote(req.Data)
 
 	v := url.Values{}
-	v.Set("To", toNumberValue)
-	v.Set("From", t.metadata.FromNumber)
+	v.Set("To", channelAddress(channelValue, toNumberValue))
+	v.Set("From", channelAddress(channelValue, t.metadata.FromNumber))
 	v.Set("Body", body)
+	if val := req.Metadata[mediaURL]; val != "" {
+		urls := strings.Split(val, ",")
+		if len(urls) > maxMediaURLs {
+			return nil, fmt.Errorf("twilio \"%s\" field can't have more than %d URLs", mediaURL, maxMediaURLs)
+		}
+		for _, u := range urls {
+			v.Add("MediaUrl", strings.TrimSpace(u))
+		}
+	}
+	statusCallbackValue := t.metadata.StatusCallback
+	if val := req.Metadata[statusCallback]; val != "" {
+		statusCallbackValue = val
+	}
+	if statusCallbackValue != "" {
+		v.Set("StatusCallback", statusCallbackValue)
+	}
 
 	twilioURL := twilioURLBase + t.metadata.AccountSid + "/Messages.json"
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, twilioURL, strings.NewReader(v.Encode()))
@@ -135,6 +184,23 @@ func (t *SMS) Invoke(ctx context.Context, req *bindings.InvokeRequest) (*binding
 	return nil, nil
 }
 
+func validateChannel(val string) error {
+	switch val {
+	case channelSMS, channelWhatsApp:
+		return nil
+	default:
+		return fmt.Errorf("twilio \"%s\" field must be \"%s\" or \"%s\"", channel, channelSMS, channelWhatsApp)
+	}
+}
+
+// channelAddress returns the address of the phone number on the channel.
+func channelAddress(channelValue string, number string) string {
+	if channelValue == channelWhatsApp && !strings.HasPrefix(number, channelWhatsApp+":") {
+		return channelWhatsApp + ":" + number
+	}
+	return number
+}
+
 // GetComponentMetadata returns the metadata of the component.
 func (t *SMS) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
 	metadataStruct := twilioMetadata{}
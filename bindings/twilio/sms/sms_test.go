@@ -221,3 +221,77 @@ func TestMessageBody(t *testing.T) {
 		t.Run("Message body is empty", tester([]byte(""), ""))
 	})
 }
+
+func TestChannelsAndMedia(t *testing.T) {
+	httpTransport := &mockTransport{
+		response: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))},
+	}
+	m := bindings.Metadata{}
+	m.Properties = map[string]string{
+		"fromNumber":     "+15550000000",
+		"accountSid":     "accountSid",
+		"authToken":      "authToken",
+		"statusCallback": "https://example.com/status",
+	}
+	tw := NewSMS(logger.NewLogger("test")).(*SMS)
+	tw.httpClient = &http.Client{
+		Transport: httpTransport,
+	}
+	err := tw.Init(context.Background(), m)
+	require.NoError(t, err)
+
+	invoke := func(t *testing.T, metadata map[string]string) url.Values {
+		t.Helper()
+		httpTransport.reset()
+		_, err := tw.Invoke(context.Background(), &bindings.InvokeRequest{
+			Data:     []byte("hello world"),
+			Metadata: metadata,
+		})
+		require.NoError(t, err)
+		body, err := io.ReadAll(httpTransport.request.Body)
+		require.NoError(t, err)
+		q, err := url.ParseQuery(string(body))
+		require.NoError(t, err)
+		return q
+	}
+
+	t.Run("SMS with status callback", func(t *testing.T) {
+		q := invoke(t, map[string]string{toNumber: "+15551111111"})
+		assert.Equal(t, "+15551111111", q.Get("To"))
+		assert.Equal(t, "+15550000000", q.Get("From"))
+		assert.Equal(t, "https://example.com/status", q.Get("StatusCallback"))
+		assert.Empty(t, q["MediaUrl"])
+	})
+
+	t.Run("MMS with media URLs", func(t *testing.T) {
+		q := invoke(t, map[string]string{
+			toNumber:       "+15551111111",
+			mediaURL:       "https://example.com/a.png, https://example.com/b.jpg",
+			statusCallback: "https://example.com/other",
+		})
+		assert.Equal(t, []string{"https://example.com/a.png", "https://example.com/b.jpg"}, q["MediaUrl"])
+		assert.Equal(t, "https://example.com/other", q.Get("StatusCallback"))
+	})
+
+	t.Run("WhatsApp", func(t *testing.T) {
+		q := invoke(t, map[string]string{toNumber: "whatsapp:+15551111111", channel: "whatsapp"})
+		assert.Equal(t, "whatsapp:+15551111111", q.Get("To"))
+		assert.Equal(t, "whatsapp:+15550000000", q.Get("From"))
+	})
+
+	t.Run("invalid channel", func(t *testing.T) {
+		_, err := tw.Invoke(context.Background(), &bindings.InvokeRequest{
+			Data:     []byte("hello world"),
+			Metadata: map[string]string{toNumber: "+15551111111", channel: "fax"},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("too many media URLs", func(t *testing.T) {
+		_, err := tw.Invoke(context.Background(), &bindings.InvokeRequest{
+			Data:     []byte("hello world"),
+			Metadata: map[string]string{toNumber: "+15551111111", mediaURL: strings.Repeat("https://example.com/a.png,", maxMediaURLs) + "https://example.com/a.png"},
+		})
+		require.Error(t, err)
+	})
+}
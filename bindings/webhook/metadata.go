@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	kitmd "github.com/dapr/kit/metadata"
+)
+
+const (
+	defaultListenPath       = "/"
+	defaultMaxBodySizeBytes = 4 << 20 // 4 MB
+	defaultHMACHeader       = "X-Signature"
+	// Type of the CloudEvents when cloudEventType is not set
+	defaultCloudEventType = "com.dapr.binding.webhook"
+
+	// Values for hmacAlgorithm.
+	hmacAlgorithmSHA256 = "sha256"
+	hmacAlgorithmSHA1   = "sha1"
+
+	// Shorthands for hmacFormat.
+	hmacFormatBody          = "body"
+	hmacFormatTimestampBody = "timestamp.body"
+
+	// Placeholders in hmacFormat and hmacSignatureFormat.
+	hmacPlaceholderBody      = "{body}"
+	hmacPlaceholderTimestamp = "{timestamp}"
+	hmacPlaceholderSignature = "{signature}"
+)
+
+type webhookMetadata struct {
+	// Address (host:port) of the HTTP server that receives webhooks, such as ":8080".
+	ListenAddress string `mapstructure:"listenAddress"`
+	// Path on which webhooks are received; paths ending with "/" match all paths with that prefix.
+	// Default: "/"
+	ListenPath string `mapstructure:"listenPath"`
+	// Maximum size of the body of requests; larger requests are rejected with status code 413. A value <= 0 means no limit.
+	// Default: 4Mi
+	MaxBodySize kitmd.ByteSize `mapstructure:"maxBodySize"`
+	// If set, the HMAC signature of requests is verified with this secret, and requests that don't match are rejected with status code 401 without being delivered to the app.
+	HMACSecret string `mapstructure:"hmacSecret"`
+	// Header containing the signature of requests, such as "X-Hub-Signature-256" (GitHub), "Stripe-Signature" (Stripe), or "X-Slack-Signature" (Slack).
+	// Default: "X-Signature"
+	HMACHeader string `mapstructure:"hmacHeader"`
+	// Hash algorithm of the signature: "sha256" or "sha1".
+	// Default: "sha256"
+	HMACAlgorithm string `mapstructure:"hmacAlgorithm"`
+	// Message that is signed: "body", "timestamp.body", or a template with the {body} and {timestamp} placeholders, such as "v0:{timestamp}:{body}" (Slack).
+	// Default: "body"
+	HMACFormat string `mapstructure:"hmacFormat"`
+	// Value of the signature header, as a template with the {signature} and {timestamp} placeholders, such as "sha256={signature}" (GitHub), "t={timestamp},v1={signature}" (Stripe), or "v0={signature}" (Slack).
+	// The signature can be hex or base64-encoded.
+	// Default: "{signature}"
+	HMACSignatureFormat string `mapstructure:"hmacSignatureFormat"`
+	// Header containing the signed timestamp in Unix seconds, when it's not part of the signature header, such as "X-Slack-Request-Timestamp" (Slack).
+	HMACTimestampHeader string `mapstructure:"hmacTimestampHeader"`
+	// Maximum difference between the signed timestamp and the current time; requests with older or newer timestamps are rejected, to prevent replay attacks.
+	// If zero, the timestamp is not checked.
+	HMACMaxAge time.Duration `mapstructure:"hmacMaxAge"`
+	// If true, requests are delivered to the app as CloudEvents, with the request body as data. Requests that are already CloudEvents are delivered as-is.
+	CloudEvents bool `mapstructure:"cloudEvents"`
+	// Source of the CloudEvents.
+	// Default: the path of the request
+	CloudEventSource string `mapstructure:"cloudEventSource"`
+	// Type of the CloudEvents.
+	// Default: "com.dapr.binding.webhook"
+	CloudEventType string `mapstructure:"cloudEventType"`
+	// Header whose value, when present, is the type of the CloudEvents instead of cloudEventType, such as "X-GitHub-Event".
+	CloudEventTypeHeader string `mapstructure:"cloudEventTypeHeader"`
+	// Header whose value, when present, is the ID of the CloudEvents instead of a random UUID, such as "X-GitHub-Delivery".
+	CloudEventIDHeader string `mapstructure:"cloudEventIdHeader"`
+
+	// Computed values
+	maxBodySizeBytes int64
+	signatureRegexp  *regexp.Regexp
+}
+
+func parseMetadata(props map[string]string) (m webhookMetadata, err error) {
+	m = webhookMetadata{
+		ListenPath:     defaultListenPath,
+		MaxBodySize:    kitmd.NewByteSize(defaultMaxBodySizeBytes),
+		CloudEventType: defaultCloudEventType,
+	}
+	err = kitmd.DecodeMetadata(props, &m)
+	if err != nil {
+		return m, err
+	}
+
+	if m.ListenAddress == "" {
+		return m, errors.New("missing listenAddress")
+	}
+	if !strings.HasPrefix(m.ListenPath, "/") {
+		return m, errors.New("invalid value for listenPath: must start with \"/\"")
+	}
+	m.maxBodySizeBytes, err = m.MaxBodySize.GetBytes()
+	if err != nil {
+		return m, fmt.Errorf("invalid value for maxBodySize: %w", err)
+	}
+	err = m.validateHMAC()
+	if err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
+// validateHMAC validates the metadata for verifying the signature of requests, sets the defaults, and compiles hmacSignatureFormat.
+func (m *webhookMetadata) validateHMAC() error {
+	if m.HMACSecret == "" {
+		return nil
+	}
+	if m.HMACHeader == "" {
+		m.HMACHeader = defaultHMACHeader
+	}
+	switch strings.ToLower(m.HMACAlgorithm) {
+	case "":
+		m.HMACAlgorithm = hmacAlgorithmSHA256
+	case hmacAlgorithmSHA256, hmacAlgorithmSHA1:
+		m.HMACAlgorithm = strings.ToLower(m.HMACAlgorithm)
+	default:
+		return fmt.Errorf("invalid value for hmacAlgorithm: %s (must be %q or %q)", m.HMACAlgorithm, hmacAlgorithmSHA256, hmacAlgorithmSHA1)
+	}
+
+	switch m.HMACFormat {
+	case "", hmacFormatBody:
+		m.HMACFormat = hmacPlaceholderBody
+	case hmacFormatTimestampBody:
+		m.HMACFormat = hmacPlaceholderTimestamp + "." + hmacPlaceholderBody
+	default:
+		if strings.Count(m.HMACFormat, hmacPlaceholderBody) != 1 {
+			return fmt.Errorf("invalid value for hmacFormat: must contain %s exactly once", hmacPlaceholderBody)
+		}
+	}
+
+	if m.HMACSignatureFormat == "" {
+		m.HMACSignatureFormat = hmacPlaceholderSignature
+	}
+	if strings.Count(m.HMACSignatureFormat, hmacPlaceholderSignature) != 1 || strings.Count(m.HMACSignatureFormat, hmacPlaceholderTimestamp) > 1 {
+		return fmt.Errorf("invalid value for hmacSignatureFormat: must contain %s exactly once, and %s at most once", hmacPlaceholderSignature, hmacPlaceholderTimestamp)
+	}
+	// The template is matched as a prefix of the header value, so headers can contain other fields after the signature
+	pattern := regexp.QuoteMeta(m.HMACSignatureFormat)
+	pattern = strings.Replace(pattern, regexp.QuoteMeta(hmacPlaceholderSignature), `(?P<signature>[A-Za-z0-9+/=_-]+)`, 1)
+	pattern = strings.Replace(pattern, regexp.QuoteMeta(hmacPlaceholderTimestamp), `(?P<timestamp>[0-9]+)`, 1)
+	m.signatureRegexp = regexp.MustCompile("^" + pattern)
+
+	hasTimestamp := strings.Contains(m.HMACSignatureFormat, hmacPlaceholderTimestamp) || m.HMACTimestampHeader != ""
+	if !hasTimestamp && (strings.Contains(m.HMACFormat, hmacPlaceholderTimestamp) || m.HMACMaxAge > 0) {
+		return fmt.Errorf("a hmacFormat containing %s, or hmacMaxAge, requires a hmacSignatureFormat containing %s, or hmacTimestampHeader", hmacPlaceholderTimestamp, hmacPlaceholderTimestamp)
+	}
+	if m.HMACMaxAge < 0 {
+		return errors.New("invalid value for hmacMaxAge: must not be negative")
+	}
+	return nil
+}
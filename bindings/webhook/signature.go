@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errSignatureMismatch = errors.New("webhook signature mismatch")
+
+// verifySignature verifies the HMAC signature of a request.
+// The signature and timestamp are extracted from the signature header with hmacSignatureFormat; the timestamp can also be in hmacTimestampHeader.
+// The signed message is built from hmacFormat, replacing {timestamp} and {body}.
+func (w *Webhook) verifySignature(header http.Header, body []byte) error {
+	value := strings.TrimSpace(header.Get(w.metadata.HMACHeader))
+	if value == "" {
+		return fmt.Errorf("%w: missing %s header", errSignatureMismatch, w.metadata.HMACHeader)
+	}
+	re := w.metadata.signatureRegexp
+	match := re.FindStringSubmatch(value)
+	if match == nil {
+		return fmt.Errorf("%w: %s header doesn't match hmacSignatureFormat", errSignatureMismatch, w.metadata.HMACHeader)
+	}
+	sig := match[re.SubexpIndex("signature")]
+
+	var ts string
+	if i := re.SubexpIndex("timestamp"); i >= 0 {
+		ts = match[i]
+	} else if w.metadata.HMACTimestampHeader != "" {
+		ts = strings.TrimSpace(header.Get(w.metadata.HMACTimestampHeader))
+	}
+	if ts != "" || w.metadata.HMACMaxAge > 0 || strings.Contains(w.metadata.HMACFormat, hmacPlaceholderTimestamp) {
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid or missing timestamp", errSignatureMismatch)
+		}
+		if w.metadata.HMACMaxAge > 0 {
+			age := time.Since(time.Unix(sec, 0))
+			if age > w.metadata.HMACMaxAge || age < -w.metadata.HMACMaxAge {
+				return fmt.Errorf("%w: timestamp is outside of hmacMaxAge", errSignatureMismatch)
+			}
+		}
+	}
+
+	var newHash func() hash.Hash
+	switch w.metadata.HMACAlgorithm {
+	case hmacAlgorithmSHA1:
+		newHash = sha1.New
+	default:
+		newHash = sha256.New
+	}
+	mac := hmac.New(newHash, []byte(w.metadata.HMACSecret))
+	prefix, suffix, _ := strings.Cut(w.metadata.HMACFormat, hmacPlaceholderBody)
+	mac.Write([]byte(strings.ReplaceAll(prefix, hmacPlaceholderTimestamp, ts)))
+	mac.Write(body)
+	mac.Write([]byte(strings.ReplaceAll(suffix, hmacPlaceholderTimestamp, ts)))
+	expected := mac.Sum(nil)
+
+	actual, err := hex.DecodeString(sig)
+	if err != nil {
+		actual, err = base64.StdEncoding.DecodeString(sig)
+		if err != nil {
+			return fmt.Errorf("%w: signature is not hex or base64-encoded", errSignatureMismatch)
+		}
+	}
+	if !hmac.Equal(expected, actual) {
+		return errSignatureMismatch
+	}
+	return nil
+}
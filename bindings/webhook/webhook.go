@@ -0,0 +1,223 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/contenttype"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/kit/logger"
+)
+
+// Maximum time to wait for in-flight requests when the binding is closed
+const shutdownTimeout = 10 * time.Second
+
+// Webhook is an input binding that receives webhooks with an HTTP server, and delivers them to the app.
+type Webhook struct {
+	metadata webhookMetadata
+	logger   logger.Logger
+	closed   atomic.Bool
+	closeCh  chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewWebhook returns a new webhook input binding.
+func NewWebhook(logger logger.Logger) bindings.InputBinding {
+	return &Webhook{
+		logger:  logger,
+		closeCh: make(chan struct{}),
+	}
+}
+
+// Init performs metadata parsing.
+func (w *Webhook) Init(_ context.Context, meta bindings.Metadata) (err error) {
+	w.metadata, err = parseMetadata(meta.Properties)
+	if err != nil {
+		return fmt.Errorf("webhook binding: %w", err)
+	}
+	return nil
+}
+
+// Read starts an HTTP server on listenAddress that delivers incoming requests to the handler.
+// The server is shut down when the context is canceled or the binding is closed.
+func (w *Webhook) Read(ctx context.Context, handler bindings.Handler) error {
+	if w.closed.Load() {
+		return errors.New("binding is closed")
+	}
+
+	ln, err := net.Listen("tcp", w.metadata.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("webhook binding: failed to listen on %s: %w", w.metadata.ListenAddress, err)
+	}
+
+	// Stop when the binding is closed, canceling the requests being handled
+	ctx, cancel := context.WithCancel(ctx)
+	mux := http.NewServeMux()
+	mux.Handle(w.metadata.ListenPath, w.handler(handler))
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	w.logger.Infof("Listening for webhooks on %s%s", ln.Addr(), w.metadata.ListenPath)
+	w.wg.Add(2)
+	go func() {
+		defer w.wg.Done()
+		err := srv.Serve(ln)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			w.logger.Errorf("Webhook listener stopped: %v", err)
+		}
+	}()
+	go func() {
+		defer w.wg.Done()
+		select {
+		case <-ctx.Done():
+		case <-w.closeCh:
+		}
+		cancel()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer shutdownCancel()
+		err := srv.Shutdown(shutdownCtx)
+		if err != nil {
+			w.logger.Warnf("Failed to shut down the webhook listener: %v", err)
+		}
+	}()
+	return nil
+}
+
+// handler returns the handler that delivers requests to the app.
+// The request method, path, query string, and headers are passed as metadata; headers use their canonical names, with multiple values joined by ", ".
+// If hmacSecret is set, requests without a valid signature are rejected with status code 401, and aren't delivered to the app.
+// If the app returns an error, the response has status code 500; otherwise, it has status code 200 and contains the data returned by the app.
+func (w *Webhook) handler(handler bindings.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var reqBody io.Reader = r.Body
+		if w.metadata.maxBodySizeBytes > 0 {
+			reqBody = http.MaxBytesReader(rw, r.Body, w.metadata.maxBodySizeBytes)
+		}
+		body, err := io.ReadAll(reqBody)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(rw, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(rw, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		if w.metadata.HMACSecret != "" {
+			err = w.verifySignature(r.Header, body)
+			if err != nil {
+				w.logger.Warnf("Rejected webhook request %s %s: %v", r.Method, r.URL.Path, err)
+				http.Error(rw, "invalid signature", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		md := make(map[string]string, len(r.Header)+3)
+		for k, v := range r.Header {
+			md[k] = strings.Join(v, ", ")
+		}
+		md["method"] = r.Method
+		md["path"] = r.URL.Path
+		md["query"] = r.URL.RawQuery
+
+		if w.metadata.CloudEvents && !contenttype.IsCloudEventContentType(r.Header.Get("Content-Type")) {
+			body, err = w.cloudEvent(r, body)
+			if err != nil {
+				w.logger.Warnf("Failed to create CloudEvent for webhook request %s %s: %v", r.Method, r.URL.Path, err)
+				http.Error(rw, "failed to handle request", http.StatusInternalServerError)
+				return
+			}
+			md["Content-Type"] = contenttype.CloudEventContentType
+		}
+
+		res, err := handler(r.Context(), &bindings.ReadResponse{
+			Data:     body,
+			Metadata: md,
+		})
+		if err != nil {
+			w.logger.Warnf("Failed to handle webhook request %s %s: %v", r.Method, r.URL.Path, err)
+			http.Error(rw, "failed to handle request", http.StatusInternalServerError)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+		if len(res) > 0 {
+			_, _ = rw.Write(res)
+		}
+	})
+}
+
+// cloudEvent returns the CloudEvent that wraps the body of a request.
+// JSON bodies are embedded as data, binary ones as data_base64, and other bodies as strings.
+func (w *Webhook) cloudEvent(r *http.Request, body []byte) ([]byte, error) {
+	var id string
+	if w.metadata.CloudEventIDHeader != "" {
+		id = r.Header.Get(w.metadata.CloudEventIDHeader)
+	}
+	source := w.metadata.CloudEventSource
+	if source == "" {
+		source = r.URL.Path
+	}
+	eventType := w.metadata.CloudEventType
+	if w.metadata.CloudEventTypeHeader != "" && r.Header.Get(w.metadata.CloudEventTypeHeader) != "" {
+		eventType = r.Header.Get(w.metadata.CloudEventTypeHeader)
+	}
+
+	traceParent := r.Header.Get("traceparent")
+	ce := pubsub.NewCloudEventsEnvelope(id, source, eventType, "", "", "", r.Header.Get("Content-Type"), body, traceParent, r.Header.Get("tracestate"))
+	// The event isn't published to a topic
+	delete(ce, pubsub.TopicField)
+	delete(ce, pubsub.PubsubField)
+	if traceParent == "" {
+		delete(ce, pubsub.TraceIDField)
+		delete(ce, pubsub.TraceParentField)
+		delete(ce, pubsub.TraceStateField)
+	}
+	return json.Marshal(ce)
+}
+
+// Close stops the HTTP server, waiting for the requests being handled.
+func (w *Webhook) Close() error {
+	if w.closed.CompareAndSwap(false, true) {
+		close(w.closeCh)
+	}
+	w.wg.Wait()
+	return nil
+}
+
+// GetComponentMetadata returns the metadata of the component.
+func (w *Webhook) GetComponentMetadata() (metadataInfo metadata.MetadataMap) {
+	metadataStruct := webhookMetadata{}
+	metadata.GetMetadataInfoFromStructType(reflect.TypeOf(metadataStruct), &metadataInfo, metadata.BindingType)
+	return
+}
@@ -0,0 +1,346 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/metadata"
+	"github.com/dapr/kit/logger"
+)
+
+func TestRead(t *testing.T) {
+	addr := freeListenAddress(t)
+	b := NewWebhook(logger.NewLogger("test"))
+	err := b.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+		"listenAddress": addr,
+		"listenPath":    "/hooks/",
+		"maxBodySize":   "16",
+	}}})
+	require.NoError(t, err)
+
+	received := make(chan *bindings.ReadResponse, 10)
+	err = b.Read(context.Background(), func(ctx context.Context, res *bindings.ReadResponse) ([]byte, error) {
+		received <- res
+		if string(res.Data) == "fail" {
+			return nil, errors.New("handler failed")
+		}
+		return []byte("ack"), nil
+	})
+	require.NoError(t, err)
+	baseURL := "http://" + addr
+
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/hooks/github?delivery=1", strings.NewReader(`{"action":"op`))
+	require.NoError(t, err)
+	req.Header.Set("X-GitHub-Event", "pull_request")
+	req.Header.Add("X-Multi", "a")
+	req.Header.Add("X-Multi", "b")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ack", string(body))
+
+	res := <-received
+	assert.Equal(t, `{"action":"op`, string(res.Data))
+	assert.Equal(t, "POST", res.Metadata["method"])
+	assert.Equal(t, "/hooks/github", res.Metadata["path"])
+	assert.Equal(t, "delivery=1", res.Metadata["query"])
+	assert.Equal(t, "pull_request", res.Metadata["X-Github-Event"])
+	assert.Equal(t, "a, b", res.Metadata["X-Multi"])
+
+	t.Run("handler error", func(t *testing.T) {
+		resp, err := http.Post(baseURL+"/hooks/", "text/plain", strings.NewReader("fail"))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		<-received
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		resp, err := http.Post(baseURL+"/hooks/", "text/plain", strings.NewReader(strings.Repeat("x", 17)))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	t.Run("other paths", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/other")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	// Closing stops the listener
+	require.NoError(t, b.Close())
+	_, err = http.Get(baseURL + "/hooks/")
+	require.Error(t, err)
+	assert.Empty(t, received)
+
+	t.Run("no body size limit", func(t *testing.T) {
+		u, received := startTestBinding(t, map[string]string{"maxBodySize": "0"})
+		resp, err := http.Post(u, "text/plain", strings.NewReader(strings.Repeat("x", 5<<20)))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Len(t, (<-received).Data, 5<<20)
+	})
+
+	t.Run("invalid listenPath", func(t *testing.T) {
+		b := NewWebhook(logger.NewLogger("test"))
+		err := b.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{
+			"listenAddress": "127.0.0.1:0",
+			"listenPath":    "hooks",
+		}}})
+		require.ErrorContains(t, err, "invalid value for listenPath")
+	})
+
+	t.Run("missing listenAddress", func(t *testing.T) {
+		b := NewWebhook(logger.NewLogger("test"))
+		err := b.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: map[string]string{}}})
+		require.ErrorContains(t, err, "missing listenAddress")
+	})
+}
+
+// freeListenAddress returns a local address with a port that is not in use.
+func freeListenAddress(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return addr
+}
+
+// startTestBinding starts the binding, and returns its URL and the channel of events delivered to the app.
+func startTestBinding(t *testing.T, props map[string]string) (string, chan *bindings.ReadResponse) {
+	t.Helper()
+
+	addr := freeListenAddress(t)
+	props["listenAddress"] = addr
+	b := NewWebhook(logger.NewLogger("test"))
+	err := b.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: props}})
+	require.NoError(t, err)
+	t.Cleanup(func() { b.Close() })
+
+	received := make(chan *bindings.ReadResponse, 10)
+	err = b.Read(context.Background(), func(ctx context.Context, res *bindings.ReadResponse) ([]byte, error) {
+		received <- res
+		return nil, nil
+	})
+	require.NoError(t, err)
+	return "http://" + addr, received
+}
+
+func TestSignature(t *testing.T) {
+	const secret = "s3cr3t"
+	sign := func(msg string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(msg))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	post := func(t *testing.T, u string, body string, headers map[string]string) int {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, u, strings.NewReader(body))
+		require.NoError(t, err)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	t.Run("GitHub", func(t *testing.T) {
+		u, received := startTestBinding(t, map[string]string{
+			"hmacSecret":          secret,
+			"hmacHeader":          "X-Hub-Signature-256",
+			"hmacSignatureFormat": "sha256={signature}",
+		})
+
+		status := post(t, u, `{"action":"opened"}`, map[string]string{"X-Hub-Signature-256": "sha256=" + sign(`{"action":"opened"}`)})
+		assert.Equal(t, http.StatusOK, status)
+		res := <-received
+		assert.Equal(t, `{"action":"opened"}`, string(res.Data))
+
+		status = post(t, u, `{"action":"closed"}`, map[string]string{"X-Hub-Signature-256": "sha256=" + sign(`{"action":"opened"}`)})
+		assert.Equal(t, http.StatusUnauthorized, status)
+		status = post(t, u, `{"action":"opened"}`, map[string]string{"X-Hub-Signature-256": sign(`{"action":"opened"}`)})
+		assert.Equal(t, http.StatusUnauthorized, status)
+		status = post(t, u, `{"action":"opened"}`, nil)
+		assert.Equal(t, http.StatusUnauthorized, status)
+		assert.Empty(t, received)
+	})
+
+	t.Run("Stripe", func(t *testing.T) {
+		u, received := startTestBinding(t, map[string]string{
+			"hmacSecret":          secret,
+			"hmacHeader":          "Stripe-Signature",
+			"hmacFormat":          "timestamp.body",
+			"hmacSignatureFormat": "t={timestamp},v1={signature}",
+			"hmacMaxAge":          "5m",
+		})
+
+		status := post(t, u, "payload", map[string]string{"Stripe-Signature": "t=" + now + ",v1=" + sign(now+".payload") + ",v0=ignored"})
+		assert.Equal(t, http.StatusOK, status)
+		<-received
+
+		// The signed timestamp is too old
+		status = post(t, u, "payload", map[string]string{"Stripe-Signature": "t=" + old + ",v1=" + sign(old+".payload")})
+		assert.Equal(t, http.StatusUnauthorized, status)
+		// The timestamp isn't the signed one
+		status = post(t, u, "payload", map[string]string{"Stripe-Signature": "t=" + now + ",v1=" + sign(old+".payload")})
+		assert.Equal(t, http.StatusUnauthorized, status)
+		assert.Empty(t, received)
+	})
+
+	t.Run("Slack", func(t *testing.T) {
+		u, received := startTestBinding(t, map[string]string{
+			"hmacSecret":          secret,
+			"hmacHeader":          "X-Slack-Signature",
+			"hmacFormat":          "v0:{timestamp}:{body}",
+			"hmacSignatureFormat": "v0={signature}",
+			"hmacTimestampHeader": "X-Slack-Request-Timestamp",
+			"hmacMaxAge":          "5m",
+		})
+
+		status := post(t, u, "token=x", map[string]string{
+			"X-Slack-Signature":         "v0=" + sign("v0:"+now+":token=x"),
+			"X-Slack-Request-Timestamp": now,
+		})
+		assert.Equal(t, http.StatusOK, status)
+		<-received
+
+		status = post(t, u, "token=x", map[string]string{"X-Slack-Signature": "v0=" + sign("v0:"+now+":token=x")})
+		assert.Equal(t, http.StatusUnauthorized, status)
+		assert.Empty(t, received)
+	})
+
+	t.Run("base64 signature with SHA-1", func(t *testing.T) {
+		u, received := startTestBinding(t, map[string]string{
+			"hmacSecret":    secret,
+			"hmacAlgorithm": "SHA1",
+		})
+
+		mac := hmac.New(sha1.New, []byte(secret))
+		mac.Write([]byte("payload"))
+		status := post(t, u, "payload", map[string]string{"X-Signature": base64.StdEncoding.EncodeToString(mac.Sum(nil))})
+		assert.Equal(t, http.StatusOK, status)
+		<-received
+	})
+
+	t.Run("invalid component metadata", func(t *testing.T) {
+		for name, props := range map[string]map[string]string{
+			"algorithm":                 {"hmacAlgorithm": "md5"},
+			"format without body":       {"hmacFormat": "{timestamp}"},
+			"signature format":          {"hmacSignatureFormat": "sha256="},
+			"timestamp without source":  {"hmacFormat": "timestamp.body"},
+			"max age without timestamp": {"hmacMaxAge": "5m"},
+			"negative max age":          {"hmacMaxAge": "-5m", "hmacTimestampHeader": "X-Timestamp"},
+			"signature format twice":    {"hmacSignatureFormat": "{signature},{signature}"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				props["hmacSecret"] = secret
+				props["listenAddress"] = "127.0.0.1:0"
+				b := NewWebhook(logger.NewLogger("test"))
+				err := b.Init(context.Background(), bindings.Metadata{Base: metadata.Base{Properties: props}})
+				require.ErrorContains(t, err, "hmac")
+			})
+		}
+	})
+}
+
+func TestCloudEvents(t *testing.T) {
+	u, received := startTestBinding(t, map[string]string{
+		"cloudEvents":          "true",
+		"cloudEventTypeHeader": "X-GitHub-Event",
+		"cloudEventIdHeader":   "X-GitHub-Delivery",
+	})
+	post := func(t *testing.T, body string, headers map[string]string) *bindings.ReadResponse {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, u+"/github", strings.NewReader(body))
+		require.NoError(t, err)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return <-received
+	}
+
+	t.Run("JSON body", func(t *testing.T) {
+		res := post(t, `{"action":"opened","number":9007199254740993}`, map[string]string{
+			"Content-Type":      "application/json",
+			"X-GitHub-Event":    "pull_request",
+			"X-GitHub-Delivery": "delivery-1",
+		})
+		assert.Equal(t, "application/cloudevents+json", res.Metadata["Content-Type"])
+		assert.Equal(t, "pull_request", res.Metadata["X-Github-Event"])
+
+		var ce map[string]any
+		require.NoError(t, json.Unmarshal(res.Data, &ce))
+		assert.Equal(t, "delivery-1", ce["id"])
+		assert.Equal(t, "1.0", ce["specversion"])
+		assert.Equal(t, "/github", ce["source"])
+		assert.Equal(t, "pull_request", ce["type"])
+		assert.Equal(t, "application/json", ce["datacontenttype"])
+		assert.NotEmpty(t, ce["time"])
+		assert.NotContains(t, ce, "topic")
+		assert.NotContains(t, ce, "traceparent")
+		assert.Equal(t, map[string]any{"action": "opened", "number": float64(9007199254740993)}, ce["data"])
+		// Large numbers are preserved
+		assert.Contains(t, string(res.Data), `"number":9007199254740993`)
+	})
+
+	t.Run("text body with default type", func(t *testing.T) {
+		res := post(t, "hello", map[string]string{"Content-Type": "text/plain"})
+
+		var ce map[string]any
+		require.NoError(t, json.Unmarshal(res.Data, &ce))
+		assert.NotEmpty(t, ce["id"])
+		assert.Equal(t, defaultCloudEventType, ce["type"])
+		assert.Equal(t, "hello", ce["data"])
+	})
+
+	t.Run("CloudEvents are delivered as-is", func(t *testing.T) {
+		event := `{"specversion":"1.0","id":"1","source":"test","type":"test","data":"x"}`
+		res := post(t, event, map[string]string{"Content-Type": "application/cloudevents+json"})
+		assert.Equal(t, event, string(res.Data))
+	})
+}
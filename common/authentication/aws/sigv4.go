@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+// Value of the X-Amz-Content-Sha256 header for streamed bodies, which can't be hashed before they are sent.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// sigV4Transport is a http.RoundTripper that signs every request with AWS Signature Version 4.
+// Requests are signed when they are sent, so retried requests get a fresh signature.
+type sigV4Transport struct {
+	next    http.RoundTripper
+	signer  *v4.Signer
+	region  string
+	service string
+}
+
+// NewSigV4Transport returns a http.RoundTripper that signs requests for the AWS service and region with the credentials, before passing them to next.
+func NewSigV4Transport(next http.RoundTripper, creds *credentials.Credentials, region string, service string) http.RoundTripper {
+	return &sigV4Transport{
+		next: next,
+		signer: v4.NewSigner(creds, func(s *v4.Signer) {
+			// The body is only read by the signer to hash it, and sent unchanged
+			s.DisableRequestBodyOverwrite = true
+		}),
+		region:  region,
+		service: service,
+	}
+}
+
+func (t *sigV4Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it's given
+	signed := req.Clone(req.Context())
+
+	var body io.ReadSeeker
+	switch {
+	case req.Body == nil || req.Body == http.NoBody:
+		// Nothing to hash
+	case req.GetBody != nil:
+		// Bodies that can be sent again are read from a copy, so the payload can be hashed
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		if rs, ok := rc.(io.ReadSeeker); ok {
+			// Files are hashed without loading them in memory
+			body = rs
+			break
+		}
+		b, err := io.ReadAll(rc)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	default:
+		signed.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	}
+
+	_, err := t.signer.Sign(signed, body, t.service, t.region, time.Now())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+	return t.next.RoundTrip(signed)
+}
+
+func (t *sigV4Transport) CloseIdleConnections() {
+	if c, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
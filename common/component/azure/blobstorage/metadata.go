@@ -18,6 +18,7 @@ import (
 	"strconv"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
 
 	azauth "github.com/dapr/components-contrib/common/authentication/azure"
 	mdutils "github.com/dapr/components-contrib/metadata"
@@ -28,7 +29,8 @@ type BlobStorageMetadata struct {
 	ContainerClientOpts     `json:",inline" mapstructure:",squash"`
 	DecodeBase64            bool `json:"decodeBase64,string" mapstructure:"decodeBase64" mdonly:"bindings"`
 	PublicAccessLevel       azblob.PublicAccessType
-	DisableEntityManagement bool `json:"disableEntityManagement,string" mapstructure:"disableEntityManagement"`
+	DisableEntityManagement bool  `json:"disableEntityManagement,string" mapstructure:"disableEntityManagement"`
+	BlockSize               int64 `json:"blockSize,string" mapstructure:"blockSize" mdonly:"bindings"`
 }
 
 type ContainerClientOpts struct {
@@ -82,6 +84,10 @@ func parseMetadata(meta map[string]string) (*BlobStorageMetadata, error) {
 			m.PublicAccessLevel, azblob.PossiblePublicAccessTypeValues())
 	}
 
+	if m.BlockSize < 0 || m.BlockSize > blockblob.MaxStageBlockBytes {
+		return nil, fmt.Errorf("invalid blockSize: %d; must be between 1 and %d bytes", m.BlockSize, int64(blockblob.MaxStageBlockBytes))
+	}
+
 	// we need this key for backwards compatibility
 	if val, ok := meta["getBlobRetryCount"]; ok && val != "" {
 		// convert val from string to int32
@@ -77,4 +77,18 @@ func TestParseMetadata(t *testing.T) {
 		_, err := parseMetadata(m)
 		require.Error(t, err)
 	})
+	t.Run("parse metadata with blockSize", func(t *testing.T) {
+		m = map[string]string{
+			"storageAccount": "account",
+			"container":      "test",
+			"blockSize":      "8388608",
+		}
+		meta, err := parseMetadata(m)
+		require.NoError(t, err)
+		assert.Equal(t, int64(8*1024*1024), meta.BlockSize)
+
+		m["blockSize"] = "-1"
+		_, err = parseMetadata(m)
+		require.ErrorContains(t, err, "invalid blockSize")
+	})
 }
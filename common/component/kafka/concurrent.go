@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/IBM/sarama"
+)
+
+// Number of messages that can be queued for each worker, so a slow message doesn't immediately block the messages with other keys.
+const concurrentQueueSize = 16
+
+// trackedMessage is a message of a partition that is being processed concurrently.
+type trackedMessage struct {
+	message   *sarama.ConsumerMessage
+	done      bool
+	processed bool
+}
+
+// offsetTracker marks the messages of a partition in offset order, as they are processed concurrently.
+// A message is marked only once all previous messages have been processed, so the committed offset never skips an unprocessed message.
+type offsetTracker struct {
+	session sarama.ConsumerGroupSession
+	lock    sync.Mutex
+	pending []*trackedMessage
+}
+
+func (t *offsetTracker) add(message *sarama.ConsumerMessage) *trackedMessage {
+	tm := &trackedMessage{message: message}
+	t.lock.Lock()
+	t.pending = append(t.pending, tm)
+	t.lock.Unlock()
+	return tm
+}
+
+// done records that the message was handled, and marks it with the other messages that are now contiguous.
+// As when messages are processed sequentially, messages whose handler failed are not marked, but don't prevent later messages from being marked.
+func (t *offsetTracker) done(tm *trackedMessage, processed bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	tm.done = true
+	tm.processed = processed
+	for len(t.pending) > 0 && t.pending[0].done {
+		if t.pending[0].processed {
+			t.session.MarkMessage(t.pending[0].message, "")
+		}
+		t.pending[0] = nil
+		t.pending = t.pending[1:]
+	}
+}
+
+// consumeConcurrently processes the messages of the claim with the given number of workers.
+// Messages are assigned to workers by key, so messages with the same key (including messages without a key) are processed in order.
+func (consumer *consumer) consumeConcurrently(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim, concurrency int) error {
+	tracker := &offsetTracker{session: session}
+	queues := make([]chan *trackedMessage, concurrency)
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan *trackedMessage, concurrentQueueSize)
+		wg.Add(1)
+		go func(queue <-chan *trackedMessage) {
+			defer wg.Done()
+			b := consumer.k.backOffConfig.NewBackOffWithContext(session.Context())
+			for tm := range queue {
+				// Queued messages are dropped when the session ends, and are delivered again to the next consumer
+				if session.Context().Err() != nil {
+					continue
+				}
+				err := consumer.processMessage(session, tm.message, b)
+				tracker.done(tm, err == nil)
+			}
+		}(queues[i])
+	}
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+		wg.Wait()
+	}()
+
+	for {
+		select {
+		case message, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+
+			queue := queues[workerIndex(message.Key, concurrency)]
+			select {
+			case queue <- tracker.add(message):
+			case <-session.Context().Done():
+				return nil
+			}
+		// Should return when `session.Context()` is done, as in ConsumeClaim.
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+// workerIndex returns the index of the worker that processes messages with the key.
+func workerIndex(key []byte, concurrency int) int {
+	h := fnv.New32a()
+	h.Write(key)
+	return int(h.Sum32() % uint32(concurrency))
+}
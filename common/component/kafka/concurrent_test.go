@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/kit/logger"
+)
+
+type testSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	lock   sync.Mutex
+	marked []int64
+}
+
+func (s *testSession) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.lock.Lock()
+	s.marked = append(s.marked, msg.Offset)
+	s.lock.Unlock()
+}
+
+func (s *testSession) markedOffsets() []int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]int64(nil), s.marked...)
+}
+
+type testClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *testClaim) Topic() string {
+	return "topic"
+}
+
+func (c *testClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
+
+func TestOffsetTracker(t *testing.T) {
+	session := &testSession{ctx: context.Background()}
+	tracker := &offsetTracker{session: session}
+	msgs := make([]*trackedMessage, 4)
+	for i := range msgs {
+		msgs[i] = tracker.add(&sarama.ConsumerMessage{Offset: int64(i)})
+	}
+
+	// Messages are marked only when all previous messages are done
+	tracker.done(msgs[2], true)
+	tracker.done(msgs[1], false)
+	assert.Empty(t, session.markedOffsets())
+
+	tracker.done(msgs[0], true)
+	assert.Equal(t, []int64{0, 2}, session.markedOffsets())
+
+	tracker.done(msgs[3], true)
+	assert.Equal(t, []int64{0, 2, 3}, session.markedOffsets())
+	assert.Empty(t, tracker.pending)
+}
+
+func TestConsumeConcurrently(t *testing.T) {
+	const count = 20
+
+	var (
+		lock      sync.Mutex
+		processed = map[string][]int64{}
+		release   = make(chan struct{})
+	)
+	handler := func(ctx context.Context, event *NewEvent) error {
+		key := event.Metadata[keyMetadataKey]
+		// Messages with key "slow" wait, while the messages with other keys are processed by other workers
+		if key == "slow" {
+			<-release
+		}
+		offset, _ := strconv.ParseInt(event.Metadata[offsetMetadataKey], 10, 64)
+		lock.Lock()
+		processed[key] = append(processed[key], offset)
+		lock.Unlock()
+		if offset == 5 {
+			return errors.New("handler error")
+		}
+		return nil
+	}
+
+	k := &Kafka{
+		logger: logger.NewLogger("test"),
+		subscribeTopics: TopicHandlerConfig{
+			"topic": SubscriptionHandlerConfig{Handler: handler, Concurrency: 4},
+		},
+	}
+	c := &consumer{k: k}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	session := &testSession{ctx: ctx}
+	claim := &testClaim{messages: make(chan *sarama.ConsumerMessage, count)}
+	keys := []string{"slow", "b", "c", "d"}
+	for i := 0; i < count; i++ {
+		claim.messages <- &sarama.ConsumerMessage{
+			Topic:  "topic",
+			Key:    []byte(keys[i%len(keys)]),
+			Value:  []byte("data"),
+			Offset: int64(i),
+		}
+	}
+	close(claim.messages)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.ConsumeClaim(session, claim)
+	}()
+
+	// The messages with other keys are processed, but offsets are not marked past the first slow message
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(processed["b"])+len(processed["c"])+len(processed["d"]) == count*3/4
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, session.markedOffsets())
+
+	close(release)
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for ConsumeClaim")
+	}
+
+	// Messages with the same key are processed in order
+	for _, key := range keys {
+		offsets := processed[key]
+		assert.Len(t, offsets, count/len(keys))
+		assert.IsIncreasing(t, offsets)
+	}
+
+	// All messages are marked in order, except the one that failed
+	marked := session.markedOffsets()
+	assert.Len(t, marked, count-1)
+	assert.IsIncreasing(t, marked)
+	assert.NotContains(t, marked, int64(5))
+}
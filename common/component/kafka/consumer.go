@@ -64,6 +64,8 @@ func (consumer *consumer) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 				consumer.mutex.Unlock()
 			}
 		}
+	} else if handlerConfig.Concurrency > 1 {
+		return consumer.consumeConcurrently(session, claim, handlerConfig.Concurrency)
 	} else {
 		for {
 			select {
@@ -72,21 +74,8 @@ func (consumer *consumer) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 					return nil
 				}
 
-				if consumer.k.consumeRetryEnabled {
-					if err := retry.NotifyRecover(func() error {
-						return consumer.doCallback(session, message)
-					}, b, func(err error, d time.Duration) {
-						consumer.k.logger.Warnf("Error processing Kafka message: %s/%d/%d [key=%s]. Error: %v. Retrying...", message.Topic, message.Partition, message.Offset, asBase64String(message.Key), err)
-					}, func() {
-						consumer.k.logger.Infof("Successfully processed Kafka message after it previously failed: %s/%d/%d [key=%s]", message.Topic, message.Partition, message.Offset, asBase64String(message.Key))
-					}); err != nil {
-						consumer.k.logger.Errorf("Too many failed attempts at processing Kafka message: %s/%d/%d [key=%s]. Error: %v.", message.Topic, message.Partition, message.Offset, asBase64String(message.Key), err)
-					}
-				} else {
-					err := consumer.doCallback(session, message)
-					if err != nil {
-						consumer.k.logger.Errorf("Error processing Kafka message: %s/%d/%d [key=%s]. Error: %v.", message.Topic, message.Partition, message.Offset, asBase64String(message.Key), err)
-					}
+				if consumer.processMessage(session, message, b) == nil {
+					session.MarkMessage(message, "")
 				}
 			// Should return when `session.Context()` is done.
 			// If not, will raise `ErrRebalanceInProgress` or `read tcp <ip>:<port>: i/o timeout` when kafka rebalance. see:
@@ -98,6 +87,30 @@ func (consumer *consumer) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 	}
 }
 
+// processMessage invokes the handler for the message, retrying if consumeRetryEnabled is set.
+// The message is not marked, so callers can mark messages in order.
+func (consumer *consumer) processMessage(session sarama.ConsumerGroupSession, message *sarama.ConsumerMessage, b backoff.BackOff) error {
+	if consumer.k.consumeRetryEnabled {
+		err := retry.NotifyRecover(func() error {
+			return consumer.doCallback(session, message)
+		}, b, func(err error, d time.Duration) {
+			consumer.k.logger.Warnf("Error processing Kafka message: %s/%d/%d [key=%s]. Error: %v. Retrying...", message.Topic, message.Partition, message.Offset, asBase64String(message.Key), err)
+		}, func() {
+			consumer.k.logger.Infof("Successfully processed Kafka message after it previously failed: %s/%d/%d [key=%s]", message.Topic, message.Partition, message.Offset, asBase64String(message.Key))
+		})
+		if err != nil {
+			consumer.k.logger.Errorf("Too many failed attempts at processing Kafka message: %s/%d/%d [key=%s]. Error: %v.", message.Topic, message.Partition, message.Offset, asBase64String(message.Key), err)
+		}
+		return err
+	}
+
+	err := consumer.doCallback(session, message)
+	if err != nil {
+		consumer.k.logger.Errorf("Error processing Kafka message: %s/%d/%d [key=%s]. Error: %v.", message.Topic, message.Partition, message.Offset, asBase64String(message.Key), err)
+	}
+	return err
+}
+
 func (consumer *consumer) flushBulkMessages(claim sarama.ConsumerGroupClaim,
 	messages []*sarama.ConsumerMessage, session sarama.ConsumerGroupSession,
 	handler BulkEventHandler, b backoff.BackOff,
@@ -194,11 +207,7 @@ func (consumer *consumer) doCallback(session sarama.ConsumerGroupSession, messag
 	}
 	event.Metadata = GetEventMetadata(message)
 
-	err = handlerConfig.Handler(session.Context(), &event)
-	if err == nil {
-		session.MarkMessage(message, "")
-	}
-	return err
+	return handlerConfig.Handler(session.Context(), &event)
 }
 
 func GetEventMetadata(message *sarama.ConsumerMessage) map[string]string {
@@ -406,6 +406,9 @@ type SubscriptionHandlerConfig struct {
 	BulkHandler     BulkEventHandler
 	Handler         EventHandler
 	ValueSchemaType SchemaType
+	// Number of messages of a partition that are processed concurrently when not using bulk subscribe.
+	// Messages with the same key are always processed in order.
+	Concurrency int
 }
 
 // NewEvent is an event arriving from a message bus instance.
@@ -36,6 +36,7 @@ import (
 	b_kafka "github.com/dapr/components-contrib/bindings/kafka"
 	b_kubemq "github.com/dapr/components-contrib/bindings/kubemq"
 	b_mqtt3 "github.com/dapr/components-contrib/bindings/mqtt3"
+	b_mqtt5 "github.com/dapr/components-contrib/bindings/mqtt5"
 	b_postgres "github.com/dapr/components-contrib/bindings/postgres"
 	b_rabbitmq "github.com/dapr/components-contrib/bindings/rabbitmq"
 	b_redis "github.com/dapr/components-contrib/bindings/redis"
@@ -96,6 +97,8 @@ func loadOutputBindings(name string) bindings.OutputBinding {
 		return b_influx.NewInflux(testLogger)
 	case "mqtt3":
 		return b_mqtt3.NewMQTT(testLogger)
+	case "mqtt5":
+		return b_mqtt5.NewMQTT(testLogger)
 	case "rabbitmq":
 		return b_rabbitmq.NewRabbitMQ(testLogger)
 	case "kubemq":
@@ -129,6 +132,8 @@ func loadInputBindings(name string) bindings.InputBinding {
 		return b_kafka.NewKafka(testLogger)
 	case "mqtt3":
 		return b_mqtt3.NewMQTT(testLogger)
+	case "mqtt5":
+		return b_mqtt5.NewMQTT(testLogger)
 	case "rabbitmq":
 		return b_rabbitmq.NewRabbitMQ(testLogger)
 	case "kubemq":